@@ -4,15 +4,21 @@ package pulumiyaml
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/pulumi/pulumi/pkg/v3/codegen"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
@@ -69,6 +75,7 @@ type typeCache struct {
 	resources     map[*ast.ResourceDecl]schema.Type
 	configuration map[string]schema.Type
 	outputs       map[string]schema.Type
+	outputTypes   map[string]ctypes.Type
 	exprs         map[ast.Expr]schema.Type
 	resourceNames map[string]*ast.ResourceDecl
 	variableNames map[string]ast.Expr
@@ -87,6 +94,8 @@ type notAssignable struct {
 	property string
 	errRange *hcl.Range
 	summary  string
+	// fix optionally replaces the text at fault with text that is assignable.
+	fix *replacement
 }
 
 func (n *notAssignable) Summary() string {
@@ -154,6 +163,27 @@ func (n *notAssignable) Range() *hcl.Range {
 	return nil
 }
 
+// Fix returns the replacement that resolves n, if a single one of its reasons has a fix. A
+// misspelled property is also reported as missing, which renaming it resolves too.
+func (n *notAssignable) Fix() *replacement {
+	if n == nil {
+		return nil
+	}
+	if n.fix != nil {
+		return n.fix
+	}
+	var fix *replacement
+	for _, b := range n.because {
+		if f := b.Fix(); f != nil {
+			if fix != nil {
+				return nil
+			}
+			fix = f
+		}
+	}
+	return fix
+}
+
 func (n *notAssignable) WithRange(r *hcl.Range) *notAssignable {
 	if n == nil {
 		return nil
@@ -327,6 +357,25 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 		if !ok {
 			return fail
 		}
+		// When we have the list expression, we check each element against the element type
+		// directly so that the values of enums are checked, and errors are reported against the
+		// offending element.
+		if list, ok := fromExpr.(*ast.ListExpr); ok {
+			failures := []*notAssignable{}
+			for i, elem := range list.Elements {
+				if _, ok := tc.exprs[elem]; !ok {
+					continue
+				}
+				notOk := tc.isAssignable(elem, to.ElementType)
+				if notOk != nil {
+					if notOk.Range() == nil {
+						notOk = notOk.WithRange(elem.Syntax().Syntax().Range())
+					}
+					failures = append(failures, notOk.Property(fmt.Sprintf("[%d]", i)))
+				}
+			}
+			return okIf(len(failures) == 0).Because(failures...)
+		}
 		return okIfAssignable(isAssignable(from.ElementType, to.ElementType))
 	case *schema.MapType:
 		switch from := from.(type) {
@@ -340,6 +389,24 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 				// map trivially
 				return okIf(true)
 			}
+			// When we have the object expression, we check each value against the element
+			// type directly so errors are reported against the offending entry.
+			if obj, ok := fromExpr.(*ast.ObjectExpr); ok {
+				failures := []*notAssignable{}
+				for _, entry := range obj.Entries {
+					key, ok := entry.Key.(*ast.StringExpr)
+					if !ok {
+						continue
+					}
+					notOk := tc.isAssignable(entry.Value, to.ElementType).
+						WithRange(entry.Value.Syntax().Syntax().Range()).
+						Property(key.GetValue())
+					if notOk != nil {
+						failures = append(failures, notOk)
+					}
+				}
+				return okIf(len(failures) == 0).Because(failures...)
+			}
 			for _, prop := range from.Properties {
 				notOk := isAssignable(prop.Type, to.ElementType)
 				if notOk != nil {
@@ -358,7 +425,7 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 		if notAssignable != nil {
 			return fail
 		}
-		return okIfAssignable(hasValidEnumValue(fromExpr, to.Elements))
+		return okIfAssignable(hasValidEnumValue(fromExpr, to))
 	case *schema.ObjectType:
 		// We implement structural typing for objects.
 		from, ok := from.(*schema.ObjectType)
@@ -382,7 +449,12 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 			primeMap()
 			if objMap != nil {
 				if kv, ok := objMap[prop]; ok {
-					return tc.isAssignable(kv.Value, to).WithRange(kv.Value.Syntax().Syntax().Range())
+					notAssignable := tc.isAssignable(kv.Value, to)
+					if notAssignable.Range() != nil {
+						// Keep the more precise range reported for a nested value.
+						return notAssignable
+					}
+					return notAssignable.WithRange(kv.Value.Syntax().Syntax().Range())
 				}
 			}
 			return isAssignable(from, to).WithRange(fromExpr.Syntax().Syntax().Range())
@@ -422,18 +494,26 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 				}
 				primeMap()
 				loc := fromExpr.Syntax().Syntax().Range()
+				var fix *replacement
 				if objMap != nil {
 					if v, ok := objMap[prop.Name]; ok {
 						r := v.Key.Syntax().Syntax().Range()
 						if r != nil {
 							loc = r
 						}
+						// A misspelled property is renamed, unless that would duplicate a key.
+						if closest, ok := fmtr.Closest(prop.Name, 2); ok {
+							if _, set := objMap[closest]; !set {
+								fix = &replacement{expr: v.Key, text: closest}
+							}
+						}
 					}
 				}
 				summary, detail := fmtr.MessageWithDetail(prop.Name, fmt.Sprintf("Property %s", prop.Name))
 				f := (&notAssignable{
 					reason:  detail,
 					summary: summary,
+					fix:     fix,
 				}).WithRange(loc)
 				failures = append(failures, f)
 			}
@@ -454,7 +534,7 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 	}
 }
 
-// hasValidEnumValue checks that `from` is a member of `to.map(.Value)`. This check only
+// hasValidEnumValue checks that `from` is a member of `enum.Elements.map(.Value)`. This check only
 // applies to constant types. All other types are let through error free. It is intended
 // that check takes place after a traditional type check on the underlying enum type.
 //
@@ -464,7 +544,8 @@ func (tc *typeCache) isAssignable(fromExpr ast.Expr, to schema.Type) *notAssigna
 //
 // If a type cast fails, it means that the schema is invalid. In that case an internal
 // error is returned.
-func hasValidEnumValue(from ast.Expr, to []*schema.Enum) *notAssignable {
+func hasValidEnumValue(from ast.Expr, enum *schema.EnumType) *notAssignable {
+	to := enum.Elements
 	var errRange *hcl.Range
 	if node := from.Syntax(); node != nil {
 		if syntax := node.Syntax(); syntax != nil {
@@ -529,9 +610,17 @@ func hasValidEnumValue(from ast.Expr, to []*schema.Enum) *notAssignable {
 	}
 	allowed := fmt.Sprintf("Allowed values are %s", strings.Join(valueList, ", "))
 
+	var value string
+	switch from := from.(type) {
+	case *ast.StringExpr:
+		value = strconv.Quote(from.Value)
+	case *ast.NumberExpr:
+		value = strconv.FormatFloat(from.Value, 'f', -1, 64)
+	}
 	return &notAssignable{
 		reason:   allowed,
 		errRange: errRange,
+		summary:  fmt.Sprintf("%s is not an allowed value of %s", value, displayType(enum)),
 	}
 }
 
@@ -576,23 +665,47 @@ func (tc *typeCache) assertTypeAssignable(ctx *evalContext, from ast.Expr, to sc
 	if s := result.Summary(); s != "" {
 		summary = s
 	}
-	ctx.addErrDiag(rng, summary, result.String())
+	diag := syntax.Error(rng, summary, result.String())
+	if fix := result.Fix(); fix != nil {
+		suggestReplacement(diag, fix.expr, fix.text)
+	}
+	ctx.addDiag(diag)
 }
 
 func (tc *typeCache) typeResource(r *Runner, node resourceNode) bool {
 	k, v := node.Key.Value, node.Value
 	ctx := r.newContext(node)
+	checkTransforms(ctx, v.Options.Transforms)
 	version, err := ParseVersion(v.Options.Version)
 	if err != nil {
 		ctx.error(v.Type, fmt.Sprintf("unable to parse resource %v provider version: %v", k, err))
 		return true
 	}
+	if expandsManifests(ctx, v) {
+		tc.typeManifestResource(ctx, node)
+		return true
+	}
 	pkg, typ, err := ResolveResource(context.TODO(), ctx.pkgLoader, ctx.packageDescriptors, v.Type.Value, version)
 	if err != nil {
-		ctx.error(v.Type, fmt.Sprintf("error resolving type of resource %v: %v", k, err))
+		migration, complete := helmChartMigration(k, v)
+		diag := ast.ExprError(v.Type, fmt.Sprintf("error resolving type of resource %v: %v", k, err), migration)
+		if complete {
+			// The properties that carry over have the same names, so only the type changes.
+			suggestReplacement(diag, v.Type, helmReleaseType)
+		}
+		ctx.addDiag(diag)
 		return true
 	}
+	ctx.checkIndexExpansion(v.Type, typ.String())
 	hint := pkg.ResourceTypeHint(typ)
+	if hint == nil || hint.Resource == nil {
+		// Such as a provider whose schema fails to bind. Its properties can't be checked.
+		diag := ast.ExprError(v.Type, fmt.Sprintf("unable to load the schema of resource %v of type %v", k, typ), "")
+		ctx.addDiag(diag)
+		return true
+	}
+	ctx.checkDeprecatedResource(v.Type, typ.String(), hint.Resource)
+	ctx.checkDeprecatedProperties(typ.String(), propertyMapEntries(v.Properties), hint.Resource.InputProperties)
 	var allProperties []string
 	for _, prop := range hint.Resource.InputProperties {
 		allProperties = append(allProperties, prop.Name)
@@ -620,10 +733,25 @@ func (tc *typeCache) typeResource(r *Runner, node resourceNode) bool {
 	// 1. They exist, or
 	// 2. The resource doesn't have a `Get` field (catching missing properties)
 	if resourceHasProperties || !resourceIsGet {
-		tc.typePropertyEntries(ctx, k, typ.String(), fmtr, v.Properties.Entries, hint.Resource.InputProperties)
+		inputProps := hint.Resource.InputProperties
+		if v.Options.Import != nil {
+			// Read-only properties of imported resources are reported by checkImportProperties.
+			inputProps = withReadOnlyProperties(hint.Resource)
+		}
+		tc.typePropertyEntries(ctx, k, typ.String(), fmtr, v.Properties.Entries, inputProps)
+	}
+
+	if v.ForEach != nil {
+		forEach := tc.exprs[v.ForEach]
+		checkLoopInstanceNames(ctx, node, forEach)
+		tc.registerResource(k, node.Value, typeLoop(ctx, v.ForEach, forEach, hint))
+	} else {
+		tc.registerResource(k, node.Value, hint)
 	}
 
-	tc.registerResource(k, node.Value, hint)
+	if v.Condition != nil {
+		tc.assertTypeAssignable(ctx, v.Condition, schema.BoolType)
+	}
 
 	if v.Get.Id != nil {
 		tc.assertTypeAssignable(ctx, v.Get.Id, schema.StringType)
@@ -648,6 +776,33 @@ func (tc *typeCache) typeResource(r *Runner, node resourceNode) bool {
 	}
 	tc.typePropertyEntries(ctx, k, typ.String(), fmtr, v.Get.State.Entries, stateProps)
 
+	if v.Options.Import != nil {
+		tc.assertTypeAssignable(ctx, v.Options.Import, schema.StringType)
+		checkImportProperties(ctx, k, v.Properties.Entries, hint.Resource)
+	}
+
+	for _, alias := range v.Options.Aliases.GetElements() {
+		checkAlias(ctx, pkg, typ, alias)
+	}
+
+	if v.Options.DeletedWith != nil {
+		tc.checkDeletedWith(ctx, v.Options.DeletedWith)
+	}
+
+	if v.Options.IgnoreChanges != nil && hint.Resource != nil {
+		checkIgnoreChanges(ctx, typ.String(), v.Options.IgnoreChanges, allProperties)
+	}
+
+	if v.Options.AdditionalSecretOutputs != nil {
+		checkAdditionalSecretOutputs(ctx, typ.String(), v.Options.AdditionalSecretOutputs, statePropNames)
+	}
+
+	if ct := v.Options.CustomTimeouts; ct != nil {
+		typeCustomTimeout(ctx, "create", ct.Create)
+		typeCustomTimeout(ctx, "update", ct.Update)
+		typeCustomTimeout(ctx, "delete", ct.Delete)
+	}
+
 	// Check for extra fields that didn't make it into the resource or resource options object
 	options := ResourceOptionsTypeHint()
 	allOptions := make([]string, 0, len(options))
@@ -725,6 +880,198 @@ func (tc *typeCache) typeResource(r *Runner, node resourceNode) bool {
 	return true
 }
 
+// checkImportProperties warns about properties of an imported resource that are read-only: outputs
+// of the resource that aren't inputs. The provider sets them, so the import fails if they are set.
+func checkImportProperties(ctx *evalContext, resourceName string, entries []ast.PropertyMapEntry, res *schema.Resource) {
+	inputs := map[string]bool{}
+	for _, prop := range res.InputProperties {
+		inputs[prop.Name] = true
+	}
+	outputs := map[string]bool{}
+	for _, prop := range res.Properties {
+		outputs[prop.Name] = true
+	}
+	for _, entry := range entries {
+		name := entry.Key.Value
+		if inputs[name] || !outputs[name] {
+			continue
+		}
+		var rng *hcl.Range
+		if s := entry.Key.Syntax(); s != nil {
+			rng = s.Syntax().Range()
+		}
+		ctx.addWarnDiag(rng,
+			fmt.Sprintf("property %q of imported resource %q is read-only", name, resourceName),
+			"It is an output of the resource that the provider sets, so the import will fail if it is set")
+	}
+}
+
+// withReadOnlyProperties returns the input properties of res, followed by its read-only properties
+// as optional properties.
+func withReadOnlyProperties(res *schema.Resource) []*schema.Property {
+	inputs := map[string]bool{}
+	for _, prop := range res.InputProperties {
+		inputs[prop.Name] = true
+	}
+	props := slices.Clone(res.InputProperties)
+	for _, prop := range res.Properties {
+		if inputs[prop.Name] {
+			continue
+		}
+		p := *prop
+		if p.IsRequired() {
+			p.Type = &schema.OptionalType{ElementType: p.Type}
+		}
+		props = append(props, &p)
+	}
+	return props
+}
+
+// checkAlias ensures that an alias either is a URN or describes how the resource has changed, and
+// that any old type token it gives is well formed.
+func checkAlias(ctx *evalContext, pkg Package, typ ResourceTypeToken, alias *ast.AliasDecl) {
+	var rng *hcl.Range
+	if s := alias.Syntax(); s != nil {
+		rng = s.Syntax().Range()
+	}
+	hasParts := alias.Name != nil || alias.Type != nil || alias.Project != nil || alias.Stack != nil
+	if alias.Urn != nil && hasParts {
+		ctx.addErrDiag(rng,
+			"an alias must either be a URN or list the name, type, project or stack the resource used to have",
+			"")
+		return
+	}
+	if alias.Urn == nil && !hasParts {
+		ctx.addErrDiag(rng,
+			"an alias must specify at least one of name, type, project or stack", "")
+		return
+	}
+	if alias.Urn != nil {
+		// The engine only rejects a malformed URN once the resource is registered.
+		if _, err := resource.ParseURN(alias.Urn.Value); err != nil {
+			ctx.error(alias.Urn, fmt.Sprintf("invalid alias URN %q: %v", alias.Urn.Value, err))
+		}
+	}
+	if alias.Type != nil {
+		if _, err := resolveAliasType(pkg, typ, alias.Type.Value); err != nil {
+			ctx.error(alias.Type, fmt.Sprintf("invalid alias type: %v", err))
+		}
+	}
+}
+
+// checkDeletedWith ensures that the deletedWith option of a resource refers to another resource in
+// the template. References to missing names are reported when the template is sorted.
+func (tc *typeCache) checkDeletedWith(ctx *evalContext, deletedWith ast.Expr) {
+	sym, ok := deletedWith.(*ast.SymbolExpr)
+	if !ok || len(sym.Property.Accessors) != 1 {
+		diag := ast.ExprError(deletedWith, "the 'deletedWith' option must be a reference to a resource",
+			"Use a reference such as ${myResource}")
+		ctx.addDiag(diag)
+		return
+	}
+	name := sym.Property.RootName()
+	if _, isResource := tc.resourceNames[name]; isResource {
+		return
+	}
+	if _, isVariable := tc.variableNames[name]; isVariable {
+		// Variables may evaluate to resources, which is checked at runtime.
+		return
+	}
+	if _, isConfig := tc.configuration[name]; isConfig || name == PulumiVarName {
+		ctx.error(deletedWith, fmt.Sprintf("%q in the 'deletedWith' option is not a resource", name))
+	}
+}
+
+// checkIgnoreChanges warns about ignoreChanges paths whose leading property is not an input
+// property of the resource. Only the leading property is checked: nested and array paths are
+// passed to the provider as is.
+func checkIgnoreChanges(ctx *evalContext, resourceType string, ignoreChanges *ast.StringListDecl, properties []string) {
+	fmtr := yamldiags.NonExistentFieldFormatter{
+		ParentLabel:         fmt.Sprintf("Resource %s", resourceType),
+		Fields:              properties,
+		MaxElements:         5,
+		FieldsAreProperties: true,
+	}
+	for _, elem := range ignoreChanges.Elements {
+		if elem == nil {
+			continue
+		}
+		var rng *hcl.Range
+		if s := elem.Syntax(); s != nil {
+			rng = s.Syntax().Range()
+		}
+		path, err := resource.ParsePropertyPath(elem.Value)
+		if err != nil {
+			ctx.addWarnDiag(rng, fmt.Sprintf("invalid ignoreChanges path %q", elem.Value), err.Error())
+			continue
+		}
+		if len(path) == 0 {
+			continue
+		}
+		name, ok := path[0].(string)
+		if !ok || name == "*" {
+			continue
+		}
+		known := false
+		for _, prop := range properties {
+			if prop == name {
+				known = true
+				break
+			}
+		}
+		if known {
+			continue
+		}
+		summary, detail := fmtr.MessageWithDetail(name, fmt.Sprintf("Property %s", name))
+		diag := syntax.Warning(rng, summary, detail)
+		if closest, ok := fmtr.Closest(name, 2); ok {
+			diag.Detail = fmt.Sprintf("Did you mean '%s'? %s", closest, detail)
+			if len(path) == 1 {
+				suggestReplacement(diag, elem, closest)
+			}
+		}
+		ctx.addDiag(diag)
+	}
+}
+
+// checkAdditionalSecretOutputs reports additionalSecretOutputs that are not output properties of
+// the resource. Unlike ignoreChanges, an unknown name is an error: the engine ignores it, so a
+// misspelled name would leave the output it was meant to protect in plaintext.
+func checkAdditionalSecretOutputs(ctx *evalContext, resourceType string, names *ast.StringListDecl, properties []string) {
+	fmtr := yamldiags.NonExistentFieldFormatter{
+		ParentLabel:         fmt.Sprintf("Resource %s", resourceType),
+		Fields:              properties,
+		MaxElements:         5,
+		FieldsAreProperties: true,
+	}
+	for _, elem := range names.Elements {
+		if elem == nil || slices.Contains(properties, elem.Value) {
+			continue
+		}
+		summary, detail := fmtr.MessageWithDetail(elem.Value, fmt.Sprintf("Output %s", elem.Value))
+		diag := ast.ExprError(elem, summary, detail)
+		if closest, ok := fmtr.Closest(elem.Value, 2); ok {
+			diag.Detail = fmt.Sprintf("Did you mean '%s'? %s", closest, detail)
+			suggestReplacement(diag, elem, closest)
+		}
+		ctx.addDiag(diag)
+	}
+}
+
+// typeCustomTimeout checks that a custom timeout is a valid Go duration string, such as "30m" or
+// "1h30m". The engine parses timeouts the same way, so a malformed value would otherwise only be
+// reported once the resource is registered.
+func typeCustomTimeout(ctx *evalContext, operation string, expr *ast.StringExpr) {
+	if expr == nil {
+		return
+	}
+	if _, err := time.ParseDuration(expr.Value); err != nil {
+		diag := ast.ExprError(expr, fmt.Sprintf("invalid %s timeout %q", operation, expr.Value),
+			`Custom timeouts must be durations such as "30s", "5m" or "1h30m"`)
+		ctx.addDiag(diag)
+	}
+}
+
 func (tc *typeCache) typePropertyEntries(ctx *evalContext, resourceName, resourceType string, fmtr yamldiags.NonExistentFieldFormatter, entries []ast.PropertyMapEntry, props []*schema.Property) {
 	to := &schema.ObjectType{
 		Token:      resourceType,
@@ -759,57 +1106,30 @@ func (tc *typeCache) typePropertyEntries(ctx *evalContext, resourceName, resourc
 }
 
 func (tc *typeCache) typeInvoke(ctx *evalContext, t *ast.InvokeExpr) bool {
+	if len(t.Assign) > 0 {
+		// The assigned variables are only declared for invokes that are the value of a variable.
+		if v, ok := ctx.root.(variableNode); !ok || v.Value != t {
+			ctx.error(t, "the assign directive can only be used when fn::invoke is the value of a variable")
+		}
+	}
 	version, err := ParseVersion(t.CallOpts.Version)
 	if err != nil {
 		ctx.error(t.CallOpts.Version, fmt.Sprintf("unable to parse function provider version: %v", err))
 		return true
 	}
+	if t.TokenRef != nil {
+		return tc.typeDynamicInvoke(ctx, t)
+	}
 	pkg, functionName, err := ResolveFunction(context.TODO(), ctx.pkgLoader, ctx.packageDescriptors, t.Token.Value, version)
 	if err != nil {
 		_, b := ctx.error(t, err.Error())
 		return b
 	}
-	var existing []string
+	ctx.checkIndexExpansion(t.Token, functionName.String())
 	hint := pkg.FunctionTypeHint(functionName)
-	inputs := map[string]schema.Type{}
-	if hint.Inputs != nil {
-		for _, input := range hint.Inputs.Properties {
-			existing = append(existing, input.Name)
-			inputs[input.Name] = input.Type
-		}
-	}
-	fmtr := yamldiags.NonExistentFieldFormatter{
-		ParentLabel: fmt.Sprintf("Invoke %s", functionName.String()),
-		Fields:      existing,
-		MaxElements: 5,
-	}
-	if t.CallArgs != nil {
-		for _, prop := range t.CallArgs.Entries {
-			k := prop.Key.(*ast.StringExpr).Value
-			if typ, ok := inputs[k]; !ok {
-				summary, detail := fmtr.MessageWithDetail(k, k)
-				subject := prop.Key.Syntax().Syntax().Range()
-				ctx.addWarnDiag(subject, summary, detail)
-			} else {
-				tc.exprs[prop.Value] = typ
-			}
-		}
-	}
-	if t.CallOpts.Parent != nil {
-		tc.typeExpr(ctx, t.CallOpts.Parent)
-	}
-	if t.CallOpts.Provider != nil {
-		tc.typeExpr(ctx, t.CallOpts.Provider)
-	}
-	if t.CallOpts.Version != nil {
-		tc.typeExpr(ctx, t.CallOpts.Version)
-	}
-	if t.CallOpts.PluginDownloadURL != nil {
-		tc.typeExpr(ctx, t.CallOpts.PluginDownloadURL)
-	}
-	if t.CallOpts.DependsOn != nil {
-		tc.typeExpr(ctx, t.CallOpts.DependsOn)
-	}
+	ctx.checkDeprecatedFunction(t.Token, functionName.String(), hint)
+	tc.typeInvokeArgs(ctx, t, functionName.String(), hint.Inputs)
+	tc.typeInvokeOptions(ctx, t, ResolvePkgName(t.Token.Value))
 	if t.Return != nil {
 		fields := []string{}
 		var (
@@ -843,6 +1163,209 @@ func (tc *typeCache) typeInvoke(ctx *evalContext, t *ast.InvokeExpr) bool {
 	return true
 }
 
+// typeInvokeArgs checks the arguments of the invoke t of the function fn against the function's
+// inputs. Missing required arguments and arguments of the wrong type are errors, as they are for
+// the properties of a resource. Arguments that aren't inputs of the function are only warned
+// about, since they may be accepted by a newer version of the provider.
+func (tc *typeCache) typeInvokeArgs(ctx *evalContext, t *ast.InvokeExpr, fn string, inputs *schema.ObjectType) {
+	to := &schema.ObjectType{Token: fn}
+	if inputs != nil {
+		to.Properties = inputs.Properties
+	}
+	fields := make([]string, len(to.Properties))
+	for i, prop := range to.Properties {
+		fields[i] = prop.Name
+	}
+	fmtr := yamldiags.NonExistentFieldFormatter{
+		ParentLabel: fmt.Sprintf("Invoke %s", fn),
+		Fields:      fields,
+		MaxElements: 5,
+	}
+
+	// Missing required arguments are reported at the arguments, or at the function if there are
+	// none.
+	node := syntax.ObjectSyntax(t.Token.Syntax().Syntax())
+	var entries []ast.ObjectProperty
+	if t.CallArgs != nil {
+		if n, ok := t.CallArgs.Syntax().(*syntax.ObjectNode); ok && n != nil {
+			node = n
+		}
+		entries = t.CallArgs.Entries
+		ctx.checkDeprecatedProperties(fn, entries, to.Properties)
+	}
+	set := map[string]bool{}
+	for _, entry := range entries {
+		if k, ok := entry.Key.(*ast.StringExpr); ok {
+			set[k.Value] = true
+		}
+	}
+
+	var known []ast.ObjectProperty
+	var knownProps []*schema.Property
+	for _, entry := range entries {
+		k, ok := entry.Key.(*ast.StringExpr)
+		if !ok {
+			continue
+		}
+		if _, ok := to.Property(k.Value); !ok {
+			summary, detail := fmtr.MessageWithDetail(k.Value, k.Value)
+			diag := syntax.Warning(k.Syntax().Syntax().Range(), summary, detail)
+			if closest, ok := fmtr.Closest(k.Value, 2); ok && !set[closest] {
+				suggestReplacement(diag, k, closest)
+			}
+			ctx.addDiag(diag)
+			continue
+		}
+		typ, ok := tc.exprs[entry.Value]
+		if !ok {
+			continue
+		}
+		known = append(known, entry)
+		knownProps = append(knownProps, &schema.Property{Name: k.Value, Type: typ})
+	}
+	args := ast.ObjectSyntax(node, known...)
+	tc.exprs[args] = &schema.ObjectType{Properties: knownProps}
+	tc.assertTypeAssignable(ctx, args, to)
+}
+
+// typeDynamicInvoke types an invoke whose function token is read from config. The token is only
+// known at runtime, so the arguments and return value can't be checked against the function's
+// schema.
+func (tc *typeCache) typeDynamicInvoke(ctx *evalContext, t *ast.InvokeExpr) bool {
+	root := t.TokenRef.Property.RootName()
+	if _, ok := tc.configuration[root]; !ok {
+		_, b := ctx.error(t.TokenRef, fmt.Sprintf("function name must be a string literal or a reference to a config value, but %q is not a config value", root))
+		return b
+	}
+	tc.assertTypeAssignable(ctx, t.TokenRef, schema.StringType)
+	ctx.addWarnDiag(t.TokenRef.Syntax().Syntax().Range(),
+		fmt.Sprintf("the function to invoke is read from config value %q at runtime", root),
+		"Its arguments and return value are not type checked.")
+	tc.typeInvokeOptions(ctx, t, "")
+	tc.exprs[t] = schema.AnyType
+	return true
+}
+
+// typeInvokeOptions checks the options of an invoke of a function in package pkgName. pkgName is
+// empty if the function is only known at runtime.
+func (tc *typeCache) typeInvokeOptions(ctx *evalContext, t *ast.InvokeExpr, pkgName string) {
+	if t.CallOpts.Parent != nil {
+		tc.typeExpr(ctx, t.CallOpts.Parent)
+		tc.checkInvokeParent(ctx, t.CallOpts.Parent)
+	}
+	if t.CallOpts.Provider != nil {
+		tc.typeExpr(ctx, t.CallOpts.Provider)
+		tc.checkInvokeProvider(ctx, t.CallOpts.Provider, pkgName)
+	}
+	if t.CallOpts.Version != nil {
+		tc.typeExpr(ctx, t.CallOpts.Version)
+	}
+	if t.CallOpts.PluginDownloadURL != nil {
+		tc.typeExpr(ctx, t.CallOpts.PluginDownloadURL)
+	}
+	if t.CallOpts.DependsOn != nil {
+		tc.typeExpr(ctx, t.CallOpts.DependsOn)
+	}
+	if t.CallOpts.Cache != nil {
+		tc.typeExpr(ctx, t.CallOpts.Cache)
+	}
+	if t.CallOpts.After != nil {
+		tc.checkInvokeAfter(ctx, t.CallOpts.After)
+	}
+}
+
+// checkInvokeProvider ensures that a resource passed as the provider of an invoke is a provider for
+// the invoke's package. Other expressions are checked at runtime.
+func (tc *typeCache) checkInvokeProvider(ctx *evalContext, provider ast.Expr, pkgName string) {
+	sym, ok := provider.(*ast.SymbolExpr)
+	if !ok || len(sym.Property.Accessors) != 1 {
+		return
+	}
+	name := sym.Property.RootName()
+	res, ok := tc.resourceNames[name]
+	if !ok || res.Type == nil {
+		return
+	}
+	rng := provider.Syntax().Syntax().Range()
+	typ := res.Type.Value
+	if !strings.HasPrefix(typ, "pulumi:providers:") {
+		detail := "The provider of an invoke must be a provider resource"
+		if pkgName != "" {
+			detail = fmt.Sprintf("The provider of an invoke must be a resource of type 'pulumi:providers:%s'", pkgName)
+		}
+		ctx.addErrDiag(rng, fmt.Sprintf("resource %q is not a provider", name), detail)
+		return
+	}
+	if providerPkg := ResolvePkgName(typ); pkgName != "" && providerPkg != pkgName {
+		ctx.addErrDiag(rng,
+			fmt.Sprintf("provider %q is for package %q, but the function is in package %q", name, providerPkg, pkgName), "")
+	}
+}
+
+// checkInvokeParent ensures that the parent of an invoke is a reference to a resource. A reference
+// to a variable is checked at runtime, since the variable may hold a resource.
+func (tc *typeCache) checkInvokeParent(ctx *evalContext, parent ast.Expr) {
+	rng := parent.Syntax().Syntax().Range()
+	sym, ok := parent.(*ast.SymbolExpr)
+	if !ok {
+		ctx.addErrDiag(rng, "the 'parent' option of an invoke must be a reference to a resource",
+			"Use a reference such as ${myResource}")
+		return
+	}
+	name := sym.Property.RootName()
+	if r, ok := tc.resourceNames[name]; ok {
+		accessors := sym.Property.Accessors
+		if r != nil && r.ForEach != nil {
+			// Each instance of a resource declared with forEach is a resource of its own.
+			if len(accessors) == 2 {
+				if _, ok := accessors[1].(*ast.PropertySubscript); ok {
+					return
+				}
+			}
+			ctx.addErrDiag(rng, "the 'parent' option of an invoke must be a reference to a resource",
+				fmt.Sprintf("%[1]s is declared with forEach, so use ${%[1]s[0]} or ${%[1]s[\"key\"]} to refer to "+
+					"one of its instances", name))
+			return
+		}
+		if len(accessors) != 1 {
+			ctx.addErrDiag(rng, "the 'parent' option of an invoke must be a reference to a resource",
+				fmt.Sprintf("Use ${%s} to refer to the resource itself, not one of its properties", name))
+		}
+		return
+	}
+	if _, ok := tc.configuration[name]; ok {
+		ctx.addErrDiag(rng, fmt.Sprintf("config %q can't be the parent of an invoke", name),
+			"The parent of an invoke must be a resource")
+	}
+}
+
+// checkInvokeAfter ensures that the 'after' option of an invoke is a list of references to
+// resources or variables.
+func (tc *typeCache) checkInvokeAfter(ctx *evalContext, after ast.Expr) {
+	list, ok := after.(*ast.ListExpr)
+	if !ok {
+		ctx.addErrDiag(after.Syntax().Syntax().Range(),
+			"the 'after' option must be a list of references to resources or variables", "")
+		return
+	}
+	for _, elem := range list.Elements {
+		sym, ok := elem.(*ast.SymbolExpr)
+		if !ok || len(sym.Property.Accessors) != 1 {
+			ctx.addErrDiag(elem.Syntax().Syntax().Range(),
+				"the 'after' option must only contain references to resources or variables",
+				"Use a reference such as ${myResource}")
+			continue
+		}
+		name := sym.Property.RootName()
+		_, isResource := tc.resourceNames[name]
+		_, isVariable := tc.variableNames[name]
+		if !isResource && !isVariable {
+			ctx.addErrDiag(elem.Syntax().Syntax().Range(),
+				fmt.Sprintf("%q in the 'after' option is not a resource or variable", name), "")
+		}
+	}
+}
+
 func (tc *typeCache) typeSymbol(ctx *evalContext, t *ast.SymbolExpr) bool {
 	var typ schema.Type = &schema.InvalidType{}
 	if root, ok := tc.resourceNames[t.Property.RootName()]; ok {
@@ -854,6 +1377,12 @@ func (tc *typeCache) typeSymbol(ctx *evalContext, t *ast.SymbolExpr) bool {
 	if root, ok := tc.configuration[t.Property.RootName()]; ok {
 		typ = root
 	}
+	if binding, ok := tc.typeLoopBinding(ctx, t); ok {
+		typ = binding
+	}
+	if binding, ok := tc.typeElementBinding(ctx, t); ok {
+		typ = binding
+	}
 	runningName := t.Property.RootName()
 	setError := func(summary, detail string) *schema.InvalidType {
 		diag := syntax.Error(t.Syntax().Syntax().Range(), summary, detail)
@@ -906,6 +1435,10 @@ func typePropertyAccess(ctx *evalContext, root schema.Type,
 
 		return &schema.UnionType{ElementTypes: possibilities.Values()}
 	}
+	if codegen.UnwrapType(root) == schema.AnyType {
+		// Dynamically typed values can't be checked, so any access is allowed.
+		return schema.AnyType
+	}
 	switch accessor := accessors[0].(type) {
 	case *ast.PropertyName:
 		properties := map[string]schema.Type{}
@@ -922,13 +1455,16 @@ func typePropertyAccess(ctx *evalContext, root schema.Type,
 				properties["id"] = schema.StringType
 			}
 			properties["urn"] = schema.StringType
+		case *schema.MapType:
+			// Maps have no fixed set of keys, so any key may be accessed.
+			return typePropertyAccess(ctx, root.ElementType, runningName+"."+accessor.Name, accessors[1:], setError)
 		case *schema.InvalidType:
 			return root
 		default:
 			return setError(
 				fmt.Sprintf("cannot access a property on '%s' (type %s)", runningName,
 					displayType(codegen.UnwrapType(root))),
-				"Property access is only allowed on Resources and Objects",
+				"Property access is only allowed on Resources, Objects and Maps",
 			)
 		}
 		// We handle the actual property access here
@@ -993,7 +1529,13 @@ func (tc *typeCache) typeExpr(ctx *evalContext, t ast.Expr) bool {
 		tc.exprs[t] = schema.NumberType
 	case *ast.BooleanExpr:
 		tc.exprs[t] = schema.BoolType
-	case *ast.AssetArchiveExpr, *ast.FileArchiveExpr, *ast.RemoteArchiveExpr:
+	case *ast.AssetArchiveExpr:
+		checkAssetArchivePaths(ctx, t)
+		tc.exprs[t] = schema.ArchiveType
+	case *ast.FileArchiveExpr, *ast.RemoteArchiveExpr:
+		tc.exprs[t] = schema.ArchiveType
+	case *ast.GlobAssetsExpr:
+		tc.assertTypeAssignable(ctx, t.Pattern, schema.StringType)
 		tc.exprs[t] = schema.ArchiveType
 	case *ast.FileAssetExpr, *ast.RemoteAssetExpr, *ast.StringAssetExpr:
 		tc.exprs[t] = schema.AssetType
@@ -1002,6 +1544,134 @@ func (tc *typeCache) typeExpr(ctx *evalContext, t ast.Expr) bool {
 		tc.exprs[t] = schema.StringType
 	case *ast.ToJSONExpr:
 		tc.exprs[t] = schema.StringType
+	case *ast.Base64GzipExpr:
+		tc.assertTypeAssignable(ctx, t.Value, schema.StringType)
+		tc.exprs[t] = schema.StringType
+	case *ast.SortExpr:
+		tc.typeListPreservingExpr(ctx, t, t.Value)
+	case *ast.UniqueExpr:
+		tc.typeListPreservingExpr(ctx, t, t.Value)
+	case *ast.FlattenExpr:
+		tc.typeFlatten(ctx, t)
+	case *ast.RangeExpr:
+		tc.assertTypeAssignable(ctx, t.Start, schema.IntType)
+		tc.assertTypeAssignable(ctx, t.End, schema.IntType)
+		if t.Step != nil {
+			tc.assertTypeAssignable(ctx, t.Step, schema.IntType)
+		}
+		tc.exprs[t] = &schema.ArrayType{ElementType: schema.IntType}
+	case *ast.NowExpr:
+		tc.exprs[t] = schema.StringType
+	case *ast.TimeAddExpr:
+		tc.assertTypeAssignable(ctx, t.Base, schema.StringType)
+		tc.assertTypeAssignable(ctx, t.Duration, schema.StringType)
+		if s, ok := t.Base.(*ast.StringExpr); ok {
+			if _, err := parseTime(s.Value); err != nil {
+				ctx.addErrDiag(s.Syntax().Syntax().Range(), err.Error(), "")
+			}
+		}
+		if s, ok := t.Duration.(*ast.StringExpr); ok {
+			if _, err := parseDuration(s.Value); err != nil {
+				ctx.addErrDiag(s.Syntax().Syntax().Range(), err.Error(), "")
+			}
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.EnvExpr:
+		tc.assertTypeAssignable(ctx, t.Variable, schema.StringType)
+		if t.Default != nil {
+			tc.assertTypeAssignable(ctx, t.Default, schema.StringType)
+		} else if name, ok := t.Variable.(*ast.StringExpr); ok {
+			if _, set := os.LookupEnv(name.Value); !set {
+				ctx.addWarnDiag(t.Syntax().Syntax().Range(),
+					fmt.Sprintf("environment variable %q is not set", name.Value),
+					"fn::env fails when the template runs unless the variable is set or fn::env is given a default")
+			}
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.StackConfigExpr:
+		tc.assertTypeAssignable(ctx, t.Key, schema.StringType)
+		if t.Default != nil {
+			tc.assertTypeAssignable(ctx, t.Default, schema.StringType)
+		}
+		if key, ok := t.Key.(*ast.StringExpr); ok {
+			name := key.Value
+			if ctx.Runner.t.Name != nil {
+				name = strings.TrimPrefix(name, ctx.Runner.t.Name.Value+":")
+			}
+			if findConfigDecl(ctx.Runner, name) != nil {
+				ctx.addWarnDiag(t.Syntax().Syntax().Range(),
+					fmt.Sprintf("config %q is declared by the template", name),
+					fmt.Sprintf("fn::stackConfig reads the stack's config as it is stored, ignoring the declaration's "+
+						"type, default and constraints; use ${%s} to get the declared value", name))
+			}
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.FormatExpr:
+		tc.assertTypeAssignable(ctx, t.Format, schema.StringType)
+		if f, ok := t.Format.(*ast.StringExpr); ok {
+			if _, verbs, err := parseFormat(f.Value); err != nil {
+				ctx.addErrDiag(t.Format.Syntax().Syntax().Range(), err.Error(), "")
+			} else if len(verbs) != len(t.Values) {
+				ctx.addErrDiag(t.Syntax().Syntax().Range(), formatArgsError(len(verbs), len(t.Values)).Error(), "")
+			}
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.TrimExpr:
+		tc.assertTypeAssignable(ctx, t.Value, schema.StringType)
+		if t.Cutset != nil {
+			tc.assertTypeAssignable(ctx, t.Cutset, schema.StringType)
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.UpperExpr:
+		tc.assertTypeAssignable(ctx, t.Value, schema.StringType)
+		tc.exprs[t] = schema.StringType
+	case *ast.LowerExpr:
+		tc.assertTypeAssignable(ctx, t.Value, schema.StringType)
+		tc.exprs[t] = schema.StringType
+	case *ast.TitleExpr:
+		tc.assertTypeAssignable(ctx, t.Value, schema.StringType)
+		tc.exprs[t] = schema.StringType
+	case *ast.ToStringExpr:
+		tc.typeConversion(ctx, t.Value, convertToString)
+		tc.exprs[t] = schema.StringType
+	case *ast.ToNumberExpr:
+		tc.typeConversion(ctx, t.Value, convertToNumber)
+		tc.exprs[t] = schema.NumberType
+	case *ast.ToBoolExpr:
+		tc.typeConversion(ctx, t.Value, convertToBool)
+		tc.exprs[t] = schema.BoolType
+	case *ast.UUIDExpr:
+		checkRandomScope(ctx, t)
+		tc.exprs[t] = schema.StringType
+	case *ast.RandomIDExpr:
+		checkRandomScope(ctx, t)
+		tc.assertTypeAssignable(ctx, t.ByteLength, schema.IntType)
+		tc.exprs[t] = schema.StringType
+	case *ast.ReadFileExpr:
+		tc.typeReadFile(ctx, t)
+	case *ast.EnvFileExpr:
+		tc.assertTypeAssignable(ctx, t.Path, schema.StringType)
+		tc.exprs[t] = &schema.MapType{ElementType: schema.StringType}
+	case *ast.FileHashExpr:
+		tc.assertTypeAssignable(ctx, t.Path, schema.StringType)
+		if t.Algorithm != nil {
+			tc.assertTypeAssignable(ctx, t.Algorithm, schema.StringType)
+			if s, ok := t.Algorithm.(*ast.StringExpr); ok {
+				if _, err := newFileHash(s.Value); err != nil {
+					ctx.addErrDiag(t.Algorithm.Syntax().Syntax().Range(), err.Error(), "")
+				}
+			}
+		}
+		tc.exprs[t] = schema.StringType
+	case *ast.CidrSubnetExpr:
+		tc.assertTypeAssignable(ctx, t.Prefix, schema.StringType)
+		tc.assertTypeAssignable(ctx, t.Newbits, schema.IntType)
+		tc.assertTypeAssignable(ctx, t.Netnum, schema.IntType)
+		tc.exprs[t] = schema.StringType
+	case *ast.CidrHostExpr:
+		tc.assertTypeAssignable(ctx, t.Prefix, schema.StringType)
+		tc.assertTypeAssignable(ctx, t.Hostnum, schema.IntType)
+		tc.exprs[t] = schema.StringType
 	case *ast.JoinExpr:
 		tc.assertTypeAssignable(ctx, t.Delimiter, schema.StringType)
 		tc.exprs[t] = schema.StringType
@@ -1047,10 +1717,67 @@ func (tc *typeCache) typeExpr(ctx *evalContext, t ast.Expr) bool {
 	case *ast.SecretExpr:
 		// The type of a secret is the type of its argument
 		tc.exprs[t] = tc.exprs[t.Value]
+	case *ast.WhenUnknownExpr:
+		if o, ok := ctx.root.(ast.PropertyMapEntry); !ok || o.Value != t {
+			ctx.error(t, "fn::whenUnknown can only be used as the value of an output")
+		}
+		tc.exprs[t] = tc.exprs[t.Value]
 	case *ast.SplitExpr:
 		tc.assertTypeAssignable(ctx, t.Delimiter, schema.StringType)
 		tc.assertTypeAssignable(ctx, t.Source, schema.StringType)
 		tc.exprs[t] = &schema.ArrayType{ElementType: schema.StringType}
+	case *ast.JSONPathExpr:
+		tc.assertTypeAssignable(ctx, t.JSON, schema.StringType)
+		tc.assertTypeAssignable(ctx, t.Path, schema.StringType)
+		if s, ok := t.Path.(*ast.StringExpr); ok {
+			if _, err := compileJSONPath(s.Value); err != nil {
+				ctx.addErrDiag(t.Path.Syntax().Syntax().Range(), err.Error(), "")
+			}
+		}
+		tc.exprs[t] = schema.AnyType
+	case *ast.LookupExpr:
+		tc.typeLookup(t)
+	case *ast.SemverSatisfiesExpr:
+		tc.typeSemverArg(ctx, t.Version, false)
+		tc.typeSemverArg(ctx, t.Constraint, true)
+		tc.exprs[t] = schema.BoolType
+	case *ast.SemverCompareExpr:
+		tc.typeSemverArg(ctx, t.Left, false)
+		tc.typeSemverArg(ctx, t.Right, false)
+		tc.exprs[t] = schema.IntType
+	case *ast.KeysExpr:
+		tc.exprs[t] = &schema.ArrayType{ElementType: schema.StringType}
+	case *ast.ValuesExpr:
+		tc.exprs[t] = &schema.ArrayType{ElementType: mapValueType(tc.exprs[t.Value])}
+	case ast.ElementExpr:
+		tc.typeElements(ctx, t)
+	case *ast.GetAttExpr:
+		tc.assertTypeAssignable(ctx, t.Attribute, schema.StringType)
+		tc.exprs[t] = schema.AnyType
+		switch typ := codegen.UnwrapType(tc.exprs[t.Resource]).(type) {
+		case *schema.ResourceType:
+			// An attribute that is written out is checked against the outputs of the resource, or
+			// of the component, like a reference to it.
+			if access := t.Access(); access != nil && tc.typeSymbol(ctx, access) {
+				tc.exprs[t] = tc.exprs[access]
+			}
+		case nil, *schema.InvalidType:
+		default:
+			if typ != schema.AnyType {
+				ctx.addErrDiag(t.Resource.Syntax().Syntax().Range(),
+					fmt.Sprintf("the first argument to fn::getAtt must be a resource, not %s", displayType(typ)), "")
+			}
+		}
+	case *ast.ResourceByURNExpr:
+		tc.assertTypeAssignable(ctx, t.URN, schema.StringType)
+		if urn, ok := t.URN.(*ast.StringExpr); ok {
+			if _, err := resource.ParseURN(urn.Value); err != nil {
+				ctx.addErrDiag(urn.Syntax().Syntax().Range(), err.Error(), "")
+			}
+		}
+		ctx.addHintDiag(t.Syntax().Syntax().Range(), "the outputs of a resource from fn::getResource are not type checked",
+			"The resource is read from the stack's state when the template runs, so its outputs may have any type")
+		tc.exprs[t] = schema.AnyType
 	case *ast.SelectExpr:
 		tc.assertTypeAssignable(ctx, t.Index, schema.IntType)
 		tc.assertTypeAssignable(ctx, t.Values,
@@ -1081,6 +1808,228 @@ func (tc *typeCache) typeExpr(ctx *evalContext, t ast.Expr) bool {
 	return true
 }
 
+// typeListPreservingExpr types a builtin that returns a list with the same element type as its
+// list argument.
+func (tc *typeCache) typeListPreservingExpr(ctx *evalContext, t, value ast.Expr) {
+	tc.assertTypeAssignable(ctx, value, &schema.ArrayType{ElementType: schema.AnyType})
+	if arr, ok := codegen.UnwrapType(tc.exprs[value]).(*schema.ArrayType); ok {
+		tc.exprs[t] = arr
+	} else {
+		tc.exprs[t] = &schema.ArrayType{ElementType: schema.AnyType}
+	}
+}
+
+func (tc *typeCache) typeFlatten(ctx *evalContext, t *ast.FlattenExpr) {
+	tc.assertTypeAssignable(ctx, t.Value, &schema.ArrayType{ElementType: schema.AnyType})
+	depth := 1
+	if t.Depth != nil {
+		tc.assertTypeAssignable(ctx, t.Depth, schema.IntType)
+		n, ok := t.Depth.(*ast.NumberExpr)
+		if !ok {
+			// The depth is only known at runtime, so we can't tell what the elements will be.
+			tc.exprs[t] = &schema.ArrayType{ElementType: schema.AnyType}
+			return
+		}
+		if n.Value < 0 {
+			ctx.addErrDiag(t.Depth.Syntax().Syntax().Range(),
+				fmt.Sprintf("depth must not be negative, got %v", n.Value), "")
+		}
+		depth = int(n.Value)
+	}
+	arr, ok := codegen.UnwrapType(tc.exprs[t.Value]).(*schema.ArrayType)
+	if !ok {
+		tc.exprs[t] = &schema.ArrayType{ElementType: schema.AnyType}
+		return
+	}
+	tc.exprs[t] = &schema.ArrayType{ElementType: flattenedElementType(arr.ElementType, depth)}
+}
+
+// mapValueType returns the type of the values of a map of type t. Objects are treated as maps of
+// the union of their properties' types.
+func mapValueType(t schema.Type) schema.Type {
+	switch t := codegen.UnwrapType(t).(type) {
+	case *schema.MapType:
+		return t.ElementType
+	case *schema.ObjectType:
+		var types OrderedTypeSet
+		for _, prop := range t.Properties {
+			types.Add(prop.Type)
+		}
+		switch types.Len() {
+		case 0:
+			return schema.AnyType
+		case 1:
+			return types.First()
+		default:
+			return &schema.UnionType{ElementTypes: types.Values()}
+		}
+	}
+	return schema.AnyType
+}
+
+// typeSemverArg checks an argument of fn::semverSatisfies or fn::semverCompare, which is a version
+// or, if isConstraint is set, a version constraint.
+func (tc *typeCache) typeSemverArg(ctx *evalContext, arg ast.Expr, isConstraint bool) {
+	tc.assertTypeAssignable(ctx, arg, schema.StringType)
+	s, ok := arg.(*ast.StringExpr)
+	if !ok {
+		return
+	}
+	var err error
+	if isConstraint {
+		_, err = parseSemverRange(s.Value)
+	} else {
+		_, err = parseSemver(s.Value)
+	}
+	if err != nil {
+		ctx.addErrDiag(arg.Syntax().Syntax().Range(), err.Error(), "")
+	}
+}
+
+// typeLookup types fn::lookup as the union of the type of the map's values and the default's type.
+func (tc *typeCache) typeLookup(t *ast.LookupExpr) {
+	var value schema.Type = schema.AnyType
+	switch m := codegen.UnwrapType(tc.exprs[t.Map]).(type) {
+	case *schema.MapType:
+		value = m.ElementType
+	case *schema.ArrayType:
+		value = m.ElementType
+	case *schema.ObjectType:
+		if key, ok := t.Key.(*ast.StringExpr); ok {
+			if prop, ok := m.Property(key.Value); ok {
+				value = prop.Type
+			}
+		}
+	}
+
+	var types OrderedTypeSet
+	types.Add(value)
+	types.Add(tc.exprs[t.Default])
+	if types.Len() == 1 {
+		tc.exprs[t] = types.First()
+		return
+	}
+	tc.exprs[t] = &schema.UnionType{ElementTypes: types.Values()}
+}
+
+// typeConversion reports arguments of fn::toString, fn::toNumber or fn::toBool that are written
+// out in the template, and that convert can't convert.
+func (tc *typeCache) typeConversion(ctx *evalContext, value ast.Expr, convert func(interface{}) (interface{}, error)) {
+	var literal interface{}
+	switch v := value.(type) {
+	case *ast.StringExpr:
+		literal = v.Value
+	case *ast.NumberExpr:
+		literal = v.Value
+	case *ast.BooleanExpr:
+		literal = v.Value
+	case *ast.ListExpr:
+		literal = []interface{}{}
+	case *ast.ObjectExpr:
+		literal = map[string]interface{}{}
+	default:
+		return
+	}
+	if _, err := convert(literal); err != nil {
+		ctx.addErrDiag(value.Syntax().Syntax().Range(), err.Error(), "")
+	}
+}
+
+func (tc *typeCache) typeReadFile(ctx *evalContext, t *ast.ReadFileExpr) {
+	tc.assertTypeAssignable(ctx, t.Path, schema.StringType)
+	if t.Sha256 != nil {
+		tc.assertTypeAssignable(ctx, t.Sha256, schema.StringType)
+		if s, ok := t.Sha256.(*ast.StringExpr); ok && !isSha256Hex(s.Value) {
+			ctx.addErrDiag(t.Sha256.Syntax().Syntax().Range(),
+				fmt.Sprintf("sha256 must be 64 hexadecimal characters, got %q", s.Value), "")
+		}
+	}
+	if t.Timeout != nil {
+		tc.assertTypeAssignable(ctx, t.Timeout, schema.StringType)
+		if s, ok := t.Timeout.(*ast.StringExpr); ok {
+			if _, err := parseReadFileTimeout(s.Value); err != nil {
+				ctx.addErrDiag(t.Timeout.Syntax().Syntax().Range(), err.Error(), "")
+			}
+		}
+	}
+	tc.exprs[t] = schema.StringType
+}
+
+// checkAssetArchivePaths reports paths in an fn::assetArchive that are absolute or outside of the
+// archive, and paths that refer to the same entry once normalized, such as ./a and a.
+func checkAssetArchivePaths(ctx *evalContext, t *ast.AssetArchiveExpr) {
+	obj, ok := t.Args().(*ast.ObjectExpr)
+	if !ok {
+		return
+	}
+	var normalized []string
+	keys := map[string][]*ast.StringExpr{}
+	for _, kvp := range obj.Entries {
+		key, ok := kvp.Key.(*ast.StringExpr)
+		if !ok {
+			continue
+		}
+		p := strings.ReplaceAll(key.Value, "\\", "/")
+		if path.IsAbs(p) || filepath.IsAbs(key.Value) || filepath.VolumeName(key.Value) != "" {
+			ctx.error(key, fmt.Sprintf("archive path %q must be relative", key.Value))
+			continue
+		}
+		p = path.Clean(p)
+		if p == ".." || strings.HasPrefix(p, "../") {
+			ctx.error(key, fmt.Sprintf("archive path %q must not refer to a parent directory", key.Value))
+			continue
+		}
+		if _, ok := keys[p]; !ok {
+			normalized = append(normalized, p)
+		}
+		keys[p] = append(keys[p], key)
+	}
+
+	for _, p := range normalized {
+		conflicting := keys[p]
+		if len(conflicting) < 2 {
+			continue
+		}
+		quoted := make([]string, len(conflicting))
+		for i, key := range conflicting {
+			quoted[i] = strconv.Quote(key.Value)
+		}
+		ctx.error(conflicting[1], fmt.Sprintf("archive paths %s all refer to %q",
+			strings.Join(quoted, ", "), p))
+	}
+}
+
+// flattenedElementType computes the element type of a list with element type typ once depth
+// levels of nesting have been removed.
+func flattenedElementType(typ schema.Type, depth int) schema.Type {
+	if depth <= 0 {
+		return typ
+	}
+	switch t := codegen.UnwrapType(typ).(type) {
+	case *schema.ArrayType:
+		return flattenedElementType(t.ElementType, depth-1)
+	case *schema.UnionType:
+		var types OrderedTypeSet
+		for _, elem := range t.ElementTypes {
+			elem = flattenedElementType(elem, depth)
+			// Empty nested lists don't contribute any elements.
+			if _, ok := elem.(*schema.InvalidType); !ok {
+				types.Add(elem)
+			}
+		}
+		switch types.Len() {
+		case 0:
+			return &schema.InvalidType{}
+		case 1:
+			return types.First()
+		default:
+			return &schema.UnionType{ElementTypes: types.Values()}
+		}
+	default:
+		return typ
+	}
+}
+
 func (tc *typeCache) typeVariable(r *Runner, node variableNode) bool {
 	k, v := node.Key.Value, node.Value
 	tc.variableNames[k] = v
@@ -1095,22 +2044,41 @@ func (tc *typeCache) typeConfig(r *Runner, node configNode) bool {
 	switch n := node.(type) {
 	case configNodeYaml:
 		v := n.Value
+		if v.HasConstraints() {
+			tc.typeConfigConstraints(r.newContext(node), k, v)
+		}
+		declared, diags := configDeclType(v)
+		if len(v.Properties.Entries) > 0 || v.Items != nil {
+			ctx := r.newContext(node)
+			for _, diag := range diags {
+				ctx.addDiag(diag)
+			}
+		}
 		switch {
+		case declared != nil && ctypes.IsStructured(declared):
+			typCurrent = declared.Schema()
+			optional = v.Default != nil
+			if d, ok := configLiteral(v.Default); ok {
+				if _, err := ctypes.Conform(declared, d); err != nil {
+					r.newContext(node).error(v.Default, configShapeError(k, err, v.Secret != nil && v.Secret.Value))
+				}
+			}
 		case v.Default != nil:
 			// We have a default, so the type is optional
 			typCurrent = tc.exprs[v.Default]
 			optional = true
-		case v.Type != nil:
-			ctype, ok := ctypes.Parse(v.Type.Value)
-			if ok {
-				typCurrent = ctype.Schema()
-			}
+		case declared != nil:
+			typCurrent = declared.Schema()
 		}
 	case configNodeProp:
 		ctype, ok := ctypes.Parse(n.v.TypeString())
 		if ok {
 			typCurrent = ctype.Schema()
 		}
+		if typ, ok := checkConfigPropShape(r, n); ok {
+			typCurrent = typ
+		}
+		checkConfigPropConstraints(r, n)
 	}
 
 	typCurrent = &schema.InputType{ElementType: typCurrent}
@@ -1131,6 +2099,134 @@ func (tc *typeCache) typeConfig(r *Runner, node configNode) bool {
 	return true
 }
 
+// typeConfigConstraints checks the constraints declared on the config entry c, and that its
+// default value satisfies them.
+func (tc *typeCache) typeConfigConstraints(ctx *evalContext, k string, c *ast.ConfigParamDecl) {
+	var typ ctypes.Type
+	defaultValue, hasDefault := configLiteral(c.Default)
+	if c.Type != nil {
+		typ, _ = ctypes.Parse(c.Type.Value)
+	} else if hasDefault {
+		typ, _ = ctypes.TypeValue(defaultValue)
+	}
+	diags := checkConfigConstraintDecls(c, typ)
+	if !diags.HasErrors() && hasDefault {
+		diags.Extend(checkConfigConstraints(k, c, defaultValue, c.Secret != nil && c.Secret.Value)...)
+	}
+	for _, diag := range diags {
+		ctx.addDiag(diag)
+	}
+}
+
+// findConfigDecl returns the template's declaration of the config entry with the given name, or
+// nil if there is none.
+func findConfigDecl(r *Runner, name string) *ast.ConfigParamDecl {
+	var decl *ast.ConfigParamDecl
+	for _, entry := range r.t.Configuration.Entries {
+		k := entry.Key.Value
+		if entry.Value != nil && entry.Value.Name != nil && entry.Value.Name.Value != "" {
+			k = entry.Value.Name.Value
+		}
+		if k == name {
+			decl = entry.Value
+		}
+	}
+	return decl
+}
+
+// configPropValue returns the value of a config entry from the stack's config, decoded according
+// to its declaration, and whether it holds secrets.
+func configPropValue(n configNodeProp, decl *ast.ConfigParamDecl) (interface{}, bool) {
+	v, secret := n.v, n.v.ContainsSecrets()
+	if v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	value := v.Mappable()
+	// Stack config may hold numbers, lists and objects as strings.
+	if s, ok := value.(string); ok && decl.Type != nil {
+		if typ, ok := ctypes.Parse(decl.Type.Value); ok && typ != ctypes.String {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(s), &parsed); err == nil {
+				value = parsed
+			}
+		}
+	}
+	return value, secret
+}
+
+// checkConfigPropShape checks that a value from the stack's config conforms to the Object or
+// List<Object> type declared for it in the template, and returns that type.
+func checkConfigPropShape(r *Runner, n configNodeProp) (schema.Type, bool) {
+	decl := findConfigDecl(r, n.k)
+	if decl == nil {
+		return nil, false
+	}
+	typ, _ := configDeclType(decl)
+	if typ == nil || !ctypes.IsStructured(typ) {
+		return nil, false
+	}
+	if !n.v.ContainsUnknowns() {
+		value, secret := configPropValue(n, decl)
+		if _, err := ctypes.Conform(typ, value); err != nil {
+			r.newContext(n).error(decl.Type, configShapeError(n.k, err, secret))
+		}
+	}
+	return typ.Schema(), true
+}
+
+// checkConfigPropConstraints checks a value from the stack's config against the constraints of its
+// declaration in the template. Unknown values are checked when the program runs.
+func checkConfigPropConstraints(r *Runner, n configNodeProp) {
+	decl := findConfigDecl(r, n.k)
+	if decl == nil || !decl.HasConstraints() || n.v.ContainsUnknowns() {
+		return
+	}
+
+	value, secret := configPropValue(n, decl)
+	ctx := r.newContext(n)
+	diags := checkConfigConstraints(n.k, decl, value, secret)
+	for _, diag := range diags {
+		ctx.addDiag(diag)
+	}
+}
+
+// configLiteral returns the value of a literal config default.
+func configLiteral(expr ast.Expr) (interface{}, bool) {
+	switch expr := expr.(type) {
+	case *ast.StringExpr:
+		return expr.Value, true
+	case *ast.NumberExpr:
+		return expr.Value, true
+	case *ast.BooleanExpr:
+		return expr.Value, true
+	case *ast.ListExpr:
+		values := make([]interface{}, len(expr.Elements))
+		for i, elem := range expr.Elements {
+			v, ok := configLiteral(elem)
+			if !ok {
+				return nil, false
+			}
+			values[i] = v
+		}
+		return values, true
+	case *ast.ObjectExpr:
+		values := make(map[string]interface{}, len(expr.Entries))
+		for _, entry := range expr.Entries {
+			k, ok := entry.Key.(*ast.StringExpr)
+			if !ok {
+				return nil, false
+			}
+			v, ok := configLiteral(entry.Value)
+			if !ok {
+				return nil, false
+			}
+			values[k.Value] = v
+		}
+		return values, true
+	}
+	return nil, false
+}
+
 func (tc *typeCache) typeMissing(r *Runner, node missingNode) bool {
 	ctx := r.newContext(node)
 	ctx.errorf(node.key(), "resource, variable, or config value %q not found", node.key().Value)
@@ -1166,11 +2262,47 @@ func isTypeCompatible(typeA, typeB schema.Type, valB interface{}) bool {
 	return false
 }
 
-func (tc *typeCache) typeOutput(r *Runner, node ast.PropertyMapEntry) bool {
-	tc.outputs[node.Key.Value] = tc.exprs[node.Value]
+func (tc *typeCache) typeOutput(ctx *evalContext, name string, expr ast.Expr) bool {
+	tc.outputs[name] = tc.exprs[expr]
+	if typ, ok := tc.outputTypes[name]; ok {
+		tc.assertTypeAssignable(ctx, expr, typ.Schema())
+	}
 	return true
 }
 
+// OutputTypes returns the types declared for a template's outputs in its outputTypes section,
+// keyed by output name. Outputs without a declared type are omitted, so tooling consuming the
+// stack's outputs can rely on these types where they exist.
+func OutputTypes(t *ast.TemplateDecl) (map[string]ctypes.Type, syntax.Diagnostics) {
+	var diags syntax.Diagnostics
+	outputs := map[string]struct{}{}
+	for _, kvp := range (&Runner{t: t}).outputs() {
+		outputs[kvp.Key.Value] = struct{}{}
+	}
+
+	types := map[string]ctypes.Type{}
+	for _, kvp := range t.OutputTypes.Entries {
+		name := kvp.Key.Value
+		if _, ok := outputs[name]; !ok {
+			diags.Extend(ast.ExprError(kvp.Key, fmt.Sprintf("outputTypes declares a type for %q, which is not an output", name), ""))
+			continue
+		}
+		s, ok := kvp.Value.(*ast.StringExpr)
+		if !ok {
+			diags.Extend(ast.ExprError(kvp.Value, fmt.Sprintf("the type of output %q must be a string", name), ""))
+			continue
+		}
+		typ, ok := ctypes.Parse(s.Value)
+		if !ok {
+			diags.Extend(ast.ExprError(kvp.Value, fmt.Sprintf("unexpected type '%s' for output %q: valid types are %s",
+				s.Value, name, ctypes.ConfigTypes), ""))
+			continue
+		}
+		types[name] = typ
+	}
+	return types, diags
+}
+
 func newTypeCache() *typeCache {
 	pulumiExpr := ast.Object(
 		ast.ObjectProperty{Key: ast.String("cwd")},
@@ -1204,22 +2336,75 @@ func TypeCheck(r *Runner) (Typing, syntax.Diagnostics) {
 	types := newTypeCache()
 
 	// Set roots
-	diags := r.Run(walker{
+	diags := checkOutputNames(r)
+	diags.Extend(checkLoopNames(r)...)
+	if warnUnused {
+		diags.Extend(checkUnused(r.t)...)
+	}
+	outputTypes, odiags := OutputTypes(r.t)
+	diags.Extend(odiags...)
+	types.outputTypes = outputTypes
+	diags.Extend(r.Run(walker{
 		VisitResource: types.typeResource,
 		VisitExpr:     types.typeExpr,
 		VisitVariable: types.typeVariable,
 		VisitConfig:   types.typeConfig,
 		VisitMissing:  types.typeMissing,
 		VisitOutput:   types.typeOutput,
-	})
+	})...)
 
 	return types, diags
 }
 
+// checkOutputNames ensures that output groups don't collide with other outputs once they are
+// flattened into dotted names.
+func checkOutputNames(r *Runner) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	groups := map[string]*ast.StringExpr{}
+	for _, group := range r.t.OutputGroups.Entries {
+		groups[group.Key.Value] = group.Key
+	}
+	seen := map[string]*ast.StringExpr{}
+	for _, kvp := range r.outputs() {
+		name := kvp.Key.Value
+		if group, ok := groups[name]; ok {
+			diags.Extend(ast.ExprError(kvp.Key, fmt.Sprintf("output %q conflicts with the output group %q", name, name), "").
+				WithRelated(exprRange(group), "The output group is declared here"))
+		}
+		if prev, ok := seen[name]; ok {
+			diags.Extend(ast.ExprError(kvp.Key, fmt.Sprintf("found duplicate output %q", name), "").
+				WithRelated(exprRange(prev), "The other output is declared here"))
+			continue
+		}
+		seen[name] = kvp.Key
+	}
+	return diags
+}
+
+// enclosingDecl returns the kind of declaration that ctx evaluates the expressions of, which is
+// one of "config", "variable", "resource" or "output", and the declaration's name. Walkers can use
+// it to tell which declaration an expression passed to VisitExpr belongs to. The kind is empty
+// if ctx isn't associated with a declaration.
+func (ctx *evalContext) enclosingDecl() (string, *ast.StringExpr) {
+	switch root := ctx.root.(type) {
+	case configNode:
+		return "config", root.key()
+	case variableNode:
+		return "variable", root.Key
+	case resourceNode:
+		return "resource", root.Key
+	case ast.PropertyMapEntry:
+		return "output", root.Key
+	}
+	return "", nil
+}
+
 type walker struct {
 	VisitConfig   func(r *Runner, node configNode) bool
 	VisitVariable func(r *Runner, node variableNode) bool
-	VisitOutput   func(r *Runner, node ast.PropertyMapEntry) bool
+	// VisitOutput is called once for each stack output, including those declared in output
+	// groups, which are named by their dotted paths.
+	VisitOutput   func(ctx *evalContext, name string, expr ast.Expr) bool
 	VisitResource func(r *Runner, node resourceNode) bool
 	VisitMissing  func(r *Runner, node missingNode) bool
 	VisitExpr     func(*evalContext, ast.Expr) bool
@@ -1247,6 +2432,28 @@ func (e walker) walk(ctx *evalContext, x ast.Expr) bool {
 			}
 		}
 	case *ast.InterpolateExpr, *ast.SymbolExpr:
+	case ast.ElementExpr:
+		if !e.walk(ctx, x.Name()) {
+			return false
+		}
+		for _, arg := range elementArgs(x) {
+			if arg != x.ElementBody() {
+				if !e.walk(ctx, arg) {
+					return false
+				}
+				continue
+			}
+			// The body is walked in the scope of the elements of the list.
+			ctx.elements = append(ctx.elements, x)
+			ok := e.walk(ctx, arg)
+			ctx.elements = ctx.elements[:len(ctx.elements)-1]
+			if !ok {
+				return false
+			}
+		}
+		if !e.VisitExpr(ctx, x.Args()) {
+			return false
+		}
 	case ast.BuiltinExpr:
 		if !e.walk(ctx, x.Name()) {
 			return false
@@ -1302,8 +2509,8 @@ func (e walker) EvalVariable(r *Runner, node variableNode) bool {
 }
 
 func (e walker) EvalOutput(r *Runner, node ast.PropertyMapEntry) bool {
+	ctx := r.newContext(node)
 	if e.VisitExpr != nil {
-		ctx := r.newContext(node)
 		if !e.walk(ctx, node.Key) {
 			return false
 		}
@@ -1313,7 +2520,7 @@ func (e walker) EvalOutput(r *Runner, node ast.PropertyMapEntry) bool {
 	}
 
 	if e.VisitOutput != nil {
-		if !e.VisitOutput(r, node) {
+		if !e.VisitOutput(ctx, node.Key.Value, node.Value) {
 			return false
 		}
 	}
@@ -1330,6 +2537,12 @@ func (e walker) EvalResource(r *Runner, node resourceNode) bool {
 		if !e.walk(ctx, v.Type) {
 			return false
 		}
+		if !e.walk(ctx, v.ForEach) {
+			return false
+		}
+		if !e.walk(ctx, v.Condition) {
+			return false
+		}
 		if !e.walkPropertyMap(ctx, v.Properties) {
 			return false
 		}
@@ -1381,8 +2594,22 @@ func (e walker) walkResourceOptions(ctx *evalContext, opts ast.ResourceOptionsDe
 	if !e.walkStringList(ctx, opts.AdditionalSecretOutputs) {
 		return false
 	}
-	if !e.walkStringList(ctx, opts.Aliases) {
-		return false
+	for _, alias := range opts.Aliases.GetElements() {
+		if !e.walk(ctx, alias.Urn) {
+			return false
+		}
+		if !e.walk(ctx, alias.Name) {
+			return false
+		}
+		if !e.walk(ctx, alias.Type) {
+			return false
+		}
+		if !e.walk(ctx, alias.Project) {
+			return false
+		}
+		if !e.walk(ctx, alias.Stack) {
+			return false
+		}
 	}
 	if !e.walk(ctx, opts.DeleteBeforeReplace) {
 		return false
@@ -1423,6 +2650,9 @@ func (e walker) walkResourceOptions(ctx *evalContext, opts ast.ResourceOptionsDe
 	if !e.walk(ctx, opts.DeletedWith) {
 		return false
 	}
+	if !e.walkStringList(ctx, opts.Transforms) {
+		return false
+	}
 
 	if ct := opts.CustomTimeouts; ct != nil {
 		if !e.walk(ctx, ct.Create) {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	ctypes "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/config"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/stretchr/testify/assert"
@@ -235,8 +236,17 @@ func TestTypePropertyAccess(t *testing.T) {
 				&ast.PropertySubscript{Index: 7},
 				&ast.PropertySubscript{Index: "foo"},
 			},
+			expectedType: "any",
+			errMsg:       ``,
+		},
+		{
+			root: &schema.ArrayType{ElementType: schema.StringType},
+			list: []ast.PropertyAccessor{
+				&ast.PropertySubscript{Index: 0},
+				&ast.PropertySubscript{Index: "foo"},
+			},
 			expectedType: "Invalid",
-			errMsg:       `Cannot index into 'start["foo"][7]' (type any):Index property access is only allowed on Maps and Lists`,
+			errMsg:       `Cannot index into 'start[0]' (type string):Index property access is only allowed on Maps and Lists`,
 		},
 		{
 			root: &schema.ResourceType{
@@ -286,7 +296,7 @@ func TestTypePropertyAccess(t *testing.T) {
 			expectedType: "Invalid",
 			errMsg: `Cannot access into start of type Union<List<string>, Map<number>, {foo: List<any>}>:'start' could be a type that does not support accessing:
   Array<string>: cannot access a property on 'start' (type List<string>)
-  Map<number>: cannot access a property on 'start' (type Map<number>)
+  Map<number>: Cannot index into 'start.foo' (type number)
   Cannot index via string into 'start.foo' (type List<any>)`,
 		},
 	}
@@ -368,3 +378,264 @@ func TestConfigCompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputTypes(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+outputs:
+  name: ${res-a.foo}
+  count: 3
+  ids: [a, b]
+  untyped: ${res-a.bar}
+outputGroups:
+  network:
+    cidr: 10.0.0.0/16
+outputTypes:
+  name: String
+  count: Int
+  ids: List<String>
+  network.cidr: String
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+
+	types, diags := OutputTypes(tmpl)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, map[string]ctypes.Type{
+		"name":         ctypes.String,
+		"count":        ctypes.Int,
+		"ids":          ctypes.StringList,
+		"network.cidr": ctypes.String,
+	}, types)
+}
+
+func TestWalkerVisitOutput(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+outputs:
+  name: ${res-a.foo}
+  count: 3
+outputGroups:
+  network:
+    cidr: 10.0.0.0/16
+`
+	tmpl := yamlTemplate(t, text)
+
+	visited := map[string]ast.Expr{}
+	diags := newRunner(tmpl, newMockPackageMap()).Run(walker{
+		VisitOutput: func(ctx *evalContext, name string, expr ast.Expr) bool {
+			require.NotNil(t, ctx)
+			visited[name] = expr
+			return true
+		},
+	})
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, visited, 3)
+	assert.IsType(t, &ast.SymbolExpr{}, visited["name"])
+	assert.Equal(t, 3.0, visited["count"].(*ast.NumberExpr).Value)
+	assert.Equal(t, "10.0.0.0/16", visited["network.cidr"].(*ast.StringExpr).Value)
+
+	// Walkers without the hook still visit everything else.
+	var exprs int
+	diags = newRunner(tmpl, newMockPackageMap()).Run(walker{
+		VisitExpr: func(*evalContext, ast.Expr) bool {
+			exprs++
+			return true
+		},
+	})
+	requireNoErrors(t, tmpl, diags)
+	assert.NotZero(t, exprs)
+}
+
+func TestWalkerEnclosingDecl(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+configuration:
+  greeting:
+    default: hello
+variables:
+  message: ${greeting}, ${res-a.foo}
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: ${greeting}
+outputs:
+  out: ${message}
+`
+	tmpl := yamlTemplate(t, text)
+
+	var refs []string
+	var variables []string
+	diags := newRunner(tmpl, newMockPackageMap()).Run(walker{
+		VisitExpr: func(ctx *evalContext, x ast.Expr) bool {
+			kind, name := ctx.enclosingDecl()
+			switch x := x.(type) {
+			case *ast.InterpolateExpr:
+				for _, p := range x.Parts {
+					if p.Value != nil {
+						refs = append(refs, fmt.Sprintf("%s %s -> %s", kind, name.Value, p.Value.RootName()))
+					}
+				}
+			case *ast.SymbolExpr:
+				refs = append(refs, fmt.Sprintf("%s %s -> %s", kind, name.Value, x.Property.RootName()))
+			}
+			return true
+		},
+		VisitVariable: func(r *Runner, node variableNode) bool {
+			variables = append(variables, node.Key.Value)
+			return true
+		},
+	})
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{
+		"resource res-a -> greeting",
+		"variable message -> greeting",
+		"variable message -> res-a",
+		"output out -> message",
+	}, refs)
+	assert.Equal(t, []string{"message"}, variables)
+}
+
+func TestOutputTypesInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+outputs:
+  name: [a, b]
+  count: 3
+outputTypes:
+  name: String
+  count: Thing
+  missing: String
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, d := range diags {
+		diagStrings = append(diagStrings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:8:10: unexpected type 'Thing' for output "count": valid types are string, List<string>, number, List<number>, integer, List<integer>, boolean, List<number>, Object, List<Object>`,
+		`<stdin>:9:3: outputTypes declares a type for "missing", which is not an output`,
+		`<stdin>:4:9: string is not assignable from List<string>; Cannot assign 'List<string>' to 'string'`,
+	}, diagStrings)
+}
+
+func TestAssetArchivePaths(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  archive:
+    fn::assetArchive:
+      ./a:
+        fn::stringAsset: one
+      a:
+        fn::stringAsset: two
+      dir/b:
+        fn::stringAsset: three
+      dir//./b:
+        fn::stringAsset: four
+      /etc/passwd:
+        fn::stringAsset: five
+      ../up:
+        fn::stringAsset: six
+      dir/../../up:
+        fn::stringAsset: seven
+      ok/c:
+        fn::stringAsset: eight
+outputs:
+  archive: ${archive}
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, d := range diags {
+		diagStrings = append(diagStrings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:14:7: archive path "/etc/passwd" must be relative`,
+		`<stdin>:16:7: archive path "../up" must not refer to a parent directory`,
+		`<stdin>:18:7: archive path "dir/../../up" must not refer to a parent directory`,
+		`<stdin>:8:7: archive paths "./a", "a" all refer to "a"`,
+		`<stdin>:12:7: archive paths "dir/b", "dir//./b" all refer to "dir/b"`,
+	}, diagStrings)
+}
+
+func TestEnumValidation(t *testing.T) {
+	t.Parallel()
+
+	size := &schema.EnumType{
+		Token:       "cloud:index:Size",
+		ElementType: schema.StringType,
+		Elements: []*schema.Enum{
+			{Value: "small"},
+			{Value: "medium"},
+			{Name: "Large", Value: "large"},
+		},
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"cloud": MockPackage{
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName,
+					schema.Property{Name: "size", Type: &schema.OptionalType{ElementType: size}},
+					schema.Property{Name: "sizes", Type: &schema.OptionalType{ElementType: &schema.ArrayType{ElementType: size}}},
+				)
+			},
+		},
+	}}
+
+	tmpl := yamlTemplate(t, `
+name: test-enums
+runtime: yaml
+variables:
+  v: medium
+resources:
+  valid:
+    type: cloud:index:Server
+    properties:
+      size: large
+      sizes: [small, "${v}"]
+  interpolated:
+    type: cloud:index:Server
+    properties:
+      size: ${v}
+  invalid:
+    type: cloud:index:Server
+    properties:
+      size: huge
+  invalidElement:
+    type: cloud:index:Server
+    properties:
+      sizes: [small, tiny]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, loader))
+	require.Len(t, diags, 2, diagStrings(diags))
+	assert.Equal(t, `<stdin>:19:13: "huge" is not an allowed value of cloud:index:Size; `+
+		`Cannot assign '{size: string}' to 'cloud:index:Server':
+  size: Cannot assign type 'string' to type 'cloud:index:Size':
+    Allowed values are "small", "medium", Large ("large")`,
+		diagString(diags[0]))
+	assert.Contains(t, diags[1].Summary, `"tiny" is not an allowed value of cloud:index:Size`)
+	assert.Equal(t, 23, diags[1].Subject.Start.Line)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// ComponentPackageName is the package of the components that a template declares.
+const ComponentPackageName = "components"
+
+// ComponentTypeToken is the type token of the component declared with the given name.
+func ComponentTypeToken(name string) string {
+	return ComponentPackageName + ":index:" + name
+}
+
+// ComponentsMapDecl declares reusable components, which are instantiated by resources whose type
+// is the name of the component:
+//
+//	components:
+//	  webServer:
+//	    inputs:
+//	      port:
+//	        type: Number
+//	        default: 80
+//	    resources:
+//	      group:
+//	        type: aws:ec2:SecurityGroup
+//	    outputs:
+//	      groupId: ${group.id}
+//	resources:
+//	  web:
+//	    type: webServer
+//	    properties:
+//	      port: 8080
+type ComponentsMapDecl struct {
+	declNode
+
+	Entries []ComponentsMapEntry
+}
+
+type ComponentsMapEntry struct {
+	syntax syntax.ObjectPropertyDef
+	Key    *StringExpr
+	Value  *ComponentDecl
+}
+
+func (d *ComponentsMapDecl) defaultValue() interface{} {
+	return &ComponentsMapDecl{}
+}
+
+func (d *ComponentsMapDecl) parse(name string, node syntax.Node) syntax.Diagnostics {
+	obj, ok := node.(*syntax.ObjectNode)
+	if !ok {
+		return syntax.Diagnostics{syntax.NodeError(node, fmt.Sprintf("%v must be an object", name), "")}
+	}
+	d.syntax = obj
+
+	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
+
+	entries := make([]ComponentsMapEntry, obj.Len())
+	for i := range entries {
+		kvp := obj.Index(i)
+
+		var v *ComponentDecl
+		vname := fmt.Sprintf("%s.%s", name, kvp.Key.Value())
+		diags.Extend(parseField(vname, reflect.ValueOf(&v).Elem(), kvp.Value)...)
+
+		entries[i] = ComponentsMapEntry{
+			syntax: kvp,
+			Key:    StringSyntax(kvp.Key),
+			Value:  v,
+		}
+	}
+	d.Entries = entries
+
+	return diags
+}
+
+// Get returns the component declared with the given name, or nil if there is none.
+func (d *ComponentsMapDecl) Get(name string) *ComponentDecl {
+	for _, entry := range d.Entries {
+		if entry.Key.Value == name {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+// A ComponentDecl declares a component: a resource whose children are declared in the template.
+// Its inputs are declared like configuration, and are set by the properties of each resource
+// that instantiates it. Its variables, resources and outputs may only refer to its inputs and to
+// each other.
+type ComponentDecl struct {
+	declNode
+
+	// source is the text of the file that declares the component, if it is declared by a file of
+	// its own rather than by the template.
+	source []byte
+
+	Description *StringExpr
+	Inputs      ConfigMapDecl
+	Variables   VariablesMapDecl
+	Resources   ResourcesMapDecl
+	Outputs     PropertyMapDecl
+}
+
+func (d *ComponentDecl) recordSyntax() *syntax.Node {
+	return &d.syntax
+}
+
+// ParseComponent parses a component declared by a file of its own, whose top level holds the
+// description, inputs, variables, resources and outputs of the component.
+func ParseComponent(source []byte, node syntax.Node) (*ComponentDecl, syntax.Diagnostics) {
+	c := ComponentDecl{source: source}
+	diags := parseRecord("component", &c, node, true)
+	return &c, diags
+}
+
+// AddComponent declares c, a component loaded from a file of its own, with the given name.
+func (d *TemplateDecl) AddComponent(name *StringExpr, c *ComponentDecl) {
+	d.Components.Entries = append(d.Components.Entries, ComponentsMapEntry{Key: name, Value: c})
+	if c.source != nil && c.syntax != nil {
+		if d.includedSources == nil {
+			d.includedSources = map[string][]byte{}
+		}
+		d.includedSources[c.syntax.Syntax().Range().Filename] = c.source
+	}
+}
+
+// ComponentTemplate returns the body of a component of the template as a template of its own,
+// with the component's inputs as its configuration, so that it can be checked and evaluated like
+// one.
+func (d *TemplateDecl) ComponentTemplate(c *ComponentDecl) *TemplateDecl {
+	source := d.source
+	if c.source != nil {
+		source = c.source
+	}
+	return &TemplateDecl{
+		source:          source,
+		includedSources: d.includedSources,
+		syntax:          c.syntax,
+		Name:            d.Name,
+		Description:     c.Description,
+		Configuration:   c.Inputs,
+		Variables:       c.Variables,
+		Resources:       c.Resources,
+		Outputs:         c.Outputs,
+	}
+}
+
+// declareComponents replaces the types of the resources that instantiate a component of the
+// template with the type token of the component.
+func (d *TemplateDecl) declareComponents() syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	for _, entry := range d.Components.Entries {
+		if strings.Contains(entry.Key.Value, ":") {
+			diags.Extend(ExprError(entry.Key,
+				fmt.Sprintf("component name %q must not contain ':'", entry.Key.Value), ""))
+		}
+		if entry.Value == nil {
+			continue
+		}
+		// A component can't instantiate components, so that instances can't be nested without end.
+		for _, r := range entry.Value.Resources.Entries {
+			if r.Value == nil || r.Value.Type == nil {
+				continue
+			}
+			typ := r.Value.Type.Value
+			if d.Components.Get(typ) != nil || strings.HasPrefix(typ, ComponentPackageName+":") {
+				diags.Extend(ExprError(r.Value.Type,
+					fmt.Sprintf("component %q can't instantiate the component %q", entry.Key.Value, typ), ""))
+			}
+		}
+	}
+
+	for _, r := range d.Resources.Entries {
+		res := r.Value
+		if res == nil || res.Type == nil || d.Components.Get(res.Type.Value) == nil {
+			continue
+		}
+		token := ComponentTypeToken(res.Type.Value)
+		if node, ok := res.Type.Syntax().(*syntax.StringNode); ok {
+			res.Type = StringSyntaxValue(node, token)
+		} else {
+			res.Type = String(token)
+		}
+	}
+	return diags
+}
@@ -3,17 +3,28 @@
 package ast
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
 )
 
 // diagnosticWriter provides an implementation of hcl.DiagnosticWriter that performs automatic fixup of hcl.Pos values
 // that are missing byte offsets prior to writing out diagnostics. Doing this here allows the rest of the world to be a
 // bit lazy and only provider line and column information, with the caveat that tabs must be treated as representing
 // 4 spaces in order for the fixup to work.
+//
+// Hints are written as warnings whose label is "Hint" instead of "Warning". A diagnostic's related
+// range is written after it, as its position and message.
 type diagnosticWriter struct {
 	w     hcl.DiagnosticWriter
+	out   io.Writer
+	width uint
+	color bool
 	files map[string]*hcl.File // map from filenames to source bytes
 	lines map[string][]int     // map from filenames to line offsets
 }
@@ -21,6 +32,9 @@ type diagnosticWriter struct {
 func newDiagnosticWriter(w io.Writer, files map[string]*hcl.File, width uint, color bool) hcl.DiagnosticWriter {
 	return &diagnosticWriter{
 		w:     hcl.NewDiagnosticTextWriter(w, files, width, color),
+		out:   w,
+		width: width,
+		color: color,
 		files: files,
 	}
 }
@@ -103,12 +117,33 @@ func (w *diagnosticWriter) fixupOffsets(d *hcl.Diagnostic) {
 
 func (w *diagnosticWriter) WriteDiagnostic(d *hcl.Diagnostic) error {
 	w.fixupOffsets(d)
-	return w.w.WriteDiagnostic(d)
+	if !syntax.IsHint(d) {
+		if err := w.w.WriteDiagnostic(d); err != nil {
+			return err
+		}
+	} else {
+		var buf bytes.Buffer
+		err := hcl.NewDiagnosticTextWriter(&buf, w.files, w.width, w.color).WriteDiagnostic(d)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w.out, strings.Replace(buf.String(), "Warning", "Hint", 1)); err != nil {
+			return err
+		}
+	}
+
+	if related := syntax.RelatedOf(d); related != nil {
+		_, err := fmt.Fprintf(w.out, "  %s: %s\n\n", syntax.Position(&related.Range), related.Message)
+		return err
+	}
+	return nil
 }
 
 func (w *diagnosticWriter) WriteDiagnostics(d hcl.Diagnostics) error {
 	for _, d := range d {
-		w.fixupOffsets(d)
+		if err := w.WriteDiagnostic(d); err != nil {
+			return err
+		}
 	}
-	return w.w.WriteDiagnostics(d)
+	return nil
 }
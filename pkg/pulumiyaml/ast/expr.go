@@ -5,6 +5,7 @@ package ast
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -42,13 +43,15 @@ func (x *exprNode) Syntax() syntax.Node {
 // ExprError creates an error-level diagnostic associated with the given expression. If the expression is non-nil and
 // has an underlying syntax node, the error will cover the underlying textual range.
 func ExprError(expr Expr, summary, detail string) *syntax.Diagnostic {
-	var rng *hcl.Range
-	if expr != nil {
-		if syntax := expr.Syntax(); syntax != nil {
-			rng = syntax.Syntax().Range()
-		}
+	return syntax.Error(exprRange(expr), summary, detail)
+}
+
+// exprRange returns the range of expr's syntax, if it has any.
+func exprRange(expr Expr) *hcl.Range {
+	if expr == nil || expr.Syntax() == nil || expr.Syntax().Syntax() == nil {
+		return nil
 	}
-	return syntax.Error(rng, summary, detail)
+	return expr.Syntax().Syntax().Range()
 }
 
 // A NullExpr represents a null literal.
@@ -150,7 +153,10 @@ func (n *InterpolateExpr) String() string {
 		// this is necessary because the parser will escape the string
 		// so when we print it back out as string, we need to un-escape it
 		str.WriteString(strings.ReplaceAll(p.Text, "$", "$$"))
-		if p.Value != nil {
+		switch {
+		case p.Value != nil && p.Default != nil:
+			fmt.Fprintf(&str, "${%v:-%v}", p.Value, p.Default)
+		case p.Value != nil:
 			fmt.Fprintf(&str, "${%v}", p.Value)
 		}
 	}
@@ -165,11 +171,18 @@ func InterpolateSyntax(node *syntax.StringNode) (*InterpolateExpr, syntax.Diagno
 		return nil, diags
 	}
 
-	for _, part := range parts {
-		if part.Value != nil && len(part.Value.Accessors) == 0 {
-			diags.Extend(syntax.NodeError(node, "Property access expressions cannot be empty", ""))
+	var checkEmpty func(parts []Interpolation)
+	checkEmpty = func(parts []Interpolation) {
+		for _, part := range parts {
+			if part.Value != nil && len(part.Value.Accessors) == 0 {
+				diags.Extend(syntax.NodeError(node, "Property access expressions cannot be empty", ""))
+			}
+			if part.Default != nil {
+				checkEmpty(part.Default.Parts)
+			}
 		}
 	}
+	checkEmpty(parts)
 
 	return &InterpolateExpr{
 		exprNode: expr(node),
@@ -190,7 +203,8 @@ func VariableSubstitution(value string) (*SymbolExpr, syntax.Diagnostics) {
 		return nil, diags
 	}
 
-	if interpolate != nil && len(interpolate.Parts) == 1 && interpolate.Parts[0].Text == "" {
+	if interpolate != nil && len(interpolate.Parts) == 1 && interpolate.Parts[0].Text == "" &&
+		interpolate.Parts[0].Default == nil {
 		return &SymbolExpr{
 			exprNode: expr(node),
 			Property: interpolate.Parts[0].Value,
@@ -277,8 +291,9 @@ func Object(entries ...ObjectProperty) *ObjectExpr {
 //   - *syntax.ListNode is parsed as a *ListExpr.
 //   - *syntax.StringNode is parsed as an *InterpolateExpr, a *SymbolExpr, or a *StringExpr. The node's literal is first
 //     parsed as an interpolated string. If the result contains a single property access with no surrounding text, (i.e.
-//     the string is of the form "${resource.property}", it is treated as a symbol. If the result contains no property
-//     accesses, it is treated as a string literal. Otherwise, it it treated as an interpolated string.
+//     the string is of the form "${resource.property}", it is treated as a symbol, unless the access has a default. If
+//     the result contains no property accesses, it is treated as a string literal. Otherwise, it it treated as an
+//     interpolated string.
 //   - *syntax.ObjectNode is parses as either an *ObjectExpr or a BuiltinExpr. If the object contains a single key and
 //     that key names a builtin function ("fn::invoke", "fn::join", "fn::select",
 //     "fn::*Asset", "fn::*Archive", or "fn::stackReference"), then the object is parsed as the corresponding BuiltinExpr.
@@ -302,7 +317,7 @@ func ParseExpr(node syntax.Node) (Expr, syntax.Diagnostics) {
 				switch {
 				case interpolate.Parts[0].Value == nil:
 					return StringSyntaxValue(node, interpolate.Parts[0].Text), diags
-				case interpolate.Parts[0].Text == "":
+				case interpolate.Parts[0].Text == "" && interpolate.Parts[0].Default == nil:
 					return &SymbolExpr{
 						exprNode: expr(node),
 						Property: interpolate.Parts[0].Value,
@@ -434,13 +449,45 @@ func (n *builtinNode) Args() Expr {
 }
 
 // InvokeExpr is a function expression that invokes a Pulumi function by type token.
+//
+// The token is normally a string literal. It may instead be a reference to a config value, in
+// which case Token is nil and TokenRef holds the reference; the token is then resolved at runtime.
 type InvokeExpr struct {
 	builtinNode
 
 	Token    *StringExpr
+	TokenRef *SymbolExpr
 	CallArgs *ObjectExpr
 	CallOpts InvokeOptionsDecl
 	Return   *StringExpr
+	// Assign destructures the result of an invoke that is the value of a variable into further
+	// variables. Each is declared alongside the variable, so the invoke is only called once.
+	Assign []InvokeAssignment
+}
+
+// InvokeAssignment declares the variable Name, whose value is the property of an invoke's result
+// at the path Property, such as 'cidrBlock' or 'tags.owner'.
+type InvokeAssignment struct {
+	Name     *StringExpr
+	Property *StringExpr
+}
+
+// assignedVariable returns the expression for the variable declared by a, where the result of the
+// invoke is the value of the variable named variable.
+func (a InvokeAssignment) assignedVariable(variable string) (*SymbolExpr, syntax.Diagnostics) {
+	access := a.Property.Value
+	if !strings.HasPrefix(access, "[") {
+		access = "." + access
+	}
+	_, property, diags := parsePropertyAccess(a.Property.Syntax(), access+"}")
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	accessors := append([]PropertyAccessor{&PropertyName{Name: variable}}, property.Accessors...)
+	return &SymbolExpr{
+		exprNode: expr(a.Property.Syntax()),
+		Property: &PropertyAccess{Accessors: accessors},
+	}, diags
 }
 
 func InvokeSyntax(node *syntax.ObjectNode, name *StringExpr, args *ObjectExpr, token *StringExpr, callArgs *ObjectExpr, callOpts InvokeOptionsDecl, ret *StringExpr) *InvokeExpr {
@@ -491,6 +538,83 @@ func ToJSON(value Expr) *ToJSONExpr {
 	return ToJSONSyntax(nil, name, value)
 }
 
+// JSONPathExpr selects values from the JSON document JSON with the JSONPath expression Path.
+// Paths support the root $, properties (.name and ['name']), list indices ([n]), wildcards (.*
+// and [*]) and recursive descent (..name and ..*), but not filters, slices or unions.
+type JSONPathExpr struct {
+	builtinNode
+
+	JSON Expr
+	Path Expr
+}
+
+func JSONPathSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *JSONPathExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 2, "Must have exactly 2 elements")
+	return &JSONPathExpr{
+		builtinNode: builtin(node, name, args),
+		JSON:        elems[0],
+		Path:        elems[1],
+	}
+}
+
+func JSONPath(json, path Expr) *JSONPathExpr {
+	name := String("fn::jsonPath")
+	return &JSONPathExpr{
+		builtinNode: builtin(nil, name, List(json, path)),
+		JSON:        json,
+		Path:        path,
+	}
+}
+
+// SemverSatisfiesExpr reports whether the semantic version Version satisfies Constraint, which is
+// a range such as ">=4.0.0 <5.0.0".
+type SemverSatisfiesExpr struct {
+	builtinNode
+
+	Version    Expr
+	Constraint Expr
+}
+
+func SemverSatisfiesSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *SemverSatisfiesExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 2, "Must have exactly 2 elements")
+	return &SemverSatisfiesExpr{
+		builtinNode: builtin(node, name, args),
+		Version:     elems[0],
+		Constraint:  elems[1],
+	}
+}
+
+func SemverSatisfies(version, constraint Expr) *SemverSatisfiesExpr {
+	name := String("fn::semverSatisfies")
+	return SemverSatisfiesSyntax(nil, name, List(version, constraint))
+}
+
+// SemverCompareExpr compares the semantic versions Left and Right, resulting in -1 if Left is
+// lower, 0 if they are equal and 1 if Left is greater.
+type SemverCompareExpr struct {
+	builtinNode
+
+	Left  Expr
+	Right Expr
+}
+
+func SemverCompareSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *SemverCompareExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 2, "Must have exactly 2 elements")
+	return &SemverCompareExpr{
+		builtinNode: builtin(node, name, args),
+		Left:        elems[0],
+		Right:       elems[1],
+	}
+}
+
+func SemverCompare(left, right Expr) *SemverCompareExpr {
+	name := String("fn::semverCompare")
+	return SemverCompareSyntax(nil, name, List(left, right))
+}
+
 // JoinExpr appends a set of values into a single value, separated by the specified delimiter.
 // If a delimiter is the empty string, the set of values are concatenated with no delimiter.
 type JoinExpr struct {
@@ -569,6 +693,71 @@ func Select(index Expr, values Expr) *SelectExpr {
 	}
 }
 
+// LookupExpr gets the value of Key in the map Map, or Default if the map has no such key. Map
+// may also be a list, which Key indexes. This mirrors Terraform's lookup.
+type LookupExpr struct {
+	builtinNode
+
+	Map     Expr
+	Key     Expr
+	Default Expr
+}
+
+func LookupSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *LookupExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 3, "Must have exactly 3 elements")
+	return &LookupExpr{
+		builtinNode: builtin(node, name, args),
+		Map:         elems[0],
+		Key:         elems[1],
+		Default:     elems[2],
+	}
+}
+
+func Lookup(m, key, def Expr) *LookupExpr {
+	name := String("fn::lookup")
+	return &LookupExpr{
+		builtinNode: builtin(nil, name, List(m, key, def)),
+		Map:         m,
+		Key:         key,
+		Default:     def,
+	}
+}
+
+// RangeExpr produces the list of integers from Start up to, but not including, End, counting by
+// Step. Step defaults to 1 and may be negative, in which case the list counts down to End.
+type RangeExpr struct {
+	builtinNode
+
+	Start Expr
+	End   Expr
+	Step  Expr
+}
+
+func RangeSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *RangeExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 2 || len(elems) == 3, "Must have 2 or 3 elements")
+	var step Expr
+	if len(elems) == 3 {
+		step = elems[2]
+	}
+	return &RangeExpr{
+		builtinNode: builtin(node, name, args),
+		Start:       elems[0],
+		End:         elems[1],
+		Step:        step,
+	}
+}
+
+func Range(start, end, step Expr) *RangeExpr {
+	name := String("fn::range")
+	args := List(start, end)
+	if step != nil {
+		args = List(start, end, step)
+	}
+	return RangeSyntax(nil, name, args)
+}
+
 type ToBase64Expr struct {
 	builtinNode
 
@@ -595,6 +784,182 @@ func FromBase64Syntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *Fro
 	}
 }
 
+// Base64GzipExpr gzip-compresses the UTF-8 bytes of its string argument and returns the
+// result encoded as standard, padded base64 (RFC 4648). This mirrors Terraform's base64gzip.
+type Base64GzipExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func Base64GzipSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *Base64GzipExpr {
+	return &Base64GzipExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Base64Gzip(value Expr) *Base64GzipExpr {
+	name := String("fn::base64gzip")
+	return Base64GzipSyntax(nil, name, value)
+}
+
+// SortExpr returns a sorted copy of a list. The list must contain only strings, which are sorted
+// lexicographically, or only numbers, which are sorted numerically.
+type SortExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func SortSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *SortExpr {
+	return &SortExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Sort(value Expr) *SortExpr {
+	name := String("fn::sort")
+	return SortSyntax(nil, name, value)
+}
+
+// KeysExpr returns the keys of a map, sorted lexicographically.
+type KeysExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func KeysSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *KeysExpr {
+	return &KeysExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Keys(value Expr) *KeysExpr {
+	name := String("fn::keys")
+	return KeysSyntax(nil, name, value)
+}
+
+// ValuesExpr returns the values of a map, in the order of their sorted keys.
+type ValuesExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func ValuesSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *ValuesExpr {
+	return &ValuesExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Values(value Expr) *ValuesExpr {
+	name := String("fn::values")
+	return ValuesSyntax(nil, name, value)
+}
+
+// UniqueExpr returns a copy of a list with duplicate elements removed. The first occurrence of
+// each element is kept, so the order of the list is otherwise preserved.
+type UniqueExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func UniqueSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *UniqueExpr {
+	return &UniqueExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Unique(value Expr) *UniqueExpr {
+	name := String("fn::unique")
+	return UniqueSyntax(nil, name, value)
+}
+
+// FlattenExpr flattens nested lists into a single list. Depth is the number of levels of nesting
+// to remove; it is nil when the default of one level is used. Elements that are not lists are
+// kept as they are.
+type FlattenExpr struct {
+	builtinNode
+
+	Value Expr
+	Depth Expr
+}
+
+func FlattenSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr, value, depth Expr) *FlattenExpr {
+	return &FlattenExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       value,
+		Depth:       depth,
+	}
+}
+
+func Flatten(value, depth Expr) *FlattenExpr {
+	name := String("fn::flatten")
+	args := value
+	if depth != nil {
+		args = Object(
+			ObjectProperty{Key: String("list"), Value: value},
+			ObjectProperty{Key: String("depth"), Value: depth},
+		)
+	}
+	return FlattenSyntax(nil, name, args, value, depth)
+}
+
+// CidrSubnetExpr computes the address of a subnet within an IP network prefix. Prefix is the
+// network in CIDR notation, Newbits is the number of additional bits with which to extend the
+// prefix and Netnum is the number of the subnet. This mirrors Terraform's cidrsubnet.
+type CidrSubnetExpr struct {
+	builtinNode
+
+	Prefix  Expr
+	Newbits Expr
+	Netnum  Expr
+}
+
+func CidrSubnetSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, prefix, newbits, netnum Expr) *CidrSubnetExpr {
+	return &CidrSubnetExpr{
+		builtinNode: builtin(node, name, args),
+		Prefix:      prefix,
+		Newbits:     newbits,
+		Netnum:      netnum,
+	}
+}
+
+func CidrSubnet(prefix, newbits, netnum Expr) *CidrSubnetExpr {
+	name := String("fn::cidrsubnet")
+	return CidrSubnetSyntax(nil, name, List(prefix, newbits, netnum), prefix, newbits, netnum)
+}
+
+// CidrHostExpr computes the address of a single host within an IP network prefix. Hostnum may be
+// negative, in which case hosts are counted back from the end of the range. This mirrors
+// Terraform's cidrhost.
+type CidrHostExpr struct {
+	builtinNode
+
+	Prefix  Expr
+	Hostnum Expr
+}
+
+func CidrHostSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, prefix, hostnum Expr) *CidrHostExpr {
+	return &CidrHostExpr{
+		builtinNode: builtin(node, name, args),
+		Prefix:      prefix,
+		Hostnum:     hostnum,
+	}
+}
+
+func CidrHost(prefix, hostnum Expr) *CidrHostExpr {
+	name := String("fn::cidrhost")
+	return CidrHostSyntax(nil, name, List(prefix, hostnum), prefix, hostnum)
+}
+
 type AssetOrArchiveExpr interface {
 	Expr
 	isAssetOrArchive()
@@ -684,6 +1049,92 @@ func AssetArchiveSyntax(node *syntax.ObjectNode, name *StringExpr, args *ObjectE
 	}
 }
 
+// FileHashExpr is the hex digest of the contents of a file inside the project directory.
+// Algorithm is only set by the object form of fn::fileHash, and defaults to sha256.
+type FileHashExpr struct {
+	builtinNode
+
+	Path      Expr
+	Algorithm Expr
+}
+
+func FileHashSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr, path, algorithm Expr) *FileHashExpr {
+	return &FileHashExpr{
+		builtinNode: builtin(node, name, args),
+		Path:        path,
+		Algorithm:   algorithm,
+	}
+}
+
+func FileHash(path, algorithm Expr) *FileHashExpr {
+	name := String("fn::fileHash")
+	args := path
+	if algorithm != nil {
+		args = Object(
+			ObjectProperty{Key: String("path"), Value: path},
+			ObjectProperty{Key: String("algorithm"), Value: algorithm},
+		)
+	}
+	return FileHashSyntax(nil, name, args, path, algorithm)
+}
+
+func parseFileHash(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	obj, ok := args.(*ObjectExpr)
+	if !ok {
+		return FileHashSyntax(node, name, args, args, nil), nil
+	}
+
+	var path, algorithm Expr
+	var diags syntax.Diagnostics
+	for _, kvp := range obj.Entries {
+		str, ok := kvp.Key.(*StringExpr)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(str.Value) {
+		case "path":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "path", str.GetValue()))
+			path = kvp.Value
+		case "algorithm":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "algorithm", str.GetValue()))
+			algorithm = kvp.Value
+		default:
+			diags.Extend(ExprError(kvp.Key, fmt.Sprintf("unknown property %q; fn::fileHash accepts 'path' and 'algorithm'", str.Value), ""))
+		}
+	}
+	if path == nil {
+		diags.Extend(ExprError(obj, "missing path of the file to hash ('path')", ""))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return FileHashSyntax(node, name, obj, path, algorithm), diags
+}
+
+// GlobAssetsExpr is an archive of the files in the project directory that match Pattern. Each
+// file is keyed by its path relative to the part of Pattern before its first wildcard, so that
+// src/**/*.js archives src/lib/a.js as lib/a.js.
+type GlobAssetsExpr struct {
+	builtinNode
+
+	Pattern Expr
+}
+
+func (*GlobAssetsExpr) isAssetOrArchive() {}
+
+func GlobAssetsSyntax(node *syntax.ObjectNode, name *StringExpr, pattern Expr) *GlobAssetsExpr {
+	return &GlobAssetsExpr{
+		builtinNode: builtin(node, name, pattern),
+		Pattern:     pattern,
+	}
+}
+
+func GlobAssets(pattern Expr) *GlobAssetsExpr {
+	name := String("fn::globAssets")
+	return GlobAssetsSyntax(nil, name, pattern)
+}
+
 // StackReferenceExpr gets an output of another stack for use in this deployment.
 type StackReferenceExpr struct {
 	builtinNode
@@ -723,9 +1174,13 @@ func SecretSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *SecretE
 	}
 }
 
+// ReadFileExpr reads a local file, or fetches an http(s) URL. Sha256 and Timeout are only set by
+// the object form of fn::readFile.
 type ReadFileExpr struct {
 	builtinNode
-	Path Expr
+	Path    Expr
+	Sha256  Expr
+	Timeout Expr
 }
 
 func ReadFileSyntax(node syntax.Node, name *StringExpr, path Expr) *ReadFileExpr {
@@ -735,55 +1190,937 @@ func ReadFileSyntax(node syntax.Node, name *StringExpr, path Expr) *ReadFileExpr
 	}
 }
 
-func parseReadFile(node *syntax.ObjectNode, name *StringExpr, path Expr) (Expr, syntax.Diagnostics) {
-	return ReadFileSyntax(node, name, path), nil
+func parseReadFile(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	obj, ok := args.(*ObjectExpr)
+	if !ok {
+		return ReadFileSyntax(node, name, args), nil
+	}
+
+	var path, sha256, timeout Expr
+	var diags syntax.Diagnostics
+	for _, kvp := range obj.Entries {
+		str, ok := kvp.Key.(*StringExpr)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(str.Value) {
+		case "path":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "path", str.GetValue()))
+			path = kvp.Value
+		case "sha256":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "sha256", str.GetValue()))
+			sha256 = kvp.Value
+		case "timeout":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "timeout", str.GetValue()))
+			timeout = kvp.Value
+		default:
+			diags.Extend(ExprError(kvp.Key, fmt.Sprintf("unknown property %q; fn::readFile accepts 'path', 'sha256' and 'timeout'", str.Value), ""))
+		}
+	}
+	if path == nil {
+		diags.Extend(ExprError(obj, "missing path or URL to read ('path')", ""))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return &ReadFileExpr{
+		builtinNode: builtin(node, name, obj),
+		Path:        path,
+		Sha256:      sha256,
+		Timeout:     timeout,
+	}, diags
+}
+
+// EnvFileExpr reads a dotenv-format file into a map from keys to values. Path must be inside
+// the project directory.
+type EnvFileExpr struct {
+	builtinNode
+
+	Path Expr
 }
 
-func tryParseFunction(node *syntax.ObjectNode) (Expr, syntax.Diagnostics, bool) {
-	if node.Len() != 1 {
-		return nil, nil, false
+func EnvFileSyntax(node *syntax.ObjectNode, name *StringExpr, path Expr) *EnvFileExpr {
+	return &EnvFileExpr{
+		builtinNode: builtin(node, name, path),
+		Path:        path,
 	}
+}
 
-	kvp := node.Index(0)
+func EnvFile(path Expr) *EnvFileExpr {
+	name := String("fn::envFile")
+	return EnvFileSyntax(nil, name, path)
+}
 
-	if _, _, ok := getAssetOrArchive(StringSyntax(kvp.Key)); ok {
-		// We will parse this node as an asset or archive later, so we don't need to do it now
-		return nil, nil, false
+func parseEnvFile(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return EnvFileSyntax(node, name, args), nil
+}
+
+// NowExpr is the current time, as an RFC3339 string in UTC. The time is read once per run, so
+// every fn::now in a template has the same value. A preview and the update that follows it are
+// separate runs, though, so the value changes between them: a resource whose name or other
+// replacing property uses fn::now is replaced on every update.
+type NowExpr struct {
+	builtinNode
+}
+
+func NowSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *NowExpr {
+	return &NowExpr{
+		builtinNode: builtin(node, name, args),
 	}
+}
 
-	var parse func(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics)
-	var diags syntax.Diagnostics
-	set := func(expected string, parseFn func(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics)) {
-		diags.Extend(syntax.UnexpectedCasing(kvp.Key.Syntax().Range(), expected, kvp.Key.Value()))
-		parse = parseFn
+func Now() *NowExpr {
+	name := String("fn::now")
+	return NowSyntax(nil, name, Object())
+}
+
+func parseNow(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	if !isEmptyArgs(args) {
+		return nil, syntax.Diagnostics{ExprError(args, "fn::now takes no arguments", "")}
 	}
-	switch strings.ToLower(kvp.Key.Value()) {
-	case "fn::invoke":
-		set("fn::invoke", parseInvoke)
-	case "fn::join":
-		set("fn::join", parseJoin)
+
+	return NowSyntax(node, name, args), nil
+}
+
+// isEmptyArgs reports whether args are missing, as they are for builtins that take no arguments.
+func isEmptyArgs(args Expr) bool {
+	switch args := args.(type) {
+	case nil, *NullExpr:
+		return true
+	case *ObjectExpr:
+		return len(args.Entries) == 0
+	case *ListExpr:
+		return len(args.Elements) == 0
+	}
+	return false
+}
+
+// TimeAddExpr adds Duration, such as 24h or -30m, to the RFC3339 time Base.
+type TimeAddExpr struct {
+	builtinNode
+
+	Base     Expr
+	Duration Expr
+}
+
+func TimeAddSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *TimeAddExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) == 2, "Must have exactly 2 elements")
+	return &TimeAddExpr{
+		builtinNode: builtin(node, name, args),
+		Base:        elems[0],
+		Duration:    elems[1],
+	}
+}
+
+func TimeAdd(base, duration Expr) *TimeAddExpr {
+	name := String("fn::timeAdd")
+	return TimeAddSyntax(nil, name, List(base, duration))
+}
+
+func parseTimeAdd(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::timeAdd must be a two-valued list of a time and a duration", "")}
+	}
+
+	return TimeAddSyntax(node, name, list), nil
+}
+
+// EnvExpr is the value of the environment variable Variable, or Default if it isn't set. Its value
+// isn't secret unless Default is.
+type EnvExpr struct {
+	builtinNode
+
+	Variable Expr
+	Default  Expr
+}
+
+func EnvSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr, varName, def Expr) *EnvExpr {
+	return &EnvExpr{
+		builtinNode: builtin(node, name, args),
+		Variable:    varName,
+		Default:     def,
+	}
+}
+
+func Env(varName, def Expr) *EnvExpr {
+	name := String("fn::env")
+	args := varName
+	if def != nil {
+		args = List(varName, def)
+	}
+	return EnvSyntax(nil, name, args, varName, def)
+}
+
+func parseEnv(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok {
+		return EnvSyntax(node, name, args, args, nil), nil
+	}
+	switch len(list.Elements) {
+	case 1:
+		return EnvSyntax(node, name, list, list.Elements[0], nil), nil
+	case 2:
+		return EnvSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+	}
+	return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::env must be a name, or a list of a name and a default", "")}
+}
+
+// StackConfigExpr is the value of Key in the config of the current stack, or Default if it isn't
+// set. A key without a namespace is in the namespace of the project. The key doesn't need to be
+// declared by the template, and its value is the string stored in the stack's config, with
+// structured values encoded as JSON. Keys declared in the config section are resolved by the CLI
+// before the template runs, so their defaults are included, but the type, default, secret and
+// constraints of a declaration in the configuration section don't apply. Its value is secret if
+// the stack stores it as a secret, or if it is Default and Default is secret.
+type StackConfigExpr struct {
+	builtinNode
+
+	Key     Expr
+	Default Expr
+}
+
+func StackConfigSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr, key, def Expr) *StackConfigExpr {
+	return &StackConfigExpr{
+		builtinNode: builtin(node, name, args),
+		Key:         key,
+		Default:     def,
+	}
+}
+
+func StackConfig(key, def Expr) *StackConfigExpr {
+	name := String("fn::stackConfig")
+	args := key
+	if def != nil {
+		args = List(key, def)
+	}
+	return StackConfigSyntax(nil, name, args, key, def)
+}
+
+func parseStackConfig(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok {
+		return StackConfigSyntax(node, name, args, args, nil), nil
+	}
+	switch len(list.Elements) {
+	case 1:
+		return StackConfigSyntax(node, name, list, list.Elements[0], nil), nil
+	case 2:
+		return StackConfigSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+	}
+	return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::stackConfig must be a key, or a list of a key and a default", "")}
+}
+
+// UUIDExpr is a UUID that stays the same from one run of the template to the next, and so
+// between a preview and the update that follows it. It is derived from the stack and from where
+// it is used, such as the name of a resource and the path of the property, so it only changes if
+// those do. It is predictable, and must not be used as a secret.
+type UUIDExpr struct {
+	builtinNode
+}
+
+func UUIDSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *UUIDExpr {
+	return &UUIDExpr{
+		builtinNode: builtin(node, name, args),
+	}
+}
+
+func UUID() *UUIDExpr {
+	name := String("fn::uuid")
+	return UUIDSyntax(nil, name, Object())
+}
+
+func parseUUID(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	if !isEmptyArgs(args) {
+		return nil, syntax.Diagnostics{ExprError(args, "fn::uuid takes no arguments", "")}
+	}
+
+	return UUIDSyntax(node, name, args), nil
+}
+
+// RandomIDExpr is the hex encoding of ByteLength random bytes, which are stable in the same way
+// as the value of fn::uuid.
+type RandomIDExpr struct {
+	builtinNode
+
+	ByteLength Expr
+}
+
+func RandomIDSyntax(node *syntax.ObjectNode, name *StringExpr, byteLength Expr) *RandomIDExpr {
+	return &RandomIDExpr{
+		builtinNode: builtin(node, name, byteLength),
+		ByteLength:  byteLength,
+	}
+}
+
+func RandomID(byteLength Expr) *RandomIDExpr {
+	name := String("fn::randomId")
+	return RandomIDSyntax(nil, name, byteLength)
+}
+
+func parseRandomID(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return RandomIDSyntax(node, name, args), nil
+}
+
+// TrimExpr removes the characters in Cutset from both ends of Value, or white space if Cutset is
+// nil.
+type TrimExpr struct {
+	builtinNode
+
+	Value  Expr
+	Cutset Expr
+}
+
+func TrimSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr, value, cutset Expr) *TrimExpr {
+	return &TrimExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       value,
+		Cutset:      cutset,
+	}
+}
+
+func Trim(value, cutset Expr) *TrimExpr {
+	name := String("fn::trim")
+	args := value
+	if cutset != nil {
+		args = List(value, cutset)
+	}
+	return TrimSyntax(nil, name, args, value, cutset)
+}
+
+func parseTrim(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok {
+		return TrimSyntax(node, name, args, args, nil), nil
+	}
+	switch len(list.Elements) {
+	case 1:
+		return TrimSyntax(node, name, list, list.Elements[0], nil), nil
+	case 2:
+		return TrimSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+	}
+	return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::trim must be a string, or a list of a string and a cutset", "")}
+}
+
+// UpperExpr maps the letters of Value to upper case.
+type UpperExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func UpperSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *UpperExpr {
+	return &UpperExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Upper(value Expr) *UpperExpr {
+	return UpperSyntax(nil, String("fn::upper"), value)
+}
+
+func parseUpper(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return UpperSyntax(node, name, args), nil
+}
+
+// LowerExpr maps the letters of Value to lower case.
+type LowerExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func LowerSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *LowerExpr {
+	return &LowerExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Lower(value Expr) *LowerExpr {
+	return LowerSyntax(nil, String("fn::lower"), value)
+}
+
+func parseLower(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return LowerSyntax(node, name, args), nil
+}
+
+// TitleExpr maps the first letter of each word in Value to title case.
+type TitleExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func TitleSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *TitleExpr {
+	return &TitleExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func Title(value Expr) *TitleExpr {
+	return TitleSyntax(nil, String("fn::title"), value)
+}
+
+func parseTitle(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return TitleSyntax(node, name, args), nil
+}
+
+// FormatExpr formats Values according to the printf-style format string Format.
+type FormatExpr struct {
+	builtinNode
+
+	Format Expr
+	Values []Expr
+}
+
+func FormatSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr) *FormatExpr {
+	elems := args.Elements
+	contract.Assertf(len(elems) >= 1, "Must have at least 1 element")
+	return &FormatExpr{
+		builtinNode: builtin(node, name, args),
+		Format:      elems[0],
+		Values:      elems[1:],
+	}
+}
+
+func Format(format Expr, values ...Expr) *FormatExpr {
+	return FormatSyntax(nil, String("fn::format"), List(append([]Expr{format}, values...)...))
+}
+
+func parseFormat(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) == 0 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::format must be a list of a format string and its arguments", "")}
+	}
+	return FormatSyntax(node, name, list), nil
+}
+
+// ToStringExpr converts Value, a string, number or boolean, to a string.
+type ToStringExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func ToStringSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *ToStringExpr {
+	return &ToStringExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func ToString(value Expr) *ToStringExpr {
+	return ToStringSyntax(nil, String("fn::toString"), value)
+}
+
+func parseToString(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return ToStringSyntax(node, name, args), nil
+}
+
+// ToNumberExpr converts Value, a number or a string that spells one, to a number.
+type ToNumberExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func ToNumberSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *ToNumberExpr {
+	return &ToNumberExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func ToNumber(value Expr) *ToNumberExpr {
+	return ToNumberSyntax(nil, String("fn::toNumber"), value)
+}
+
+func parseToNumber(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return ToNumberSyntax(node, name, args), nil
+}
+
+// ToBoolExpr converts Value, a boolean or a string or number that spells one, to a boolean.
+type ToBoolExpr struct {
+	builtinNode
+
+	Value Expr
+}
+
+func ToBoolSyntax(node *syntax.ObjectNode, name *StringExpr, args Expr) *ToBoolExpr {
+	return &ToBoolExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       args,
+	}
+}
+
+func ToBool(value Expr) *ToBoolExpr {
+	return ToBoolSyntax(nil, String("fn::toBool"), value)
+}
+
+func parseToBool(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return ToBoolSyntax(node, name, args), nil
+}
+
+// GetAttExpr gets the attribute Attribute, such as an output of a component, of Resource.
+// fn::getAtt: [${resource}, name] is the same as ${resource.name}, except that the name of the
+// attribute may be computed.
+type GetAttExpr struct {
+	builtinNode
+
+	Resource  Expr
+	Attribute Expr
+}
+
+func GetAttSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, resource, attribute Expr) *GetAttExpr {
+	return &GetAttExpr{
+		builtinNode: builtin(node, name, args),
+		Resource:    resource,
+		Attribute:   attribute,
+	}
+}
+
+func GetAtt(resource, attribute Expr) *GetAttExpr {
+	return GetAttSyntax(nil, String("fn::getAtt"), List(resource, attribute), resource, attribute)
+}
+
+// Access returns the reference to the attribute, if Resource is a reference and Attribute is
+// written out in the template, or nil otherwise.
+func (x *GetAttExpr) Access() *SymbolExpr {
+	resource, ok := x.Resource.(*SymbolExpr)
+	if !ok {
+		return nil
+	}
+	attribute, ok := x.Attribute.(*StringExpr)
+	if !ok {
+		return nil
+	}
+	accessors := append(slices.Clone(resource.Property.Accessors), &PropertyName{Name: attribute.Value})
+	return &SymbolExpr{
+		exprNode: resource.exprNode,
+		Property: &PropertyAccess{Accessors: accessors},
+	}
+}
+
+func parseGetAtt(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::getAtt must be a two-valued list of a resource and the name of an attribute", "")}
+	}
+
+	return GetAttSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+}
+
+// ResourceByURNExpr is the resource of the stack whose URN is URN, such as a resource registered
+// by another program, which is looked up when the template runs. Its outputs can be read with
+// fn::getAtt or property access, but their types aren't known until then.
+type ResourceByURNExpr struct {
+	builtinNode
+
+	URN Expr
+}
+
+func ResourceByURNSyntax(node *syntax.ObjectNode, name *StringExpr, urn Expr) *ResourceByURNExpr {
+	return &ResourceByURNExpr{
+		builtinNode: builtin(node, name, urn),
+		URN:         urn,
+	}
+}
+
+func ResourceByURN(urn Expr) *ResourceByURNExpr {
+	return ResourceByURNSyntax(nil, String("fn::getResource"), urn)
+}
+
+func parseGetResource(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return ResourceByURNSyntax(node, name, args), nil
+}
+
+// An ElementExpr evaluates an expression for each element of a list, with ${item} bound to the
+// element and ${index} bound to its index. Its arguments are a list, of which the list and the
+// body are elements.
+type ElementExpr interface {
+	BuiltinExpr
+
+	// ElementList is the list whose elements are evaluated.
+	ElementList() Expr
+	// ElementBody is the expression that is evaluated for each element.
+	ElementBody() Expr
+}
+
+// MapExpr transforms each element of List with Transform.
+type MapExpr struct {
+	builtinNode
+
+	List      Expr
+	Transform Expr
+}
+
+func MapSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, list, transform Expr) *MapExpr {
+	return &MapExpr{
+		builtinNode: builtin(node, name, args),
+		List:        list,
+		Transform:   transform,
+	}
+}
+
+func Map(list, transform Expr) *MapExpr {
+	return MapSyntax(nil, String("fn::map"), List(list, transform), list, transform)
+}
+
+func (x *MapExpr) ElementList() Expr {
+	return x.List
+}
+
+func (x *MapExpr) ElementBody() Expr {
+	return x.Transform
+}
+
+func parseMap(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::map must be a two-valued list of a list and a transform", "")}
+	}
+
+	return MapSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+}
+
+// FilterExpr keeps the elements of List for which Predicate is true.
+type FilterExpr struct {
+	builtinNode
+
+	List      Expr
+	Predicate Expr
+}
+
+func FilterSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, list, predicate Expr) *FilterExpr {
+	return &FilterExpr{
+		builtinNode: builtin(node, name, args),
+		List:        list,
+		Predicate:   predicate,
+	}
+}
+
+func Filter(list, predicate Expr) *FilterExpr {
+	return FilterSyntax(nil, String("fn::filter"), List(list, predicate), list, predicate)
+}
+
+func (x *FilterExpr) ElementList() Expr {
+	return x.List
+}
+
+func (x *FilterExpr) ElementBody() Expr {
+	return x.Predicate
+}
+
+func parseFilter(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::filter must be a two-valued list of a list and a predicate", "")}
+	}
+
+	return FilterSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+}
+
+// ReduceExpr folds List into a single value. Accumulator is evaluated for each element in order,
+// with ${acc} bound to Initial for the first element and to the value of Accumulator for the
+// previous element after that.
+type ReduceExpr struct {
+	builtinNode
+
+	List        Expr
+	Initial     Expr
+	Accumulator Expr
+}
+
+func ReduceSyntax(node *syntax.ObjectNode, name *StringExpr, args *ListExpr, list, initial, accumulator Expr) *ReduceExpr {
+	return &ReduceExpr{
+		builtinNode: builtin(node, name, args),
+		List:        list,
+		Initial:     initial,
+		Accumulator: accumulator,
+	}
+}
+
+func Reduce(list, initial, accumulator Expr) *ReduceExpr {
+	return ReduceSyntax(nil, String("fn::reduce"), List(list, initial, accumulator), list, initial, accumulator)
+}
+
+func (x *ReduceExpr) ElementList() Expr {
+	return x.List
+}
+
+func (x *ReduceExpr) ElementBody() Expr {
+	return x.Accumulator
+}
+
+func parseReduce(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 3 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::reduce must be a three-valued list of a list, an initial value and an accumulator", "")}
+	}
+
+	return ReduceSyntax(node, name, list, list.Elements[0], list.Elements[1], list.Elements[2]), nil
+}
+
+func parseGlobAssets(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return GlobAssetsSyntax(node, name, args), nil
+}
+
+// WhenUnknownExpr is the value of an output, Value, with what a preview exports in its place while
+// Value isn't known yet: Substitute, which is a literal, or nothing at all if Omit is set. Updates
+// always export Value.
+type WhenUnknownExpr struct {
+	builtinNode
+
+	Value      Expr
+	Substitute Expr
+	Omit       bool
+}
+
+func WhenUnknownSyntax(node *syntax.ObjectNode, name *StringExpr, args, value, substitute Expr, omit bool) *WhenUnknownExpr {
+	return &WhenUnknownExpr{
+		builtinNode: builtin(node, name, args),
+		Value:       value,
+		Substitute:  substitute,
+		Omit:        omit,
+	}
+}
+
+func WhenUnknown(value, substitute Expr, omit bool) *WhenUnknownExpr {
+	name := String("fn::whenUnknown")
+	args := Object(ObjectProperty{Key: String("value"), Value: value})
+	if omit {
+		args.Entries = append(args.Entries, ObjectProperty{Key: String("omit"), Value: Boolean(true)})
+	} else {
+		args.Entries = append(args.Entries, ObjectProperty{Key: String("substitute"), Value: substitute})
+	}
+	return WhenUnknownSyntax(nil, name, args, value, substitute, omit)
+}
+
+func parseWhenUnknown(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	const detail = "Use either 'substitute: <value>' or 'omit: true' alongside 'value'"
+	obj, ok := args.(*ObjectExpr)
+	if !ok {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::whenUnknown must be an object containing 'value' and either 'substitute' or 'omit'", "")}
+	}
+
+	var value, substitute, omit Expr
+	var diags syntax.Diagnostics
+	for _, kvp := range obj.Entries {
+		str, ok := kvp.Key.(*StringExpr)
+		if !ok {
+			continue
+		}
+		switch str.Value {
+		case "value":
+			value = kvp.Value
+		case "substitute":
+			substitute = kvp.Value
+		case "omit":
+			omit = kvp.Value
+		default:
+			diags.Extend(ExprError(kvp.Key, fmt.Sprintf("unknown property %q; fn::whenUnknown accepts 'value', 'substitute' and 'omit'", str.Value), ""))
+		}
+	}
+	if value == nil {
+		diags.Extend(ExprError(obj, "missing the value of the output ('value')", ""))
+	}
+	switch {
+	case substitute == nil && omit == nil:
+		diags.Extend(ExprError(obj, "missing what to export while the value is unknown", detail))
+	case substitute != nil && omit != nil:
+		diags.Extend(ExprError(omit, "'substitute' and 'omit' can't be used together", detail))
+	case substitute != nil && !IsLiteral(substitute):
+		diags.Extend(ExprError(substitute, "the substitute must be a literal value",
+			"It may not refer to resources, variables or config, or call functions"))
+	case omit != nil:
+		if b, ok := omit.(*BooleanExpr); !ok || !b.Value {
+			diags.Extend(ExprError(omit, "omit must be true", detail))
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return WhenUnknownSyntax(node, name, obj, value, substitute, omit != nil), diags
+}
+
+// IsLiteral reports whether x is a value that doesn't depend on anything else.
+func IsLiteral(x Expr) bool {
+	switch x := x.(type) {
+	case *NullExpr, *BooleanExpr, *NumberExpr, *StringExpr:
+		return true
+	case *ListExpr:
+		for _, e := range x.Elements {
+			if !IsLiteral(e) {
+				return false
+			}
+		}
+		return true
+	case *ObjectExpr:
+		for _, e := range x.Entries {
+			if _, ok := e.Key.(*StringExpr); !ok || !IsLiteral(e.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+type builtinParser func(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics)
+
+// lookupBuiltin returns the canonical name and the parser of the builtin function named key, which
+// is matched case-insensitively. It returns a nil parser if key doesn't name a builtin.
+func lookupBuiltin(key string) (string, builtinParser) {
+	switch strings.ToLower(key) {
+	case "fn::invoke":
+		return "fn::invoke", parseInvoke
+	case "fn::join":
+		return "fn::join", parseJoin
 	case "fn::tojson":
-		set("fn::toJSON", parseToJSON)
+		return "fn::toJSON", parseToJSON
 	case "fn::tobase64":
-		set("fn::toBase64", parseToBase64)
+		return "fn::toBase64", parseToBase64
 	case "fn::frombase64":
-		set("fn::fromBase64", parseFromBase64)
+		return "fn::fromBase64", parseFromBase64
+	case "fn::base64gzip":
+		return "fn::base64gzip", parseBase64Gzip
+	case "fn::sort":
+		return "fn::sort", parseSort
+	case "fn::unique":
+		return "fn::unique", parseUnique
+	case "fn::keys":
+		return "fn::keys", parseKeys
+	case "fn::values":
+		return "fn::values", parseValues
+	case "fn::flatten":
+		return "fn::flatten", parseFlatten
+	case "fn::range":
+		return "fn::range", parseRange
+	case "fn::cidrsubnet":
+		return "fn::cidrsubnet", parseCidrSubnet
+	case "fn::cidrhost":
+		return "fn::cidrhost", parseCidrHost
 	case "fn::select":
-		set("fn::select", parseSelect)
+		return "fn::select", parseSelect
 	case "fn::split":
-		set("fn::split", parseSplit)
+		return "fn::split", parseSplit
+	case "fn::lookup":
+		return "fn::lookup", parseLookup
+	case "fn::jsonpath":
+		return "fn::jsonPath", parseJSONPath
+	case "fn::semversatisfies":
+		return "fn::semverSatisfies", parseSemverSatisfies
+	case "fn::semvercompare":
+		return "fn::semverCompare", parseSemverCompare
 	case "fn::stackreference":
-		set("fn::stackReference", parseStackReference)
-		diags = append(diags, syntax.Warning(kvp.Key.Syntax().Range(),
-			`'fn::stackReference' is deprecated; please use 'pulumi:pulumi:StackReference' instead`,
-			`see "https://www.pulumi.com/docs/intro/concepts/stack/#stackreferences for more info.`))
+		return "fn::stackReference", parseStackReference
 	case "fn::assetarchive":
-		set("fn::assetArchive", parseAssetArchive)
+		return "fn::assetArchive", parseAssetArchive
+	case "fn::filehash":
+		return "fn::fileHash", parseFileHash
+	case "fn::globassets":
+		return "fn::globAssets", parseGlobAssets
 	case "fn::secret":
-		set("fn::secret", parseSecret)
+		return "fn::secret", parseSecret
 	case "fn::readfile":
-		set("fn::readFile", parseReadFile)
-	default:
+		return "fn::readFile", parseReadFile
+	case "fn::envfile":
+		return "fn::envFile", parseEnvFile
+	case "fn::now":
+		return "fn::now", parseNow
+	case "fn::timeadd":
+		return "fn::timeAdd", parseTimeAdd
+	case "fn::env":
+		return "fn::env", parseEnv
+	case "fn::stackconfig":
+		return "fn::stackConfig", parseStackConfig
+	case "fn::uuid":
+		return "fn::uuid", parseUUID
+	case "fn::randomid":
+		return "fn::randomId", parseRandomID
+	case "fn::trim":
+		return "fn::trim", parseTrim
+	case "fn::upper":
+		return "fn::upper", parseUpper
+	case "fn::lower":
+		return "fn::lower", parseLower
+	case "fn::title":
+		return "fn::title", parseTitle
+	case "fn::format":
+		return "fn::format", parseFormat
+	case "fn::tostring":
+		return "fn::toString", parseToString
+	case "fn::tonumber":
+		return "fn::toNumber", parseToNumber
+	case "fn::tobool":
+		return "fn::toBool", parseToBool
+	case "fn::getatt":
+		return "fn::getAtt", parseGetAtt
+	case "fn::getresource":
+		return "fn::getResource", parseGetResource
+	case "fn::map":
+		return "fn::map", parseMap
+	case "fn::filter":
+		return "fn::filter", parseFilter
+	case "fn::reduce":
+		return "fn::reduce", parseReduce
+	case "fn::whenunknown":
+		return "fn::whenUnknown", parseWhenUnknown
+	}
+	return "", nil
+}
+
+// CanonicalBuiltinName returns the canonical spelling of the builtin function or asset named key,
+// which is matched case-insensitively, and whether key names one.
+func CanonicalBuiltinName(key string) (string, bool) {
+	if expected, p := lookupBuiltin(key); p != nil {
+		return expected, true
+	}
+	switch strings.ToLower(key) {
+	case "fn::stringasset":
+		return "fn::stringAsset", true
+	case "fn::fileasset":
+		return "fn::fileAsset", true
+	case "fn::remoteasset":
+		return "fn::remoteAsset", true
+	case "fn::filearchive":
+		return "fn::fileArchive", true
+	case "fn::remotearchive":
+		return "fn::remoteArchive", true
+	}
+	return "", false
+}
+
+func tryParseFunction(node *syntax.ObjectNode) (Expr, syntax.Diagnostics, bool) {
+	if node.Len() != 1 {
+		return nil, nil, false
+	}
+
+	kvp := node.Index(0)
+
+	if _, _, ok := getAssetOrArchive(StringSyntax(kvp.Key)); ok {
+		// We will parse this node as an asset or archive later, so we don't need to do it now
+		return nil, nil, false
+	}
+
+	var parse builtinParser
+	var diags syntax.Diagnostics
+	if expected, p := lookupBuiltin(kvp.Key.Value()); p != nil {
+		diags.Extend(syntax.UnexpectedCasing(kvp.Key.Syntax().Range(), expected, kvp.Key.Value()))
+		parse = p
+		if expected == "fn::stackReference" {
+			diags = append(diags, syntax.Warning(kvp.Key.Syntax().Range(),
+				`'fn::stackReference' is deprecated; please use 'pulumi:pulumi:StackReference' instead`,
+				`see "https://www.pulumi.com/docs/intro/concepts/stack/#stackreferences for more info.`))
+		}
+	} else {
 		k := kvp.Key.Value()
 		// fn::invoke can be called as fn::${pkg}:${module}(:${name})?
 		// error is thrown if regex pattern cannot be parsed — handled by `regex.MustCompile(fnInvokeRegex)`
@@ -810,14 +2147,15 @@ func tryParseFunction(node *syntax.ObjectNode) (Expr, syntax.Diagnostics, bool)
 				)
 			}
 			parse = parseInvoke
-			break
 		} else if strings.HasPrefix(strings.ToLower(k), "fn::") {
 			diags = append(diags, syntax.Warning(kvp.Key.Syntax().Range(),
 				"'fn::' is a reserved prefix",
 				fmt.Sprintf("If you need to use the raw key '%s',"+
 					" please open an issue at https://github.com/pulumi/pulumi-yaml/issues", k)))
 		}
-		return nil, diags, false
+		if parse == nil {
+			return nil, diags, false
+		}
 	}
 
 	name := StringSyntax(kvp.Key)
@@ -845,7 +2183,7 @@ func parseInvoke(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, sy
 		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::invoke must be an object containing 'function', 'arguments', 'options', and 'return'", "")}
 	}
 
-	var functionExpr, argumentsExpr, returnExpr Expr
+	var functionExpr, argumentsExpr, returnExpr, assignExpr Expr
 	var diags syntax.Diagnostics
 	opts := InvokeOptionsDecl{}
 
@@ -868,16 +2206,20 @@ func parseInvoke(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, sy
 			case "return":
 				diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "return", str.GetValue()))
 				returnExpr = kvp.Value
+			case "assign":
+				diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "assign", str.GetValue()))
+				assignExpr = kvp.Value
 			}
 		}
 	}
 
 	function, ok := functionExpr.(*StringExpr)
-	if !ok {
+	functionRef, isRef := functionExpr.(*SymbolExpr)
+	if !ok && !isRef {
 		if functionExpr == nil {
 			diags.Extend(ExprError(obj, "missing function name ('function')", ""))
 		} else {
-			diags.Extend(ExprError(functionExpr, "function name must be a string literal", ""))
+			diags.Extend(ExprError(functionExpr, "function name must be a string literal or a reference to a config value", ""))
 		}
 	}
 
@@ -891,11 +2233,36 @@ func parseInvoke(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, sy
 		diags.Extend(ExprError(returnExpr, "return directive must be a string literal", ""))
 	}
 
+	var assign []InvokeAssignment
+	if assignExpr != nil {
+		assignObj, ok := assignExpr.(*ObjectExpr)
+		switch {
+		case !ok:
+			diags.Extend(ExprError(assignExpr, "assign directive must be an object mapping variable names to properties of the result", ""))
+		case returnExpr != nil:
+			diags.Extend(ExprError(assignExpr, "assign directive cannot be used with the return directive", ""))
+		default:
+			for _, kvp := range assignObj.Entries {
+				variable, vok := kvp.Key.(*StringExpr)
+				property, pok := kvp.Value.(*StringExpr)
+				if !vok || !pok || property.Value == "" {
+					diags.Extend(ExprError(kvp.Value, "each assignment must map a variable name to a property of the result, "+
+						"such as 'vpcId: id'", ""))
+					continue
+				}
+				assign = append(assign, InvokeAssignment{Name: variable, Property: property})
+			}
+		}
+	}
+
 	if diags.HasErrors() {
 		return nil, diags
 	}
 
-	return InvokeSyntax(node, name, obj, function, arguments, opts, ret), diags
+	invoke := InvokeSyntax(node, name, obj, function, arguments, opts, ret)
+	invoke.TokenRef = functionRef
+	invoke.Assign = assign
+	return invoke, diags
 }
 
 func parseJoin(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
@@ -922,6 +2289,24 @@ func parseSelect(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, sy
 	return SelectSyntax(node, name, list, index, values), nil
 }
 
+func parseLookup(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 3 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::lookup must be a three-valued list of a map, a key and a default", "")}
+	}
+
+	return LookupSyntax(node, name, list), nil
+}
+
+func parseRange(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || (len(list.Elements) != 2 && len(list.Elements) != 3) {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::range must be a list of a start, an end and an optional step", "")}
+	}
+
+	return RangeSyntax(node, name, list), nil
+}
+
 func parseSplit(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
 	list, ok := args.(*ListExpr)
 	if !ok || len(list.Elements) != 2 {
@@ -931,6 +2316,33 @@ func parseSplit(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syn
 	return SplitSyntax(node, name, list), nil
 }
 
+func parseSemverSatisfies(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::semverSatisfies must be a two-valued list of a version and a constraint", "")}
+	}
+
+	return SemverSatisfiesSyntax(node, name, list), nil
+}
+
+func parseSemverCompare(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::semverCompare must be a two-valued list of versions", "")}
+	}
+
+	return SemverCompareSyntax(node, name, list), nil
+}
+
+func parseJSONPath(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::jsonPath must be a two-valued list of a JSON string and a path", "")}
+	}
+
+	return JSONPathSyntax(node, name, list), nil
+}
+
 func parseToBase64(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
 	return ToBase64Syntax(node, name, args), nil
 }
@@ -939,6 +2351,78 @@ func parseFromBase64(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr
 	return FromBase64Syntax(node, name, args), nil
 }
 
+func parseBase64Gzip(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return Base64GzipSyntax(node, name, args), nil
+}
+
+func parseSort(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return SortSyntax(node, name, args), nil
+}
+
+func parseKeys(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return KeysSyntax(node, name, args), nil
+}
+
+func parseValues(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return ValuesSyntax(node, name, args), nil
+}
+
+func parseUnique(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	return UniqueSyntax(node, name, args), nil
+}
+
+func parseFlatten(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	obj, ok := args.(*ObjectExpr)
+	if !ok {
+		return FlattenSyntax(node, name, args, args, nil), nil
+	}
+
+	var value, depth Expr
+	var diags syntax.Diagnostics
+	for _, kvp := range obj.Entries {
+		str, ok := kvp.Key.(*StringExpr)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(str.Value) {
+		case "list":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "list", str.GetValue()))
+			value = kvp.Value
+		case "depth":
+			diags.Extend(syntax.UnexpectedCasing(str.syntax.Syntax().Range(), "depth", str.GetValue()))
+			depth = kvp.Value
+		default:
+			diags.Extend(ExprError(kvp.Key, fmt.Sprintf("unknown property %q; fn::flatten accepts 'list' and 'depth'", str.Value), ""))
+		}
+	}
+	if value == nil {
+		diags.Extend(ExprError(obj, "missing list to flatten ('list')", ""))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return FlattenSyntax(node, name, obj, value, depth), diags
+}
+
+func parseCidrSubnet(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 3 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::cidrsubnet must be a three-valued list", "")}
+	}
+
+	return CidrSubnetSyntax(node, name, list, list.Elements[0], list.Elements[1], list.Elements[2]), nil
+}
+
+func parseCidrHost(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
+	list, ok := args.(*ListExpr)
+	if !ok || len(list.Elements) != 2 {
+		return nil, syntax.Diagnostics{ExprError(args, "the argument to fn::cidrhost must be a two-valued list", "")}
+	}
+
+	return CidrHostSyntax(node, name, list, list.Elements[0], list.Elements[1]), nil
+}
+
 func parseStackReference(node *syntax.ObjectNode, name *StringExpr, args Expr) (Expr, syntax.Diagnostics) {
 	list, ok := args.(*ListExpr)
 	if !ok || len(list.Elements) != 2 {
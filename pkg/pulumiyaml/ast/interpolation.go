@@ -11,6 +11,10 @@ import (
 type Interpolation struct {
 	Text  string
 	Value *PropertyAccess
+
+	// Default is interpolated in place of Value when Value is null or empty, as in
+	// ${property.access:-default}. It is nil if the interpolation has no default.
+	Default *InterpolateExpr
 }
 
 func parseInterpolate(node syntax.Node, value string) ([]Interpolation, syntax.Diagnostics) {
@@ -26,9 +30,22 @@ func parseInterpolate(node syntax.Node, value string) ([]Interpolation, syntax.D
 			if len(diags) != 0 {
 				return nil, diags
 			}
+			var def *InterpolateExpr
+			if strings.HasPrefix(rest, ":-") {
+				text, after, ok := splitInterpolationDefault(rest[2:])
+				if !ok {
+					return nil, syntax.Diagnostics{syntax.NodeError(node, "unterminated interpolation", "")}
+				}
+				defParts, diags := parseInterpolate(node, text)
+				if len(diags) != 0 {
+					return nil, diags
+				}
+				def, rest = &InterpolateExpr{exprNode: expr(node), Parts: defParts}, after
+			}
 			parts = append(parts, Interpolation{
-				Text:  str.String(),
-				Value: access,
+				Text:    str.String(),
+				Value:   access,
+				Default: def,
 			})
 			str.Reset()
 
@@ -43,3 +60,24 @@ func parseInterpolate(node syntax.Node, value string) ([]Interpolation, syntax.D
 	}
 	return parts, nil
 }
+
+// splitInterpolationDefault splits the default of an interpolation, which may itself contain
+// interpolations, from the rest of the string that follows its closing brace.
+func splitInterpolationDefault(value string) (string, string, bool) {
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "$$"):
+			i++
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}':
+			if depth == 0 {
+				return value[:i], value[i+1:], true
+			}
+			depth--
+		}
+	}
+	return "", "", false
+}
@@ -16,3 +16,24 @@ func TestEscapeInterpolationWorks(t *testing.T) {
 	assert.Len(t, parts, 1, "Expected one interpolation part")
 	assert.Equal(t, "Hello ${world}!", parts[0].Text)
 }
+
+func TestInterpolationDefault(t *testing.T) {
+	t.Parallel()
+	node := syntax.String("a ${foo.bar:-x-${baz:-}} $${qux:-y} c:-d")
+	parts, diags := parseInterpolate(node, node.Value())
+	assert.Empty(t, diags)
+	assert.Len(t, parts, 2)
+	assert.Equal(t, "a ", parts[0].Text)
+	assert.Equal(t, "foo.bar", parts[0].Value.String())
+	assert.Equal(t, "x-${baz:-}", parts[0].Default.String())
+	assert.Equal(t, " ${qux:-y} c:-d", parts[1].Text)
+	assert.Nil(t, parts[1].Default)
+
+	x := MustInterpolate(node.Value())
+	assert.Equal(t, node.Value(), x.String())
+
+	// A lone access with a default is an interpolation, so it always evaluates to a string.
+	e, diags := ParseExpr(syntax.String("${foo:-bar}"))
+	assert.Empty(t, diags)
+	assert.IsType(t, &InterpolateExpr{}, e)
+}
@@ -83,6 +83,8 @@ func (p *PropertySubscript) rootName() string {
 //	propertyAccessor := ( ( '.' propertyName ) |  propertyIndex )
 //	path := rootProperty { propertyAccessor }
 //
+// A path may be followed by ':-' and a default, which is left for the caller to parse.
+//
 // Examples of valid paths:
 // - root
 // - root.nested
@@ -108,6 +110,10 @@ func parsePropertyAccess(node syntax.Node, access string) (string, *PropertyAcce
 	// path := { pathElement }
 	var accessors []PropertyAccessor
 	for len(access) > 0 {
+		if strings.HasPrefix(access, ":-") {
+			// the start of a default, which the caller parses
+			return access, &PropertyAccess{Accessors: accessors}, nil
+		}
 		switch access[0] {
 		case '}':
 			// interpolation terminator
@@ -159,7 +165,8 @@ func parsePropertyAccess(node syntax.Node, access string) (string, *PropertyAcce
 			accessors, access = append(accessors, &PropertySubscript{Index: indexNode}), access[1:]
 		default:
 			for i := 0; ; i++ {
-				if i == len(access) || access[i] == '.' || access[i] == '[' || access[i] == '}' {
+				if i == len(access) || access[i] == '.' || access[i] == '[' || access[i] == '}' ||
+					strings.HasPrefix(access[i:], ":-") {
 					accessors, access = append(accessors, &PropertyName{Name: access[:i]}), access[i:]
 					break
 				}
@@ -0,0 +1,82 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package ast
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// DefaultProviderName is the name of the provider resource that the providers section declares
+// for pkg.
+func DefaultProviderName(pkg string) string {
+	return pkg + "-default-provider"
+}
+
+// declareProviders adds a provider resource to the template for each entry of its providers
+// section. The provider is the default provider of its package, so it is used by the resources and
+// invokes of the package that don't set a provider of their own, and its properties are checked
+// against the schema of the provider like those of any other resource.
+func (d *TemplateDecl) declareProviders() syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	for _, kvp := range d.Providers.Entries {
+		pkg := kvp.Key.Value
+		name := DefaultProviderName(pkg)
+		typ := "pulumi:providers:" + pkg
+
+		conflict := false
+		for _, r := range d.Resources.Entries {
+			if r.Key.Value == name {
+				diags.Extend(ExprError(r.Key,
+					fmt.Sprintf("resource %q has the name of the default provider of %q", name, pkg),
+					"The providers section declares it. Rename the resource"))
+				conflict = true
+			}
+			res := r.Value
+			if res != nil && res.Type != nil && res.Type.Value == typ &&
+				res.DefaultProvider != nil && res.DefaultProvider.Value {
+				diags.Extend(ExprError(res.DefaultProvider,
+					fmt.Sprintf("the default provider of %q is already declared in providers", pkg),
+					"Remove defaultProvider from this resource, or remove the package from providers"))
+				conflict = true
+			}
+		}
+		if conflict {
+			continue
+		}
+
+		obj, ok := kvp.Value.(*ObjectExpr)
+		if !ok {
+			diags.Extend(ExprError(kvp.Value,
+				fmt.Sprintf("the configuration of the default provider of %q must be an object", pkg), ""))
+			continue
+		}
+		var properties PropertyMapDecl
+		for _, entry := range obj.Entries {
+			key, ok := entry.Key.(*StringExpr)
+			if !ok {
+				diags.Extend(ExprError(entry.Key, "provider properties must be named by strings", ""))
+				continue
+			}
+			properties.Entries = append(properties.Entries, PropertyMapEntry{Key: key, Value: entry.Value})
+		}
+
+		// The provider takes its position from the key, so that diagnostics about it point there.
+		// Its declaration has no syntax of its own, since its fields aren't written out.
+		keyNode, _ := kvp.Key.Syntax().(*syntax.StringNode)
+		key, typeExpr := String(name), String(typ)
+		if keyNode != nil {
+			key, typeExpr = StringSyntaxValue(keyNode, name), StringSyntaxValue(keyNode, typ)
+		}
+		d.Resources.Entries = append(d.Resources.Entries, ResourcesMapEntry{
+			Key: key,
+			Value: &ResourceDecl{
+				Type:            typeExpr,
+				DefaultProvider: Boolean(true),
+				Properties:      properties,
+			},
+		})
+	}
+	return diags
+}
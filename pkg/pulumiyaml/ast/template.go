@@ -76,6 +76,108 @@ func (d *StringListDecl) parse(name string, node syntax.Node) syntax.Diagnostics
 	return diags
 }
 
+// An AliasDecl describes an identity that a resource previously had. An alias is either the URN
+// the resource used to have, or an object listing the parts of its identity that have changed.
+type AliasDecl struct {
+	declNode
+
+	// We need to call the field Urn instead of URN because we want the derived user field to be urn instead of uRN
+	Urn     *StringExpr //nolint:revive
+	Name    *StringExpr
+	Type    *StringExpr
+	Project *StringExpr
+	Stack   *StringExpr
+}
+
+func (d *AliasDecl) recordSyntax() *syntax.Node {
+	return &d.syntax
+}
+
+type AliasesDecl struct {
+	declNode
+
+	Elements []*AliasDecl
+}
+
+func AliasSyntax(node syntax.Node, urn, name, typ, project, stack *StringExpr) *AliasDecl {
+	return &AliasDecl{
+		declNode: decl(node),
+		Urn:      urn,
+		Name:     name,
+		Type:     typ,
+		Project:  project,
+		Stack:    stack,
+	}
+}
+
+func Alias(urn, name, typ, project, stack *StringExpr) *AliasDecl {
+	return AliasSyntax(nil, urn, name, typ, project, stack)
+}
+
+func AliasesSyntax(node syntax.Node, elements ...*AliasDecl) *AliasesDecl {
+	return &AliasesDecl{
+		declNode: decl(node),
+		Elements: elements,
+	}
+}
+
+func Aliases(elements ...*AliasDecl) *AliasesDecl {
+	return AliasesSyntax(nil, elements...)
+}
+
+func (d *AliasesDecl) GetElements() []*AliasDecl {
+	if d == nil {
+		return nil
+	}
+	return d.Elements
+}
+
+func (d *AliasesDecl) parse(name string, node syntax.Node) syntax.Diagnostics {
+	list, ok := node.(*syntax.ListNode)
+	if !ok {
+		return syntax.Diagnostics{syntax.NodeError(node, fmt.Sprintf("%v must be a list", name), "")}
+	}
+
+	var diags syntax.Diagnostics
+
+	elements := make([]*AliasDecl, list.Len())
+	for i := range elements {
+		ename := fmt.Sprintf("%s[%d]", name, i)
+		elem := list.Index(i)
+		alias := &AliasDecl{declNode: decl(elem)}
+		switch elem.(type) {
+		case *syntax.StringNode:
+			diags.Extend(parseField(ename, reflect.ValueOf(&alias.Urn).Elem(), elem)...)
+		case *syntax.ObjectNode:
+			diags.Extend(parseRecord(ename, alias, elem, true)...)
+		default:
+			diags.Extend(syntax.NodeError(elem, fmt.Sprintf("%v must be a URN or an object", ename), ""))
+		}
+		elements[i] = alias
+	}
+	d.Elements = elements
+
+	return diags
+}
+
+// checkDuplicateKeys reports the keys of obj that appear more than once. Decoding the template
+// would otherwise silently keep only one of the entries.
+func checkDuplicateKeys(name string, obj *syntax.ObjectNode) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	seen := map[string]*syntax.StringNode{}
+	for i := 0; i < obj.Len(); i++ {
+		key := obj.Index(i).Key
+		first, ok := seen[key.Value()]
+		if !ok {
+			seen[key.Value()] = key
+			continue
+		}
+		diags.Extend(syntax.NodeError(key, fmt.Sprintf("found duplicate key %q in %s", key.Value(), name), "").
+			WithRelated(first.Syntax().Range(), fmt.Sprintf("The other %q is declared here", key.Value())))
+	}
+	return diags
+}
+
 type ConfigMapEntry struct {
 	syntax syntax.ObjectPropertyDef
 	Key    *StringExpr
@@ -99,6 +201,7 @@ func (d *ConfigMapDecl) parse(name string, node syntax.Node) syntax.Diagnostics
 	}
 
 	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
 
 	entries := make([]ConfigMapEntry, obj.Len())
 	for i := range entries {
@@ -154,6 +257,7 @@ func (d *VariablesMapDecl) parse(name string, node syntax.Node) syntax.Diagnosti
 	}
 
 	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
 
 	entries := make([]VariablesMapEntry, obj.Len())
 	for i := range entries {
@@ -168,6 +272,21 @@ func (d *VariablesMapDecl) parse(name string, node syntax.Node) syntax.Diagnosti
 			Value:  v,
 		}
 	}
+
+	// Declare the variables assigned from the results of invokes.
+	for _, entry := range entries {
+		invoke, ok := entry.Value.(*InvokeExpr)
+		if !ok {
+			continue
+		}
+		for _, a := range invoke.Assign {
+			value, adiags := a.assignedVariable(entry.Key.Value)
+			diags.Extend(adiags...)
+			if value != nil {
+				entries = append(entries, VariablesMapEntry{Key: a.Name, Value: value})
+			}
+		}
+	}
 	d.Entries = entries
 
 	return diags
@@ -190,12 +309,17 @@ func (d *ResourcesMapDecl) defaultValue() interface{} {
 }
 
 func (d *ResourcesMapDecl) parse(name string, node syntax.Node) syntax.Diagnostics {
+	if list, ok := node.(*syntax.ListNode); ok {
+		return d.parseList(name, list)
+	}
+
 	obj, ok := node.(*syntax.ObjectNode)
 	if !ok {
-		return syntax.Diagnostics{syntax.NodeError(node, fmt.Sprintf("%v must be an object", name), "")}
+		return syntax.Diagnostics{syntax.NodeError(node, fmt.Sprintf("%v must be an object or a list", name), "")}
 	}
 
 	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
 
 	entries := make([]ResourcesMapEntry, obj.Len())
 	for i := range entries {
@@ -217,6 +341,50 @@ func (d *ResourcesMapDecl) parse(name string, node syntax.Node) syntax.Diagnosti
 	return diags
 }
 
+// parseList parses the ordered list form of resources, where each element is a resource
+// declaration that carries its own name:
+//
+//	resources:
+//	  - name: my-bucket
+//	    type: aws:s3:Bucket
+//
+// The declared name is used as the entry key, so list and map forms are interchangeable once
+// parsed. Duplicate names are reported when the template is sorted.
+func (d *ResourcesMapDecl) parseList(name string, list *syntax.ListNode) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+
+	entries := make([]ResourcesMapEntry, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		elem := list.Index(i)
+
+		var v *ResourceDecl
+		vname := fmt.Sprintf("%s[%d]", name, i)
+		vdiags := parseField(vname, reflect.ValueOf(&v).Elem(), elem)
+		diags.Extend(vdiags...)
+		if vdiags.HasErrors() || v == nil {
+			continue
+		}
+
+		var nameNode *syntax.StringNode
+		if v.Name != nil {
+			nameNode, _ = v.Name.Syntax().(*syntax.StringNode)
+		}
+		if nameNode == nil || nameNode.Value() == "" {
+			diags.Extend(syntax.NodeError(elem, fmt.Sprintf("%s must have a 'name'", vname), ""))
+			continue
+		}
+
+		entries = append(entries, ResourcesMapEntry{
+			syntax: syntax.ObjectPropertySyntax(elem.Syntax(), nameNode, elem),
+			Key:    StringSyntax(nameNode),
+			Value:  v,
+		})
+	}
+	d.Entries = entries
+
+	return diags
+}
+
 type PropertyMapEntry struct {
 	syntax syntax.ObjectPropertyDef
 	Key    *StringExpr
@@ -248,6 +416,7 @@ func (d *PropertyMapDecl) parse(name string, node syntax.Node) syntax.Diagnostic
 	}
 
 	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
 
 	entries := make([]PropertyMapEntry, obj.Len())
 	for i := range entries {
@@ -269,6 +438,81 @@ func (d *PropertyMapDecl) parse(name string, node syntax.Node) syntax.Diagnostic
 	return diags
 }
 
+type OutputGroupEntry struct {
+	syntax syntax.ObjectPropertyDef
+	Key    *StringExpr
+	Value  PropertyMapDecl
+}
+
+// OutputGroupsDecl declares groups of stack outputs. Each output in a group is exported under the
+// dotted name "<group>.<output>", so that large stacks can namespace their outputs:
+//
+//	outputGroups:
+//	  network:
+//	    vpcId: ${vpc.id}
+//
+// exports the output "network.vpcId".
+type OutputGroupsDecl struct {
+	declNode
+
+	Entries []OutputGroupEntry
+}
+
+func (d *OutputGroupsDecl) defaultValue() interface{} {
+	return &OutputGroupsDecl{}
+}
+
+func (d *OutputGroupsDecl) parse(name string, node syntax.Node) syntax.Diagnostics {
+	obj, ok := node.(*syntax.ObjectNode)
+	if !ok {
+		return syntax.Diagnostics{syntax.NodeError(node, fmt.Sprintf("%v must be an object", name), "")}
+	}
+	d.syntax = obj
+
+	var diags syntax.Diagnostics
+	diags.Extend(checkDuplicateKeys(name, obj)...)
+
+	entries := make([]OutputGroupEntry, obj.Len())
+	for i := range entries {
+		kvp := obj.Index(i)
+
+		var v PropertyMapDecl
+		vname := fmt.Sprintf("%s.%s", name, kvp.Key.Value())
+		diags.Extend(v.parse(vname, kvp.Value)...)
+
+		entries[i] = OutputGroupEntry{
+			syntax: kvp,
+			Key:    StringSyntax(kvp.Key),
+			Value:  v,
+		}
+	}
+	d.Entries = entries
+
+	return diags
+}
+
+// Flatten returns the outputs of every group, keyed by their dotted names. The keys keep the
+// syntax of the output's own key, so diagnostics point at the declaration in its group.
+func (d *OutputGroupsDecl) Flatten() []PropertyMapEntry {
+	var entries []PropertyMapEntry
+	for _, group := range d.Entries {
+		for _, kvp := range group.Value.Entries {
+			var key *StringExpr
+			if node, ok := kvp.Key.Syntax().(*syntax.StringNode); ok {
+				key = StringSyntaxValue(node, group.Key.Value+"."+kvp.Key.Value)
+			} else {
+				key = String(group.Key.Value + "." + kvp.Key.Value)
+			}
+			entries = append(entries, PropertyMapEntry{
+				syntax: kvp.syntax,
+				Key:    key,
+				Value:  kvp.Value,
+			})
+		}
+	}
+	return entries
+}
+
 type ConfigParamDecl struct {
 	declNode
 
@@ -278,6 +522,23 @@ type ConfigParamDecl struct {
 	Default Expr
 	Value   Expr
 	Items   *ConfigParamDecl
+	// The properties of an Object config entry. Items describes the elements of a
+	// List<Object> config entry.
+	Properties ConfigMapDecl
+
+	// Constraints on the value of the config entry, in the style of JSON schema.
+	Minimum       *NumberExpr
+	Maximum       *NumberExpr
+	Pattern       *StringExpr
+	AllowedValues *ListExpr
+	MinLength     *NumberExpr
+	MaxLength     *NumberExpr
+}
+
+// HasConstraints returns true if the config entry declares any constraints on its value.
+func (d *ConfigParamDecl) HasConstraints() bool {
+	return d.Minimum != nil || d.Maximum != nil || d.Pattern != nil || d.AllowedValues != nil ||
+		d.MinLength != nil || d.MaxLength != nil
 }
 
 func (d *ConfigParamDecl) recordSyntax() *syntax.Node {
@@ -304,21 +565,31 @@ type ResourceOptionsDecl struct {
 	declNode
 
 	AdditionalSecretOutputs *StringListDecl
-	Aliases                 *StringListDecl
+	Aliases                 *AliasesDecl
 	CustomTimeouts          *CustomTimeoutsDecl
 	DeleteBeforeReplace     *BooleanExpr
 	DependsOn               Expr
 	IgnoreChanges           *StringListDecl
-	Import                  *StringExpr
+	Import                  Expr
 	Parent                  Expr
-	Protect                 Expr
-	Provider                Expr
-	Providers               Expr
-	Version                 *StringExpr
-	PluginDownloadURL       *StringExpr
-	ReplaceOnChanges        *StringListDecl
-	RetainOnDelete          *BooleanExpr
-	DeletedWith             Expr
+	// Protect defaults to whether the parent is protected. A resource can protect itself when its
+	// parent isn't, but can't unprotect itself when its parent is protected.
+	Protect Expr
+	// Provider defaults to the provider of the parent for the resource's package, as given by the
+	// parent's own provider or providers options or inherited from its parent in turn. The
+	// provider set here takes precedence over any provider inherited from the parent.
+	Provider  Expr
+	Providers Expr
+	Version   *StringExpr
+	// PluginDownloadURL isn't interpolated, so that it can contain the placeholders ${version},
+	// ${os} and ${arch}, which are substituted when the plugin is downloaded.
+	PluginDownloadURL *StringExpr `ast:"literal"`
+	ReplaceOnChanges  *StringListDecl
+	RetainOnDelete    *BooleanExpr
+	DeletedWith       Expr
+	// Transforms names transforms registered by the program embedding the YAML runtime, which
+	// are applied to the resource before it is registered.
+	Transforms *StringListDecl
 }
 
 func (d *ResourceOptionsDecl) defaultValue() interface{} {
@@ -329,6 +600,8 @@ func (d *ResourceOptionsDecl) recordSyntax() *syntax.Node {
 	return &d.syntax
 }
 
+// ResourceOptionsSyntax takes aliases as a list of URNs and the import ID as a string. Aliases
+// given as objects, or an import ID given as an expression, can be set on the returned options.
 func ResourceOptionsSyntax(node *syntax.ObjectNode,
 	additionalSecretOutputs, aliases *StringListDecl, customTimeouts *CustomTimeoutsDecl,
 	deleteBeforeReplace *BooleanExpr, dependsOn Expr, ignoreChanges *StringListDecl, importID *StringExpr,
@@ -336,15 +609,13 @@ func ResourceOptionsSyntax(node *syntax.ObjectNode,
 	pluginDownloadURL *StringExpr, replaceOnChanges *StringListDecl,
 	retainOnDelete *BooleanExpr, deletedWith Expr) ResourceOptionsDecl {
 
-	return ResourceOptionsDecl{
+	options := ResourceOptionsDecl{
 		declNode:                decl(node),
 		AdditionalSecretOutputs: additionalSecretOutputs,
-		Aliases:                 aliases,
 		CustomTimeouts:          customTimeouts,
 		DeleteBeforeReplace:     deleteBeforeReplace,
 		DependsOn:               dependsOn,
 		IgnoreChanges:           ignoreChanges,
-		Import:                  importID,
 		Parent:                  parent,
 		Protect:                 protect,
 		Provider:                provider,
@@ -354,6 +625,17 @@ func ResourceOptionsSyntax(node *syntax.ObjectNode,
 		RetainOnDelete:          retainOnDelete,
 		DeletedWith:             deletedWith,
 	}
+	if aliases != nil {
+		elements := make([]*AliasDecl, len(aliases.Elements))
+		for i, urn := range aliases.Elements {
+			elements[i] = AliasSyntax(urn.Syntax(), urn, nil, nil, nil, nil)
+		}
+		options.Aliases = AliasesSyntax(aliases.syntax, elements...)
+	}
+	if importID != nil {
+		options.Import = importID
+	}
+	return options
 }
 
 func ResourceOptions(additionalSecretOutputs, aliases *StringListDecl,
@@ -370,11 +652,23 @@ func ResourceOptions(additionalSecretOutputs, aliases *StringListDecl,
 type InvokeOptionsDecl struct {
 	declNode
 
-	DependsOn         Expr
-	Parent            Expr
-	Provider          Expr
-	Version           *StringExpr
-	PluginDownloadURL *StringExpr
+	// After lists resources and variables that the invoke must be evaluated after, for when the
+	// order can't be inferred from the invoke's arguments.
+	After Expr
+	// Cache controls whether the result of the invoke may be shared with identical invokes in the
+	// same run. It defaults to true, and should be set to false for functions with side effects.
+	Cache *BooleanExpr
+	// DependsOn lists resources that must be created before the invoke runs. The invoke waits for
+	// them, so if any of them are being created or changed it is run during the update instead
+	// of during preview.
+	DependsOn Expr
+	// Parent is a resource that the invoke belongs to. The invoke uses the parent's provider for
+	// the function's package, unless Provider is set.
+	Parent   Expr
+	Provider Expr
+	Version  *StringExpr
+	// PluginDownloadURL is taken literally, like the PluginDownloadURL of a resource.
+	PluginDownloadURL *StringExpr `ast:"literal"`
 }
 
 func (d *InvokeOptionsDecl) defaultValue() interface{} {
@@ -415,12 +709,22 @@ func GetResource(id *StringExpr, state PropertyMapDecl) GetResourceDecl {
 type ResourceDecl struct {
 	declNode
 
-	Type            *StringExpr
+	// Type isn't interpolated, since it must be known before the template runs. It may refer to
+	// config, such as ${cloud}:compute:Instance, which is resolved before the template is analysed.
+	Type            *StringExpr `ast:"literal"`
 	Name            *StringExpr
 	DefaultProvider *BooleanExpr
 	Properties      PropertyMapDecl
 	Options         ResourceOptionsDecl
 	Get             GetResourceDecl
+	// ForEach, if set, is a list or a map with one element per instance of the resource. Each
+	// instance is named by suffixing the resource's name with its index or key. The properties and
+	// options of an instance refer to the element of a list as ${index} and ${item}, and the entry
+	// of a map as ${key} and ${value}.
+	ForEach Expr
+	// Condition, if set, is a boolean that decides whether the resource is created. References to
+	// a resource that isn't created are null.
+	Condition Expr
 }
 
 func (d *ResourceDecl) recordSyntax() *syntax.Node {
@@ -429,7 +733,7 @@ func (d *ResourceDecl) recordSyntax() *syntax.Node {
 
 // The names of exported fields.
 func (*ResourceDecl) Fields() []string {
-	return []string{"type", "name", "defaultprovider", "properties", "options", "get"}
+	return []string{"type", "name", "defaultprovider", "properties", "options", "get", "foreach", "condition"}
 }
 
 func ResourceSyntax(node *syntax.ObjectNode, typ *StringExpr, name *StringExpr, defaultProvider *BooleanExpr,
@@ -483,6 +787,8 @@ func CustomTimeouts(create, update, delete *StringExpr) *CustomTimeoutsDecl {
 // A TemplateDecl represents a Pulumi YAML template.
 type TemplateDecl struct {
 	source []byte
+	// includedSources holds the source text of the files merged into the template, by filename.
+	includedSources map[string][]byte
 
 	syntax syntax.Node
 
@@ -493,7 +799,23 @@ type TemplateDecl struct {
 	Variables     VariablesMapDecl
 	Resources     ResourcesMapDecl
 	Outputs       PropertyMapDecl
-	Packages      []packages.PackageDecl
+	OutputGroups  OutputGroupsDecl
+	// OutputTypes optionally declares the types of outputs by name. It is kept apart from Outputs
+	// so that object-valued outputs can't be mistaken for type annotations.
+	OutputTypes PropertyMapDecl
+	// Includes lists files, relative to the file that declares them, whose config, variables,
+	// resources and outputs are merged into the template. They are resolved when the template is
+	// loaded from a file or directory.
+	Includes *StringListDecl
+	// DefaultTags are merged into the tags of every resource whose schema has a map-valued tags
+	// input. Tags set by the resource take precedence. They may only refer to config.
+	DefaultTags PropertyMapDecl
+	// Providers configures the default provider of each package by name. See declareProviders.
+	Providers PropertyMapDecl
+	// Components declares components that resources of the template can instantiate. See
+	// ComponentsMapDecl.
+	Components ComponentsMapDecl
+	Packages   []packages.PackageDecl
 }
 
 func (d *TemplateDecl) Syntax() syntax.Node {
@@ -516,6 +838,9 @@ func (d *TemplateDecl) NewDiagnosticWriter(w io.Writer, width uint, color bool)
 			fileMap[s.Syntax().Range().Filename] = &hcl.File{Bytes: d.source}
 		}
 	}
+	for filename, source := range d.includedSources {
+		fileMap[filename] = &hcl.File{Bytes: source}
+	}
 	return newDiagnosticWriter(w, fileMap, width, color)
 }
 
@@ -544,9 +869,84 @@ func ParseTemplate(source []byte, node syntax.Node) (*TemplateDecl, syntax.Diagn
 	template := TemplateDecl{source: source}
 
 	diags := parseRecord("template", &template, node, false)
+	diags.Extend(template.declareProviders()...)
+	diags.Extend(template.declareComponents()...)
 	return &template, diags
 }
 
+// IncludableSections are the top-level sections of an included file that are merged into the
+// including template.
+var IncludableSections = []string{"configuration", "config", "variables", "resources", "outputs", "includes"}
+
+// Merge adds the config, variables, resources and outputs declared by other, a file included by
+// the template, to the template. Entries with the same name as an entry the template already has
+// are reported rather than merged.
+func (d *TemplateDecl) Merge(other *TemplateDecl) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	duplicate := func(section string, key *StringExpr, seen map[string]*StringExpr) bool {
+		first, ok := seen[key.Value]
+		if !ok {
+			seen[key.Value] = key
+			return false
+		}
+		diags.Extend(ExprError(key, fmt.Sprintf("found duplicate key %q in %s", key.Value, section), "").
+			WithRelated(exprRange(first), fmt.Sprintf("The other %q is declared here", key.Value)))
+		return true
+	}
+
+	mergeConfig := func(section string, dest *ConfigMapDecl, entries []ConfigMapEntry) {
+		seen := map[string]*StringExpr{}
+		for _, e := range dest.Entries {
+			seen[e.Key.Value] = e.Key
+		}
+		for _, e := range entries {
+			if !duplicate(section, e.Key, seen) {
+				dest.Entries = append(dest.Entries, e)
+			}
+		}
+	}
+	mergeConfig("configuration", &d.Configuration, other.Configuration.Entries)
+	mergeConfig("config", &d.Config, other.Config.Entries)
+
+	seen := map[string]*StringExpr{}
+	for _, e := range d.Variables.Entries {
+		seen[e.Key.Value] = e.Key
+	}
+	for _, e := range other.Variables.Entries {
+		if !duplicate("variables", e.Key, seen) {
+			d.Variables.Entries = append(d.Variables.Entries, e)
+		}
+	}
+
+	seen = map[string]*StringExpr{}
+	for _, e := range d.Resources.Entries {
+		seen[e.Key.Value] = e.Key
+	}
+	for _, e := range other.Resources.Entries {
+		if !duplicate("resources", e.Key, seen) {
+			d.Resources.Entries = append(d.Resources.Entries, e)
+		}
+	}
+
+	seen = map[string]*StringExpr{}
+	for _, e := range d.Outputs.Entries {
+		seen[e.Key.Value] = e.Key
+	}
+	for _, e := range other.Outputs.Entries {
+		if !duplicate("outputs", e.Key, seen) {
+			d.Outputs.Entries = append(d.Outputs.Entries, e)
+		}
+	}
+
+	if other.source != nil && other.syntax != nil {
+		if d.includedSources == nil {
+			d.includedSources = map[string][]byte{}
+		}
+		d.includedSources[other.syntax.Syntax().Range().Filename] = other.source
+	}
+	return diags
+}
+
 var parseDeclType = reflect.TypeOf((*parseDecl)(nil)).Elem()
 var nonNilDeclType = reflect.TypeOf((*nonNilDecl)(nil)).Elem()
 var recordDeclType = reflect.TypeOf((*recordDecl)(nil)).Elem()
@@ -631,7 +1031,12 @@ func parseRecord(objName string, dest recordDecl, node syntax.Node, noMatchWarni
 		for _, f := range reflect.VisibleFields(t) {
 			if f.IsExported() && strings.EqualFold(f.Name, key) {
 				diags.Extend(syntax.UnexpectedCasing(kvp.Key.Syntax().Range(), camel(f.Name), key))
-				diags.Extend(parseField(camel(f.Name), v.FieldByIndex(f.Index), kvp.Value)...)
+				// Fields tagged `ast:"literal"` take strings as written, without interpolation.
+				if str, ok := kvp.Value.(*syntax.StringNode); ok && f.Tag.Get("ast") == "literal" {
+					v.FieldByIndex(f.Index).Set(reflect.ValueOf(StringSyntax(str)))
+				} else {
+					diags.Extend(parseField(camel(f.Name), v.FieldByIndex(f.Index), kvp.Value)...)
+				}
 				hasMatch = true
 				break
 			}
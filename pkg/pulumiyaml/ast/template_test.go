@@ -3,6 +3,7 @@
 package ast
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -63,3 +64,83 @@ func TestExample(t *testing.T) {
 
 	assert.Nil(t, template.Description)
 }
+
+func TestResourceListForm(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: list-yaml
+runtime: yaml
+resources:
+  - name: zeta
+    type: test:resource:type
+  - name: alpha
+    type: test:resource:type
+    properties:
+      foo: ${zeta.out}
+  - type: test:resource:type
+`
+
+	syntax, diags := encoding.DecodeYAML("<stdin>", yaml.NewDecoder(strings.NewReader(text)), nil)
+	require.Len(t, diags, 0)
+
+	template, diags := ParseTemplate([]byte(text), syntax)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "resources[2] must have a 'name'", diags[0].Summary)
+
+	require.Len(t, template.Resources.Entries, 2)
+	assert.Equal(t, "zeta", template.Resources.Entries[0].Key.Value)
+	assert.Equal(t, "alpha", template.Resources.Entries[1].Key.Value)
+	assert.Equal(t, "alpha", template.Resources.Entries[1].Value.Name.Value)
+	assert.Equal(t, 7, template.Resources.Entries[1].Key.Syntax().Syntax().Range().Start.Line)
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: duplicate-yaml
+runtime: yaml
+resources:
+  bucket:
+    type: test:resource:type
+  bucket:
+    type: test:resource:other
+    properties:
+      foo: 1
+      foo: 2
+`
+	syntax, diags := encoding.DecodeYAML("<stdin>", yaml.NewDecoder(strings.NewReader(text)), nil)
+	require.Len(t, diags, 0)
+
+	template, diags := ParseTemplate([]byte(text), syntax)
+	require.Len(t, diags, 2)
+	assert.Equal(t, `found duplicate key "bucket" in resources`, diags[0].Summary)
+	assert.Empty(t, diags[0].Detail)
+	require.NotNil(t, diags[0].Related)
+	assert.Equal(t, `The other "bucket" is declared here`, diags[0].Related.Message)
+	assert.Equal(t, 5, diags[0].Related.Range.Start.Line)
+	assert.Equal(t, 7, diags[0].Subject.Start.Line)
+	assert.Equal(t, `found duplicate key "foo" in properties`, diags[1].Summary)
+	// Both entries are kept, so later passes can still refer to either.
+	assert.Len(t, template.Resources.Entries, 2)
+
+	// The related range is written after the diagnostic.
+	var buf bytes.Buffer
+	err := template.NewDiagnosticWriter(&buf, 0, false).WriteDiagnostic(diags[0].HCL())
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `<stdin>:5:3: The other "bucket" is declared here`)
+}
+
+func TestResourceOptionsAliasURNs(t *testing.T) {
+	t.Parallel()
+
+	urn := String("urn:pulumi:stack::project::test:resource:type::old")
+	options := ResourceOptions(nil, &StringListDecl{Elements: []*StringExpr{urn}},
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	require.Len(t, options.Aliases.GetElements(), 1)
+	assert.Equal(t, urn, options.Aliases.Elements[0].Urn)
+	assert.Nil(t, options.Aliases.Elements[0].Name)
+	assert.Nil(t, options.Import)
+}
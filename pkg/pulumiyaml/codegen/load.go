@@ -189,6 +189,9 @@ func (imp *importer) importInterpolate(node *ast.InterpolateExpr, substitutions
 	for _, part := range node.Parts {
 		parts = append(parts, plainLit(part.Text))
 
+		if part.Default != nil {
+			diags.Extend(ast.ExprError(node, "an interpolation with a default has no PCL equivalent", ""))
+		}
 		if part.Value != nil {
 			ref, rdiags := imp.importPropertyAccess(node, part.Value, environment, nil)
 			diags.Extend(rdiags...)
@@ -303,6 +306,10 @@ func (imp *importer) importBuiltin(node ast.BuiltinExpr) (model.Expression, synt
 	case *ast.InvokeExpr:
 		var diags syntax.Diagnostics
 
+		if node.TokenRef != nil {
+			return nil, syntax.Diagnostics{ast.ExprError(node.TokenRef, "fn::invoke with a function name read from config has no PCL equivalent", "")}
+		}
+
 		version, err := pulumiyaml.ParseVersion(node.CallOpts.Version)
 		if err != nil {
 			return nil, syntax.Diagnostics{ast.ExprError(node.CallOpts.Version, fmt.Sprintf("unable to parse function provider version: %v", err), "")}
@@ -445,6 +452,15 @@ func (imp *importer) importBuiltin(node ast.BuiltinExpr) (model.Expression, synt
 			Name: "fromBase64",
 			Args: []model.Expression{path},
 		}, pdiags
+	case *ast.Base64GzipExpr, *ast.CidrSubnetExpr, *ast.CidrHostExpr, *ast.SortExpr, *ast.UniqueExpr,
+		*ast.FlattenExpr, *ast.EnvFileExpr, *ast.GlobAssetsExpr, *ast.FileHashExpr,
+		*ast.JSONPathExpr, *ast.LookupExpr, *ast.KeysExpr, *ast.ValuesExpr, *ast.RangeExpr,
+		*ast.SemverSatisfiesExpr, *ast.SemverCompareExpr, *ast.NowExpr, *ast.TimeAddExpr,
+		*ast.UUIDExpr, *ast.RandomIDExpr, *ast.EnvExpr, *ast.TrimExpr, *ast.UpperExpr, *ast.LowerExpr,
+		*ast.TitleExpr, *ast.FormatExpr, *ast.ToStringExpr, *ast.ToNumberExpr, *ast.ToBoolExpr,
+		*ast.GetAttExpr, *ast.ResourceByURNExpr, *ast.MapExpr, *ast.FilterExpr,
+		*ast.ReduceExpr, *ast.StackConfigExpr, *ast.WhenUnknownExpr:
+		return nil, syntax.Diagnostics{ast.ExprError(node, fmt.Sprintf("%s has no PCL equivalent", node.Name().Value), "")}
 	default:
 		contract.Failf("unexpected builtin type %T", node)
 		return nil, nil
@@ -728,7 +744,7 @@ func (imp *importer) getLatestPkgInfoResource(kvp ast.ResourcesMapEntry, pkgInfo
 func (imp *importer) getLatestPkgInfoVariable(kvp ast.VariablesMapEntry, pkgInfo map[string]*packageInfo) {
 	value := kvp.Value
 	if v, ok := value.(*ast.InvokeExpr); ok {
-		if v.CallOpts.Version == nil {
+		if v.CallOpts.Version == nil || v.Token == nil {
 			return
 		}
 
@@ -911,6 +927,19 @@ func (imp *importer) importResource(kvp ast.ResourcesMapEntry, latestPkgInfo map
 		}
 	}
 
+	if resource.ForEach != nil {
+		diags.Extend(ast.ExprError(resource.ForEach, "forEach has no PCL equivalent", ""))
+	}
+
+	if resource.Condition != nil {
+		diags.Extend(ast.ExprError(resource.Condition, "condition has no PCL equivalent", ""))
+	}
+
+	if resource.Options.Transforms != nil {
+		diags.Extend(syntax.NodeError(resource.Options.Transforms.Syntax(),
+			"the transforms resource option has no PCL equivalent", ""))
+	}
+
 	if len(resourceOptions.Body.Items) > 0 {
 		items = append(items, resourceOptions)
 	}
@@ -1054,6 +1083,12 @@ func (imp *importer) findStackReferences(node ast.Expr) {
 
 func (imp *importer) importTemplate(file *ast.TemplateDecl) (*model.Body, syntax.Diagnostics) {
 	var diags syntax.Diagnostics
+	if len(file.OutputGroups.Entries) != 0 {
+		return nil, syntax.Diagnostics{syntax.NodeError(file.OutputGroups.Syntax(), "output groups have no PCL equivalent", "")}
+	}
+	if len(file.DefaultTags.Entries) != 0 {
+		return nil, syntax.Diagnostics{syntax.NodeError(file.DefaultTags.Syntax(), "defaultTags have no PCL equivalent", "")}
+	}
 	// Declare config variables, resources, and outputs.
 
 	for _, kvp := range append(file.Configuration.Entries, file.Config.Entries...) {
@@ -0,0 +1,228 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// tokenLister is implemented by packages that can list the canonical tokens of their resources
+// and functions.
+type tokenLister interface {
+	resourceTokens() ([]string, error)
+	functionTokens() ([]string, error)
+}
+
+func (p resourcePackage) resourceTokens() ([]string, error) {
+	tokens := []string{"pulumi:providers:" + p.Name()}
+	for it := p.Resources().Range(); it.Next(); {
+		tokens = append(tokens, it.Token())
+	}
+	return tokens, nil
+}
+
+func (p resourcePackage) functionTokens() ([]string, error) {
+	var tokens []string
+	for it := p.Functions().Range(); it.Next(); {
+		tokens = append(tokens, it.Token())
+	}
+	return tokens, nil
+}
+
+func (p componentPackage) resourceTokens() ([]string, error) {
+	tokens := make([]string, 0, len(p.t.Components.Entries))
+	for _, entry := range p.t.Components.Entries {
+		tokens = append(tokens, ast.ComponentTypeToken(entry.Key.Value))
+	}
+	return tokens, nil
+}
+
+func (p componentPackage) functionTokens() ([]string, error) {
+	return nil, nil
+}
+
+// CompleteResourceTypes returns the type tokens of the resources of pkg that start with partial,
+// sorted and without duplicates. Each resource is offered both by its canonical token and by the
+// shorter forms that resolve to it, such as `pkg:Type` for `pkg:index:Type` and `pkg:module:Type`
+// for `pkg:module/type:Type`.
+func CompleteResourceTypes(pkg Package, partial string) ([]string, error) {
+	lister, ok := pkg.(tokenLister)
+	if !ok {
+		return nil, fmt.Errorf("package %q does not list its resources", pkg.Name())
+	}
+	tokens, err := lister.resourceTokens()
+	if err != nil {
+		return nil, err
+	}
+	return completeTokens(tokens, partial, func(tk string) (string, error) {
+		typ, err := pkg.ResolveResource(tk)
+		return typ.String(), err
+	}), nil
+}
+
+// CompleteFunctions returns the tokens of the functions of pkg that start with partial, in the
+// same way as CompleteResourceTypes.
+func CompleteFunctions(pkg Package, partial string) ([]string, error) {
+	lister, ok := pkg.(tokenLister)
+	if !ok {
+		return nil, fmt.Errorf("package %q does not list its functions", pkg.Name())
+	}
+	tokens, err := lister.functionTokens()
+	if err != nil {
+		return nil, err
+	}
+	return completeTokens(tokens, partial, func(tk string) (string, error) {
+		fn, err := pkg.ResolveFunction(tk)
+		return fn.String(), err
+	}), nil
+}
+
+// completeTokens returns the forms of the canonical tokens that start with partial. A shorter form
+// is only offered if resolve, which follows resolveToken, maps it back to the same token.
+func completeTokens(tokens []string, partial string, resolve func(string) (string, error)) []string {
+	var matches []string
+	for _, tk := range tokens {
+		for _, form := range tokenForms(tk) {
+			if !strings.HasPrefix(form, partial) {
+				continue
+			}
+			if form != tk {
+				if resolved, err := resolve(form); err != nil || resolved != tk {
+					continue
+				}
+			}
+			matches = append(matches, form)
+		}
+	}
+	sort.Strings(matches)
+	return slices.Compact(matches)
+}
+
+// tokenForms returns the canonical token tk, and the forms that resolveToken expands to it.
+func tokenForms(tk string) []string {
+	forms := []string{tk}
+	parts := strings.Split(tk, ":")
+	if len(parts) != 3 {
+		return forms
+	}
+	pkg, module, name := parts[0], parts[1], parts[2]
+	// A classic token such as `aws:s3/bucket:Bucket` may be written `aws:s3:Bucket`.
+	if m, member, ok := strings.Cut(module, "/"); ok && member == strcase.ToLowerCamel(name) {
+		module = m
+		forms = append(forms, fmt.Sprintf("%s:%s:%s", pkg, module, name))
+	}
+	// The index module may be left out.
+	if module == "index" {
+		forms = append(forms, fmt.Sprintf("%s:%s", pkg, name))
+	}
+	return forms
+}
+
+// A PropertyCompletion is an input property of a resource that completes a partial name.
+type PropertyCompletion struct {
+	// Name is the name of the property.
+	Name string
+	// Type is the type of the property, as it is displayed in diagnostics.
+	Type string
+	// Description is the documentation of the property from its schema. It may be empty.
+	Description string
+}
+
+// CompleteProperties returns the input properties of the resource of type resourceType in pkg
+// whose names start with partial, sorted by name. The type may be written in any form that
+// resolves to the resource.
+func CompleteProperties(pkg Package, resourceType, partial string) ([]PropertyCompletion, error) {
+	typ, err := pkg.ResolveResource(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	hint := pkg.ResourceTypeHint(typ)
+	if hint == nil || hint.Resource == nil {
+		return nil, fmt.Errorf("unable to load the schema of resource type %q", typ)
+	}
+	var matches []PropertyCompletion
+	for _, prop := range hint.Resource.InputProperties {
+		if strings.HasPrefix(prop.Name, partial) {
+			matches = append(matches, PropertyCompletion{
+				Name:        prop.Name,
+				Type:        displayType(prop.Type),
+				Description: prop.Comment,
+			})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Name < matches[j].Name
+	})
+	return slices.CompactFunc(matches, func(a, b PropertyCompletion) bool {
+		return a.Name == b.Name
+	}), nil
+}
+
+// An EnumCompletion is a member of an enum that completes a partial value.
+type EnumCompletion struct {
+	// Value is the value of the member, as it is written in a template.
+	Value string
+	// Name is the name of the member in the schema. It may be empty.
+	Name string
+	// Description is the documentation of the member from its schema. It may be empty.
+	Description string
+}
+
+// CompleteEnumValues returns the members of the enum that types the input property named property
+// of the resource of type resourceType in pkg, or the elements of the property if it is a list,
+// whose values start with partial. Members are returned in the order that the schema declares
+// them. It returns nothing if the property isn't an enum.
+func CompleteEnumValues(pkg Package, resourceType, property, partial string) ([]EnumCompletion, error) {
+	typ, err := pkg.ResolveResource(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	hint := pkg.ResourceTypeHint(typ)
+	if hint == nil || hint.Resource == nil {
+		return nil, fmt.Errorf("unable to load the schema of resource type %q", typ)
+	}
+	var propType schema.Type
+	for _, prop := range hint.Resource.InputProperties {
+		if prop.Name == property {
+			propType = codegen.UnwrapType(prop.Type)
+			break
+		}
+	}
+	if propType == nil {
+		return nil, fmt.Errorf("resource type %q has no input property %q", typ, property)
+	}
+	if list, ok := propType.(*schema.ArrayType); ok {
+		propType = codegen.UnwrapType(list.ElementType)
+	}
+	enum, ok := propType.(*schema.EnumType)
+	if !ok {
+		return nil, nil
+	}
+
+	var matches []EnumCompletion
+	for _, member := range enum.Elements {
+		var value string
+		switch v := member.Value.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			value = fmt.Sprint(v)
+		}
+		if strings.HasPrefix(value, partial) {
+			matches = append(matches, EnumCompletion{Value: value, Name: member.Name, Description: member.Comment})
+		}
+	}
+	return matches, nil
+}
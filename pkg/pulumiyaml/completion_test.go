@@ -0,0 +1,141 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const completionSchema = `{
+  "name": "shop",
+  "version": "1.0.0",
+  "resources": {
+    "shop:index:Store": {
+      "inputProperties": {
+        "name": {"type": "string", "description": "The name of the store."},
+        "region": {"type": "string"},
+        "tags": {"type": "object", "additionalProperties": {"type": "string"}},
+        "tier": {"$ref": "#/types/shop:index:Tier"},
+        "floors": {"type": "array", "items": {"$ref": "#/types/shop:index:Floor"}}
+      }
+    },
+    "shop:catalog/item:Item": {
+      "inputProperties": {
+        "price": {"type": "number"}
+      }
+    },
+    "shop:catalog:Category": {}
+  },
+  "types": {
+    "shop:index:Tier": {
+      "type": "string",
+      "enum": [
+        {"value": "basic"},
+        {"name": "Premium", "value": "premium", "description": "A store with extended hours."},
+        {"value": "pop-up"}
+      ]
+    },
+    "shop:index:Floor": {
+      "type": "number",
+      "enum": [{"value": 1}, {"value": 2.5}]
+    }
+  },
+  "functions": {
+    "shop:index:getStore": {
+      "inputs": {"properties": {"name": {"type": "string"}}}
+    },
+    "shop:catalog/getItem:getItem": {
+      "inputs": {"properties": {"id": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(completionSchema), &spec))
+	bound, err := schema.ImportSpec(spec, nil)
+	require.NoError(t, err)
+	pkg := NewResourcePackage(bound.Reference())
+
+	resources, err := CompleteResourceTypes(pkg, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"pulumi:providers:shop",
+		"shop:Store",
+		"shop:catalog/item:Item",
+		"shop:catalog:Category",
+		"shop:catalog:Item",
+		"shop:index:Store",
+	}, resources)
+
+	resources, err = CompleteResourceTypes(pkg, "shop:cat")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop:catalog/item:Item", "shop:catalog:Category", "shop:catalog:Item"}, resources)
+
+	resources, err = CompleteResourceTypes(pkg, "shop:St")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop:Store"}, resources)
+
+	resources, err = CompleteResourceTypes(pkg, "other:")
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+
+	functions, err := CompleteFunctions(pkg, "shop:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"shop:catalog/getItem:getItem",
+		"shop:catalog:getItem",
+		"shop:getStore",
+		"shop:index:getStore",
+	}, functions)
+
+	// Properties can be completed for a resource written in any form.
+	for _, typ := range []string{"shop:Store", "shop:index:Store"} {
+		props, err := CompleteProperties(pkg, typ, "")
+		require.NoError(t, err)
+		assert.Equal(t, []PropertyCompletion{
+			{Name: "floors", Type: "List<shop:index:Floor>"},
+			{Name: "name", Type: "string", Description: "The name of the store."},
+			{Name: "region", Type: "string"},
+			{Name: "tags", Type: "Map<string>"},
+			{Name: "tier", Type: "shop:index:Tier"},
+		}, props)
+	}
+	props, err := CompleteProperties(pkg, "shop:catalog:Item", "pr")
+	require.NoError(t, err)
+	assert.Equal(t, []PropertyCompletion{{Name: "price", Type: "number"}}, props)
+
+	_, err = CompleteProperties(pkg, "shop:Missing", "")
+	assert.Error(t, err)
+
+	values, err := CompleteEnumValues(pkg, "shop:Store", "tier", "")
+	require.NoError(t, err)
+	assert.Equal(t, []EnumCompletion{
+		{Value: "basic"},
+		{Value: "premium", Name: "Premium", Description: "A store with extended hours."},
+		{Value: "pop-up"},
+	}, values)
+	values, err = CompleteEnumValues(pkg, "shop:Store", "tier", "p")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"premium", "pop-up"}, []string{values[0].Value, values[1].Value})
+	// The elements of lists of enums are completed too.
+	values, err = CompleteEnumValues(pkg, "shop:Store", "floors", "")
+	require.NoError(t, err)
+	assert.Equal(t, []EnumCompletion{{Value: "1"}, {Value: "2.5"}}, values)
+	values, err = CompleteEnumValues(pkg, "shop:Store", "region", "")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+	_, err = CompleteEnumValues(pkg, "shop:Store", "missing", "")
+	assert.Error(t, err)
+
+	// Packages that can't list their contents can't complete tokens.
+	_, err = CompleteResourceTypes(MockPackage{}, "")
+	assert.Error(t, err)
+}
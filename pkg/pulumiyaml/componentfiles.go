@@ -0,0 +1,145 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax/encoding"
+)
+
+// isComponentFile reports whether the type of a resource is the path of a file that declares a
+// component, such as ./components/webapp.yaml, rather than a type token or a component name.
+func isComponentFile(typ string) bool {
+	if strings.Contains(typ, ":") {
+		return false
+	}
+	ext := filepath.Ext(typ)
+	return ext == ".yaml" || ext == ".yml" || strings.HasPrefix(typ, "./") || strings.HasPrefix(typ, "../")
+}
+
+// loadComponentFiles loads the components instantiated by the resources of t, which was loaded
+// from the file filename in directory, whose type is the path of a file. Paths are relative to
+// the file that declares the resource, and must be within directory. Each file declares one
+// component, named after the file, which may instantiate components from files of its own.
+func loadComponentFiles(t *ast.TemplateDecl, directory, filename string) syntax.Diagnostics {
+	l := componentFileLoader{
+		root:  t,
+		dir:   directory,
+		names: map[string]string{},
+	}
+	l.instantiate(t.Resources, []string{filename})
+	return l.diags
+}
+
+type componentFileLoader struct {
+	root *ast.TemplateDecl
+	// dir is the directory of the root template. Component files are named relative to it.
+	dir string
+	// names holds the name of the component declared by each file that has been loaded.
+	names map[string]string
+	diags syntax.Diagnostics
+}
+
+// instantiate loads the component files that resources instantiate, and replaces the type of each
+// resource with the type token of its component. chain lists the names of the files that led to
+// the resources being instantiated, starting with the root template.
+func (l *componentFileLoader) instantiate(resources ast.ResourcesMapDecl, chain []string) {
+	for _, r := range resources.Entries {
+		res := r.Value
+		if res == nil || res.Type == nil || !isComponentFile(res.Type.Value) {
+			continue
+		}
+		typ := res.Type
+		if filepath.IsAbs(typ.Value) {
+			l.diags.Extend(ast.ExprError(typ, fmt.Sprintf("component file %q must be a relative path", typ.Value), ""))
+			continue
+		}
+
+		// Files are named relative to the project directory, so the declaring file is found there.
+		declaringFile := chain[len(chain)-1]
+		if s := typ.Syntax(); s != nil && s.Syntax() != nil && s.Syntax().Range().Filename != "" {
+			declaringFile = s.Syntax().Range().Filename
+		}
+		target := filepath.Join(l.dir, filepath.Dir(declaringFile), typ.Value)
+		name, err := filepath.Rel(l.dir, target)
+		outside := err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator))
+		// A symlink within the project directory may still point outside of it. Missing files are
+		// reported when they are loaded.
+		if real, err := filepath.EvalSymlinks(target); !outside && err == nil {
+			realRoot, err := filepath.EvalSymlinks(l.dir)
+			if err != nil {
+				realRoot = l.dir
+			}
+			outside = !isWithin(realRoot, real)
+		}
+		if outside {
+			l.diags.Extend(ast.ExprError(typ,
+				fmt.Sprintf("component file %q is outside of the project directory", typ.Value),
+				"Component files must be within "+l.dir))
+			continue
+		}
+
+		if slices.Contains(chain, name) {
+			l.diags.Extend(ast.ExprError(typ, fmt.Sprintf("circular instantiation of component file %q", name),
+				"The chain of component files is "+strings.Join(append(chain, name), " -> ")))
+			continue
+		}
+		component, ok := l.names[name]
+		if !ok {
+			component, ok = l.load(typ, target, name, chain)
+			if !ok {
+				continue
+			}
+		}
+
+		token := ast.ComponentTypeToken(component)
+		if node, ok := typ.Syntax().(*syntax.StringNode); ok {
+			res.Type = ast.StringSyntaxValue(node, token)
+		} else {
+			res.Type = ast.String(token)
+		}
+	}
+}
+
+// load loads the component file target, which is named name, then the component files that it
+// instantiates. It returns the name of the component that the file declares.
+func (l *componentFileLoader) load(typ *ast.StringExpr, target, name string, chain []string) (string, bool) {
+	component := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if l.root.Components.Get(component) != nil {
+		l.diags.Extend(ast.ExprError(typ,
+			fmt.Sprintf("component file %q declares the component %q, which is already declared", typ.Value, component),
+			"Components are named after the file that declares them, so each name must be declared once"))
+		return "", false
+	}
+
+	source, err := os.ReadFile(target)
+	if err != nil {
+		l.diags.Extend(ast.ExprError(typ, fmt.Sprintf("unable to read component file %q: %v", typ.Value, err), ""))
+		return "", false
+	}
+	syn, diags := encoding.DecodeYAML(name, yaml.NewDecoder(bytes.NewReader(source)), TagDecoder)
+	l.diags.Extend(diags...)
+	if diags.HasErrors() {
+		return "", false
+	}
+	decl, diags := ast.ParseComponent(source, syn)
+	l.diags.Extend(diags...)
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	l.names[name] = component
+	l.root.AddComponent(ast.String(component), decl)
+	l.instantiate(decl.Resources, append(chain, name))
+	return component, true
+}
@@ -0,0 +1,243 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"Pulumi.yaml": `name: test-component-files
+runtime: yaml
+resources:
+  web:
+    type: ./components/webapp.yaml
+    properties:
+      name: web
+  consumer:
+    type: test:resource:type
+    properties:
+      foo:
+        fn::getAtt: ["${web}", "url"]
+outputs:
+  url: ${web.url}
+`,
+		"components/webapp.yaml": `description: A web application
+inputs:
+  name:
+    type: String
+resources:
+  server:
+    type: test:resource:type
+    properties:
+      foo: ${name}-server
+  storage:
+    type: ../shared/storage.yml
+    properties:
+      prefix: ${name}
+outputs:
+  url: https://${server.foo}/${storage.bucket}
+`,
+		"shared/storage.yml": `inputs:
+  prefix:
+    type: String
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${prefix}-bucket
+outputs:
+  bucket: ${bucket.foo}
+`,
+	})
+
+	tmpl, diags, err := LoadDir(dir)
+	require.NoError(t, err)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, "components:index:webapp", tmpl.Resources.Entries[0].Value.Type.Value)
+	require.NotNil(t, tmpl.Components.Get("webapp"))
+	require.NotNil(t, tmpl.Components.Get("storage"))
+
+	var m sync.Mutex
+	registered := map[string]string{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			var foo string
+			if v, ok := args.Inputs["foo"]; ok {
+				foo = v.StringValue()
+			}
+			registered[args.Name] = foo
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"web":                "",
+		"web-server":         "web-server",
+		"web-storage":        "",
+		"web-storage-bucket": "web-bucket",
+		"consumer":           "https://web-server/web-bucket",
+	}, registered)
+}
+
+func TestComponentFilesDiags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name: "outside of the project",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nresources:\n  res:\n    type: ../webapp.yaml\n",
+			},
+			expected: []string{`Pulumi.yaml:5:11: component file "../webapp.yaml" is outside of the project directory`},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nresources:\n  res:\n    type: ./a.yaml\n",
+				"a.yaml":      "resources:\n  b:\n    type: ./sub/b.yaml\n",
+				"sub/b.yaml":  "resources:\n  a:\n    type: ../a.yaml\n",
+			},
+			expected: []string{`sub/b.yaml:3:11: circular instantiation of component file "a.yaml"; ` +
+				`The chain of component files is Pulumi.yaml -> a.yaml -> sub/b.yaml -> a.yaml`},
+		},
+		{
+			name: "missing",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nresources:\n  res:\n    type: ./missing.yaml\n",
+			},
+			expected: []string{`Pulumi.yaml:5:11: unable to read component file "./missing.yaml"`},
+		},
+		{
+			name: "name conflict",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nresources:\n" +
+					"  a:\n    type: ./a/web.yaml\n  b:\n    type: ./b/web.yaml\n",
+				"a/web.yaml": "resources: {}\n",
+				"b/web.yaml": "resources: {}\n",
+			},
+			expected: []string{`Pulumi.yaml:7:11: component file "./b/web.yaml" declares the component "web", ` +
+				`which is already declared`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeFiles(t, tt.files)
+			_, diags, _ := LoadDir(dir)
+			actual := diagStrings(diags)
+			require.Len(t, actual, len(tt.expected), actual)
+			for i, expected := range tt.expected {
+				assert.Contains(t, actual[i], expected)
+			}
+		})
+	}
+}
+
+func TestComponentFilesSymlinks(t *testing.T) {
+	t.Parallel()
+
+	outside := writeFiles(t, map[string]string{
+		"webapp.yaml": "resources: {}\n",
+	})
+	root := writeFiles(t, map[string]string{
+		"Pulumi.yaml": "name: test\nruntime: yaml\nresources:\n  res:\n    type: ./link.yaml\n" +
+			"  other:\n    type: ./local.yaml\n",
+		"components/local.yaml": "resources: {}\n",
+	})
+	require.NoError(t, os.Symlink(filepath.Join(outside, "webapp.yaml"), filepath.Join(root, "link.yaml")))
+	// Symlinks that stay within the project directory are followed.
+	require.NoError(t, os.Symlink(filepath.Join(root, "components", "local.yaml"), filepath.Join(root, "local.yaml")))
+
+	_, diags, _ := LoadDir(root)
+	assert.Equal(t, []string{
+		`Pulumi.yaml:5:11: component file "./link.yaml" is outside of the project directory; ` +
+			"Component files must be within " + root,
+	}, diagStrings(diags))
+}
+
+func TestGetAtt(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-getatt
+runtime: yaml
+variables:
+  attribute: foo
+resources:
+  source:
+    type: test:resource:type
+    properties:
+      foo: hello
+  sink:
+    type: test:resource:type
+    properties:
+      foo:
+        fn::getAtt: ["${source}", "${attribute}"]
+`)
+	var m sync.Mutex
+	var foo string
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			if args.Name == "sink" {
+				m.Lock()
+				defer m.Unlock()
+				foo = args.Inputs["foo"].StringValue()
+			}
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", foo)
+
+	// An attribute that is written out is checked like a reference to it.
+	tmpl = yamlTemplate(t, `
+name: test-getatt
+runtime: yaml
+resources:
+  source:
+    type: test:resource:type
+    properties:
+      foo: hello
+outputs:
+  out:
+    fn::getAtt: ["${source}", "missing"]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.True(t, diags.HasErrors())
+	assert.Contains(t, diagStrings(diags)[0], "missing")
+}
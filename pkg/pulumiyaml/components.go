@@ -0,0 +1,293 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	ctypes "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/config"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// componentPackage is the package of the components declared by a template. Its resources are
+// instantiated by evaluating the declaration of the component rather than by a provider, and its
+// schema is derived from the component's inputs and outputs, so that instances are type checked
+// like any other resource.
+type componentPackage struct {
+	t *ast.TemplateDecl
+}
+
+// component returns the name and declaration of the component with the canonical type token typ.
+func (p componentPackage) component(typ ResourceTypeToken) (string, *ast.ComponentDecl) {
+	name := strings.TrimPrefix(typ.String(), ast.ComponentPackageName+":index:")
+	return name, p.t.Components.Get(name)
+}
+
+func (p componentPackage) Name() string {
+	return ast.ComponentPackageName
+}
+
+func (p componentPackage) Version() *semver.Version {
+	return nil
+}
+
+func (p componentPackage) ResolveResource(typeName string) (ResourceTypeToken, error) {
+	parts, err := splitTypeToken(typeName)
+	if err != nil {
+		return "", err
+	}
+	name := parts[len(parts)-1]
+	if len(parts) > 3 || len(parts) == 3 && parts[1] != "index" || p.t.Components.Get(name) == nil {
+		return "", fmt.Errorf("unknown component %q", typeName)
+	}
+	return ResourceTypeToken(ast.ComponentTypeToken(name)), nil
+}
+
+func (p componentPackage) ResolveFunction(typeName string) (FunctionTypeToken, error) {
+	return "", fmt.Errorf("unable to find function %q: components don't declare functions", typeName)
+}
+
+func (p componentPackage) IsComponent(typeName ResourceTypeToken) (bool, error) {
+	return true, nil
+}
+
+func (p componentPackage) IsResourcePropertySecret(typeName ResourceTypeToken, propertyName string) (bool, error) {
+	_, c := p.component(typeName)
+	if c == nil {
+		return false, fmt.Errorf("unknown component %q", typeName)
+	}
+	for _, entry := range c.Inputs.Entries {
+		if entry.Key.Value == propertyName && entry.Value != nil {
+			return entry.Value.Secret != nil && entry.Value.Secret.Value, nil
+		}
+	}
+	return false, nil
+}
+
+func (p componentPackage) ResourceTypeHint(typeName ResourceTypeToken) *schema.ResourceType {
+	_, c := p.component(typeName)
+	if c == nil {
+		return nil
+	}
+
+	// Inputs are typed by their declarations, and are required unless they have a default.
+	var inputs []*schema.Property
+	for _, entry := range c.Inputs.Entries {
+		prop := &schema.Property{Name: entry.Key.Value, Type: schema.AnyType}
+		if decl := entry.Value; decl != nil {
+			if typ, diags := configDeclType(decl); typ != nil && !diags.HasErrors() {
+				prop.Type = typ.Schema()
+			} else if d, ok := configLiteral(decl.Default); ok {
+				if typ, err := ctypes.TypeValue(d); err == nil {
+					prop.Type = typ.Schema()
+				}
+			}
+			prop.Secret = decl.Secret != nil && decl.Secret.Value
+			if decl.Default != nil {
+				prop.Type = &schema.OptionalType{ElementType: prop.Type}
+			}
+		}
+		inputs = append(inputs, prop)
+	}
+	// The types of outputs are only known once the component is checked, so any value is allowed.
+	var outputs []*schema.Property
+	for _, entry := range c.Outputs.Entries {
+		outputs = append(outputs, &schema.Property{Name: entry.Key.Value, Type: schema.AnyType})
+	}
+	return &schema.ResourceType{
+		Token: typeName.String(),
+		Resource: &schema.Resource{
+			Token:           typeName.String(),
+			Comment:         c.Description.GetValue(),
+			InputProperties: inputs,
+			Properties:      outputs,
+			IsComponent:     true,
+		},
+	}
+}
+
+func (p componentPackage) FunctionTypeHint(typeName FunctionTypeToken) *schema.Function {
+	return nil
+}
+
+func (p componentPackage) ResourceConstants(typeName ResourceTypeToken) map[string]interface{} {
+	return nil
+}
+
+// componentLoader loads the package of the components declared by a template, and every other
+// package with the loader that it wraps.
+type componentLoader struct {
+	PackageLoader
+
+	pkg componentPackage
+}
+
+func (l componentLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	if descriptor.Name == ast.ComponentPackageName {
+		return l.pkg, nil
+	}
+	return l.PackageLoader.LoadPackage(ctx, descriptor)
+}
+
+// withComponents returns loader, extended to load the components declared by t if it has any.
+func withComponents(t *ast.TemplateDecl, loader PackageLoader) PackageLoader {
+	if t == nil || loader == nil || len(t.Components.Entries) == 0 {
+		return loader
+	}
+	if _, ok := loader.(componentLoader); ok {
+		return loader
+	}
+	return componentLoader{PackageLoader: loader, pkg: componentPackage{t: t}}
+}
+
+// checkComponents checks the body of each component of the template like a template of its own,
+// whose configuration is the inputs of the component.
+func (r *Runner) checkComponents() syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	for _, entry := range r.t.Components.Entries {
+		if entry.Value != nil {
+			diags.Extend(Analyze(r.t.ComponentTemplate(entry.Value), r.pkgLoader)...)
+		}
+	}
+	return diags
+}
+
+// componentInstance is an instance of a component, whose body a runner evaluates.
+type componentInstance struct {
+	// name is the name of the instance, which prefixes the names of its children.
+	name string
+	// resource is the component resource, which is the default parent of its children.
+	resource lateboundResource
+	inputs   map[string]interface{}
+	outputs  pulumi.Map
+}
+
+// lateboundComponentResourceState is the state of an instance of a component declared by the
+// template. Its outputs are those of the component's body, rather than being read from the engine.
+type lateboundComponentResourceState struct {
+	pulumi.ResourceState
+	name           string
+	outputs        pulumi.MapOutput
+	resourceSchema *schema.Resource
+	protected      bool
+}
+
+// GetOutputs returns the resource's outputs.
+func (st *lateboundComponentResourceState) GetOutputs() pulumi.Output {
+	return st.outputs
+}
+
+// GetOutput returns the named output of the resource.
+func (st *lateboundComponentResourceState) GetOutput(k string) pulumi.Output {
+	return st.outputs.ApplyT(func(outputs map[string]interface{}) (interface{}, error) {
+		out, ok := outputs[k]
+		if !ok {
+			return nil, fmt.Errorf("no output '%s' on resource '%s'", k, st.name)
+		}
+		return out, nil
+	})
+}
+
+func (st *lateboundComponentResourceState) CustomResource() *pulumi.CustomResourceState {
+	return nil
+}
+
+func (st *lateboundComponentResourceState) ProviderResource() *pulumi.ProviderResourceState {
+	return nil
+}
+
+func (st *lateboundComponentResourceState) pulumiResource() pulumi.Resource {
+	return st
+}
+
+func (*lateboundComponentResourceState) ElementType() reflect.Type {
+	return reflect.TypeOf((**lateboundComponentResourceState)(nil)).Elem()
+}
+
+func (st *lateboundComponentResourceState) GetRawOutputs() pulumi.Output {
+	return st.outputs
+}
+
+func (st *lateboundComponentResourceState) GetResourceSchema() *schema.Resource {
+	return st.resourceSchema
+}
+
+func (st *lateboundComponentResourceState) isProtected() bool {
+	return st.protected
+}
+
+// registerComponent registers an instance of a component declared by the template, then
+// evaluates the body of the component with the properties of the instance as its inputs.
+func (e *programEvaluator) registerComponent(pkg componentPackage, kvp resourceNode, typ ResourceTypeToken,
+	state *lateboundComponentResourceState, props map[string]interface{}, opts []pulumi.ResourceOption,
+) bool {
+	err := e.pulumiCtx.RegisterComponentResource(typ.String(), state.name, state, opts...)
+	if err != nil {
+		e.error(kvp.Key, err.Error())
+		return false
+	}
+
+	_, decl := pkg.component(typ)
+	instance := &componentInstance{
+		name:     state.name,
+		resource: state,
+		inputs:   props,
+		outputs:  pulumi.Map{},
+	}
+	body := newRunner(pkg.t.ComponentTemplate(decl), e.pkgLoader)
+	body.packageDescriptors = e.packageDescriptors
+	body.indexExpansion = e.indexExpansion
+	body.deprecationWarnings = e.deprecationWarnings
+	body.absentResourceErrors = e.absentResourceErrors
+	body.secrets = e.secrets
+	body.clock = e.clock
+	body.now = e.now
+	body.randomSeed = e.randomSeed
+	body.parallelism = e.parallelism
+	body.trace = e.trace
+	body.component = instance
+	diags := body.Run(programEvaluator{
+		evalContext: body.newContext(nil),
+		pulumiCtx:   e.pulumiCtx,
+		packageRefs: e.packageRefs,
+	})
+	// The diagnostics of the body have already been logged.
+	for _, diag := range diags {
+		e.report(diag)
+	}
+	if diags.HasErrors() {
+		e.sdiags.Extend(diags...)
+		return false
+	}
+
+	state.outputs = instance.outputs.ToMapOutput()
+	if err := e.pulumiCtx.RegisterResourceOutputs(state, instance.outputs); err != nil {
+		e.error(kvp.Key, err.Error())
+		return false
+	}
+	return true
+}
+
+// componentInput returns the value of an input of the component whose body is being evaluated.
+func (e *programEvaluator) componentInput(node configNodeYaml) (interface{}, bool) {
+	if v, ok := e.component.inputs[node.Key.Value]; ok {
+		return v, true
+	}
+	decl := node.Value
+	if decl == nil || decl.Default == nil {
+		return e.errorf(node.Key, "missing required input %q of component", node.Key.Value)
+	}
+	v, ok := e.evaluateExpr(decl.Default)
+	if ok && decl.Secret != nil && decl.Secret.Value {
+		v = pulumi.ToSecret(v)
+	}
+	return v, ok
+}
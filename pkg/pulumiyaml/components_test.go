@@ -0,0 +1,308 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+const componentsTemplate = `
+name: test-components
+runtime: yaml
+components:
+  pair:
+    inputs:
+      prefix:
+        type: String
+      suffix:
+        type: String
+        default: x
+    variables:
+      first: ${prefix}-1
+    resources:
+      one:
+        type: test:resource:type
+        properties:
+          foo: ${first}
+      two:
+        type: test:resource:type
+        properties:
+          foo: ${prefix}-2-${suffix}
+        options:
+          dependsOn: ["${one}"]
+    outputs:
+      names: ["${one.foo}", "${two.foo}"]
+resources:
+  a:
+    type: pair
+    properties:
+      prefix: a
+  b:
+    type: pair
+    properties:
+      prefix: b
+      suffix: y
+  consumer:
+    type: test:resource:type
+    properties:
+      foo: ${a.names[1]}
+`
+
+func TestComponents(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, componentsTemplate)
+	type registration struct {
+		typ, parent, foo string
+	}
+	var m sync.Mutex
+	registered := map[string]registration{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			var foo string
+			if v, ok := args.Inputs["foo"]; ok {
+				foo = v.StringValue()
+			}
+			registered[args.Name] = registration{
+				typ:    args.TypeToken,
+				parent: args.RegisterRPC.GetParent(),
+				foo:    foo,
+			}
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	const stack = "urn:pulumi:stack::project::pulumi:pulumi:Stack::project-stack"
+	const component = "urn:pulumi:stack::project::components:index:pair::"
+	assert.Equal(t, map[string]registration{
+		"a":        {typ: "components:index:pair", parent: stack},
+		"a-one":    {typ: testResourceToken, parent: component + "a", foo: "a-1"},
+		"a-two":    {typ: testResourceToken, parent: component + "a", foo: "a-2-x"},
+		"b":        {typ: "components:index:pair", parent: stack},
+		"b-one":    {typ: testResourceToken, parent: component + "b", foo: "b-1"},
+		"b-two":    {typ: testResourceToken, parent: component + "b", foo: "b-2-y"},
+		"consumer": {typ: testResourceToken, parent: stack, foo: "a-2-x"},
+	}, registered)
+}
+
+func TestComponentDiags(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-components
+runtime: yaml
+components:
+  pair:
+    inputs:
+      prefix:
+        type: String
+      count:
+        type: Number
+        default: 1
+    resources:
+      one:
+        type: test:resource:type
+        properties:
+          foo: ${prefix}-${missing}
+    outputs:
+      name: ${one.foo}
+resources:
+  a:
+    type: pair
+    properties:
+      count: many
+      extra: true
+  consumer:
+    type: test:resource:type
+    properties:
+      foo: ${a.other}
+`)
+	diags := Analyze(tmpl, newMockPackageMap())
+	assert.ElementsMatch(t, []string{
+		"components:index:pair is not assignable from {count: string, extra: boolean}; " +
+			"Cannot assign '{count: string, extra: boolean}' to 'components:index:pair':\n" +
+			"  prefix: Missing required property 'prefix'\n" +
+			"  count: Cannot assign type 'string' to type 'number'\n" +
+			"  Existing properties are: count, prefix",
+		"<stdin>:28:12: other does not exist on a; Existing properties are: name, urn",
+		`<stdin>:16:16: unknown reference "missing"; Declare it as a resource, a variable or config`,
+	}, diagStrings(diags))
+}
+
+func TestNestedComponents(t *testing.T) {
+	t.Parallel()
+
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-components
+runtime: yaml
+components:
+  inner:
+    resources:
+      res:
+        type: test:resource:type
+  outer:
+    resources:
+      nested:
+        type: inner
+      qualified:
+        type: components:inner
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`<stdin>:12:15: component "outer" can't instantiate the component "inner"`,
+		`<stdin>:14:15: component "outer" can't instantiate the component "components:inner"`,
+	}, diagStrings(diags))
+}
+
+func TestComponentReferencedPackages(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-components
+runtime: yaml
+components:
+  store:
+    resources:
+      bucket:
+        type: aws:s3:Bucket
+        options:
+          version: 6.0.0
+resources:
+  a:
+    type: store
+`)
+	pkgs, diags := GetReferencedPackages(tmpl)
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "aws", pkgs[0].Name)
+	assert.Equal(t, "6.0.0", pkgs[0].Version)
+}
+
+func TestComponentNow(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-components
+runtime: yaml
+components:
+  stamped:
+    resources:
+      res:
+        type: test:resource:type
+        properties:
+          foo:
+            fn::now: {}
+resources:
+  a:
+    type: stamped
+variables:
+  deployed:
+    fn::now: {}
+`)
+	var m sync.Mutex
+	var stamp string
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			if v, ok := args.Inputs["foo"]; ok {
+				m.Lock()
+				stamp = v.StringValue()
+				m.Unlock()
+			}
+			return args.Name, args.Inputs, nil
+		},
+	}
+	reads := 0
+	runner := newRunner(tmpl, newMockPackageMap())
+	runner.clock = func() time.Time {
+		reads++
+		return time.Date(2024, 2, 1, 9, 30, reads, 0, time.UTC)
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, "2024-02-01T09:30:01Z", runner.variables["deployed"])
+	assert.Equal(t, "2024-02-01T09:30:01Z", stamp)
+}
+
+func TestComponentTrace(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, componentsTemplate)
+	runner := newRunner(tmpl, newMockPackageMap())
+	runner.trace = &Trace{}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+
+	var bodySteps []TraceStep
+	for _, step := range runner.trace.Steps {
+		if step.Node == "variables.first" {
+			bodySteps = append(bodySteps, step)
+		}
+	}
+	require.Len(t, bodySteps, 2)
+	assert.ElementsMatch(t, []interface{}{"a-1", "b-1"},
+		[]interface{}{bodySteps[0].Value, bodySteps[1].Value})
+}
+
+func TestComponentSecrets(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-components
+runtime: yaml
+components:
+  keeper:
+    variables:
+      encoded:
+        fn::toBase64:
+          fn::secret: hunter2
+    outputs:
+      encoded: ${encoded}
+resources:
+  a:
+    type: keeper
+variables:
+  encoded: ${a.encoded}
+`)
+	runner := newRunner(tmpl, newMockPackageMap())
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		out, ok := runner.variables["encoded"].(pulumi.Output)
+		require.True(t, ok)
+		_, err := internals.UnsafeAwaitOutput(ctx.Context(), out)
+		return err
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+
+	// The secret was seen in the body of the component, so the stack's diagnostics mask it too.
+	diag := syntax.Error(nil, `"hunter2" is not a number`, "")
+	runner.secrets.mask(diag)
+	assert.Equal(t, `"[secret]" is not a number`, diag.Summary)
+}
@@ -0,0 +1,63 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// When set, referring to a resource whose condition is false is an error, rather than null.
+var defaultAbsentResourceErrors = cmdutil.IsTruthy(os.Getenv("PULUMI_YAML_ABSENT_RESOURCE_ERRORS"))
+
+// evaluateCondition returns whether the resource of node is registered. A condition that is
+// unknown during a preview registers the resource, since it may be created by the update.
+func (e programEvaluator) evaluateCondition(node resourceNode) (bool, bool) {
+	k, v := node.Key.Value, node.Value
+	value, ok := e.evaluateExpr(v.Condition)
+	if !ok {
+		return false, false
+	}
+	if o, ok := value.(pulumi.Output); ok {
+		result, err := internals.UnsafeAwaitOutput(e.pulumiCtx.Context(), o)
+		if err != nil {
+			e.error(v.Condition, fmt.Sprintf("unable to evaluate condition: %v", err))
+			return false, false
+		}
+		if !result.Known {
+			if !e.pulumiCtx.DryRun() {
+				e.error(v.Condition, "condition must be known when resources are registered")
+				return false, false
+			}
+			e.addWarnDiag(v.Condition.Syntax().Syntax().Range(),
+				fmt.Sprintf("condition of resource %q is unknown, so the preview assumes it is true", k),
+				"Whether the resource is created is decided when the update runs")
+			return true, true
+		}
+		value = result.Value
+	}
+	create, ok := value.(bool)
+	if !ok {
+		e.error(v.Condition, fmt.Sprintf("condition of resource %q must be a boolean, not %v", k, typeString(value)))
+		return false, false
+	}
+	return create, true
+}
+
+// evaluateAbsentResource evaluates a reference to the resource name, which wasn't registered
+// because its condition was false. The reference is null, unless the runner treats it as an error.
+func (e *programEvaluator) evaluateAbsentResource(expr ast.Expr, name string) (interface{}, bool) {
+	if e.absentResourceErrors {
+		return e.error(expr, fmt.Sprintf("resource %q is not created, since its condition is false", name))
+	}
+	e.addWarnDiag(expr.Syntax().Syntax().Range(),
+		fmt.Sprintf("resource %q is not created, since its condition is false, so it is null", name),
+		"Set PULUMI_YAML_ABSENT_RESOURCE_ERRORS to make this an error")
+	return nil, true
+}
@@ -0,0 +1,179 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+const conditionTemplate = `
+name: test-condition
+runtime: yaml
+variables:
+  enabled: false
+resources:
+  optional:
+    type: test:resource:type
+    condition: ${enabled}
+    properties:
+      foo: bar
+  always:
+    type: test:resource:type
+    condition: true
+    properties:
+      foo: ${optional.bar}
+    options:
+      dependsOn:
+        - ${optional}
+`
+
+func TestResourceCondition(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, conditionTemplate)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+
+	var lock sync.Mutex
+	inputs := map[string]resource.PropertyMap{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			inputs[args.Name] = args.Inputs
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+	requireNoErrors(t, tmpl, diags)
+
+	// The reference to the absent resource is null, with a warning.
+	assert.Equal(t, map[string]resource.PropertyMap{"always": {}}, inputs)
+	require.Len(t, diags, 2)
+	for _, d := range diags {
+		assert.Equal(t, `resource "optional" is not created, since its condition is false, so it is null`, d.Summary)
+	}
+
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		runner.absentResourceErrors = true
+		diags = runner.Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `resource "optional" is not created, since its condition is false`, diags[0].Summary)
+}
+
+func TestResourceConditionUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-condition
+runtime: yaml
+resources:
+  source:
+    type: test:resource:with-tags
+  optional:
+    type: test:resource:with-tags
+    condition: ${source.foo}
+`)
+	run := func(preview bool) ([]string, syntax.Diagnostics) {
+		var lock sync.Mutex
+		var names []string
+		var diags syntax.Diagnostics
+		mocks := &testMonitor{
+			NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				names = append(names, args.Name)
+				outputs := args.Inputs.Copy()
+				if args.Name == "source" && !preview {
+					outputs["foo"] = resource.NewBoolProperty(false)
+				}
+				return args.Name, outputs, nil
+			},
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks), func(ri *pulumi.RunInfo) {
+			ri.DryRun = preview
+		})
+		require.NoError(t, err)
+		return names, diags
+	}
+
+	// The preview creates the resource, since it may be created by the update.
+	names, diags := run(true)
+	requireNoErrors(t, tmpl, diags)
+	assert.ElementsMatch(t, []string{"source", "optional"}, names)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `condition of resource "optional" is unknown, so the preview assumes it is true`, diags[0].Summary)
+
+	names, diags = run(false)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"source"}, names)
+}
+
+func TestResourceConditionType(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-condition
+runtime: yaml
+resources:
+  optional:
+    type: test:resource:type
+    condition: [true]
+    properties:
+      foo: bar
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "boolean is not assignable from List<boolean>", diags[0].Summary)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `condition of resource "optional" must be a boolean, not a list`, diags[0].Summary)
+}
+
+func TestLoadTemplateCondition(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{
+		Resources: map[string]*Resource{
+			"always": {
+				Type: testResourceToken,
+			},
+			"optional": {
+				Type:      testResourceToken,
+				Condition: "${enabled}",
+			},
+		},
+	})
+	resources := map[string]*ast.ResourceDecl{}
+	for _, entry := range tmpl.Resources.Entries {
+		resources[entry.Key.Value] = entry.Value
+	}
+	assert.Nil(t, resources["always"].Condition)
+	assert.IsType(t, &ast.SymbolExpr{}, resources["optional"].Condition)
+}
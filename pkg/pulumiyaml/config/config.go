@@ -6,6 +6,7 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/model"
@@ -50,6 +51,17 @@ func (t typ) Pcl() model.Type {
 	switch t := t.inner.(type) {
 	case *schema.ArrayType:
 		return model.NewListType(typ{t.ElementType}.Pcl())
+	case *schema.OptionalType:
+		return model.NewOptionalType(typ{t.ElementType}.Pcl())
+	case *schema.ObjectType:
+		if len(t.Properties) == 0 {
+			return model.NewMapType(model.DynamicType)
+		}
+		properties := make(map[string]model.Type, len(t.Properties))
+		for _, p := range t.Properties {
+			properties[p.Name] = typ{p.Type}.Pcl()
+		}
+		return model.NewObjectType(properties)
 	}
 
 	// We should never hit this, but if we do an error should be reported instead of
@@ -66,6 +78,10 @@ var (
 	BooleanList      = typ{&schema.ArrayType{ElementType: schema.NumberType}}
 	Int              = typ{schema.IntType}
 	IntList          = typ{&schema.ArrayType{ElementType: schema.IntType}}
+	// Object is the type of config objects whose properties aren't declared. Use NewObject
+	// for objects with a known shape.
+	Object     = typ{&schema.ObjectType{Token: "Object"}}
+	ObjectList = typ{&schema.ArrayType{ElementType: Object.inner}}
 )
 
 type Types []Type
@@ -86,6 +102,8 @@ var ConfigTypes = Types{
 	IntList,
 	Boolean,
 	BooleanList,
+	Object,
+	ObjectList,
 }
 
 func newList(c Type) typ {
@@ -99,6 +117,8 @@ func newList(c Type) typ {
 		return IntList
 	case Boolean:
 		return BooleanList
+	case Object:
+		return ObjectList
 	default:
 		return typ{&schema.ArrayType{ElementType: c.(typ).inner}}
 	}
@@ -141,6 +161,8 @@ func Parse(s string) (Type, bool) {
 		return Number, true
 	case "int", "integer":
 		return Int, true
+	case "object":
+		return Object, true
 	default:
 		return nil, false
 	}
@@ -217,6 +239,8 @@ func TypeValue(v interface{}) (Type, error) {
 			expected = IntList
 		case bool:
 			expected = BooleanList
+		case map[string]interface{}:
+			expected = ObjectList
 		}
 		for i := 1; i < len(v); i++ {
 			if reflect.TypeOf(v[i-1]) != reflect.TypeOf(v[i]) {
@@ -236,7 +260,198 @@ func TypeValue(v interface{}) (Type, error) {
 		return NumberList, nil
 	case []int:
 		return IntList, nil
+	case map[string]interface{}:
+		return Object, nil
 	default:
 		return nil, &UnexpectedTypeErr{v}
 	}
 }
+
+// ObjectProperty describes a property of a config object.
+type ObjectProperty struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+// NewObject returns the type of config objects with the given properties.
+func NewObject(properties []ObjectProperty) Type {
+	props := make([]*schema.Property, len(properties))
+	for i, p := range properties {
+		t := p.Type.Schema()
+		if p.Optional {
+			t = &schema.OptionalType{ElementType: t}
+		}
+		props[i] = &schema.Property{Name: p.Name, Type: t}
+	}
+	return typ{&schema.ObjectType{Properties: props}}
+}
+
+// NewList returns the type of config lists with elements of type element.
+func NewList(element Type) Type {
+	return newList(element)
+}
+
+// IsStructured returns true if t is an object type or a list of objects, whose values need
+// to be checked with Conform.
+func IsStructured(t Type) bool {
+	switch t := t.Schema().(type) {
+	case *schema.ObjectType:
+		return true
+	case *schema.ArrayType:
+		return IsStructured(typ{t.ElementType})
+	}
+	return false
+}
+
+// ShapeErr describes a value that doesn't have the shape of its config type.
+type ShapeErr struct {
+	// The path to the offending part of the value, such as "[1].port".
+	Path    string
+	Message string
+	// Redacted is Message without the offending value, for values that are secret.
+	Redacted string
+}
+
+func (e *ShapeErr) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Conform checks that the value v, as decoded from JSON, has the shape of the type t. Numbers
+// are converted to ints where t expects them. If v doesn't conform, the error is a *ShapeErr.
+func Conform(t Type, v interface{}) (interface{}, error) {
+	return conform(t.Schema(), v, "")
+}
+
+func conform(t schema.Type, v interface{}, path string) (interface{}, error) {
+	mismatch := func() (interface{}, error) {
+		return nil, &ShapeErr{
+			Path:     path,
+			Message:  fmt.Sprintf("expected %s, got %s", yamldiags.DisplayType(t), describeValue(v)),
+			Redacted: fmt.Sprintf("expected %s, got %s", yamldiags.DisplayType(t), describeKind(v)),
+		}
+	}
+	switch t {
+	case schema.StringType:
+		if _, ok := v.(string); !ok {
+			return mismatch()
+		}
+		return v, nil
+	case schema.BoolType:
+		if _, ok := v.(bool); !ok {
+			return mismatch()
+		}
+		return v, nil
+	case schema.NumberType:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		}
+		return mismatch()
+	case schema.IntType:
+		switch v := v.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		}
+		return mismatch()
+	}
+
+	switch t := t.(type) {
+	case *schema.OptionalType:
+		return conform(t.ElementType, v, path)
+	case *schema.ArrayType:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		result := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			e, err := conform(t.ElementType, elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = e
+		}
+		return result, nil
+	case *schema.ObjectType:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		if len(t.Properties) == 0 {
+			return obj, nil
+		}
+		result := make(map[string]interface{}, len(obj))
+		for _, p := range t.Properties {
+			propPath := p.Name
+			if path != "" {
+				propPath = path + "." + p.Name
+			}
+			pv, ok := obj[p.Name]
+			if !ok {
+				if _, optional := p.Type.(*schema.OptionalType); !optional {
+					return nil, &ShapeErr{Path: path, Message: fmt.Sprintf("missing required property %q", p.Name), Redacted: fmt.Sprintf("missing required property %q", p.Name)}
+				}
+				continue
+			}
+			e, err := conform(p.Type, pv, propPath)
+			if err != nil {
+				return nil, err
+			}
+			result[p.Name] = e
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := t.Property(k); !ok {
+				return nil, &ShapeErr{Path: path, Message: fmt.Sprintf("unknown property %q", k), Redacted: fmt.Sprintf("unknown property %q", k)}
+			}
+		}
+		return result, nil
+	}
+	return mismatch()
+}
+
+// describeValue returns the kind of a value decoded from JSON, for use in error messages.
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64, int:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// describeKind returns the kind of a value decoded from JSON without the value itself.
+func describeKind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case float64, int:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return describeValue(v)
+}
@@ -19,6 +19,8 @@ func TestParse(t *testing.T) {
 		{"List< String >", StringList},
 		{"List", nil},
 		{"List<>", nil},
+		{"Object", Object},
+		{"List<Object>", ObjectList},
 	}
 
 	for _, c := range cases {
@@ -53,6 +55,8 @@ func TestTypeValue(t *testing.T) {
 		{[]int{}, IntList, nil},
 		{[]interface{}{}, nil, ErrEmptyList},
 		{[]interface{}{false, true}, BooleanList, nil},
+		{map[string]interface{}{"a": 1}, Object, nil},
+		{[]interface{}{map[string]interface{}{"a": 1}}, ObjectList, nil},
 	}
 	//nolint:paralleltest // false positive that the "c" var isn't used, it is used via "c.input"
 	for _, c := range cases {
@@ -69,3 +73,103 @@ func TestTypeValue(t *testing.T) {
 		})
 	}
 }
+
+func TestConform(t *testing.T) {
+	t.Parallel()
+
+	rule := NewObject([]ObjectProperty{
+		{Name: "port", Type: Int},
+		{Name: "cidrs", Type: StringList},
+		{Name: "description", Type: String, Optional: true},
+	})
+	network := NewObject([]ObjectProperty{
+		{Name: "name", Type: String},
+		{Name: "rules", Type: NewList(rule)},
+	})
+
+	cases := []struct {
+		name     string
+		typ      Type
+		input    interface{}
+		expected interface{}
+		error    string
+	}{
+		{
+			name: "list of objects",
+			typ:  NewList(rule),
+			input: []interface{}{
+				map[string]interface{}{"port": 80.0, "cidrs": []interface{}{"0.0.0.0/0"}},
+			},
+			expected: []interface{}{
+				map[string]interface{}{"port": 80, "cidrs": []interface{}{"0.0.0.0/0"}},
+			},
+		},
+		{
+			name: "nested object",
+			typ:  network,
+			input: map[string]interface{}{
+				"name": "main",
+				"rules": []interface{}{
+					map[string]interface{}{"port": 443.0, "cidrs": []interface{}{}, "description": "https"},
+				},
+			},
+			expected: map[string]interface{}{
+				"name": "main",
+				"rules": []interface{}{
+					map[string]interface{}{"port": 443, "cidrs": []interface{}{}, "description": "https"},
+				},
+			},
+		},
+		{
+			name:     "untyped object",
+			typ:      Object,
+			input:    map[string]interface{}{"anything": true},
+			expected: map[string]interface{}{"anything": true},
+		},
+		{
+			name: "wrong property type",
+			typ:  network,
+			input: map[string]interface{}{
+				"name": "main",
+				"rules": []interface{}{
+					map[string]interface{}{"port": 80.0, "cidrs": []interface{}{}},
+					map[string]interface{}{"port": "http", "cidrs": []interface{}{}},
+				},
+			},
+			error: `rules[1].port: expected integer, got string "http"`,
+		},
+		{
+			name:  "missing property",
+			typ:   NewList(rule),
+			input: []interface{}{map[string]interface{}{"cidrs": []interface{}{}}},
+			error: `[0]: missing required property "port"`,
+		},
+		{
+			name:  "unknown property",
+			typ:   network,
+			input: map[string]interface{}{"name": "main", "rules": []interface{}{}, "zone": "a"},
+			error: `unknown property "zone"`,
+		},
+		{
+			name:  "not an object",
+			typ:   network,
+			input: []interface{}{},
+			error: "expected {name: string, rules: List<{port: integer, cidrs: List<string>, description: string}>}, got a list",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := Conform(c.typ, c.input)
+			if c.error != "" {
+				var shapeErr *ShapeErr
+				assert.ErrorAs(t, err, &shapeErr)
+				assert.EqualError(t, err, c.error)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	ctypes "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/config"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// configDeclType returns the type declared by the config entry c, including the shape of Object
+// and List<Object> types. It returns nil if c doesn't declare a type.
+func configDeclType(c *ast.ConfigParamDecl) (ctypes.Type, syntax.Diagnostics) {
+	var diags syntax.Diagnostics
+	if c.Type == nil {
+		if len(c.Properties.Entries) > 0 || c.Items != nil {
+			diags.Extend(syntax.NodeError(c.Syntax(),
+				"'type' is required when 'properties' or 'items' is set", ""))
+		}
+		return nil, diags
+	}
+	t, ok := ctypes.Parse(c.Type.Value)
+	if !ok {
+		diags.Extend(ast.ExprError(c.Type, fmt.Sprintf("unexpected configuration type '%s': valid types are %s",
+			c.Type.Value, ctypes.ConfigTypes), ""))
+		return nil, diags
+	}
+
+	switch t {
+	case ctypes.Object:
+		if c.Items != nil {
+			diags.Extend(ast.ExprError(c.Type, "'items' can only be used with the List<Object> type", ""))
+		}
+		return configObjectType(c, diags)
+	case ctypes.ObjectList:
+		if len(c.Properties.Entries) > 0 {
+			diags.Extend(ast.ExprError(c.Type, "'properties' can only be used with the Object type",
+				"Declare the properties of the list's elements under 'items'"))
+		}
+		if c.Items == nil {
+			return t, diags
+		}
+		if c.Items.Type != nil {
+			if it, ok := ctypes.Parse(c.Items.Type.Value); !ok || it != ctypes.Object {
+				diags.Extend(ast.ExprError(c.Items.Type, "the items of a List<Object> must have type Object", ""))
+				return nil, diags
+			}
+		}
+		elem, diags := configObjectType(c.Items, diags)
+		if elem == nil {
+			return nil, diags
+		}
+		return ctypes.NewList(elem), diags
+	}
+
+	if len(c.Properties.Entries) > 0 {
+		diags.Extend(ast.ExprError(c.Type, "'properties' can only be used with the Object type", ""))
+	}
+	return t, diags
+}
+
+// configObjectType returns the type of the object whose properties are declared by c.
+func configObjectType(c *ast.ConfigParamDecl, diags syntax.Diagnostics) (ctypes.Type, syntax.Diagnostics) {
+	if len(c.Properties.Entries) == 0 {
+		return ctypes.Object, diags
+	}
+	properties := make([]ctypes.ObjectProperty, 0, len(c.Properties.Entries))
+	for _, entry := range c.Properties.Entries {
+		name := entry.Key.Value
+		if entry.Value == nil || entry.Value.Type == nil {
+			diags.Extend(ast.ExprError(entry.Key, fmt.Sprintf("property %q must declare its type", name), ""))
+			continue
+		}
+		t, pdiags := configDeclType(entry.Value)
+		diags.Extend(pdiags...)
+		if t == nil {
+			continue
+		}
+		properties = append(properties, ctypes.ObjectProperty{
+			Name:     name,
+			Type:     t,
+			Optional: entry.Value.Default != nil,
+		})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return ctypes.NewObject(properties), diags
+}
+
+// configShapeError describes a value of the config entry name that doesn't conform to its type.
+// The value itself is left out if it is secret.
+func configShapeError(name string, err error, secret bool) string {
+	var shapeErr *ctypes.ShapeErr
+	if !errors.As(err, &shapeErr) {
+		return fmt.Sprintf("invalid value for config %q: %v", name, err)
+	}
+	path := name
+	switch {
+	case strings.HasPrefix(shapeErr.Path, "["):
+		path += shapeErr.Path
+	case shapeErr.Path != "":
+		path += "." + shapeErr.Path
+	}
+	message := shapeErr.Message
+	if secret {
+		message = shapeErr.Redacted
+	}
+	return fmt.Sprintf("invalid value for config %s: %s", path, message)
+}
+
+// applyConfigDecl fills in the defaults of the object properties declared by c that are missing
+// from v, and marks the properties declared as secret. v must conform to the type of c.
+func applyConfigDecl(c *ast.ConfigParamDecl, v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		if c.Items == nil {
+			return v
+		}
+		for i, elem := range v {
+			v[i] = applyConfigDecl(c.Items, elem)
+		}
+		return v
+	case map[string]interface{}:
+		for _, entry := range c.Properties.Entries {
+			name, decl := entry.Key.Value, entry.Value
+			if decl == nil {
+				continue
+			}
+			pv, ok := v[name]
+			if !ok {
+				d, isLiteral := configLiteral(decl.Default)
+				if !isLiteral {
+					continue
+				}
+				if t, _ := configDeclType(decl); t != nil {
+					if conformed, err := ctypes.Conform(t, d); err == nil {
+						d = conformed
+					}
+				}
+				pv = d
+			}
+			pv = applyConfigDecl(decl, pv)
+			if decl.Secret != nil && decl.Secret.Value {
+				pv = pulumi.ToSecret(pv)
+			}
+			v[name] = pv
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,165 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const objectConfigTemplate = `
+name: test-yaml
+runtime: yaml
+configuration:
+  rules:
+    type: List<Object>
+    items:
+      properties:
+        port:
+          type: Integer
+        cidrs:
+          type: List<String>
+        description:
+          type: String
+          default: managed by pulumi
+  network:
+    type: Object
+    properties:
+      name:
+        type: String
+      credentials:
+        type: Object
+        properties:
+          user:
+            type: String
+          password:
+            type: String
+            secret: true
+variables:
+  firstPort: ${rules[0].port}
+  user: ${network.credentials.user}
+`
+
+func TestConfigObjectTypes(t *testing.T) { //nolint:paralleltest
+	tmpl := yamlTemplate(t, strings.TrimSpace(objectConfigTemplate))
+	// Structured config reaches the program as JSON.
+	setConfig(t, resource.PropertyMap{
+		projectConfigKey("rules"): resource.NewStringProperty(
+			`[{"port": 80, "cidrs": ["10.0.0.0/8"]}, {"port": 443, "cidrs": [], "description": "https"}]`),
+		projectConfigKey("network"): resource.NewStringProperty(
+			`{"name": "main", "credentials": {"user": "admin", "password": "hunter2"}}`),
+	})
+	testRan := false
+	err := testTemplateDiags(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"port": 80, "cidrs": []interface{}{"10.0.0.0/8"}, "description": "managed by pulumi"},
+			map[string]interface{}{"port": 443, "cidrs": []interface{}{}, "description": "https"},
+		}, e.config["rules"])
+		assert.Equal(t, 80, e.variables["firstPort"])
+
+		network := e.config["network"].(map[string]interface{})
+		credentials := network["credentials"].(map[string]interface{})
+		assert.Equal(t, "admin", credentials["user"])
+		password, ok := credentials["password"].(pulumi.Output)
+		require.True(t, ok, "the password should be a secret output")
+		assert.True(t, pulumi.IsSecret(password))
+
+		testRan = true
+	})
+	assert.True(t, testRan, "Our tests didn't run")
+	diags, found := HasDiagnostics(err)
+	assert.False(t, found, "We should not get any errors: '%s'", diags)
+}
+
+func TestConfigObjectTypesTyping(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(objectConfigTemplate))
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, "integer", typing.TypeVariable("firstPort").String())
+	assert.Equal(t, "string", typing.TypeVariable("user").String())
+}
+
+func TestConfigObjectTypesStackConfig(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(objectConfigTemplate))
+	r := newRunner(tmpl, newMockPackageMap())
+	r.setIntermediates(testProject, nil, resource.NewPropertyMapFromMap(map[string]interface{}{
+		string(projectConfigKey("rules")): []interface{}{
+			map[string]interface{}{"port": 80, "cidrs": []interface{}{"10.0.0.0/8"}},
+			map[string]interface{}{"port": "https", "cidrs": []interface{}{}},
+		},
+		string(projectConfigKey("network")): `{"name": "main", "credentials": {"user": "admin"}}`,
+	}), false)
+	require.False(t, r.sdiags.HasErrors(), r.sdiags.Error())
+	_, diags := TypeCheck(r)
+	assert.ElementsMatch(t, []string{
+		`<stdin>:5:11: invalid value for config rules[1].port: expected integer, got string "https"`,
+		`<stdin>:16:11: invalid value for config network.credentials: missing required property "password"`,
+	}, diagStrings(diags))
+}
+
+func TestConfigObjectTypesSecretStackConfig(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(objectConfigTemplate))
+	r := newRunner(tmpl, newMockPackageMap())
+	rules := resource.NewPropertyValue([]interface{}{
+		map[string]interface{}{"port": "hunter2", "cidrs": []interface{}{}},
+	})
+	r.setIntermediates(testProject, nil, resource.PropertyMap{
+		projectConfigKey("rules"): resource.MakeSecret(rules),
+		projectConfigKey("network"): resource.NewStringProperty(
+			`{"name": "main", "credentials": {"user": "admin", "password": "pw"}}`),
+	}, false)
+	require.False(t, r.sdiags.HasErrors(), r.sdiags.Error())
+	_, diags := TypeCheck(r)
+	assert.Equal(t, []string{
+		`<stdin>:5:11: invalid value for config rules[0].port: expected integer, got a string`,
+	}, diagStrings(diags))
+}
+
+func TestConfigObjectTypesDiags(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  name:
+    type: String
+    properties:
+      first:
+        type: String
+  tags:
+    type: List<Object>
+    items:
+      type: String
+  rule:
+    type: Object
+    properties:
+      port: {}
+  defaults:
+    type: Object
+    properties:
+      size:
+        type: Integer
+    default:
+      size: large
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		"<stdin>:5:11: 'properties' can only be used with the Object type",
+		"<stdin>:12:13: the items of a List<Object> must have type Object",
+		`<stdin>:16:7: property "port" must declare its type`,
+		`<stdin>:23:7: invalid value for config defaults.size: expected integer, got string "large"`,
+	}, diagStrings(diags))
+}
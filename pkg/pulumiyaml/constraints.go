@@ -0,0 +1,172 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	ctypes "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/config"
+	yamldiags "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/diags"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// checkConfigConstraintDecls checks that the constraints declared on a config entry of type typ are
+// well formed and apply to that type.
+func checkConfigConstraintDecls(c *ast.ConfigParamDecl, typ ctypes.Type) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	isNumber := typ == ctypes.Number || typ == ctypes.Int
+	isString := typ == ctypes.String
+	isList := false
+	if typ != nil {
+		_, isList = typ.Schema().(*schema.ArrayType)
+	}
+
+	requireType := func(expr ast.Expr, name string, ok bool, expected string) bool {
+		if expr == nil || reflect.ValueOf(expr).IsNil() {
+			return false
+		}
+		if !ok && typ != nil {
+			diags.Extend(ast.ExprError(expr,
+				fmt.Sprintf("%s can only be used with %s, but the config entry has type %s", name, expected, typ), ""))
+			return false
+		}
+		return true
+	}
+	requireType(c.Minimum, "minimum", isNumber, "numbers")
+	requireType(c.Maximum, "maximum", isNumber, "numbers")
+	if c.Minimum != nil && c.Maximum != nil && c.Minimum.Value > c.Maximum.Value {
+		diags.Extend(ast.ExprError(c.Minimum, "minimum is greater than maximum", ""))
+	}
+	for _, length := range []struct {
+		name string
+		expr *ast.NumberExpr
+	}{{"minLength", c.MinLength}, {"maxLength", c.MaxLength}} {
+		if !requireType(length.expr, length.name, isString || isList, "strings and lists") {
+			continue
+		}
+		if v := length.expr.Value; v < 0 || v != math.Trunc(v) {
+			diags.Extend(ast.ExprError(length.expr,
+				fmt.Sprintf("%s must be a non-negative integer", length.name), ""))
+		}
+	}
+	if c.MinLength != nil && c.MaxLength != nil && c.MinLength.Value > c.MaxLength.Value {
+		diags.Extend(ast.ExprError(c.MinLength, "minLength is greater than maxLength", ""))
+	}
+	if requireType(c.Pattern, "pattern", isString, "strings") {
+		if _, err := regexp.Compile(c.Pattern.Value); err != nil {
+			diags.Extend(ast.ExprError(c.Pattern, "invalid pattern", err.Error()))
+		}
+	}
+	if requireType(c.AllowedValues, "allowedValues", isNumber || isString || typ == ctypes.Boolean,
+		"strings, numbers and booleans") {
+		for _, elem := range c.AllowedValues.Elements {
+			switch elem.(type) {
+			case *ast.StringExpr, *ast.NumberExpr, *ast.BooleanExpr:
+			default:
+				diags.Extend(ast.ExprError(elem, "allowedValues must only contain strings, numbers and booleans", ""))
+			}
+		}
+	}
+	return diags
+}
+
+// checkConfigConstraints checks the value of the config entry name against the constraints
+// declared by c. Diagnostics point at the violated constraint. If secret is true, the value is
+// left out of them.
+func checkConfigConstraints(name string, c *ast.ConfigParamDecl, value interface{}, secret bool) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	violated := func(constraint ast.Expr, requirement string) {
+		summary := fmt.Sprintf("config %q must %s", name, requirement)
+		if !secret {
+			summary = fmt.Sprintf("%s, but is %s", summary, displayConfigValue(value))
+		}
+		diags.Extend(ast.ExprError(constraint, summary, ""))
+	}
+
+	if n, ok := configNumber(value); ok {
+		if c.Minimum != nil && n < c.Minimum.Value {
+			violated(c.Minimum, fmt.Sprintf("be at least %v", c.Minimum.Value))
+		}
+		if c.Maximum != nil && n > c.Maximum.Value {
+			violated(c.Maximum, fmt.Sprintf("be at most %v", c.Maximum.Value))
+		}
+	}
+
+	length := -1
+	if s, ok := value.(string); ok {
+		length = utf8.RuneCountInString(s)
+		if c.Pattern != nil {
+			if re, err := regexp.Compile(c.Pattern.Value); err == nil && !re.MatchString(s) {
+				violated(c.Pattern, fmt.Sprintf("match the pattern %q", c.Pattern.Value))
+			}
+		}
+	} else if v := reflect.ValueOf(value); v.Kind() == reflect.Slice {
+		length = v.Len()
+	}
+	if length >= 0 {
+		if c.MinLength != nil && float64(length) < c.MinLength.Value {
+			violated(c.MinLength, fmt.Sprintf("have a length of at least %v", c.MinLength.Value))
+		}
+		if c.MaxLength != nil && float64(length) > c.MaxLength.Value {
+			violated(c.MaxLength, fmt.Sprintf("have a length of at most %v", c.MaxLength.Value))
+		}
+	}
+
+	if c.AllowedValues != nil {
+		var allowed []string
+		found := false
+		for _, elem := range c.AllowedValues.Elements {
+			var v interface{}
+			switch elem := elem.(type) {
+			case *ast.StringExpr:
+				v = elem.Value
+			case *ast.NumberExpr:
+				v = elem.Value
+			case *ast.BooleanExpr:
+				v = elem.Value
+			default:
+				continue
+			}
+			allowed = append(allowed, displayConfigValue(v))
+			if configValuesEqual(v, value) {
+				found = true
+			}
+		}
+		if !found {
+			violated(c.AllowedValues, fmt.Sprintf("be one of %v", yamldiags.OrList(allowed)))
+		}
+	}
+	return diags
+}
+
+func configNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func configValuesEqual(a, b interface{}) bool {
+	if an, ok := configNumber(a); ok {
+		bn, ok := configNumber(b)
+		return ok && an == bn
+	}
+	return a == b
+}
+
+func displayConfigValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", value)
+}
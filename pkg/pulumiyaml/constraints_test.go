@@ -0,0 +1,181 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func diagStrings(diags syntax.Diagnostics) []string {
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	return messages
+}
+
+func TestConfigConstraintDecls(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  port:
+    type: integer
+    minimum: 1024
+    maximum: 80
+    pattern: "[0-9]+"
+  name:
+    type: string
+    minimum: 3
+    minLength: -1
+    maxLength: 2.5
+    pattern: "[a-z"
+  zones:
+    type: List<String>
+    maxLength: 3
+    allowedValues: [a, b]
+  mode:
+    type: string
+    allowedValues: [fast, [slow]]
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		"<stdin>:6:14: minimum is greater than maximum",
+		"<stdin>:8:14: pattern can only be used with strings, but the config entry has type integer",
+		"<stdin>:11:14: minimum can only be used with numbers, but the config entry has type string",
+		"<stdin>:12:16: minLength must be a non-negative integer",
+		"<stdin>:13:16: maxLength must be a non-negative integer",
+		"<stdin>:14:14: invalid pattern; error parsing regexp: missing closing ]: `[a-z`",
+		"<stdin>:18:20: allowedValues can only be used with strings, numbers and booleans, but the config entry has type List<string>",
+		"<stdin>:21:27: allowedValues must only contain strings, numbers and booleans",
+	}, diagStrings(diags))
+}
+
+func TestConfigConstraintsDefault(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  port:
+    default: 80
+    minimum: 1024
+  name:
+    default: My-Bucket
+    pattern: ^[a-z-]+$
+    maxLength: 5
+  password:
+    default: hunter2
+    secret: true
+    minLength: 12
+  mode:
+    default: fast
+    allowedValues: [fast, slow]
+  zones:
+    default: [a]
+    minLength: 2
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		`<stdin>:6:14: config "port" must be at least 1024, but is 80`,
+		`<stdin>:9:14: config "name" must match the pattern "^[a-z-]+$", but is "My-Bucket"`,
+		`<stdin>:10:16: config "name" must have a length of at most 5, but is "My-Bucket"`,
+		`<stdin>:14:16: config "password" must have a length of at least 12`,
+		`<stdin>:20:16: config "zones" must have a length of at least 2, but is [a]`,
+	}, diagStrings(diags))
+}
+
+func TestConfigConstraintsStackConfig(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  port:
+    type: integer
+    maximum: 65535
+  region:
+    type: string
+    allowedValues: [us-east-1, eu-west-1]
+  token:
+    type: string
+    minLength: 10
+  pending:
+    type: string
+    minLength: 10
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	r := newRunner(tmpl, newMockPackageMap())
+	r.setIntermediates(testProject, nil, resource.PropertyMap{
+		projectConfigKey("port"):    resource.NewStringProperty("70000"),
+		projectConfigKey("region"):  resource.NewStringProperty("ap-south-1"),
+		projectConfigKey("token"):   resource.MakeSecret(resource.NewStringProperty("short")),
+		projectConfigKey("pending"): resource.MakeComputed(resource.NewStringProperty("")),
+	}, false)
+	require.False(t, r.sdiags.HasErrors(), r.sdiags.Error())
+	_, diags := TypeCheck(r)
+	assert.ElementsMatch(t, []string{
+		`<stdin>:6:14: config "port" must be at most 65535, but is 70000`,
+		`<stdin>:9:20: config "region" must be one of "us-east-1" or "eu-west-1", but is "ap-south-1"`,
+		`<stdin>:12:16: config "token" must have a length of at least 10`,
+	}, diagStrings(diags))
+}
+
+func TestConfigConstraintsRuntime(t *testing.T) { //nolint:paralleltest
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  port:
+    type: integer
+    minimum: 1024
+  name:
+    type: string
+    pattern: ^[a-z]+$
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	setConfig(t, resource.PropertyMap{
+		projectConfigKey("port"): resource.NewStringProperty("80"),
+		projectConfigKey("name"): resource.NewStringProperty("bucket"),
+	})
+	diags := testTemplateDiags(t, tmpl, nil)
+	assert.Equal(t, []string{
+		`<stdin>:6:14: config "port" must be at least 1024, but is 80`,
+	}, diagStrings(diags))
+}
+
+func TestConfigConstraintsRuntimeSecret(t *testing.T) { //nolint:paralleltest
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  password:
+    type: string
+    minLength: 12
+    secret: true
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	setConfig(t, resource.PropertyMap{
+		projectConfigKey("password"): resource.NewStringProperty("hunter2"),
+	})
+	diags := testTemplateDiags(t, tmpl, nil)
+	assert.Equal(t, []string{
+		`<stdin>:6:16: config "password" must have a length of at least 12`,
+	}, diagStrings(diags))
+	for _, d := range diags {
+		assert.NotContains(t, d.Summary+d.Detail, "hunter2")
+	}
+}
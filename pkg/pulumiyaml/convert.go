@@ -0,0 +1,72 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// convertToString is the conversion of fn::toString. Numbers are written in decimal, without an
+// exponent, with as many digits as are needed to read them back exactly.
+func convertToString(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return nil, fmt.Errorf("fn::toString can't convert %s; use fn::toJSON for lists and objects", typeString(v))
+}
+
+// convertToNumber is the conversion of fn::toNumber. Strings must spell a finite number, in
+// decimal or with an exponent, without surrounding space.
+func convertToNumber(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("fn::toNumber can't convert %s", typeString(v))
+}
+
+// convertToBool is the conversion of fn::toBool. Strings must be true, false, yes, no, 1 or 0, in
+// any case, and numbers must be 1 or 0.
+func convertToBool(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		switch v {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		}
+		return nil, fmt.Errorf("%v is not a boolean; only 1 and 0 are", v)
+	case int:
+		return convertToBool(float64(v))
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "yes", "1":
+			return true, nil
+		case "false", "no", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a boolean; use true, false, yes, no, 1 or 0", v)
+	}
+	return nil, fmt.Errorf("fn::toBool can't convert %s", typeString(v))
+}
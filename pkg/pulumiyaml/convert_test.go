@@ -0,0 +1,133 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func TestConversions(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-convert
+runtime: yaml
+variables:
+  count: "3"
+  big:
+    fn::toString: 1e21
+  fraction:
+    fn::toString: 0.5
+  flag:
+    fn::toString: true
+  number:
+    fn::toNumber: ${count}
+  exponent:
+    fn::toNumber: "2.5e3"
+  yes:
+    fn::toBool: YES
+  zero:
+    fn::toBool: 0
+  falsy:
+    fn::toBool: "False"
+`)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+	assert.Equal(t, "string", displayType(typing.TypeVariable("big")))
+	assert.Equal(t, "number", displayType(typing.TypeVariable("number")))
+	assert.Equal(t, "boolean", displayType(typing.TypeVariable("yes")))
+
+	// The result types satisfy the properties they are assigned to.
+	resTmpl := yamlTemplate(t, `
+name: test-convert
+runtime: yaml
+variables:
+  size: "3"
+resources:
+  res:
+    type: test:resource:with-create-only
+    properties:
+      name:
+        fn::toString: 42
+      size:
+        fn::toNumber: ${size}
+`)
+	_, diags = TypeCheck(newRunner(resTmpl, newMockPackageMap()))
+	requireNoErrors(t, resTmpl, diags)
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, "1000000000000000000000", e.variables["big"])
+		assert.Equal(t, "0.5", e.variables["fraction"])
+		assert.Equal(t, "true", e.variables["flag"])
+		assert.Equal(t, 3.0, e.variables["number"])
+		assert.Equal(t, 2500.0, e.variables["exponent"])
+		assert.Equal(t, true, e.variables["yes"])
+		assert.Equal(t, false, e.variables["zero"])
+		assert.Equal(t, false, e.variables["falsy"])
+
+		e.variables["unknown"] = pulumi.UnsafeUnknownOutput(nil)
+		unknown := &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "unknown"}}},
+		}
+		for _, x := range []ast.Expr{ast.ToString(unknown), ast.ToNumber(unknown), ast.ToBool(unknown)} {
+			v, ok := e.evaluateExpr(x)
+			require.True(t, ok)
+			e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+				assert.Failf(t, "unexpected apply", "applied a conversion to an unknown: %v", x)
+				return x, nil
+			}))
+		}
+	})
+}
+
+func TestConversionErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-convert
+runtime: yaml
+variables:
+  word:
+    fn::toNumber: abc
+  maybe:
+    fn::toBool: maybe
+  two:
+    fn::toBool: 2
+  list:
+    fn::toString: [a]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`<stdin>:6:19: "abc" is not a number`,
+		`<stdin>:8:17: "maybe" is not a boolean; use true, false, yes, no, 1 or 0`,
+		`<stdin>:10:17: 2 is not a boolean; only 1 and 0 are`,
+		`<stdin>:12:19: fn::toString can't convert a list; use fn::toJSON for lists and objects`,
+	}, diagStrings(diags))
+
+	// Values that are only known when the template runs are converted then.
+	tmpl = yamlTemplate(t, `
+name: test-convert
+runtime: yaml
+variables:
+  word: abc
+  number:
+    fn::toNumber: ${word}
+`)
+	var evalDiags syntax.Diagnostics
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		evalDiags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, evalDiags.HasErrors())
+	assert.Equal(t, `"abc" is not a number`, evalDiags[0].Summary)
+}
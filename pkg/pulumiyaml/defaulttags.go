@@ -0,0 +1,83 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// hasTagsInput reports whether r has a map-valued tags input property, to which defaultTags
+// are added.
+func hasTagsInput(r *schema.Resource) bool {
+	if r == nil {
+		return false
+	}
+	for _, p := range r.InputProperties {
+		if p.Name == "tags" {
+			_, ok := codegen.UnwrapType(p.Type).(*schema.MapType)
+			return ok
+		}
+	}
+	return false
+}
+
+// evaluateDefaultTags evaluates the template's defaultTags. They only refer to config, so they
+// are evaluated once, when the first resource that takes tags is registered.
+func (e *programEvaluator) evaluateDefaultTags() (map[string]interface{}, bool) {
+	e.defaultTagsOnce.Do(func() {
+		tags := make(map[string]interface{}, len(e.t.DefaultTags.Entries))
+		ok := true
+		for _, kvp := range e.t.DefaultTags.Entries {
+			v, vok := e.evaluateExpr(kvp.Value)
+			if !vok {
+				ok = false
+				continue
+			}
+			tags[kvp.Key.Value] = v
+		}
+		e.defaultTags, e.defaultTagsOk = tags, ok
+	})
+	return e.defaultTags, e.defaultTagsOk
+}
+
+// applyDefaultTags merges the template's defaultTags into the tags property of a resource with
+// the schema r. Tags set by the resource take precedence. Resources without a tags input are
+// left alone.
+func (e *programEvaluator) applyDefaultTags(r *schema.Resource, props map[string]interface{}) bool {
+	if len(e.t.DefaultTags.Entries) == 0 || !hasTagsInput(r) {
+		return true
+	}
+	defaults, ok := e.evaluateDefaultTags()
+	if !ok {
+		return false
+	}
+	merge := func(tags map[string]interface{}) map[string]interface{} {
+		merged := make(map[string]interface{}, len(defaults)+len(tags))
+		for k, v := range defaults {
+			merged[k] = v
+		}
+		for k, v := range tags {
+			merged[k] = v
+		}
+		return merged
+	}
+
+	tags, ok := props["tags"]
+	if !ok || tags == nil {
+		props["tags"] = merge(nil)
+		return true
+	}
+	merged, ok := e.lift(func(args ...interface{}) (interface{}, bool) {
+		tags, ok := args[0].(map[string]interface{})
+		if !ok {
+			// Leave values that aren't maps for the provider to reject.
+			return args[0], true
+		}
+		return merge(tags), true
+	})(tags)
+	if ok {
+		props["tags"] = merged
+	}
+	return ok
+}
@@ -0,0 +1,114 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTags(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+configuration:
+  costCenter:
+    default: cc-1234
+defaultTags:
+  org: platform
+  cost-center: ${costCenter}
+  stack: ${pulumi.stack}
+resources:
+  untagged:
+    type: test:resource:with-tags
+  tagged:
+    type: test:resource:with-tags
+    properties:
+      tags:
+        org: payments
+        team: checkout
+  computed:
+    type: test:resource:with-tags
+    properties:
+      foo: ${tagged.foo}
+      tags:
+        source: ${tagged.foo}
+  no-tags-input:
+    type: test:resource:type
+    properties:
+      foo: bar
+`)
+	var lock sync.Mutex
+	inputs := map[string]resource.PropertyMap{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			inputs[args.Name] = args.Inputs
+			outputs := args.Inputs.Copy()
+			if args.Name == "tagged" {
+				outputs["foo"] = resource.NewStringProperty("from-tagged")
+			}
+			return args.Name, outputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		diags := runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	tags := func(name string) map[string]interface{} {
+		v, ok := inputs[name]["tags"]
+		require.True(t, ok, "resource %q has no tags", name)
+		return v.ObjectValue().Mappable()
+	}
+	assert.Equal(t, map[string]interface{}{
+		"org":         "platform",
+		"cost-center": "cc-1234",
+		"stack":       "stack",
+	}, tags("untagged"))
+	assert.Equal(t, map[string]interface{}{
+		"org":         "payments",
+		"cost-center": "cc-1234",
+		"stack":       "stack",
+		"team":        "checkout",
+	}, tags("tagged"))
+	assert.Equal(t, map[string]interface{}{
+		"org":         "platform",
+		"cost-center": "cc-1234",
+		"stack":       "stack",
+		"source":      "from-tagged",
+	}, tags("computed"))
+	assert.Equal(t, resource.PropertyMap{
+		"foo": resource.NewStringProperty("bar"),
+	}, inputs["no-tags-input"])
+}
+
+func TestDefaultTagsReferences(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+variables:
+  owner: someone
+defaultTags:
+  owner: ${owner}
+resources:
+  res:
+    type: test:resource:with-tags
+`)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `<stdin>:7:10: defaultTags can only refer to config, not "owner"`, diagString(diags[0]))
+}
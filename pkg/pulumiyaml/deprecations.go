@@ -0,0 +1,119 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// deprecationWarnings is the set of kinds of schema deprecations that the analyser warns about.
+type deprecationWarnings int
+
+const (
+	// deprecatedResources warns about resources whose type is deprecated.
+	deprecatedResources deprecationWarnings = 1 << iota
+	// deprecatedFunctions warns about invokes of deprecated functions.
+	deprecatedFunctions
+	// deprecatedProperties warns about deprecated properties that are set, including the
+	// properties of objects and of the arguments of invokes.
+	deprecatedProperties
+
+	allDeprecations = deprecatedResources | deprecatedFunctions | deprecatedProperties
+)
+
+// PULUMI_YAML_SUPPRESS_DEPRECATIONS is a comma-separated list of the kinds of deprecations that
+// aren't reported: resources, functions and properties. It is true to suppress all of them.
+var defaultDeprecationWarnings = parseDeprecationWarnings(os.Getenv("PULUMI_YAML_SUPPRESS_DEPRECATIONS"))
+
+func parseDeprecationWarnings(suppress string) deprecationWarnings {
+	if cmdutil.IsTruthy(suppress) {
+		return 0
+	}
+	warnings := allDeprecations
+	for _, kind := range strings.Split(suppress, ",") {
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "resources":
+			warnings &^= deprecatedResources
+		case "functions":
+			warnings &^= deprecatedFunctions
+		case "properties":
+			warnings &^= deprecatedProperties
+		}
+	}
+	return warnings
+}
+
+// checkDeprecatedResource warns if the resource type typ, written as token, is deprecated.
+func (ctx *evalContext) checkDeprecatedResource(token *ast.StringExpr, typ string, res *schema.Resource) {
+	if ctx.deprecationWarnings&deprecatedResources == 0 || res.DeprecationMessage == "" {
+		return
+	}
+	ctx.addWarnDiag(token.Syntax().Syntax().Range(),
+		fmt.Sprintf("resource type %s is deprecated", typ), res.DeprecationMessage)
+}
+
+// checkDeprecatedFunction warns if the function fn, written as token, is deprecated.
+func (ctx *evalContext) checkDeprecatedFunction(token *ast.StringExpr, name string, fn *schema.Function) {
+	if ctx.deprecationWarnings&deprecatedFunctions == 0 || fn.DeprecationMessage == "" {
+		return
+	}
+	ctx.addWarnDiag(token.Syntax().Syntax().Range(),
+		fmt.Sprintf("function %s is deprecated", name), fn.DeprecationMessage)
+}
+
+// checkDeprecatedProperties warns about the deprecated properties among entries, whose
+// properties are props, and among the properties of objects within their values. token is the
+// resource type or function that the properties belong to.
+func (ctx *evalContext) checkDeprecatedProperties(token string, entries []ast.ObjectProperty, props []*schema.Property) {
+	if ctx.deprecationWarnings&deprecatedProperties == 0 {
+		return
+	}
+	for _, entry := range entries {
+		key, ok := entry.Key.(*ast.StringExpr)
+		if !ok {
+			continue
+		}
+		for _, prop := range props {
+			if prop.Name != key.Value {
+				continue
+			}
+			if prop.DeprecationMessage != "" {
+				ctx.addWarnDiag(key.Syntax().Syntax().Range(),
+					fmt.Sprintf("property %q of %s is deprecated", prop.Name, token), prop.DeprecationMessage)
+			}
+			ctx.checkDeprecatedValue(entry.Value, prop.Type)
+			break
+		}
+	}
+}
+
+// checkDeprecatedValue warns about the deprecated properties of the objects within x, which is of
+// type typ.
+func (ctx *evalContext) checkDeprecatedValue(x ast.Expr, typ schema.Type) {
+	switch typ := codegen.UnwrapType(typ).(type) {
+	case *schema.ObjectType:
+		if obj, ok := x.(*ast.ObjectExpr); ok {
+			ctx.checkDeprecatedProperties(typ.Token, obj.Entries, typ.Properties)
+		}
+	case *schema.ArrayType:
+		if list, ok := x.(*ast.ListExpr); ok {
+			for _, elem := range list.Elements {
+				ctx.checkDeprecatedValue(elem, typ.ElementType)
+			}
+		}
+	case *schema.MapType:
+		if obj, ok := x.(*ast.ObjectExpr); ok {
+			for _, entry := range obj.Entries {
+				ctx.checkDeprecatedValue(entry.Value, typ.ElementType)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecationSchema = `{
+  "name": "legacy",
+  "version": "1.0.0",
+  "types": {
+    "legacy:index:Listener": {
+      "type": "object",
+      "properties": {
+        "port": {"type": "integer"},
+        "insecure": {"type": "boolean", "deprecationMessage": "Use tls instead."}
+      }
+    }
+  },
+  "resources": {
+    "legacy:index:Server": {
+      "inputProperties": {
+        "name": {"type": "string"},
+        "size": {"type": "string", "deprecationMessage": "Use instanceType instead."},
+        "instanceType": {"type": "string"},
+        "listeners": {"type": "array", "items": {"$ref": "#/types/legacy:index:Listener"}}
+      }
+    },
+    "legacy:index:Machine": {
+      "deprecationMessage": "Machine has been renamed to Server.",
+      "inputProperties": {
+        "name": {"type": "string"}
+      }
+    }
+  },
+  "functions": {
+    "legacy:index:getImage": {
+      "deprecationMessage": "Use getImages instead.",
+      "inputs": {"properties": {
+        "name": {"type": "string"},
+        "owner": {"type": "string", "deprecationMessage": "Owners are no longer supported."}
+      }}
+    }
+  }
+}`
+
+func TestDeprecationWarnings(t *testing.T) {
+	t.Parallel()
+
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(deprecationSchema), &spec))
+	bound, err := schema.ImportSpec(spec, nil)
+	require.NoError(t, err)
+	loader := MockPackageLoader{packages: map[string]Package{
+		"legacy": NewResourcePackage(bound.Reference()),
+	}}
+
+	tmpl := yamlTemplate(t, `
+name: test-deprecations
+runtime: yaml
+resources:
+  server:
+    type: legacy:index:Server
+    properties:
+      name: web
+      size: large
+      listeners:
+        - port: 80
+          insecure: true
+  machine:
+    type: legacy:index:Machine
+    properties:
+      name: old
+variables:
+  image:
+    fn::invoke:
+      function: legacy:index:getImage
+      arguments:
+        name: ubuntu
+        owner: canonical
+`)
+	typeCheck := func(suppress string) []string {
+		runner := newRunner(tmpl, loader)
+		runner.deprecationWarnings = parseDeprecationWarnings(suppress)
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		return diagStrings(diags)
+	}
+
+	resource := `<stdin>:14:11: resource type legacy:index:Machine is deprecated; Machine has been renamed to Server.`
+	properties := []string{
+		`<stdin>:9:7: property "size" of legacy:index:Server is deprecated; Use instanceType instead.`,
+		`<stdin>:12:11: property "insecure" of legacy:index:Listener is deprecated; Use tls instead.`,
+		`<stdin>:23:9: property "owner" of legacy:index:getImage is deprecated; Owners are no longer supported.`,
+	}
+	function := `<stdin>:20:17: function legacy:index:getImage is deprecated; Use getImages instead.`
+
+	assert.ElementsMatch(t, append([]string{resource, function}, properties...), typeCheck(""))
+	assert.ElementsMatch(t, []string{function}, typeCheck("resources, properties"))
+	assert.ElementsMatch(t, append([]string{resource}, properties...), typeCheck("functions"))
+	assert.Empty(t, typeCheck("true"))
+}
@@ -33,6 +33,15 @@ func (e NonExistentFieldFormatter) MessageWithDetail(field, fieldLabel string) (
 	return e.messageHeader(fieldLabel), e.messageBody(field)
 }
 
+// The existing field closest to `field`, if one is within `distanceLimit` edits of it.
+func (e NonExistentFieldFormatter) Closest(field string, distanceLimit int) (string, bool) {
+	existing := sortByEditDistance(e.Fields, field)
+	if len(existing) == 0 || editDistance(existing[0], field) > distanceLimit {
+		return "", false
+	}
+	return existing[0], true
+}
+
 func (e NonExistentFieldFormatter) messageHeader(fieldLabel string) string {
 	return fmt.Sprintf("%s does not exist on %s", fieldLabel, e.ParentLabel)
 }
@@ -106,3 +106,18 @@ func TestNonExistentFieldFormatterMessageWithDetail(t *testing.T) {
 		assert.Equalf(t, tt.want2, got2, "MessageWithDetail(%v, %v)", tt.field, tt.fieldLabel)
 	}
 }
+
+func TestNonExistentFieldFormatterClosest(t *testing.T) {
+	t.Parallel()
+	formatter := NonExistentFieldFormatter{ParentLabel: "parent", Fields: []string{"name", "tags", "size"}}
+
+	closest, ok := formatter.Closest("naem", 3)
+	assert.True(t, ok)
+	assert.Equal(t, "name", closest)
+
+	_, ok = formatter.Closest("description", 3)
+	assert.False(t, ok)
+
+	_, ok = NonExistentFieldFormatter{ParentLabel: "parent"}.Closest("name", 3)
+	assert.False(t, ok)
+}
@@ -0,0 +1,224 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// The name that the accumulator of fn::reduce uses to refer to the value folded so far.
+const reduceAccName = "acc"
+
+// elementScope binds ${item} and ${index}, and ${acc} for fn::reduce, while the body of fn::map,
+// fn::filter or fn::reduce is evaluated for an element of its list. The bindings of a nested
+// scope shadow those of the enclosing one, and those of a resource's forEach.
+type elementScope struct {
+	parent   *elementScope
+	bindings map[string]interface{}
+}
+
+// binding returns the value of the element variable name, if name is one.
+func (s *elementScope) binding(name string) (interface{}, bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.bindings[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// elementVariables are the names that x binds within its body.
+func elementVariables(x ast.ElementExpr) []string {
+	if _, ok := x.(*ast.ReduceExpr); ok {
+		return []string{loopIndexName, loopItemName, reduceAccName}
+	}
+	return []string{loopIndexName, loopItemName}
+}
+
+// elementArgs are the arguments of x, of which its body is one.
+func elementArgs(x ast.ElementExpr) []ast.Expr {
+	return x.Args().(*ast.ListExpr).Elements
+}
+
+// evaluateElements evaluates the body of x for each element of its list. The list must be known,
+// so the result is unknown if any element of the list is. Each element is evaluated in a scope of
+// its own rather than by changing the evaluator, which may be evaluating other expressions while
+// the list resolves.
+func (e *programEvaluator) evaluateElements(x ast.ElementExpr, results func(items, values []interface{}) (interface{}, bool)) (interface{}, bool) {
+	list, ok := e.evaluateExpr(x.ElementList())
+	if !ok {
+		return nil, false
+	}
+	evaluate := e.lift(func(args ...interface{}) (interface{}, bool) {
+		items, ok := args[0].([]interface{})
+		if !ok {
+			return e.error(x.ElementList(), fmt.Sprintf("the first argument to %s must be a list, not %v",
+				x.Name().Value, typeString(args[0])))
+		}
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			scoped := *e
+			scoped.element = &elementScope{
+				parent:   e.element,
+				bindings: map[string]interface{}{loopIndexName: float64(i), loopItemName: item},
+			}
+			v, ok := scoped.evaluateExpr(x.ElementBody())
+			if !ok {
+				return nil, false
+			}
+			values[i] = v
+		}
+		return results(items, values)
+	})
+	return evaluate(list)
+}
+
+func (e *programEvaluator) evaluateBuiltinMap(v *ast.MapExpr) (interface{}, bool) {
+	return e.evaluateElements(v, func(items, values []interface{}) (interface{}, bool) {
+		return values, true
+	})
+}
+
+func (e *programEvaluator) evaluateBuiltinFilter(v *ast.FilterExpr) (interface{}, bool) {
+	return e.evaluateElements(v, func(items, values []interface{}) (interface{}, bool) {
+		// Which elements are kept isn't known until every predicate is.
+		keep := e.lift(func(predicates ...interface{}) (interface{}, bool) {
+			kept := []interface{}{}
+			for i, p := range predicates {
+				b, ok := p.(bool)
+				if !ok {
+					return e.error(v.Predicate, fmt.Sprintf("the predicate of fn::filter must be a boolean, not %v", typeString(p)))
+				}
+				if b {
+					kept = append(kept, items[i])
+				}
+			}
+			return kept, true
+		})
+		return keep(values...)
+	})
+}
+
+func (e *programEvaluator) evaluateBuiltinReduce(v *ast.ReduceExpr) (interface{}, bool) {
+	list, ok := e.evaluateExpr(v.List)
+	if !ok {
+		return nil, false
+	}
+	initial, ok := e.evaluateExpr(v.Initial)
+	if !ok {
+		return nil, false
+	}
+	// The initial value isn't awaited: an unknown or secret accumulator makes the value of each
+	// later step unknown or secret through the expressions that refer to it.
+	reduce := e.lift(func(args ...interface{}) (interface{}, bool) {
+		items, ok := args[0].([]interface{})
+		if !ok {
+			return e.error(v.List, fmt.Sprintf("the first argument to fn::reduce must be a list, not %v", typeString(args[0])))
+		}
+		acc := initial
+		for i, item := range items {
+			scoped := *e
+			scoped.element = &elementScope{
+				parent: e.element,
+				bindings: map[string]interface{}{
+					loopIndexName: float64(i),
+					loopItemName:  item,
+					reduceAccName: acc,
+				},
+			}
+			acc, ok = scoped.evaluateExpr(v.Accumulator)
+			if !ok {
+				return nil, false
+			}
+		}
+		return acc, true
+	})
+	return reduce(list)
+}
+
+// typeElementBinding returns the type of the symbol t if it refers to an element variable of a
+// fn::map, fn::filter or fn::reduce whose body is being typed. The innermost binding wins.
+func (tc *typeCache) typeElementBinding(ctx *evalContext, t *ast.SymbolExpr) (schema.Type, bool) {
+	name := t.Property.RootName()
+	for i := len(ctx.elements) - 1; i >= 0; i-- {
+		x := ctx.elements[i]
+		switch name {
+		case loopIndexName:
+			return schema.IntType, true
+		case loopItemName:
+			if list, ok := codegen.UnwrapType(tc.exprs[x.ElementList()]).(*schema.ArrayType); ok {
+				return list.ElementType, true
+			}
+			return schema.AnyType, true
+		case reduceAccName:
+			if x, ok := x.(*ast.ReduceExpr); ok {
+				if typ, ok := tc.exprs[x.Initial]; ok {
+					return typ, true
+				}
+				return schema.AnyType, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// typeElements types fn::map, fn::filter and fn::reduce, tracking the type of the elements of the
+// list through the body.
+func (tc *typeCache) typeElements(ctx *evalContext, x ast.ElementExpr) {
+	tc.assertTypeAssignable(ctx, x.ElementList(), &schema.ArrayType{ElementType: schema.AnyType})
+	switch x := x.(type) {
+	case *ast.MapExpr:
+		var elem schema.Type = schema.AnyType
+		if typ, ok := tc.exprs[x.Transform]; ok {
+			elem = typ
+		}
+		tc.exprs[x] = &schema.ArrayType{ElementType: elem}
+	case *ast.FilterExpr:
+		tc.assertTypeAssignable(ctx, x.Predicate, schema.BoolType)
+		if list, ok := codegen.UnwrapType(tc.exprs[x.List]).(*schema.ArrayType); ok {
+			tc.exprs[x] = list
+		} else {
+			tc.exprs[x] = &schema.ArrayType{ElementType: schema.AnyType}
+		}
+	case *ast.ReduceExpr:
+		// The result is the initial value if the list is empty, and the accumulator's otherwise.
+		var types OrderedTypeSet
+		for _, expr := range []ast.Expr{x.Initial, x.Accumulator} {
+			if typ, ok := tc.exprs[expr]; ok {
+				types.Add(typ)
+			} else {
+				types.Add(schema.AnyType)
+			}
+		}
+		if types.Len() == 1 {
+			tc.exprs[x] = types.First()
+		} else {
+			tc.exprs[x] = &schema.UnionType{ElementTypes: types.Values()}
+		}
+	}
+}
+
+// elementDependencies adds the dependencies of x to deps. The element variables that its body
+// refers to are bound by x, so they aren't dependencies.
+func elementDependencies(deps *[]*ast.StringExpr, x ast.ElementExpr) {
+	bound := elementVariables(x)
+	for _, arg := range elementArgs(x) {
+		if arg != x.ElementBody() {
+			getExpressionDependencies(deps, arg)
+			continue
+		}
+		var body []*ast.StringExpr
+		getExpressionDependencies(&body, arg)
+		for _, dep := range body {
+			if !slices.Contains(bound, dep.Value) {
+				*deps = append(*deps, dep)
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+const elementsTemplate = `
+name: test-elements
+runtime: yaml
+variables:
+  subnets:
+    - id: a
+      public: true
+    - id: b
+      public: false
+    - id: c
+      public: true
+  ids:
+    fn::map: ["${subnets}", "${item.id}"]
+  public:
+    fn::filter: ["${subnets}", "${item.public}"]
+  publicIds:
+    fn::map:
+      - fn::filter: ["${subnets}", "${item.public}"]
+      - ${index}-${item.id}
+  pairs:
+    fn::map:
+      - [1, 2]
+      - fn::map: [[x, y], "${item}${index}"]
+  none:
+    fn::filter: [[], true]
+`
+
+func TestElements(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, elementsTemplate)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, "List<string>", displayType(typing.TypeVariable("ids")))
+	assert.Equal(t, "List<string>", displayType(typing.TypeVariable("publicIds")))
+	assert.Equal(t, "List<List<string>>", displayType(typing.TypeVariable("pairs")))
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, []interface{}{"a", "b", "c"}, e.variables["ids"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": "a", "public": true},
+			map[string]interface{}{"id": "c", "public": true},
+		}, e.variables["public"])
+		assert.Equal(t, []interface{}{"0-a", "1-c"}, e.variables["publicIds"])
+		// The bindings of the inner fn::map shadow those of the outer one.
+		assert.Equal(t, []interface{}{
+			[]interface{}{"x0", "y1"},
+			[]interface{}{"x0", "y1"},
+		}, e.variables["pairs"])
+		assert.Equal(t, []interface{}{}, e.variables["none"])
+	})
+}
+
+func TestElementsUnknown(t *testing.T) {
+	t.Parallel()
+
+	symbol := func(name string) *ast.SymbolExpr {
+		return &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: name}}},
+		}
+	}
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		unknown := pulumi.UnsafeUnknownOutput(nil)
+		e.variables["partly"] = []interface{}{"a", unknown, "b"}
+		e.variables["flags"] = []interface{}{true, unknown}
+		e.variables["words"] = []interface{}{"a", "b"}
+		e.variables["unknown"] = unknown
+
+		for _, x := range []ast.Expr{
+			// The list is unknown if any of its elements is.
+			ast.Map(symbol("partly"), ast.List(symbol("index"), symbol("item"))),
+			ast.Filter(symbol("partly"), ast.Boolean(true)),
+			// Which elements are kept isn't known while a predicate is unknown.
+			ast.Filter(symbol("flags"), symbol("item")),
+			ast.Filter(symbol("words"), symbol("unknown")),
+		} {
+			v, ok := e.evaluateExpr(x)
+			require.True(t, ok)
+			e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+				assert.Failf(t, "unexpected apply", "applied a transform of an unknown: %v", x)
+				return x, nil
+			}))
+		}
+	})
+}
+
+func TestElementsDiags(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-elements
+runtime: yaml
+variables:
+  words: [a, b]
+  upper:
+    fn::filter: ["${words}", "${item}"]
+resources:
+  res:
+    type: test:resource:type
+    properties:
+      foo:
+        fn::map: ["${words}", "${item}"]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	actual := diagStrings(diags)
+	require.Len(t, actual, 2, actual)
+	assert.Equal(t, "<stdin>:13:9: test:resource:type is not assignable from {foo: List<string>}; "+
+		"Cannot assign '{foo: List<string>}' to 'test:resource:type':\n  foo: Cannot assign 'List<string>' to 'string'", actual[0])
+	assert.Equal(t, "<stdin>:7:30: boolean is not assignable from string; Cannot assign type 'string' to type 'boolean'", actual[1])
+
+	tmpl = template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		_, ok := e.evaluateExpr(ast.Filter(ast.List(ast.String("a")), ast.String("yes")))
+		assert.False(t, ok)
+		_, ok = e.evaluateExpr(ast.Map(ast.String("a"), ast.String("b")))
+		assert.False(t, ok)
+		require.Len(t, e.sdiags.diags, 2)
+		assert.Equal(t, "the predicate of fn::filter must be a boolean, not a string", e.sdiags.diags[0].Summary)
+		assert.Equal(t, "the first argument to fn::map must be a list, not a string", e.sdiags.diags[1].Summary)
+	})
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-reduce
+runtime: yaml
+variables:
+  subnets:
+    - name: a
+      id: subnet-1
+    - name: b
+      id: subnet-2
+  summary:
+    fn::reduce:
+      - ${subnets}
+      - names: ""
+        ids: []
+      - names: ${acc.names}${item.name}
+        ids:
+          fn::flatten: ["${acc.ids}", ["${item.id}"]]
+  order:
+    fn::reduce: [[x, y, z], "", "${acc}${index}${item}"]
+  empty:
+    fn::reduce: [[], none, "${acc}${item}"]
+`)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, "string", displayType(typing.TypeVariable("order")))
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, map[string]interface{}{
+			"names": "ab",
+			"ids":   []interface{}{"subnet-1", "subnet-2"},
+		}, e.variables["summary"])
+		// Elements are folded from left to right.
+		assert.Equal(t, "0x1y2z", e.variables["order"])
+		assert.Equal(t, "none", e.variables["empty"])
+	})
+}
+
+func TestReduceUnknownAndSecret(t *testing.T) {
+	t.Parallel()
+
+	symbol := func(name string) *ast.SymbolExpr {
+		return &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: name}}},
+		}
+	}
+	concat, diags := ast.Interpolate("${acc}${item}")
+	require.Empty(t, diags)
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		e.variables["partly"] = []interface{}{"a", pulumi.UnsafeUnknownOutput(nil)}
+		e.variables["letters"] = []interface{}{"a", "b"}
+		e.variables["unknown"] = pulumi.UnsafeUnknownOutput(nil)
+
+		for _, x := range []ast.Expr{
+			ast.Reduce(symbol("partly"), ast.String(""), concat),
+			// An unknown initial value makes every step of the fold unknown.
+			ast.Reduce(symbol("letters"), symbol("unknown"), concat),
+		} {
+			v, ok := e.evaluateExpr(x)
+			require.True(t, ok)
+			e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+				assert.Failf(t, "unexpected apply", "applied a transform of an unknown: %v", x)
+				return x, nil
+			}))
+		}
+
+		e.variables["secret"] = pulumi.ToSecret("s")
+		v, ok := e.evaluateExpr(ast.Reduce(symbol("letters"), symbol("secret"), concat))
+		require.True(t, ok)
+		out, ok := v.(pulumi.Output)
+		require.True(t, ok)
+		// The secret initial value makes the result secret.
+		assert.True(t, pulumi.IsSecret(out))
+		e.pulumiCtx.Export("secret", out.ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Equal(t, "sab", x)
+			return x, nil
+		}))
+	})
+}
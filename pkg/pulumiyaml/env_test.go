@@ -0,0 +1,78 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel, since it sets environment variables.
+func TestEnv(t *testing.T) {
+	t.Setenv("PULUMI_YAML_TEST_ENV", "from-env")
+
+	tmpl := yamlTemplate(t, `
+name: test-env
+runtime: yaml
+variables:
+  set:
+    fn::env: PULUMI_YAML_TEST_ENV
+  overridden:
+    fn::env: [PULUMI_YAML_TEST_ENV, default]
+  defaulted:
+    fn::env: [PULUMI_YAML_TEST_ENV_UNSET, default]
+`)
+	runner := newRunner(tmpl, newMockPackageMap())
+	typing, diags := TypeCheck(runner)
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+	assert.Equal(t, "string", displayType(typing.TypeVariable("set")))
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags := runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", runner.variables["set"])
+	assert.Equal(t, "from-env", runner.variables["overridden"])
+	assert.Equal(t, "default", runner.variables["defaulted"])
+}
+
+func TestEnvUnset(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-env
+runtime: yaml
+variables:
+  missing:
+    fn::env: PULUMI_YAML_TEST_ENV_UNSET
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `environment variable "PULUMI_YAML_TEST_ENV_UNSET" is not set`, diags[0].Summary)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `environment variable "PULUMI_YAML_TEST_ENV_UNSET" is not set, and fn::env has no default`,
+		diags[0].Summary)
+
+	_, diags, err = LoadYAMLBytes("<stdin>", []byte(`
+name: test-env
+runtime: yaml
+variables:
+  bad:
+    fn::env: [a, b, c]
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "the argument to fn::env must be a name, or a list of a name and a default", diags[0].Summary)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:  "comments and blank lines",
+			input: "# comment\n\n  # indented comment\nA=1\r\n",
+			expected: map[string]string{
+				"A": "1",
+			},
+		},
+		{
+			name:  "unquoted",
+			input: "A = some value  # trailing comment\nB=x#y\nexport C=3\nexported=4",
+			expected: map[string]string{
+				"A":        "some value",
+				"B":        "x#y",
+				"C":        "3",
+				"exported": "4",
+			},
+		},
+		{
+			name:  "quoted",
+			input: `A="line\nbreak \"quoted\" # not a comment" # comment` + "\nB='single \\n # quoted'\nC=\"\"",
+			expected: map[string]string{
+				"A": "line\nbreak \"quoted\" # not a comment",
+				"B": `single \n # quoted`,
+				"C": "",
+			},
+		},
+		{
+			name:  "missing equals",
+			input: "A=1\n\nJUST_A_KEY",
+			err:   `line 3: expected KEY=VALUE, got "JUST_A_KEY"`,
+		},
+		{
+			name:  "invalid key",
+			input: "1A=1",
+			err:   `line 1: invalid key "1A"`,
+		},
+		{
+			name:  "unterminated quote",
+			input: "A=1\nB=\"open",
+			err:   `line 2: missing closing "`,
+		},
+		{
+			name:  "text after quote",
+			input: "A='x' y",
+			err:   `line 1: unexpected "y" after closing quote`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := parseDotenv([]byte(tt.input))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, env)
+		})
+	}
+}
+
+func TestResolveProjectPath(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, ".env"), []byte("A=1"), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, ".env"), filepath.Join(root, "link.env")))
+
+	path, err := resolveProjectPath(root, "env file", "config/.env")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "config", ".env"), path)
+
+	path, err = resolveProjectPath(root, "env file", filepath.Join(root, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".env"), path)
+
+	_, err = resolveProjectPath(root, "env file", "../.env")
+	assert.EqualError(t, err, `env file "../.env" must be inside the project directory`)
+
+	_, err = resolveProjectPath(root, "env file", filepath.Join(outside, ".env"))
+	assert.Error(t, err)
+
+	_, err = resolveProjectPath(root, "env file", "link.env")
+	assert.EqualError(t, err, `env file "link.env" must be inside the project directory`)
+}
+
+func TestEnvFile(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-envfile
+runtime: yaml
+variables:
+  env:
+    fn::envFile: testdata/app.env
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, map[string]interface{}{
+			"APP_NAME": "demo",
+			"PORT":     "8080",
+			"GREETING": "hello\tworld",
+			"RAW":      `kept # as \n written`,
+			"EMPTY":    "",
+		}, e.variables["env"])
+	})
+}
+
+func TestEnvFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{
+			path:     "testdata/missing.env",
+			expected: `env file "testdata/missing.env" does not exist`,
+		},
+		{
+			path:     "../../README.md",
+			expected: `env file "../../README.md" must be inside the project directory`,
+		},
+		{
+			path:     "run.go",
+			expected: `invalid env file "run.go": line 1: expected KEY=VALUE`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := yamlTemplate(t, `
+name: test-envfile
+runtime: yaml
+variables:
+  env:
+    fn::envFile: `+tt.path+`
+`)
+			diags := testTemplateDiags(t, tmpl, nil)
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diagString(diags[0]), tt.expected)
+		})
+	}
+}
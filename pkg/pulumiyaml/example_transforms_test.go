@@ -0,0 +1,49 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml_test
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml"
+)
+
+// A program that embeds the YAML runtime registers a transform that tags every resource it is
+// applied to. Templates opt in with the transforms resource option:
+//
+//	resources:
+//	  bucket:
+//	    type: aws:s3:Bucket
+//	    options:
+//	      transforms: [defaultTags]
+func ExampleRegisterTransform() {
+	pulumiyaml.RegisterTransform("defaultTags",
+		func(_ context.Context, args *pulumi.ResourceTransformArgs) *pulumi.ResourceTransformResult {
+			tags := pulumi.StringMap{"managed-by": pulumi.String("pulumi-yaml")}
+			if existing, ok := args.Props["tags"].(pulumi.StringMap); ok {
+				for k, v := range existing {
+					tags[k] = v
+				}
+			}
+			args.Props["tags"] = tags
+			return &pulumi.ResourceTransformResult{Props: args.Props, Opts: args.Opts}
+		})
+
+	pulumi.Run(func(ctx *pulumi.Context) error {
+		template, diags, err := pulumiyaml.LoadDir(".")
+		if err != nil {
+			return err
+		}
+		if diags.HasErrors() {
+			return diags
+		}
+		loader, err := pulumiyaml.NewPackageLoader(nil)
+		if err != nil {
+			return err
+		}
+		defer loader.Close()
+		return pulumiyaml.RunTemplate(ctx, template, nil, nil, loader)
+	})
+}
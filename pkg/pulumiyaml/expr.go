@@ -25,9 +25,21 @@ func GetResourceDependencies(r *ast.ResourceDecl) []*ast.StringExpr {
 	if r.Options.Providers != nil {
 		getExpressionDependencies(&deps, r.Options.Providers)
 	}
+	if r.Options.DeletedWith != nil {
+		getExpressionDependencies(&deps, r.Options.DeletedWith)
+	}
+	if r.Options.Import != nil {
+		getExpressionDependencies(&deps, r.Options.Import)
+	}
 	if r.Get.Id != nil {
 		getExpressionDependencies(&deps, r.Get.Id)
 	}
+	if r.Condition != nil {
+		getExpressionDependencies(&deps, r.Condition)
+	}
+	if r.ForEach != nil {
+		deps = loopDependencies(r, deps)
+	}
 	return deps
 }
 
@@ -57,6 +69,9 @@ func getExpressionDependencies(deps *[]*ast.StringExpr, x ast.Expr) {
 				sx := ast.StringSyntax(syntax.StringSyntax(x.Syntax().Syntax(), name))
 				*deps = append(*deps, sx)
 			}
+			if p.Default != nil {
+				getExpressionDependencies(deps, p.Default)
+			}
 		}
 	case *ast.SymbolExpr:
 		name := x.Property.RootName()
@@ -73,6 +88,11 @@ func getExpressionDependencies(deps *[]*ast.StringExpr, x ast.Expr) {
 		if x.CallOpts.DependsOn != nil {
 			getExpressionDependencies(deps, x.CallOpts.DependsOn)
 		}
+		if x.CallOpts.After != nil {
+			getExpressionDependencies(deps, x.CallOpts.After)
+		}
+	case ast.ElementExpr:
+		elementDependencies(deps, x)
 	case ast.BuiltinExpr:
 		getExpressionDependencies(deps, x.Args())
 	}
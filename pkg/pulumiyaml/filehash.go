@@ -0,0 +1,28 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"crypto/md5"  //nolint:gosec // Used to identify file contents, not for security.
+	"crypto/sha1" //nolint:gosec // Used to identify file contents, not for security.
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+const defaultFileHashAlgorithm = "sha256"
+
+// newFileHash returns a hash for the fn::fileHash algorithm named algorithm.
+func newFileHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil //nolint:gosec
+	case "md5":
+		return md5.New(), nil //nolint:gosec
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q; fn::fileHash supports 'sha256', 'sha1' and 'md5'", algorithm)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileHash(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/app.env")
+	require.NoError(t, err)
+	sha := sha256.Sum256(data)
+	sum := md5.Sum(data) //nolint:gosec
+
+	tmpl := yamlTemplate(t, `
+name: test-filehash
+runtime: yaml
+variables:
+  default:
+    fn::fileHash: testdata/app.env
+  md5:
+    fn::fileHash:
+      path: testdata/app.env
+      algorithm: md5
+  name: app-${default}
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, hex.EncodeToString(sha[:]), e.variables["default"])
+		assert.Equal(t, hex.EncodeToString(sum[:]), e.variables["md5"])
+		assert.Equal(t, "app-"+hex.EncodeToString(sha[:]), e.variables["name"])
+	})
+}
+
+func TestFileHashErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{
+			name:     "missing file",
+			args:     "testdata/missing.txt",
+			expected: `file "testdata/missing.txt" does not exist`,
+		},
+		{
+			name:     "outside project",
+			args:     "../../README.md",
+			expected: `file "../../README.md" must be inside the project directory`,
+		},
+		{
+			name:     "unknown algorithm",
+			args:     "{path: testdata/app.env, algorithm: crc32}",
+			expected: `unsupported algorithm "crc32"; fn::fileHash supports 'sha256', 'sha1' and 'md5'`,
+		},
+		{
+			name:     "missing path",
+			args:     "{algorithm: sha1}",
+			expected: `missing path of the file to hash ('path')`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-filehash
+runtime: yaml
+variables:
+  hash:
+    fn::fileHash: `+tt.args+`
+`))
+			require.NoError(t, err)
+			if !diags.HasErrors() {
+				diags = testTemplateDiags(t, tmpl, nil)
+			}
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diagString(diags[0]), tt.expected)
+		})
+	}
+}
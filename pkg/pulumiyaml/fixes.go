@@ -0,0 +1,130 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax/encoding"
+)
+
+// A replacement is an edit that replaces the text of an expression.
+type replacement struct {
+	expr ast.Expr
+	text string
+}
+
+// suggestReplacement suggests replacing the text of expr with text to resolve diag, and returns
+// diag. No fix is suggested unless expr is a plain YAML scalar on a single line and text can be
+// written as one: the ranges of quoted and block scalars don't cover all of their characters, so
+// an edit to them could leave the template malformed.
+func suggestReplacement(diag *syntax.Diagnostic, expr ast.Expr, text string) *syntax.Diagnostic {
+	if diag == nil || expr == nil || expr.Syntax() == nil {
+		return diag
+	}
+	syn, ok := expr.Syntax().Syntax().(encoding.YAMLSyntax)
+	if !ok || syn.Node == nil || syn.Kind != yaml.ScalarNode || syn.Style != 0 {
+		return diag
+	}
+	rng := syn.Range()
+	if rng == nil || rng.Start.Line != rng.End.Line || !isPlainScalar(text) {
+		return diag
+	}
+	diag.Fix = &syntax.SuggestedEdit{Range: *rng, Replacement: text}
+	return diag
+}
+
+// isPlainScalar reports whether s can be written as a plain YAML scalar that reads back as s.
+func isPlainScalar(s string) bool {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &node); err != nil || len(node.Content) != 1 {
+		return false
+	}
+	n := node.Content[0]
+	return n.Kind == yaml.ScalarNode && n.Style == 0 && n.Tag == "!!str" && n.Value == s
+}
+
+// ApplyFixes applies the edits suggested by diags to source, the text of the file filename, and
+// returns the edited text. Edits to other files are ignored, and the rest of the text, including its
+// comments, is kept as it is.
+//
+// Edits that overlap an edit earlier in the file can't be applied with it. They are left out, and
+// a warning is returned for each of them.
+func ApplyFixes(filename string, source []byte, diags syntax.Diagnostics) ([]byte, syntax.Diagnostics) {
+	type edit struct {
+		start, end int
+		diag       *syntax.Diagnostic
+	}
+
+	var edits []edit
+	var conflicts syntax.Diagnostics
+	for _, d := range diags {
+		if d == nil || d.Fix == nil || d.Fix.Range.Filename != filename {
+			continue
+		}
+		rng := d.Fix.Range
+		start, ok := byteOffset(source, rng.Start)
+		end, endOk := byteOffset(source, rng.End)
+		if !ok || !endOk || end < start {
+			conflicts.Extend(syntax.Warning(&rng,
+				fmt.Sprintf("the fix for %q is outside of the file, and was not applied", d.Summary), ""))
+			continue
+		}
+		edits = append(edits, edit{start, end, d})
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var b []byte
+	last, prev := 0, (*syntax.SuggestedEdit)(nil)
+	for _, e := range edits {
+		// The same edit may be suggested by more than one diagnostic.
+		if prev != nil && *e.diag.Fix == *prev {
+			continue
+		}
+		if e.start < last {
+			rng := e.diag.Fix.Range
+			conflicts.Extend(syntax.Warning(&rng,
+				fmt.Sprintf("the fix for %q overlaps another fix, and was not applied", e.diag.Summary), ""))
+			continue
+		}
+		b = append(b, source[last:e.start]...)
+		b = append(b, e.diag.Fix.Replacement...)
+		last, prev = e.end, e.diag.Fix
+	}
+	b = append(b, source[last:]...)
+	return b, conflicts
+}
+
+// byteOffset returns the offset in source of pos. Positions from the YAML decoder only carry lines
+// and columns, and columns count characters rather than bytes.
+func byteOffset(source []byte, pos hcl.Pos) (int, bool) {
+	if pos.Line < 1 || pos.Column < 1 {
+		return 0, false
+	}
+	offset := 0
+	for line := 1; line < pos.Line; line++ {
+		nl := bytes.IndexByte(source[offset:], '\n')
+		if nl == -1 {
+			return 0, false
+		}
+		offset += nl + 1
+	}
+	for col := 1; col < pos.Column; col++ {
+		if offset >= len(source) || source[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(source[offset:])
+		offset += size
+	}
+	return offset, true
+}
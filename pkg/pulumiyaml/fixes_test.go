@@ -0,0 +1,230 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func TestSuggestedFixes(t *testing.T) {
+	t.Parallel()
+
+	strictLoader := MockPackageLoader{packages: map[string]Package{
+		"strict": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				return ResourceTypeToken(strings.Replace(typeName, ":", ":index:", 1)), nil
+			},
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+		},
+	}}
+	typeCheck := func(loader PackageLoader, mode IndexExpansion) func(t *testing.T, source string) syntax.Diagnostics {
+		return func(t *testing.T, source string) syntax.Diagnostics {
+			runner := newRunner(yamlTemplate(t, source), loader)
+			runner.indexExpansion = mode
+			_, diags := TypeCheck(runner)
+			return diags
+		}
+	}
+	referencedPackages := func(t *testing.T, source string) syntax.Diagnostics {
+		_, diags := GetReferencedPackages(yamlTemplate(t, source))
+		return diags
+	}
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, source string) syntax.Diagnostics
+		// The source of the template is preceded by its name and runtime.
+		source string
+		// The source once the fix is applied, or empty if there is no fix.
+		fixed string
+	}{
+		{
+			name:   "misspelled property",
+			check:  typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      fooo: hello\n",
+			fixed:  "resources:\n  res:\n    type: test:resource:type\n    properties:\n      foo: hello\n",
+		},
+		{
+			name:   "quoted property",
+			check:  typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      \"fooo\": hello\n",
+		},
+		{
+			name:  "property that is already set",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  res:\n    type: test:resource:type\n    properties:\n" +
+				"      foo: hello\n      fooo: hello\n",
+		},
+		{
+			name:  "misspelled ignoreChanges",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      foo: hello\n" +
+				"    options:\n      ignoreChanges: [fooo]\n",
+			fixed: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      foo: hello\n" +
+				"    options:\n      ignoreChanges: [foo]\n",
+		},
+		{
+			name:  "misspelled additionalSecretOutputs",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      foo: hello\n" +
+				"    options:\n      additionalSecretOutputs: [barr]\n",
+			fixed: "resources:\n  res:\n    type: test:resource:type\n    properties:\n      foo: hello\n" +
+				"    options:\n      additionalSecretOutputs: [bar]\n",
+		},
+		{
+			name:  "misspelled invoke argument",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "variables:\n  v:\n    fn::invoke:\n      function: test:fn\n" +
+				"      arguments:\n        yesArgg: hello\n",
+			fixed: "variables:\n  v:\n    fn::invoke:\n      function: test:fn\n" +
+				"      arguments:\n        yesArg: hello\n",
+		},
+		{
+			name:   "missing index",
+			check:  typeCheck(strictLoader, WarnIndexExpansion),
+			source: "resources:\n  res:\n    type: strict:Thing\n",
+			fixed:  "resources:\n  res:\n    type: strict:index:Thing\n",
+		},
+		{
+			name:  "helm chart",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  nginx:\n    type: kubernetes:helm.sh/v3:Chart\n" +
+				"    properties:\n      chart: nginx-ingress\n",
+			fixed: "resources:\n  nginx:\n    type: kubernetes:helm.sh/v3:Release\n" +
+				"    properties:\n      chart: nginx-ingress\n",
+		},
+		{
+			name:  "helm chart with unmapped properties",
+			check: typeCheck(newMockPackageMap(), AllowIndexExpansion),
+			source: "resources:\n  nginx:\n    type: kubernetes:helm.sh/v3:Chart\n" +
+				"    properties:\n      chart: nginx-ingress\n      skipAwait: true\n",
+		},
+		{
+			name:   "old docker version",
+			check:  referencedPackages,
+			source: "resources:\n  image:\n    type: docker:Image\n    options:\n      version: 3.6.1\n",
+			fixed:  "resources:\n  image:\n    type: docker:Image\n    options:\n      version: 4.0.0\n",
+		},
+		{
+			name:   "unpinned docker version",
+			check:  referencedPackages,
+			source: "resources:\n  image:\n    type: docker:Image\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			const header = "name: test-fixes\nruntime: yaml\n"
+			diags := tt.check(t, header+tt.source)
+			require.NotEmpty(t, diags)
+			var fixes []*syntax.SuggestedEdit
+			for _, d := range diags {
+				if d.Fix != nil {
+					fixes = append(fixes, d.Fix)
+				}
+			}
+			if tt.fixed == "" {
+				assert.Empty(t, fixes)
+				return
+			}
+			require.Len(t, fixes, 1, diagStrings(diags))
+			fixed, conflicts := ApplyFixes("<stdin>", []byte(header+tt.source), diags)
+			assert.Empty(t, conflicts)
+			assert.Equal(t, header+tt.fixed, string(fixed))
+		})
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	t.Parallel()
+
+	loader := MockPackageLoader{packages: map[string]Package{
+		"strict": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				return ResourceTypeToken(strings.Replace(typeName, ":", ":index:", 1)), nil
+			},
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName, schema.Property{Name: "bucketName", Type: schema.StringType})
+			},
+		},
+	}}
+	source := `name: test-fixes
+runtime: yaml
+resources:
+  # The bücket.
+  bucket:
+    type: strict:Bucket # Not canonical.
+    properties:
+      bucketNme: logs # Misspelled.
+`
+	runner := newRunner(yamlTemplate(t, source), loader)
+	runner.indexExpansion = WarnIndexExpansion
+	_, diags := TypeCheck(runner)
+
+	fixed, conflicts := ApplyFixes("<stdin>", []byte(source), diags)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, `name: test-fixes
+runtime: yaml
+resources:
+  # The bücket.
+  bucket:
+    type: strict:index:Bucket # Not canonical.
+    properties:
+      bucketName: logs # Misspelled.
+`, string(fixed))
+
+	// Fixed templates have nothing left to fix.
+	runner = newRunner(yamlTemplate(t, string(fixed)), loader)
+	runner.indexExpansion = WarnIndexExpansion
+	_, diags = TypeCheck(runner)
+	requireNoErrors(t, nil, diags)
+	assert.Empty(t, diags)
+}
+
+func TestApplyFixesConflicts(t *testing.T) {
+	t.Parallel()
+
+	fix := func(line, start, end int, replacement string) *syntax.Diagnostic {
+		rng := hcl.Range{
+			Filename: "Pulumi.yaml",
+			Start:    hcl.Pos{Line: line, Column: start},
+			End:      hcl.Pos{Line: line, Column: end},
+		}
+		d := syntax.Warning(&rng, replacement, "")
+		d.Fix = &syntax.SuggestedEdit{Range: rng, Replacement: replacement}
+		return d
+	}
+	source := "a: héllo\nb: world\n"
+
+	other := fix(1, 1, 2, "x")
+	other.Fix.Range.Filename = "other.yaml"
+	fixed, conflicts := ApplyFixes("Pulumi.yaml", []byte(source), syntax.Diagnostics{
+		fix(2, 4, 9, "there"),
+		fix(1, 4, 9, "hi"),
+		// The same fix, suggested twice.
+		fix(1, 4, 9, "hi"),
+		// Overlaps the fix before it.
+		fix(1, 5, 6, "a"),
+		// Outside of the file.
+		fix(3, 4, 5, "x"),
+		other,
+		syntax.Warning(nil, "no fix", ""),
+	})
+	assert.Equal(t, "a: hi\nb: there\n", string(fixed))
+	assert.Equal(t, []string{
+		`Pulumi.yaml:3:4: the fix for "x" is outside of the file, and was not applied`,
+		`Pulumi.yaml:1:5: the fix for "a" overlaps another fix, and was not applied`,
+	}, diagStrings(conflicts))
+}
@@ -0,0 +1,73 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseFormat splits the format string of fn::format into its verbs and the text around them, so
+// text has one more element than verbs. Only %s, %d, %v and %q are supported, without flags,
+// widths or precisions, and %% is a literal %.
+func parseFormat(format string) ([]string, []byte, error) {
+	var text []string
+	var verbs []byte
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return nil, nil, fmt.Errorf("the format %q ends with a lone %%; use %%%% for a literal %%", format)
+		}
+		switch verb := format[i]; verb {
+		case '%':
+			b.WriteByte('%')
+		case 's', 'd', 'v', 'q':
+			text = append(text, b.String())
+			verbs = append(verbs, verb)
+			b.Reset()
+		default:
+			return nil, nil, fmt.Errorf("the format %q uses %%%c, but fn::format only supports %%s, %%d, %%v, %%q and %%%%",
+				format, verb)
+		}
+	}
+	return append(text, b.String()), verbs, nil
+}
+
+// formatArgsError reports that fn::format was given the wrong number of arguments for its format.
+func formatArgsError(verbs, args int) error {
+	return fmt.Errorf("the format of fn::format has verbs for %d arguments, but it is given %d", verbs, args)
+}
+
+// formatValue formats v, an argument of fn::format, with verb. %s and %v format any value as an
+// interpolation would.
+func formatValue(verb byte, v interface{}) (string, error) {
+	switch verb {
+	case 'd':
+		switch v := v.(type) {
+		case int:
+			return strconv.Itoa(v), nil
+		case float64:
+			if v == math.Trunc(v) && !math.IsInf(v, 0) {
+				return strconv.FormatFloat(v, 'f', 0, 64), nil
+			}
+		}
+		if f, ok := v.(float64); ok {
+			return "", fmt.Errorf("%%d needs an integer, not %v", f)
+		}
+		return "", fmt.Errorf("%%d needs an integer, not %s", typeString(v))
+	case 'q':
+		if s, ok := v.(string); ok {
+			return strconv.Quote(s), nil
+		}
+		return "", fmt.Errorf("%%q needs a string, not %s", typeString(v))
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-format
+runtime: yaml
+variables:
+  name: web
+  formatted:
+    fn::format: ["%s-%d: %q is 100%% %v", "${name}", 3, "a \"b\"", [1, x]]
+  plain:
+    fn::format: [no verbs]
+`)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+	assert.Equal(t, "string", displayType(typing.TypeVariable("formatted")))
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, `web-3: "a \"b\"" is 100% [1 x]`, e.variables["formatted"])
+		assert.Equal(t, "no verbs", e.variables["plain"])
+
+		// An unknown argument makes the whole result unknown.
+		e.variables["unknown"] = pulumi.UnsafeUnknownOutput(nil)
+		v, ok := e.evaluateExpr(ast.Format(ast.String("%s-%s"), ast.String("a"), &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "unknown"}}},
+		}))
+		require.True(t, ok)
+		e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Failf(t, "unexpected apply", "formatted an unknown: %v", x)
+			return x, nil
+		}))
+	})
+}
+
+func TestFormatErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-format
+runtime: yaml
+variables:
+  tooFew:
+    fn::format: ["%s and %s", a]
+  badVerb:
+    fn::format: ["%x", 1]
+  lone:
+    fn::format: ["50%"]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	assert.ElementsMatch(t, []string{
+		"the format of fn::format has verbs for 2 arguments, but it is given 1",
+		`the format "%x" uses %x, but fn::format only supports %s, %d, %v, %q and %%`,
+		`the format "50%" ends with a lone %; use %% for a literal %`,
+	}, summaries)
+
+	tmpl = yamlTemplate(t, `
+name: test-format
+runtime: yaml
+variables:
+  notInteger:
+    fn::format: ["%d", 1.5]
+  notString:
+    fn::format: ["%q", [a]]
+`)
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	summaries = nil
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	assert.ElementsMatch(t, []string{
+		"%d needs an integer, not 1.5",
+		"%q needs a string, not a list",
+	}, summaries)
+
+	_, diags, err = LoadYAMLBytes("<stdin>", []byte(`
+name: test-format
+runtime: yaml
+variables:
+  bad:
+    fn::format: "%s"
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "the argument to fn::format must be a list of a format string and its arguments", diags[0].Summary)
+}
@@ -0,0 +1,181 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax/encoding"
+)
+
+// templateKeyOrder is the canonical order of the sections of a template. The project's name,
+// runtime and description come first, followed by other keys of the project file in their
+// original order, and then these sections.
+var templateKeyOrder = []string{
+	"packages", "includes", "config", "configuration", "providers", "defaultTags", "components",
+	"variables", "resources", "outputs", "outputTypes", "outputGroups",
+}
+
+// FormatTemplate returns the canonical form of the template source, which is read from the file
+// filename. In the canonical form:
+//
+//   - the sections of the template are in a fixed order, starting with its name, runtime and
+//     description;
+//   - the fields of each resource are in the order type, name, defaultProvider, properties,
+//     options, get, forEach and condition;
+//   - builtin functions, such as fn::toJSON, are spelled with their canonical casing;
+//   - mappings and sequences are indented by two spaces.
+//
+// Comments are kept with the keys and values that they are attached to, or at the start or end of
+// the file if they are separated from the template by a blank line or come before its first key. The order of the keys
+// within properties, variables and outputs is kept. Formatting a template that is already in its
+// canonical form leaves it unchanged.
+//
+// The source must be a valid template. If it isn't, the problems are returned instead.
+func FormatTemplate(filename string, source []byte) ([]byte, syntax.Diagnostics) {
+	syn, diags := encoding.DecodeYAML(filename, yaml.NewDecoder(bytes.NewReader(source)), TagDecoder)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if _, tdiags := ast.ParseTemplate(source, syn); tdiags.HasErrors() {
+		return nil, append(diags, tdiags...)
+	}
+
+	formatted := formatTemplateNode(syn)
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	diags.Extend(encoding.EncodeYAML(enc, formatted)...)
+	if err := enc.Close(); err != nil {
+		diags.Extend(syntax.Error(nil, err.Error(), ""))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return b.Bytes(), nil
+}
+
+func formatTemplateNode(n *syntax.ObjectNode) *syntax.ObjectNode {
+	rank := func(key string) int {
+		switch strings.ToLower(key) {
+		case "name":
+			return 0
+		case "runtime":
+			return 1
+		case "description":
+			return 2
+		}
+		for i, k := range templateKeyOrder {
+			if strings.EqualFold(k, key) {
+				return 4 + i
+			}
+		}
+		return 3
+	}
+	entries := sortEntries(n, rank)
+	syn := n.Syntax()
+	if n.Len() > 0 && entries[0].Key != n.Index(0).Key {
+		// A comment at the start of the file is attached to the first key when no blank line
+		// follows it. It stays at the start of the file when that key is moved.
+		first := n.Index(0).Key
+		if keyComments := syntax.CommentsOf(first); keyComments.Head != "" {
+			comments := syntax.CommentsOf(n)
+			if comments.Head != "" {
+				comments.Head += "\n\n"
+			}
+			comments.Head += keyComments.Head
+			syn = comments
+
+			keyComments.Head = ""
+			for i := range entries {
+				if entries[i].Key == first {
+					entries[i].Key = syntax.StringSyntax(keyComments, first.Value())
+				}
+			}
+		}
+	}
+	for i, entry := range entries {
+		if strings.EqualFold(entry.Key.Value(), "resources") {
+			if resources, ok := entry.Value.(*syntax.ObjectNode); ok {
+				entry.Value = formatResourcesNode(resources)
+				entries[i] = entry
+				continue
+			}
+		}
+		entries[i].Value = formatNode(entry.Value)
+	}
+	return syntax.ObjectSyntax(syn, entries...)
+}
+
+func formatResourcesNode(n *syntax.ObjectNode) *syntax.ObjectNode {
+	fields := (&ast.ResourceDecl{}).Fields()
+	rank := func(key string) int {
+		for i, f := range fields {
+			if strings.EqualFold(f, key) {
+				return i
+			}
+		}
+		return len(fields)
+	}
+
+	entries := make([]syntax.ObjectPropertyDef, n.Len())
+	for i := range entries {
+		entry := n.Index(i)
+		if resource, ok := entry.Value.(*syntax.ObjectNode); ok {
+			fields := sortEntries(resource, rank)
+			for j := range fields {
+				fields[j].Value = formatNode(fields[j].Value)
+			}
+			entry.Value = syntax.ObjectSyntax(resource.Syntax(), fields...)
+		}
+		entries[i] = entry
+	}
+	return syntax.ObjectSyntax(n.Syntax(), entries...)
+}
+
+// formatNode returns the canonical form of an expression.
+func formatNode(n syntax.Node) syntax.Node {
+	switch n := n.(type) {
+	case *syntax.ListNode:
+		elements := make([]syntax.Node, n.Len())
+		for i := range elements {
+			elements[i] = formatNode(n.Index(i))
+		}
+		return syntax.ListSyntax(n.Syntax(), elements...)
+	case *syntax.ObjectNode:
+		entries := make([]syntax.ObjectPropertyDef, n.Len())
+		for i := range entries {
+			entry := n.Index(i)
+			entry.Value = formatNode(entry.Value)
+			entries[i] = entry
+		}
+		if len(entries) == 1 {
+			key := entries[0].Key
+			if name, ok := ast.CanonicalBuiltinName(key.Value()); ok && name != key.Value() {
+				entries[0].Key = syntax.StringSyntax(key.Syntax(), name)
+			}
+		}
+		return syntax.ObjectSyntax(n.Syntax(), entries...)
+	default:
+		return n
+	}
+}
+
+// sortEntries returns the entries of n stably sorted by the rank of their keys.
+func sortEntries(n *syntax.ObjectNode, rank func(key string) int) []syntax.ObjectPropertyDef {
+	entries := make([]syntax.ObjectPropertyDef, n.Len())
+	for i := range entries {
+		entries[i] = n.Index(i)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return rank(entries[i].Key.Value()) < rank(entries[j].Key.Value())
+	})
+	return entries
+}
@@ -0,0 +1,203 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		source string
+		// The canonical form of the source, or empty if it is already canonical.
+		formatted string
+	}{
+		{
+			name: "sections and resource fields",
+			source: `# The project.
+
+resources:
+  # The bucket.
+  bucket:
+    options:
+        protect: true
+    properties:
+      # Its name.
+      bucketName: ${prefix}-bucket # Prefixed.
+      tags: {env: dev, team: core}
+    type: test:resource:type
+variables:
+    prefix: app
+runtime: yaml
+name: test-format
+outputs:
+  name: ${bucket.id}
+`,
+			formatted: `# The project.
+
+name: test-format
+runtime: yaml
+variables:
+  prefix: app
+resources:
+  # The bucket.
+  bucket:
+    type: test:resource:type
+    properties:
+      # Its name.
+      bucketName: ${prefix}-bucket # Prefixed.
+      tags: {env: dev, team: core}
+    options:
+      protect: true
+outputs:
+  name: ${bucket.id}
+`,
+		},
+		{
+			name: "file header comment",
+			source: `# The project.
+outputs:
+  # The greeting.
+  greeting: hello
+name: test-format
+runtime: yaml
+`,
+			formatted: `# The project.
+
+name: test-format
+runtime: yaml
+outputs:
+  # The greeting.
+  greeting: hello
+`,
+		},
+		{
+			name: "builtin casing",
+			source: `name: test-format
+runtime: yaml
+variables:
+  joined:
+    Fn::Join: ["-", [a, b]]
+  encoded:
+    fn::tojson:
+      fn::SELECT: [0, [x]]
+  asset:
+    fn::stringasset: hello
+  notBuiltin:
+    fn::Unknown: value
+`,
+			formatted: `name: test-format
+runtime: yaml
+variables:
+  joined:
+    fn::join: ["-", [a, b]]
+  encoded:
+    fn::toJSON:
+      fn::select: [0, [x]]
+  asset:
+    fn::stringAsset: hello
+  notBuiltin:
+    fn::Unknown: value
+`,
+		},
+		{
+			name: "unknown project keys",
+			source: `description: A test.
+main: src/
+name: test-format
+config:
+  region:
+    type: string
+runtime: yaml
+`,
+			formatted: `name: test-format
+runtime: yaml
+description: A test.
+main: src/
+config:
+  region:
+    type: string
+`,
+		},
+		{
+			name: "canonical",
+			source: `name: test-format
+runtime: yaml
+variables:
+  script: |
+    echo hello
+    echo world
+  empty: ""
+  number: '42'
+resources:
+  res:
+    type: test:resource:type
+    properties:
+      foo: ${script}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected := tt.formatted
+			if expected == "" {
+				expected = tt.source
+			}
+			formatted, diags := FormatTemplate("Pulumi.yaml", []byte(tt.source))
+			requireNoErrors(t, nil, diags)
+			assert.Equal(t, expected, string(formatted))
+
+			again, diags := FormatTemplate("Pulumi.yaml", formatted)
+			requireNoErrors(t, nil, diags)
+			assert.Equal(t, string(formatted), string(again), "formatting is not idempotent")
+		})
+	}
+}
+
+func TestFormatTemplateExamples(t *testing.T) {
+	t.Parallel()
+
+	paths, err := filepath.Glob(filepath.Join("..", "..", "examples", "*", "Pulumi.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(filepath.Dir(path)), func(t *testing.T) {
+			t.Parallel()
+
+			source, err := os.ReadFile(path)
+			require.NoError(t, err)
+			formatted, diags := FormatTemplate(path, source)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			// The formatted template is still valid, and formatting it again changes nothing.
+			_, diags, err = LoadYAMLBytes(path, formatted)
+			require.NoError(t, err)
+			require.False(t, diags.HasErrors(), diags.Error())
+			again, diags := FormatTemplate(path, formatted)
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, string(formatted), string(again))
+		})
+	}
+}
+
+func TestFormatTemplateInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, diags := FormatTemplate("Pulumi.yaml", []byte("name: test-format\nresources: [a, b]\n"))
+	assert.True(t, diags.HasErrors())
+
+	_, diags = FormatTemplate("Pulumi.yaml", []byte("- not\n- an object\n"))
+	assert.True(t, diags.HasErrors())
+}
@@ -0,0 +1,93 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func TestGetResource(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-get-resource
+runtime: yaml
+variables:
+  existing:
+    fn::getResource: ${original.urn}
+resources:
+  original:
+    type: test:resource:type
+    properties:
+      foo: from-state
+  byGetAtt:
+    type: test:resource:type
+    properties:
+      foo:
+        fn::getAtt:
+          - ${existing}
+          - foo
+  byAccess:
+    type: test:resource:type
+    properties:
+      foo: ${existing.foo}
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{
+		"<stdin>:6:5: the outputs of a resource from fn::getResource are not type checked; " +
+			"The resource is read from the stack's state when the template runs, so its outputs may have any type",
+	}, diagStrings(diags))
+	assert.True(t, syntax.IsHint(diags[0].HCL()))
+
+	var lock sync.Mutex
+	inputs := map[string]resource.PropertyMap{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			inputs[args.Name] = args.Inputs
+			return args.Name + "-id", args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+	requireNoErrors(t, tmpl, diags)
+
+	// The outputs of the resource are read from the engine's state.
+	assert.Equal(t, resource.NewStringProperty("from-state"), inputs["byGetAtt"]["foo"])
+	assert.Equal(t, resource.NewStringProperty("from-state"), inputs["byAccess"]["foo"])
+}
+
+func TestGetResourceInvalidURN(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-get-resource
+runtime: yaml
+variables:
+  existing:
+    fn::getResource: not-a-urn
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	var errors []string
+	for _, d := range diags {
+		if d.Severity == hcl.DiagError {
+			errors = append(errors, diagString(d))
+		}
+	}
+	assert.Equal(t, []string{`<stdin>:6:22: invalid URN "not-a-urn"`}, errors)
+}
@@ -0,0 +1,109 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// globProjectFiles returns the files within the project directory root that match pattern, keyed
+// by their slash-separated path relative to the part of pattern before its first wildcard. The
+// pattern is relative to root and separated by slashes. A segment of '**' matches any number of
+// directories, and other segments are matched with path.Match.
+func globProjectFiles(root, pattern string) (map[string]string, error) {
+	if path.IsAbs(pattern) || filepath.IsAbs(pattern) || filepath.VolumeName(pattern) != "" {
+		return nil, fmt.Errorf("the pattern %q must be relative to the project directory", pattern)
+	}
+	cleaned := path.Clean(pattern)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return nil, fmt.Errorf("the pattern %q must not match files outside of the project directory", pattern)
+	}
+
+	segments := strings.Split(cleaned, "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	// Keys are relative to the directory before the first wildcard. A pattern without wildcards
+	// names a single file, which is keyed by its name.
+	base := 0
+	for base < len(segments)-1 && !hasGlobMeta(segments[base]) {
+		base++
+	}
+	baseDir := filepath.Join(append([]string{root}, segments[:base]...)...)
+	segments = segments[base:]
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	files := map[string]string{}
+	err = filepath.WalkDir(baseDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(baseDir, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !matchGlobSegments(segments, strings.Split(key, "/")) {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			real, err := filepath.EvalSymlinks(file)
+			if err != nil {
+				return err
+			}
+			if !isWithin(realRoot, real) {
+				return fmt.Errorf("%q links to a file outside of the project directory", key)
+			}
+			if info, err := os.Stat(real); err != nil || info.IsDir() {
+				return err
+			}
+		}
+		files[key] = file
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to match %q: %w", pattern, err)
+	}
+	return files, nil
+}
+
+func hasGlobMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
+
+// matchGlobSegments reports whether the path segments name match the pattern segments.
+func matchGlobSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlobSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchGlobSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,125 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobProjectFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, file := range []string{"src/a.js", "src/lib/b.js", "src/lib/deep/c.js", "src/lib/d.ts", "top.js"} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(file), 0o600))
+	}
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.js"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.js"), filepath.Join(root, "link.js")))
+
+	tests := []struct {
+		pattern  string
+		expected []string
+		err      string
+	}{
+		{
+			pattern:  "src/**/*.js",
+			expected: []string{"a.js", "lib/b.js", "lib/deep/c.js"},
+		},
+		{
+			pattern:  "src/lib/*",
+			expected: []string{"b.js", "d.ts"},
+		},
+		{
+			pattern:  "./src/lib/d.ts",
+			expected: []string{"d.ts"},
+		},
+		{
+			pattern:  "src/*.py",
+			expected: []string{},
+		},
+		{
+			pattern: "*.js",
+			err:     `unable to match "*.js": "link.js" links to a file outside of the project directory`,
+		},
+		{
+			pattern: "../*.js",
+			err:     `the pattern "../*.js" must not match files outside of the project directory`,
+		},
+		{
+			pattern: "/src/*.js",
+			err:     `the pattern "/src/*.js" must be relative to the project directory`,
+		},
+		{
+			pattern: "src/[.js",
+			err:     `invalid pattern "src/[.js": syntax error in pattern`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			files, err := globProjectFiles(root, tt.pattern)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			keys := []string{}
+			for key := range files {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+}
+
+func TestGlobAssets(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-globassets
+runtime: yaml
+variables:
+  archive:
+    fn::assetArchive:
+      index.js:
+        fn::stringAsset: exports.handler = () => {}
+      config:
+        fn::globAssets: testdata/*.env
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		archive, ok := e.variables["archive"].(pulumi.Archive)
+		require.True(t, ok)
+		config, ok := archive.Assets()["config"].(pulumi.Archive)
+		require.True(t, ok)
+		require.Contains(t, config.Assets(), "app.env")
+		assert.Equal(t, filepath.Join(e.cwd, "testdata", "app.env"), config.Assets()["app.env"].(pulumi.Asset).Path())
+	})
+}
+
+func TestGlobAssetsNoMatches(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-globassets
+runtime: yaml
+variables:
+  archive:
+    fn::globAssets: testdata/*.missing
+`)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diagString(diags[0]), `the pattern "testdata/*.missing" does not match any files`)
+}
@@ -0,0 +1,274 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// GraphNodeKind is the kind of value a GraphNode represents.
+type GraphNodeKind string
+
+const (
+	GraphNodeConfig   GraphNodeKind = "config"
+	GraphNodeVariable GraphNodeKind = "variable"
+	GraphNodeResource GraphNodeKind = "resource"
+	GraphNodeOutput   GraphNodeKind = "output"
+	// GraphNodeInvoke is a call to fn::invoke within another node.
+	GraphNodeInvoke GraphNodeKind = "invoke"
+)
+
+// GraphEdgeKind describes why one node of a DependencyGraph depends on another.
+type GraphEdgeKind string
+
+const (
+	// GraphEdgeReference is a reference such as ${bucket.arn} to another node's value.
+	GraphEdgeReference GraphEdgeKind = "reference"
+	// GraphEdgeDependsOn is an explicit dependency from the dependsOn or after options.
+	GraphEdgeDependsOn GraphEdgeKind = "dependsOn"
+	GraphEdgeParent    GraphEdgeKind = "parent"
+	// GraphEdgeProvider is an explicit provider, from the provider or providers options.
+	GraphEdgeProvider GraphEdgeKind = "provider"
+	// GraphEdgeDefaultProvider is the implicit dependency of a resource on the resource that is
+	// the default provider for its package.
+	GraphEdgeDefaultProvider GraphEdgeKind = "defaultProvider"
+	GraphEdgeDeletedWith     GraphEdgeKind = "deletedWith"
+	// GraphEdgeInvoke links a node to an fn::invoke that it calls.
+	GraphEdgeInvoke GraphEdgeKind = "invoke"
+)
+
+// GraphNode is a value declared by a template, or an fn::invoke within one.
+type GraphNode struct {
+	// ID uniquely identifies the node within its graph, e.g. "resource:bucket".
+	ID   string
+	Kind GraphNodeKind
+	// Name is the name the node is declared with. It is empty for invokes.
+	Name string
+	// Token is the type of a resource or the function of an invoke, if it is known statically.
+	Token string
+	Range *hcl.Range
+}
+
+// GraphEdge records that the node From depends on the node To.
+type GraphEdge struct {
+	From string
+	To   string
+	Kind GraphEdgeKind
+	// Range is the location of the first expression that gives rise to the edge.
+	Range *hcl.Range
+}
+
+// DependencyGraph is the graph of dependencies between the values declared by a template.
+type DependencyGraph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GetDependencyGraph computes the dependency graph of a template without evaluating it. Config
+// is limited to the template's own configuration section.
+//
+// The graph is returned even if the template has errors, such as circular dependencies or
+// references to values that don't exist, so that they can be displayed.
+func GetDependencyGraph(t *ast.TemplateDecl) (*DependencyGraph, syntax.Diagnostics) {
+	b := graphBuilder{
+		t:     t,
+		graph: &DependencyGraph{},
+		ids:   map[string]string{},
+		edges: map[GraphEdge]bool{},
+	}
+
+	// Declare every node before adding edges, so that references can be resolved in any order.
+	configEntries := [][]ast.ConfigMapEntry{t.Configuration.Entries, t.Config.Entries}
+	for _, entries := range configEntries {
+		for _, kvp := range entries {
+			b.declare(GraphNodeConfig, kvp.Key, "")
+		}
+	}
+	for _, kvp := range t.Variables.Entries {
+		b.declare(GraphNodeVariable, kvp.Key, "")
+	}
+	defaultProviders := map[string]string{}
+	for _, kvp := range t.Resources.Entries {
+		token := ""
+		if kvp.Value != nil && kvp.Value.Type != nil {
+			token = kvp.Value.Type.Value
+			if resourceIsDefaultProvider(resourceNode(kvp)) {
+				if parts := strings.Split(token, ":"); len(parts) > 2 {
+					defaultProviders[parts[2]] = kvp.Key.Value
+				}
+			}
+		}
+		b.declare(GraphNodeResource, kvp.Key, token)
+	}
+	outputs := (&Runner{t: t}).outputs()
+	for _, kvp := range outputs {
+		b.declare(GraphNodeOutput, kvp.Key, "")
+	}
+
+	for _, entries := range configEntries {
+		for _, kvp := range entries {
+			if kvp.Value != nil {
+				b.addDependencies(b.ids[kvp.Key.Value], GraphEdgeReference, kvp.Value.Default)
+			}
+		}
+	}
+	for _, kvp := range t.Variables.Entries {
+		b.addDependencies(b.ids[kvp.Key.Value], GraphEdgeReference, kvp.Value)
+	}
+	for _, kvp := range t.Resources.Entries {
+		r := kvp.Value
+		if r == nil {
+			continue
+		}
+		from := b.ids[kvp.Key.Value]
+		b.addDependencies(from, GraphEdgeReference, r.ForEach)
+		b.loop = r.ForEach != nil
+		for _, prop := range r.Properties.Entries {
+			b.addDependencies(from, GraphEdgeReference, prop.Value)
+		}
+		b.addDependencies(from, GraphEdgeReference, r.Get.Id)
+		b.addDependencies(from, GraphEdgeReference, r.Options.Import)
+		b.addDependencies(from, GraphEdgeDependsOn, r.Options.DependsOn)
+		b.addDependencies(from, GraphEdgeParent, r.Options.Parent)
+		b.addDependencies(from, GraphEdgeProvider, r.Options.Provider)
+		b.addDependencies(from, GraphEdgeProvider, r.Options.Providers)
+		b.addDependencies(from, GraphEdgeDeletedWith, r.Options.DeletedWith)
+		b.loop = false
+
+		if resourceNodeHasNoExplicitProvider(resourceNode(kvp)) && !resourceIsDefaultProvider(resourceNode(kvp)) &&
+			r.Type != nil {
+			pkg, _, _ := strings.Cut(r.Type.Value, ":")
+			if provider, ok := defaultProviders[pkg]; ok {
+				b.addEdge(from, b.ids[provider], GraphEdgeDefaultProvider, nil)
+			}
+		}
+	}
+	for _, kvp := range outputs {
+		b.addDependencies(b.ids["output:"+kvp.Key.Value], GraphEdgeReference, kvp.Value)
+	}
+
+	// The sort reports duplicate names and circular dependencies.
+	_, sdiags := topologicallySortedResources(t, nil)
+	b.diags.Extend(sdiags...)
+	return b.graph, b.diags
+}
+
+type graphBuilder struct {
+	t     *ast.TemplateDecl
+	graph *DependencyGraph
+	// ids maps the names that expressions may refer to to the IDs of their nodes. Outputs, which
+	// can't be referred to, are keyed by their ID.
+	ids     map[string]string
+	edges   map[GraphEdge]bool
+	invokes int
+	diags   syntax.Diagnostics
+	// loop is set while adding the dependencies of a resource declared with forEach, whose
+	// properties and options may refer to the loop variables.
+	loop bool
+}
+
+func (b *graphBuilder) declare(kind GraphNodeKind, key *ast.StringExpr, token string) {
+	id := string(kind) + ":" + key.Value
+	if _, ok := b.ids[key.Value]; ok && kind != GraphNodeOutput {
+		// A duplicate name, which the sort reports.
+		return
+	}
+	if kind == GraphNodeOutput {
+		b.ids[id] = id
+	} else {
+		b.ids[key.Value] = id
+	}
+	b.graph.Nodes = append(b.graph.Nodes, GraphNode{
+		ID:    id,
+		Kind:  kind,
+		Name:  key.Value,
+		Token: token,
+		Range: exprRange(key),
+	})
+}
+
+func (b *graphBuilder) addEdge(from, to string, kind GraphEdgeKind, rng *hcl.Range) {
+	key := GraphEdge{From: from, To: to, Kind: kind}
+	if b.edges[key] {
+		return
+	}
+	b.edges[key] = true
+	key.Range = rng
+	b.graph.Edges = append(b.graph.Edges, key)
+}
+
+// addReference adds an edge from the node from to the node declared as name.
+func (b *graphBuilder) addReference(from string, kind GraphEdgeKind, name string, x ast.Expr) {
+	if name == PulumiVarName || b.loop && isLoopVariable(name) {
+		return
+	}
+	to, ok := b.ids[name]
+	if !ok && b.t.Name != nil {
+		to, ok = b.ids[stripConfigNamespace(b.t.Name.Value, name)]
+	}
+	if !ok {
+		b.diags.Extend(ast.ExprError(x, fmt.Sprintf("resource, variable, or config value %q not found", name), ""))
+		return
+	}
+	b.addEdge(from, to, kind, exprRange(x))
+}
+
+// addDependencies adds an edge from the node from for each reference in x. Invokes within x
+// become nodes of their own, with their dependencies.
+func (b *graphBuilder) addDependencies(from string, kind GraphEdgeKind, x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.ListExpr:
+		for _, e := range x.Elements {
+			b.addDependencies(from, kind, e)
+		}
+	case *ast.ObjectExpr:
+		for _, kvp := range x.Entries {
+			b.addDependencies(from, kind, kvp.Key)
+			b.addDependencies(from, kind, kvp.Value)
+		}
+	case *ast.InterpolateExpr:
+		for _, p := range x.Parts {
+			if p.Value != nil && len(p.Value.Accessors) > 0 {
+				b.addReference(from, kind, p.Value.Accessors[0].(*ast.PropertyName).Name, x)
+			}
+			if p.Default != nil {
+				b.addDependencies(from, kind, p.Default)
+			}
+		}
+	case *ast.SymbolExpr:
+		b.addReference(from, kind, x.Property.RootName(), x)
+	case *ast.InvokeExpr:
+		b.invokes++
+		id := fmt.Sprintf("invoke:%d", b.invokes)
+		token := ""
+		if x.Token != nil {
+			token = x.Token.Value
+		}
+		b.graph.Nodes = append(b.graph.Nodes, GraphNode{
+			ID:    id,
+			Kind:  GraphNodeInvoke,
+			Token: token,
+			Range: exprRange(x),
+		})
+		b.addEdge(from, id, GraphEdgeInvoke, exprRange(x))
+
+		if x.TokenRef != nil {
+			b.addDependencies(id, GraphEdgeReference, x.TokenRef)
+		}
+		if x.CallArgs != nil {
+			b.addDependencies(id, GraphEdgeReference, x.CallArgs)
+		}
+		b.addDependencies(id, GraphEdgeDependsOn, x.CallOpts.DependsOn)
+		b.addDependencies(id, GraphEdgeDependsOn, x.CallOpts.After)
+		b.addDependencies(id, GraphEdgeParent, x.CallOpts.Parent)
+		b.addDependencies(id, GraphEdgeProvider, x.CallOpts.Provider)
+	case ast.BuiltinExpr:
+		b.addDependencies(from, kind, x.Args())
+	}
+}
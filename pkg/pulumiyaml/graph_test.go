@@ -0,0 +1,193 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func graphEdges(g *DependencyGraph) []string {
+	edges := make([]string, len(g.Edges))
+	for i, e := range g.Edges {
+		edges[i] = e.From + " -" + string(e.Kind) + "-> " + e.To
+	}
+	return edges
+}
+
+func TestDependencyGraph(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-graph
+runtime: yaml
+configuration:
+  prefix:
+    type: string
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:lookup
+      arguments:
+        name: ${prefix}-lookup
+      options:
+        dependsOn:
+          - ${provider}
+resources:
+  provider:
+    type: pulumi:providers:test
+    defaultProvider: true
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${lookup.id}
+      bar: ${prefix}
+  child:
+    type: test:resource:type
+    properties:
+      foo: ${pulumi.stack}
+    options:
+      parent: ${bucket}
+      dependsOn:
+        - ${bucket}
+outputs:
+  bucket: ${bucket.foo}
+`)
+	g, diags := GetDependencyGraph(tmpl)
+	requireNoErrors(t, tmpl, diags)
+
+	ids := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[i] = n.ID
+		require.NotNil(t, n.Range, n.ID)
+	}
+	assert.Equal(t, []string{
+		"config:prefix",
+		"variable:lookup",
+		"resource:provider",
+		"resource:bucket",
+		"resource:child",
+		"output:bucket",
+		"invoke:1",
+	}, ids)
+	assert.Equal(t, GraphNode{
+		ID:    "invoke:1",
+		Kind:  GraphNodeInvoke,
+		Token: "test:invoke:lookup",
+		Range: g.Nodes[6].Range,
+	}, g.Nodes[6])
+	assert.Equal(t, 9, g.Nodes[6].Range.Start.Line)
+	assert.Equal(t, "pulumi:providers:test", g.Nodes[2].Token)
+
+	assert.Equal(t, []string{
+		"variable:lookup -invoke-> invoke:1",
+		"invoke:1 -reference-> config:prefix",
+		"invoke:1 -dependsOn-> resource:provider",
+		"resource:bucket -reference-> variable:lookup",
+		"resource:bucket -reference-> config:prefix",
+		"resource:bucket -defaultProvider-> resource:provider",
+		"resource:child -dependsOn-> resource:bucket",
+		"resource:child -parent-> resource:bucket",
+		"resource:child -defaultProvider-> resource:provider",
+		"output:bucket -reference-> resource:bucket",
+	}, graphEdges(g))
+	assert.Equal(t, 23, g.Edges[3].Range.Start.Line)
+}
+
+func TestDependencyGraphErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-graph
+runtime: yaml
+variables:
+  a: ${b}
+  b: ${a}
+  c: ${missing}
+`)
+	g, diags := GetDependencyGraph(tmpl)
+	assert.ElementsMatch(t, []string{
+		`<stdin>:7:6: resource, variable, or config value "missing" not found`,
+		`<stdin>:6:6: circular dependency of variable 'a' transitively on itself`,
+	}, diagStrings(diags))
+	assert.Equal(t, []string{
+		"variable:a -reference-> variable:b",
+		"variable:b -reference-> variable:a",
+	}, graphEdges(g))
+}
+
+func TestDependencyGraphForEach(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-graph
+runtime: yaml
+variables:
+  names:
+    - a
+    - b
+  tags:
+    env: prod
+resources:
+  bucket:
+    type: test:resource:type
+    forEach: ${names}
+    properties:
+      foo: ${item}-${index}
+  tagged:
+    type: test:resource:type
+    forEach: ${tags}
+    properties:
+      foo: ${key}=${value}
+    options:
+      dependsOn:
+        - ${bucket}
+outputs:
+  item: ${item}
+`)
+	g, diags := GetDependencyGraph(tmpl)
+	// The loop variables are only bound within the resources declared with forEach.
+	assert.Equal(t, []string{
+		`<stdin>:25:9: resource, variable, or config value "item" not found`,
+	}, diagStrings(diags))
+	assert.Equal(t, []string{
+		"resource:bucket -reference-> variable:names",
+		"resource:tagged -reference-> variable:tags",
+		"resource:tagged -dependsOn-> resource:bucket",
+	}, graphEdges(g))
+}
+
+func TestDependencyGraphConfig(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-graph
+runtime: yaml
+configuration:
+  prefix:
+    type: string
+config:
+  region:
+    type: string
+    default: ${prefix}-west
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${region}
+`)
+	g, diags := GetDependencyGraph(tmpl)
+	requireNoErrors(t, tmpl, diags)
+
+	ids := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[i] = n.ID
+	}
+	assert.Equal(t, []string{"config:prefix", "config:region", "resource:bucket"}, ids)
+	assert.Equal(t, []string{
+		"config:region -reference-> config:prefix",
+		"resource:bucket -reference-> config:region",
+	}, graphEdges(g))
+}
@@ -0,0 +1,88 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax/encoding"
+)
+
+const helmReleaseType = "kubernetes:helm.sh/v3:Release"
+
+// The properties of a Helm Chart that mean the same for a Helm Release.
+var helmReleaseProperties = []string{"chart", "version", "values", "namespace"}
+
+// HelmChartToRelease converts the declaration of a Helm Chart resource named key to the
+// declaration of the equivalent Helm Release, as YAML. The chart, version, values and namespace
+// properties, the resource's name and its options are carried over. The other properties have no
+// equivalent, and are returned as unmapped.
+func HelmChartToRelease(key string, r *ast.ResourceDecl) (string, []string, error) {
+	var mapped []syntax.ObjectPropertyDef
+	var unmapped []string
+	for _, entry := range r.Properties.Entries {
+		if !slices.Contains(helmReleaseProperties, entry.Key.Value) {
+			unmapped = append(unmapped, entry.Key.Value)
+			continue
+		}
+		value := entry.Value.Syntax()
+		if value == nil {
+			return "", nil, fmt.Errorf("property %q has no syntax", entry.Key.Value)
+		}
+		mapped = append(mapped, syntax.ObjectProperty(syntax.String(entry.Key.Value), value))
+	}
+	if r.Get.Id != nil || len(r.Get.State.Entries) > 0 {
+		unmapped = append(unmapped, "get")
+	}
+
+	decl := []syntax.ObjectPropertyDef{
+		syntax.ObjectProperty(syntax.String("type"), syntax.String(helmReleaseType)),
+	}
+	if r.Name != nil && r.Name.Syntax() != nil {
+		decl = append(decl, syntax.ObjectProperty(syntax.String("name"), r.Name.Syntax()))
+	}
+	if len(mapped) > 0 {
+		decl = append(decl, syntax.ObjectProperty(syntax.String("properties"), syntax.Object(mapped...)))
+	}
+	if options := r.Options.Syntax(); options != nil {
+		decl = append(decl, syntax.ObjectProperty(syntax.String("options"), options))
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	release := syntax.Object(syntax.ObjectProperty(syntax.String(key), syntax.Object(decl...)))
+	if diags := encoding.EncodeYAML(enc, release); diags.HasErrors() {
+		return "", nil, diags
+	}
+	if err := enc.Close(); err != nil {
+		return "", nil, err
+	}
+	return b.String(), unmapped, nil
+}
+
+// helmChartMigration describes how to replace the Helm Chart resource r, named key, with a Helm
+// Release. It is empty if r isn't a Helm Chart. The migration is complete if every field of the
+// Chart has an equivalent.
+func helmChartMigration(key string, r *ast.ResourceDecl) (string, bool) {
+	if _, ok := helmResourceNames[r.Type.GetValue()]; !ok {
+		return "", false
+	}
+	release, unmapped, err := HelmChartToRelease(key, r)
+	if err != nil {
+		return "", false
+	}
+	detail := "The equivalent Helm Release is:\n\n" + release
+	if len(unmapped) > 0 {
+		detail += fmt.Sprintf("\nThese fields of the Chart have no equivalent and were left out: %s",
+			strings.Join(unmapped, ", "))
+	}
+	return detail, len(unmapped) == 0
+}
@@ -0,0 +1,60 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmChartToRelease(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-helm
+runtime: yaml
+variables:
+  namespace: ingress
+resources:
+  nginx:
+    type: kubernetes:helm.sh/v3:Chart
+    name: ingress
+    properties:
+      chart: nginx-ingress
+      version: 4.10.0
+      namespace: ${namespace}
+      fetchOpts:
+        repo: https://kubernetes.github.io/ingress-nginx
+      values:
+        controller:
+          replicaCount: 2
+      skipAwait: true
+    options:
+      protect: true
+`)
+	r := tmpl.Resources.Entries[0]
+	release, unmapped, err := HelmChartToRelease(r.Key.Value, r.Value)
+	require.NoError(t, err)
+	assert.Equal(t, `nginx:
+  type: kubernetes:helm.sh/v3:Release
+  name: ingress
+  properties:
+    chart: nginx-ingress
+    version: 4.10.0
+    namespace: ${namespace}
+    values:
+      controller:
+        replicaCount: 2
+  options:
+    protect: true
+`, release)
+	assert.Equal(t, []string{"fetchOpts", "skipAwait"}, unmapped)
+
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags[0].Detail, "The equivalent Helm Release is:\n\n"+release)
+	assert.Contains(t, diags[0].Detail,
+		"These fields of the Chart have no equivalent and were left out: fetchOpts, skipAwait")
+}
@@ -0,0 +1,235 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// HoverInfo describes the property of a resource or of the arguments of an invoke whose key is at
+// a position in a template, such as an editor shows on hover.
+type HoverInfo struct {
+	// Name is the name of the property.
+	Name string
+	// Type is the type of the property, as it is displayed in diagnostics.
+	Type string
+	// Description is the documentation of the property from its schema. It may be empty.
+	Description string
+	// Token is the type token of the resource or function whose inputs the property belongs to,
+	// possibly through nested objects.
+	Token string
+	// Range is the range of the property's key.
+	Range *hcl.Range
+}
+
+// Hover returns information about the property whose key is at pos in the file filename, which
+// is any file of the template if it is empty. Properties of resources, of the state of resources
+// that are read with get, and of the arguments of invokes are described by the schemas of their
+// packages, including the properties of objects nested within them. It returns nil if pos isn't
+// on the key of such a property, or if the schema of the resource or function can't be loaded.
+//
+// Unlike the other analyses, Hover doesn't require the template to be valid, since it is usually
+// being edited.
+func Hover(tmpl *ast.TemplateDecl, loader PackageLoader, filename string, pos hcl.Pos) *HoverInfo {
+	h := hover{filename: filename, pos: pos}
+	r := newRunner(tmpl, loader)
+	if err := r.setPackageDesciptors(); err != nil {
+		return nil
+	}
+
+	for _, kvp := range tmpl.Resources.Entries {
+		res := kvp.Value
+		if res == nil || res.Type == nil || !h.within(res) {
+			continue
+		}
+		for _, entry := range res.Properties.Entries {
+			if h.within(entry.Value) {
+				if info := h.invokes(r, entry.Value); info != nil {
+					return info
+				}
+			}
+		}
+
+		version, err := ParseVersion(res.Options.Version)
+		if err != nil {
+			return nil
+		}
+		pkg, typ, err := ResolveResource(context.TODO(), r.pkgLoader, r.packageDescriptors, res.Type.Value, version)
+		if err != nil {
+			return nil
+		}
+		hint := pkg.ResourceTypeHint(typ)
+		if hint == nil || hint.Resource == nil {
+			return nil
+		}
+		if info := h.object(typ.String(), propertyMapEntries(res.Properties), hint.Resource.InputProperties); info != nil {
+			return info
+		}
+		return h.object(typ.String(), propertyMapEntries(res.Get.State), hint.Resource.Properties)
+	}
+	for _, kvp := range tmpl.Variables.Entries {
+		if h.within(kvp.Value) {
+			return h.invokes(r, kvp.Value)
+		}
+	}
+	for _, kvp := range tmpl.Outputs.Entries {
+		if h.within(kvp.Value) {
+			return h.invokes(r, kvp.Value)
+		}
+	}
+	return nil
+}
+
+type hover struct {
+	filename string
+	pos      hcl.Pos
+}
+
+// within reports whether the range of the syntax of n contains the position.
+func (h hover) within(n interface{ Syntax() syntax.Node }) bool {
+	if n == nil || n.Syntax() == nil || n.Syntax().Syntax() == nil {
+		return false
+	}
+	rng := n.Syntax().Syntax().Range()
+	if rng == nil || h.filename != "" && rng.Filename != h.filename {
+		return false
+	}
+	before := func(a, b hcl.Pos) bool {
+		return a.Line < b.Line || a.Line == b.Line && a.Column <= b.Column
+	}
+	return before(rng.Start, h.pos) && before(h.pos, rng.End)
+}
+
+// invokes returns information about the property at the position within the arguments of an
+// invoke within x.
+func (h hover) invokes(r *Runner, x ast.Expr) *HoverInfo {
+	switch x := x.(type) {
+	case *ast.ListExpr:
+		for _, elem := range x.Elements {
+			if h.within(elem) {
+				return h.invokes(r, elem)
+			}
+		}
+	case *ast.ObjectExpr:
+		for _, entry := range x.Entries {
+			if h.within(entry.Value) {
+				return h.invokes(r, entry.Value)
+			}
+		}
+	case *ast.InvokeExpr:
+		if x.CallArgs == nil || !h.within(x.CallArgs) {
+			return nil
+		}
+		// The arguments may themselves invoke functions.
+		for _, entry := range x.CallArgs.Entries {
+			if h.within(entry.Value) {
+				if info := h.invokes(r, entry.Value); info != nil {
+					return info
+				}
+			}
+		}
+		if x.Token == nil {
+			return nil
+		}
+		version, err := ParseVersion(x.CallOpts.Version)
+		if err != nil {
+			return nil
+		}
+		pkg, fn, err := ResolveFunction(context.TODO(), r.pkgLoader, r.packageDescriptors, x.Token.Value, version)
+		if err != nil {
+			return nil
+		}
+		hint := pkg.FunctionTypeHint(fn)
+		if hint == nil || hint.Inputs == nil {
+			return nil
+		}
+		return h.object(fn.String(), x.CallArgs.Entries, hint.Inputs.Properties)
+	case ast.BuiltinExpr:
+		if args := x.Args(); h.within(args) {
+			return h.invokes(r, args)
+		}
+	}
+	return nil
+}
+
+// object returns information about the property at the position among entries, whose properties
+// are props, or among the properties of the values of entries.
+func (h hover) object(token string, entries []ast.ObjectProperty, props []*schema.Property) *HoverInfo {
+	for _, entry := range entries {
+		key, ok := entry.Key.(*ast.StringExpr)
+		if !ok {
+			continue
+		}
+		var prop *schema.Property
+		for _, p := range props {
+			if p.Name == key.Value {
+				prop = p
+				break
+			}
+		}
+		if prop == nil {
+			continue
+		}
+		if h.within(key) {
+			return &HoverInfo{
+				Name:        prop.Name,
+				Type:        displayType(prop.Type),
+				Description: prop.Comment,
+				Token:       token,
+				Range:       key.Syntax().Syntax().Range(),
+			}
+		}
+		if h.within(entry.Value) {
+			return h.value(token, entry.Value, prop.Type)
+		}
+	}
+	return nil
+}
+
+// value returns information about the property at the position within x, which is of type typ.
+func (h hover) value(token string, x ast.Expr, typ schema.Type) *HoverInfo {
+	switch typ := codegen.UnwrapType(typ).(type) {
+	case *schema.ObjectType:
+		if obj, ok := x.(*ast.ObjectExpr); ok {
+			return h.object(token, obj.Entries, typ.Properties)
+		}
+	case *schema.ArrayType:
+		if list, ok := x.(*ast.ListExpr); ok {
+			for _, elem := range list.Elements {
+				if h.within(elem) {
+					return h.value(token, elem, typ.ElementType)
+				}
+			}
+		}
+	case *schema.MapType:
+		if obj, ok := x.(*ast.ObjectExpr); ok {
+			for _, entry := range obj.Entries {
+				if h.within(entry.Value) {
+					return h.value(token, entry.Value, typ.ElementType)
+				}
+			}
+		}
+	case *schema.UnionType:
+		for _, elem := range typ.ElementTypes {
+			if info := h.value(token, x, elem); info != nil {
+				return info
+			}
+		}
+	}
+	return nil
+}
+
+func propertyMapEntries(m ast.PropertyMapDecl) []ast.ObjectProperty {
+	entries := make([]ast.ObjectProperty, len(m.Entries))
+	for i, entry := range m.Entries {
+		entries[i] = entry.Object()
+	}
+	return entries
+}
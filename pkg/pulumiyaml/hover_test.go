@@ -0,0 +1,136 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHover(t *testing.T) {
+	t.Parallel()
+
+	rule := &schema.ObjectType{
+		Token: "web:index:Rule",
+		Properties: []*schema.Property{
+			{Name: "port", Type: schema.IntType, Comment: "The port that the rule allows."},
+		},
+	}
+	listener := &schema.ObjectType{
+		Token: "web:index:Listener",
+		Properties: []*schema.Property{
+			{Name: "protocol", Type: schema.StringType, Comment: "The protocol of the listener."},
+			{Name: "rules", Type: &schema.ArrayType{ElementType: rule}},
+		},
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"web": MockPackage{
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return &schema.ResourceType{
+					Token: typeName,
+					Resource: &schema.Resource{
+						Token: typeName,
+						InputProperties: []*schema.Property{
+							{Name: "name", Type: schema.StringType, Comment: "The name of the server."},
+							{Name: "listener", Type: &schema.OptionalType{ElementType: listener}},
+							{Name: "tags", Type: &schema.MapType{ElementType: listener}},
+						},
+						Properties: []*schema.Property{
+							{Name: "address", Type: schema.StringType, Comment: "The address of the server."},
+						},
+					},
+				}
+			},
+			functionTypeHint: func(typeName string) *schema.Function {
+				return &schema.Function{
+					Token: typeName,
+					Inputs: &schema.ObjectType{Properties: []*schema.Property{
+						{Name: "region", Type: schema.StringType, Comment: "The region to look up."},
+					}},
+				}
+			},
+		},
+	}}
+
+	tmpl := yamlTemplate(t, `
+name: test-hover
+runtime: yaml
+resources:
+  server:
+    type: web:index:Server
+    properties:
+      name: ${missing}
+      listener:
+        protocol: http
+        rules:
+          - port: 80
+      tags:
+        primary:
+          protocol: https
+      unknown: value
+  existing:
+    type: web:index:Server
+    get:
+      id: server-1
+      state:
+        address: 10.0.0.1
+variables:
+  zone:
+    fn::invoke:
+      function: web:index:getZone
+      arguments:
+        region: us-west-2
+`)
+	hover := func(line, column int) *HoverInfo {
+		return Hover(tmpl, loader, "", hcl.Pos{Line: line, Column: column})
+	}
+
+	info := hover(8, 8)
+	require.NotNil(t, info)
+	assert.Equal(t, &HoverInfo{
+		Name:        "name",
+		Type:        "string",
+		Description: "The name of the server.",
+		Token:       "web:index:Server",
+		Range:       info.Range,
+	}, info)
+	assert.Equal(t, hcl.Pos{Line: 8, Column: 7}, hcl.Pos{Line: info.Range.Start.Line, Column: info.Range.Start.Column})
+
+	tests := []struct {
+		line, column int
+		name         string
+		typ          string
+		description  string
+	}{
+		{line: 9, column: 9, name: "listener", typ: "web:index:Listener"},
+		{line: 10, column: 10, name: "protocol", typ: "string", description: "The protocol of the listener."},
+		{line: 12, column: 13, name: "port", typ: "integer", description: "The port that the rule allows."},
+		{line: 15, column: 11, name: "protocol", typ: "string", description: "The protocol of the listener."},
+		{line: 22, column: 9, name: "address", typ: "string", description: "The address of the server."},
+		{line: 28, column: 10, name: "region", typ: "string", description: "The region to look up."},
+	}
+	for _, tt := range tests {
+		info := hover(tt.line, tt.column)
+		if assert.NotNil(t, info, "%d:%d", tt.line, tt.column) {
+			assert.Equal(t, tt.name, info.Name)
+			assert.Equal(t, tt.typ, info.Type)
+			assert.Equal(t, tt.description, info.Description)
+		}
+	}
+
+	// Positions that aren't on the key of a property in the schema.
+	for _, pos := range [][2]int{
+		{8, 15},  // A value.
+		{16, 9},  // A property that isn't in the schema.
+		{5, 5},   // The name of a resource.
+		{27, 15}, // The function of an invoke.
+		{100, 1}, // Outside of the template.
+	} {
+		assert.Nil(t, hover(pos[0], pos[1]), "%d:%d", pos[0], pos[1])
+	}
+	assert.Nil(t, Hover(tmpl, loader, "other.yaml", hcl.Pos{Line: 8, Column: 8}))
+}
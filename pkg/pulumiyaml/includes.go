@@ -0,0 +1,113 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// loadIncludes merges the files included by t, which was loaded from the file filename in
+// directory, into t. Included files may include files of their own. Each file is merged once,
+// however many files include it.
+func loadIncludes(t *ast.TemplateDecl, directory, filename string) syntax.Diagnostics {
+	if len(t.Includes.GetElements()) == 0 {
+		return nil
+	}
+	l := includeLoader{
+		root:     t,
+		dir:      directory,
+		included: map[string]bool{},
+	}
+	path := filepath.Join(directory, filename)
+	l.included[absPath(path)] = true
+	l.include(t, path, []string{filename})
+	return l.diags
+}
+
+type includeLoader struct {
+	root *ast.TemplateDecl
+	// dir is the directory of the root template. Included files are named relative to it.
+	dir      string
+	included map[string]bool
+	diags    syntax.Diagnostics
+}
+
+// include merges the files included by t, which was loaded from path, into the root template.
+// chain lists the names of the files that led to t being included, starting with the root.
+func (l *includeLoader) include(t *ast.TemplateDecl, path string, chain []string) {
+	for _, inc := range t.Includes.GetElements() {
+		if filepath.IsAbs(inc.Value) {
+			l.diags.Extend(ast.ExprError(inc, fmt.Sprintf("included file %q must be a relative path", inc.Value), ""))
+			continue
+		}
+		target := filepath.Join(filepath.Dir(path), inc.Value)
+		name := target
+		if rel, err := filepath.Rel(l.dir, target); err == nil {
+			name = rel
+		}
+
+		if slices.Contains(chain, name) {
+			l.diags.Extend(ast.ExprError(inc, fmt.Sprintf("circular include of %q", name),
+				"The chain of includes is "+strings.Join(append(chain, name), " -> ")))
+			continue
+		}
+		abs := absPath(target)
+		if l.included[abs] {
+			continue
+		}
+		l.included[abs] = true
+
+		source, err := os.ReadFile(target)
+		if err != nil {
+			l.diags.Extend(ast.ExprError(inc, fmt.Sprintf("unable to read included file %q: %v", inc.Value, err), ""))
+			continue
+		}
+		it, diags, err := LoadYAMLBytes(name, source)
+		l.diags.Extend(diags...)
+		if err != nil {
+			l.diags.Extend(ast.ExprError(inc, fmt.Sprintf("unable to load included file %q: %v", inc.Value, err), ""))
+			continue
+		}
+		if it == nil {
+			continue
+		}
+		l.checkSections(it)
+
+		l.include(it, target, append(chain, name))
+		l.diags.Extend(l.root.Merge(it)...)
+	}
+}
+
+// checkSections warns about the top-level sections of an included file that are not merged.
+func (l *includeLoader) checkSections(t *ast.TemplateDecl) {
+	obj, ok := t.Syntax().(*syntax.ObjectNode)
+	if !ok {
+		return
+	}
+outer:
+	for i := 0; i < obj.Len(); i++ {
+		key := obj.Index(i).Key
+		for _, section := range ast.IncludableSections {
+			if strings.EqualFold(key.Value(), section) {
+				continue outer
+			}
+		}
+		l.diags.Extend(syntax.Warning(key.Syntax().Range(),
+			fmt.Sprintf("%q is ignored in included files", key.Value()),
+			"Only "+strings.Join(ast.IncludableSections, ", ")+" are merged into the including template"))
+	}
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,154 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(text), 0o600))
+	}
+	return dir
+}
+
+func TestIncludes(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"Pulumi.yaml": `name: test-includes
+runtime: yaml
+includes:
+  - network/vpc.yaml
+  - storage.yaml
+resources:
+  main:
+    type: test:resource:type
+`,
+		"network/vpc.yaml": `includes:
+  - ../shared.yaml
+config:
+  cidr:
+    type: string
+variables:
+  subnet: ${cidr}
+resources:
+  vpc:
+    type: test:resource:type
+outputs:
+  vpcId: ${vpc.id}
+`,
+		"storage.yaml": `includes:
+  - shared.yaml
+resources:
+  bucket:
+    type: test:resource:type
+`,
+		"shared.yaml": `variables:
+  region: us-west-2
+`,
+	})
+
+	tmpl, diags, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, diags)
+
+	var names []string
+	for _, r := range tmpl.Resources.Entries {
+		names = append(names, r.Key.Value)
+	}
+	assert.Equal(t, []string{"main", "vpc", "bucket"}, names)
+	var vars []string
+	for _, v := range tmpl.Variables.Entries {
+		vars = append(vars, v.Key.Value)
+	}
+	assert.Equal(t, []string{"region", "subnet"}, vars)
+	require.Len(t, tmpl.Config.Entries, 1)
+	assert.Equal(t, "cidr", tmpl.Config.Entries[0].Key.Value)
+	require.Len(t, tmpl.Outputs.Entries, 1)
+	assert.Equal(t, filepath.Join("network", "vpc.yaml"),
+		tmpl.Outputs.Entries[0].Key.Syntax().Syntax().Range().Filename)
+}
+
+func TestIncludesDiags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name: "duplicate",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nincludes: [a.yaml]\nresources:\n  res:\n    type: test:resource:type\n",
+				"a.yaml":      "resources:\n  res:\n    type: test:resource:type\n",
+			},
+			expected: []string{`a.yaml:2:3: found duplicate key "res" in resources ` +
+				`[Pulumi.yaml:5:3: The other "res" is declared here]`},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nincludes: [a.yaml]\n",
+				"a.yaml":      "includes: [sub/b.yaml]\n",
+				"sub/b.yaml":  "includes: [../Pulumi.yaml]\n",
+			},
+			expected: []string{`sub/b.yaml:1:12: circular include of "Pulumi.yaml"; ` +
+				`The chain of includes is Pulumi.yaml -> a.yaml -> sub/b.yaml -> Pulumi.yaml`},
+		},
+		{
+			name: "missing",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nincludes: [missing.yaml]\n",
+			},
+			expected: []string{`Pulumi.yaml:3:12: unable to read included file "missing.yaml"`},
+		},
+		{
+			name: "ignored section",
+			files: map[string]string{
+				"Pulumi.yaml": "name: test\nruntime: yaml\nincludes: [a.yaml]\n",
+				"a.yaml":      "name: other\nvariables:\n  a: 1\n",
+			},
+			expected: []string{`a.yaml:1:1: "name" is ignored in included files; ` +
+				`Only configuration, config, variables, resources, outputs, includes are merged into the including template`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeFiles(t, tt.files)
+			_, diags, _ := LoadDir(dir)
+			actual := diagStrings(diags)
+			require.Len(t, actual, len(tt.expected), actual)
+			for i, expected := range tt.expected {
+				assert.Contains(t, actual[i], expected)
+			}
+		})
+	}
+}
+
+func TestIncludesLoadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"app/Main.yaml": "name: test\nruntime: yaml\nincludes: [more.yaml]\n",
+		"app/more.yaml": "variables:\n  a: 1\n",
+	})
+	tmpl, diags, err := LoadFile(filepath.Join(dir, "app", "Main.yaml"))
+	require.NoError(t, err)
+	require.Empty(t, diags)
+	require.Len(t, tmpl.Variables.Entries, 1)
+	assert.Equal(t, "a", tmpl.Variables.Entries[0].Key.Value)
+}
@@ -0,0 +1,84 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// An Inventory counts what a template declares.
+type Inventory struct {
+	// ResourcesByPackage maps the name of each package to the number of resources it provides.
+	ResourcesByPackage map[string]int
+	// ResourcesByType maps each resource type token to the number of resources of that type.
+	ResourcesByType map[string]int
+
+	Variables int
+	Outputs   int
+	Config    int
+}
+
+// Resources is the total number of resources.
+func (i *Inventory) Resources() int {
+	total := 0
+	for _, n := range i.ResourcesByType {
+		total += n
+	}
+	return total
+}
+
+// GetInventory counts the resources, variables, outputs and config of a template without
+// evaluating it. A resource declared with forEach counts once, since the number of its instances
+// may only be known when the template runs.
+//
+// Type tokens are counted as written in the template. If loader is not nil, it is used to
+// normalize them to their canonical form, such as aws:s3/bucket:Bucket for aws:s3:Bucket, so that
+// resources of the same type are counted together. Tokens that can't be resolved are counted as
+// written.
+func GetInventory(tmpl *ast.TemplateDecl, loader PackageLoader) (*Inventory, syntax.Diagnostics) {
+	inventory := &Inventory{
+		ResourcesByPackage: map[string]int{},
+		ResourcesByType:    map[string]int{},
+	}
+	diags := newRunner(tmpl, nil).Run(walker{
+		VisitConfig: func(r *Runner, node configNode) bool {
+			inventory.Config++
+			return true
+		},
+		VisitVariable: func(r *Runner, node variableNode) bool {
+			inventory.Variables++
+			return true
+		},
+		VisitOutput: func(ctx *evalContext, name string, expr ast.Expr) bool {
+			inventory.Outputs++
+			return true
+		},
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			res := node.Value
+			if res.Type == nil {
+				r.sdiags.Extend(syntax.NodeError(res.Syntax(), fmt.Sprintf("Resource declared without a 'type': %q", node.Key.Value), ""))
+				return true
+			}
+			token := res.Type.Value
+			if loader != nil {
+				if version, err := ParseVersion(res.Options.Version); err == nil {
+					_, canonical, err := ResolveResource(context.TODO(), loader, nil, token, version)
+					if err == nil {
+						token = string(canonical)
+					}
+				}
+			}
+			inventory.ResourcesByType[token]++
+			inventory.ResourcesByPackage[ResolvePkgName(token)]++
+			return true
+		},
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return inventory, diags
+}
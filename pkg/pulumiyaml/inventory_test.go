@@ -0,0 +1,76 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInventory(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-inventory
+runtime: yaml
+configuration:
+  region:
+    type: String
+    default: us-west-2
+  zones:
+    type: List<String>
+    default: [a, b]
+variables:
+  prefix: app-${region}
+resources:
+  logs:
+    type: aws:s3:Bucket
+  assets:
+    type: aws:s3/bucket:Bucket
+  subnet:
+    type: aws:ec2:Subnet
+    forEach: ${zones}
+  res:
+    type: test:resource:type
+outputs:
+  logs: ${logs.id}
+  endpoints:
+    assets: ${assets.id}
+    prefix: ${prefix}
+`)
+	inventory, diags := GetInventory(tmpl, nil)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, map[string]int{"aws": 3, "test": 1}, inventory.ResourcesByPackage)
+	assert.Equal(t, map[string]int{
+		"aws:s3:Bucket":        1,
+		"aws:s3/bucket:Bucket": 1,
+		"aws:ec2:Subnet":       1,
+		"test:resource:type":   1,
+	}, inventory.ResourcesByType)
+	assert.Equal(t, 4, inventory.Resources())
+	assert.Equal(t, 1, inventory.Variables)
+	assert.Equal(t, 2, inventory.Outputs)
+	assert.Equal(t, 2, inventory.Config)
+
+	loader := MockPackageLoader{packages: map[string]Package{
+		"aws": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				if typeName == "aws:s3:Bucket" {
+					return "aws:s3/bucket:Bucket", nil
+				}
+				return ResourceTypeToken(typeName), nil
+			},
+		},
+	}}
+	inventory, diags = GetInventory(tmpl, loader)
+	requireNoErrors(t, tmpl, diags)
+	// The test package isn't known to the loader, so its token is counted as written.
+	assert.Equal(t, map[string]int{
+		"aws:s3/bucket:Bucket": 2,
+		"aws:ec2:Subnet":       1,
+		"test:resource:type":   1,
+	}, inventory.ResourcesByType)
+	require.Equal(t, 4, inventory.Resources())
+}
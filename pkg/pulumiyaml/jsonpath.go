@@ -0,0 +1,220 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath expression, as used by fn::jsonPath. The supported subset is:
+//
+//	$          the document; every path starts with it
+//	.name      the property name of an object
+//	['name']   the property name, which may contain any character; "name" may also be used
+//	[n]        the nth element of a list, counting from 0; negative indices count from the end
+//	.* or [*]  every property of an object, in order of their names, or every element of a list
+//	..name     the property name of the value and of every value nested in it
+//	..*        every value nested in the value
+//
+// Filters, slices, unions and script expressions are not supported.
+//
+// A path that uses only the first four forms selects at most one value, which is the result. Other
+// paths select a list of values. In both cases, a path that selects nothing results in null.
+type jsonPath struct {
+	steps []jsonPathStep
+	// definite is set if the path selects at most one value.
+	definite bool
+}
+
+type jsonPathStepKind int
+
+const (
+	jsonPathProperty jsonPathStepKind = iota
+	jsonPathIndex
+	jsonPathWildcard
+	jsonPathDescendant
+	jsonPathDescendantWildcard
+)
+
+type jsonPathStep struct {
+	kind  jsonPathStepKind
+	name  string
+	index int
+}
+
+// compileJSONPath parses the JSONPath expression path.
+func compileJSONPath(path string) (*jsonPath, error) {
+	pathErr := func(offset int, format string, args ...interface{}) error {
+		return fmt.Errorf("invalid JSONPath %q at offset %d: %s", path, offset, fmt.Sprintf(format, args...))
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: paths must start with '$'", path)
+	}
+
+	p := &jsonPath{definite: true}
+	// name reads a property name starting at i, which ends at the next '.' or '['.
+	name := func(i int) (string, int) {
+		end := i
+		for end < len(path) && path[end] != '.' && path[end] != '[' {
+			end++
+		}
+		return path[i:end], end
+	}
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			if strings.HasPrefix(path[i:], "..") {
+				p.definite = false
+				i += 2
+				if i < len(path) && path[i] == '*' {
+					p.steps = append(p.steps, jsonPathStep{kind: jsonPathDescendantWildcard})
+					i++
+					continue
+				}
+				n, end := name(i)
+				if n == "" {
+					return nil, pathErr(i, "expected a property name after '..'")
+				}
+				p.steps = append(p.steps, jsonPathStep{kind: jsonPathDescendant, name: n})
+				i = end
+				continue
+			}
+			i++
+			if i < len(path) && path[i] == '*' {
+				p.definite = false
+				p.steps = append(p.steps, jsonPathStep{kind: jsonPathWildcard})
+				i++
+				continue
+			}
+			n, end := name(i)
+			if n == "" {
+				return nil, pathErr(i, "expected a property name after '.'")
+			}
+			p.steps = append(p.steps, jsonPathStep{kind: jsonPathProperty, name: n})
+			i = end
+		case '[':
+			i++
+			switch {
+			case strings.HasPrefix(path[i:], "*]"):
+				p.definite = false
+				p.steps = append(p.steps, jsonPathStep{kind: jsonPathWildcard})
+				i += 2
+			case i < len(path) && (path[i] == '\'' || path[i] == '"'):
+				quote := path[i]
+				var b strings.Builder
+				j := i + 1
+				for ; j < len(path) && path[j] != quote; j++ {
+					if path[j] == '\\' && j+1 < len(path) {
+						j++
+					}
+					b.WriteByte(path[j])
+				}
+				if j >= len(path) {
+					return nil, pathErr(i, "missing closing %c", quote)
+				}
+				if j+1 >= len(path) || path[j+1] != ']' {
+					return nil, pathErr(j+1, "expected ']' after the property name")
+				}
+				p.steps = append(p.steps, jsonPathStep{kind: jsonPathProperty, name: b.String()})
+				i = j + 2
+			default:
+				end := strings.IndexByte(path[i:], ']')
+				if end < 0 {
+					return nil, pathErr(i-1, "missing closing ']'")
+				}
+				index, err := strconv.Atoi(path[i : i+end])
+				if err != nil {
+					return nil, pathErr(i, "expected an index, a quoted property name or '*', got %q", path[i:i+end])
+				}
+				p.steps = append(p.steps, jsonPathStep{kind: jsonPathIndex, index: index})
+				i += end + 1
+			}
+		default:
+			return nil, pathErr(i, "unexpected %q; expected '.' or '['", path[i])
+		}
+	}
+	return p, nil
+}
+
+// selectFrom returns the values selected from the decoded JSON document root.
+func (p *jsonPath) selectFrom(root interface{}) interface{} {
+	nodes := []interface{}{root}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			next = step.apply(next, node)
+		}
+		nodes = next
+	}
+
+	switch {
+	case len(nodes) == 0:
+		return nil
+	case p.definite:
+		return nodes[0]
+	default:
+		return nodes
+	}
+}
+
+// apply appends the values the step selects from node to selected.
+func (s jsonPathStep) apply(selected []interface{}, node interface{}) []interface{} {
+	switch s.kind {
+	case jsonPathProperty:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if v, ok := obj[s.name]; ok {
+				selected = append(selected, v)
+			}
+		}
+	case jsonPathIndex:
+		if list, ok := node.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				selected = append(selected, list[i])
+			}
+		}
+	case jsonPathWildcard:
+		selected = append(selected, jsonChildren(node)...)
+	case jsonPathDescendant:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if v, ok := obj[s.name]; ok {
+				selected = append(selected, v)
+			}
+		}
+		for _, child := range jsonChildren(node) {
+			selected = s.apply(selected, child)
+		}
+	case jsonPathDescendantWildcard:
+		for _, child := range jsonChildren(node) {
+			selected = append(selected, child)
+			selected = s.apply(selected, child)
+		}
+	}
+	return selected
+}
+
+// jsonChildren returns the elements of a list, or the properties of an object ordered by name.
+func jsonChildren(node interface{}) []interface{} {
+	switch node := node.(type) {
+	case []interface{}:
+		return node
+	case map[string]interface{}:
+		keys := make([]string, 0, len(node))
+		for k := range node {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		children := make([]interface{}, len(keys))
+		for i, k := range keys {
+			children[i] = node[k]
+		}
+		return children
+	}
+	return nil
+}
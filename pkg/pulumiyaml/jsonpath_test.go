@@ -0,0 +1,146 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPathSelect(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{
+  "name": "app",
+  "db": {"host": "localhost", "port": 5432},
+  "servers": [
+    {"name": "a", "tags": {"zone": "x"}},
+    {"name": "b", "tags": {"zone": "y"}}
+  ],
+  "odd key": true
+}`
+	var root interface{}
+	require.NoError(t, json.Unmarshal([]byte(doc), &root))
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "$", expected: root},
+		{path: "$.db.port", expected: 5432.0},
+		{path: "$['db'][\"host\"]", expected: "localhost"},
+		{path: "$['odd key']", expected: true},
+		{path: "$.servers[1].name", expected: "b"},
+		{path: "$.servers[-1].tags.zone", expected: "y"},
+		{path: "$.servers[*].name", expected: []interface{}{"a", "b"}},
+		{path: "$.db.*", expected: []interface{}{"localhost", 5432.0}},
+		{path: "$..zone", expected: []interface{}{"x", "y"}},
+		{path: "$.servers[0]..*", expected: []interface{}{"a", map[string]interface{}{"zone": "x"}, "x"}},
+		{path: "$.missing", expected: nil},
+		{path: "$.servers[5]", expected: nil},
+		{path: "$.name.first", expected: nil},
+		{path: "$..nothing", expected: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := compileJSONPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p.selectFrom(root))
+		})
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{path: "db.port", err: `invalid JSONPath "db.port": paths must start with '$'`},
+		{path: "$.", err: `invalid JSONPath "$." at offset 2: expected a property name after '.'`},
+		{path: "$..", err: `invalid JSONPath "$.." at offset 3: expected a property name after '..'`},
+		{path: "$[1", err: `invalid JSONPath "$[1" at offset 1: missing closing ']'`},
+		{path: "$['a]", err: `invalid JSONPath "$['a]" at offset 2: missing closing '`},
+		{path: "$['a'x]", err: `invalid JSONPath "$['a'x]" at offset 5: expected ']' after the property name`},
+		{path: "$[?(@.a)]", err: `invalid JSONPath "$[?(@.a)]" at offset 2: expected an index, a quoted property name or '*', got "?(@.a)"`},
+		{path: "$x", err: `invalid JSONPath "$x" at offset 1: unexpected 'x'; expected '.' or '['`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := compileJSONPath(tt.path)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-jsonpath
+runtime: yaml
+variables:
+  doc: '{"db": {"port": 5432}, "hosts": ["a", "b"]}'
+  port:
+    fn::jsonPath: [ "${doc}", "$.db.port" ]
+  hosts:
+    fn::jsonPath: [ "${doc}", "$.hosts[*]" ]
+  missing:
+    fn::jsonPath: [ "${doc}", "$.db.user" ]
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, 5432.0, e.variables["port"])
+		assert.Equal(t, []interface{}{"a", "b"}, e.variables["hosts"])
+		assert.Nil(t, e.variables["missing"])
+	})
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{
+			name:     "invalid path",
+			args:     `[ "{}", "$.a[" ]`,
+			expected: `<stdin>:6:27: invalid JSONPath "$.a[" at offset 3: missing closing ']'`,
+		},
+		{
+			name:     "invalid JSON",
+			args:     `[ "{", "$.a" ]`,
+			expected: `<stdin>:6:21: invalid JSON: unexpected end of JSON input`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := yamlTemplate(t, `
+name: test-jsonpath
+runtime: yaml
+variables:
+  value:
+    fn::jsonPath: `+tt.args+`
+`)
+			diags := testTemplateDiags(t, tmpl, nil)
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.expected, diagString(diags[0]))
+		})
+	}
+}
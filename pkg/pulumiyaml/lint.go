@@ -0,0 +1,111 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// LintOptions are the rules checked by Lint.
+type LintOptions struct {
+	// ResourceNamePattern, if set, must match the logical name of every resource. The logical
+	// name is the resource's 'name', or its key if it has no name.
+	ResourceNamePattern *regexp.Regexp
+	// RequiredProperties maps resource types, such as aws:s3:Bucket, to the properties that
+	// resources of the type must set. A property of an object may be named with a dotted path,
+	// such as tags.owner.
+	RequiredProperties map[string][]string
+	// Severity is the severity of violations. It defaults to hcl.DiagError.
+	Severity hcl.DiagnosticSeverity
+}
+
+// Lint checks the resources of a template against the rules in opts, and returns a diagnostic
+// for each violation. If the template's resources can't be ordered, for example because they
+// depend on each other, the problems are returned instead.
+func Lint(t *ast.TemplateDecl, opts LintOptions) syntax.Diagnostics {
+	severity := opts.Severity
+	if severity == hcl.DiagInvalid {
+		severity = hcl.DiagError
+	}
+	violation := func(x ast.Expr, summary, detail string) *syntax.Diagnostic {
+		d := ast.ExprError(x, summary, detail)
+		d.Severity = severity
+		return d
+	}
+
+	r := newRunner(t, nil)
+	r.setIntermediates("", nil, nil, false)
+	return r.Run(walker{
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			v := node.Value
+			if opts.ResourceNamePattern != nil {
+				var name ast.Expr = node.Key
+				logicalName := node.Key.Value
+				if v.Name != nil && v.Name.Value != "" {
+					name, logicalName = v.Name, v.Name.Value
+				}
+				if !opts.ResourceNamePattern.MatchString(logicalName) {
+					r.sdiags.Extend(violation(name,
+						fmt.Sprintf("resource name %q does not match the pattern %s", logicalName, opts.ResourceNamePattern),
+						""))
+				}
+			}
+
+			if v.Type == nil {
+				return true
+			}
+			required := append([]string(nil), opts.RequiredProperties[v.Type.Value]...)
+			sort.Strings(required)
+			for _, path := range required {
+				if !hasPropertyPath(v.Properties.Entries, strings.Split(path, ".")) {
+					r.sdiags.Extend(violation(node.Key,
+						fmt.Sprintf("resource %q of type %s must set the property %q", node.Key.Value, v.Type.Value, path),
+						""))
+				}
+			}
+			return true
+		},
+	})
+}
+
+// hasPropertyPath reports whether entries set the property at path. Properties whose values are
+// only known when the program runs, such as interpolations, are assumed to set every path below
+// them.
+func hasPropertyPath(entries []ast.PropertyMapEntry, path []string) bool {
+	for _, kvp := range entries {
+		if kvp.Key.Value != path[0] {
+			continue
+		}
+		if _, isNull := kvp.Value.(*ast.NullExpr); isNull {
+			return false
+		}
+		if len(path) == 1 {
+			return true
+		}
+		var obj *ast.ObjectExpr
+		switch v := kvp.Value.(type) {
+		case *ast.ObjectExpr:
+			obj = v
+		case *ast.BooleanExpr, *ast.NumberExpr, *ast.StringExpr, *ast.ListExpr:
+			return false
+		default:
+			return true
+		}
+		nested := make([]ast.PropertyMapEntry, 0, len(obj.Entries))
+		for _, entry := range obj.Entries {
+			if key, ok := entry.Key.(*ast.StringExpr); ok {
+				nested = append(nested, ast.PropertyMapEntry{Key: key, Value: entry.Value})
+			}
+		}
+		return hasPropertyPath(nested, path[1:])
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const lintTemplate = `name: test-lint
+runtime: yaml
+resources:
+  good-bucket:
+    type: aws:s3:Bucket
+    properties:
+      tags:
+        owner: platform
+        team: storage
+  BadBucket:
+    type: aws:s3:Bucket
+    properties:
+      tags:
+        owner: ${owner}
+  renamed:
+    type: aws:s3:Bucket
+    name: Renamed_Bucket
+    properties:
+      tags: ${tags}
+  untagged:
+    type: aws:s3:BucketPolicy
+variables:
+  owner: me
+  tags:
+    owner: me
+`
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, lintTemplate)
+	diags := Lint(tmpl, LintOptions{
+		ResourceNamePattern: regexp.MustCompile(`^[a-z][a-z0-9-]*$`),
+		RequiredProperties: map[string][]string{
+			"aws:s3:Bucket": {"tags.team", "tags.owner"},
+		},
+	})
+	assert.Equal(t, []string{
+		`<stdin>:10:3: resource name "BadBucket" does not match the pattern ^[a-z][a-z0-9-]*$`,
+		`<stdin>:10:3: resource "BadBucket" of type aws:s3:Bucket must set the property "tags.team"`,
+		`<stdin>:17:11: resource name "Renamed_Bucket" does not match the pattern ^[a-z][a-z0-9-]*$`,
+	}, diagStrings(diags))
+	for _, d := range diags {
+		assert.Equal(t, hcl.DiagError, d.Severity)
+	}
+}
+
+func TestLintWarnings(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, lintTemplate)
+	diags := Lint(tmpl, LintOptions{
+		RequiredProperties: map[string][]string{
+			"aws:s3:BucketPolicy": {"policy"},
+		},
+		Severity: hcl.DiagWarning,
+	})
+	assert.Equal(t, []string{
+		`<stdin>:20:3: resource "untagged" of type aws:s3:BucketPolicy must set the property "policy"`,
+	}, diagStrings(diags))
+	assert.False(t, diags.HasErrors())
+}
+
+func TestLintNoRules(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, lintTemplate)
+	assert.Empty(t, Lint(tmpl, LintOptions{}))
+}
@@ -0,0 +1,332 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// The names that the properties and options of a resource with a forEach use to refer to the
+// current iteration. Lists bind index and item, and maps bind key and value.
+const (
+	loopIndexName = "index"
+	loopItemName  = "item"
+	loopKeyName   = "key"
+	loopValueName = "value"
+)
+
+func isLoopVariable(name string) bool {
+	switch name {
+	case loopIndexName, loopItemName, loopKeyName, loopValueName:
+		return true
+	}
+	return false
+}
+
+// resourceLoop is an iteration of a resource declared with forEach.
+type resourceLoop struct {
+	// suffix is appended to the resource's name to name the iteration's instance: the index of
+	// an element of a list, or the key of an entry of a map.
+	suffix   string
+	bindings map[string]interface{}
+	// dependsOn are the resources that forEach depends on.
+	dependsOn []pulumi.Resource
+}
+
+// binding returns the value of the loop variable name, if name is one.
+func (l *resourceLoop) binding(name string) (interface{}, bool) {
+	if l == nil {
+		return nil, false
+	}
+	v, ok := l.bindings[name]
+	return v, ok
+}
+
+// loopResourceName is the logical name of the instance of a resource named name for the
+// iteration with the given suffix.
+func loopResourceName(name, suffix string) string {
+	return name + "-" + suffix
+}
+
+// logicalName is the name a resource is registered with: its 'name', or its key if it has none.
+func logicalName(key *ast.StringExpr, r *ast.ResourceDecl) (*ast.StringExpr, string) {
+	if r.Name != nil && r.Name.Value != "" {
+		return r.Name, r.Name.Value
+	}
+	return key, key.Value
+}
+
+// evalResourceLoop registers one resource per element of a resource's forEach. The resource's
+// value is the list of the registered resources if forEach is a list, or the map from keys to the
+// registered resources if it is a map.
+//
+// forEach must be known before the resources are registered. If it is an output that isn't known
+// during a preview, the resources are left out of the preview and the resource's value is unknown.
+func (e programEvaluator) evalResourceLoop(r *Runner, node resourceNode) bool {
+	ctx := r.newContext(node)
+	k, v := node.Key.Value, node.Value
+	fail := func() bool {
+		e.setVariableValue(k, poisonMarker{})
+		msg := fmt.Sprintf("Error registering resource [%v]: %v", k, ctx.sdiags.Error())
+		return e.pulumiCtx.Log.Error(msg, &pulumi.LogArgs{}) == nil
+	}
+
+	value, ok := e.evaluateExpr(v.ForEach)
+	if !ok {
+		return fail()
+	}
+	if p, ok := value.(poisonMarker); ok {
+		e.setVariableValue(k, p)
+		return true
+	}
+	var dependsOn []pulumi.Resource
+	secret := false
+	if o, ok := value.(pulumi.Output); ok {
+		result, err := internals.UnsafeAwaitOutput(e.pulumiCtx.Context(), o)
+		if err != nil {
+			e.error(v.ForEach, fmt.Sprintf("unable to evaluate forEach: %v", err))
+			return fail()
+		}
+		if !result.Known {
+			if !e.pulumiCtx.DryRun() {
+				e.error(v.ForEach, "forEach must be known when resources are registered")
+				return fail()
+			}
+			e.addWarnDiag(v.ForEach.Syntax().Syntax().Range(),
+				fmt.Sprintf("forEach of resource %q is unknown, so its instances are not shown in the preview", k),
+				"The instances are registered when the update runs")
+			e.setVariableValue(k, pulumi.UnsafeUnknownOutput(result.Dependencies))
+			return true
+		}
+		value, dependsOn, secret = result.Value, result.Dependencies, result.Secret
+	}
+
+	var loops []resourceLoop
+	var results interface{}
+	var setResult func(i int, res lateboundResource)
+	switch collection := value.(type) {
+	case []interface{}:
+		for i, item := range collection {
+			if secret {
+				item = pulumi.ToSecret(item)
+			}
+			loops = append(loops, resourceLoop{
+				suffix:   strconv.Itoa(i),
+				bindings: map[string]interface{}{loopIndexName: float64(i), loopItemName: item},
+			})
+		}
+		list := make([]interface{}, len(loops))
+		results = list
+		setResult = func(i int, res lateboundResource) { list[i] = res }
+	case map[string]interface{}:
+		if secret {
+			e.error(v.ForEach, "forEach can't be a secret map, since its keys are part of the names of resources")
+			return fail()
+		}
+		keys := sortedKeys(collection)
+		for _, key := range keys {
+			loops = append(loops, resourceLoop{
+				suffix:   key,
+				bindings: map[string]interface{}{loopKeyName: key, loopValueName: collection[key]},
+			})
+		}
+		m := make(map[string]interface{}, len(keys))
+		results = m
+		setResult = func(i int, res lateboundResource) { m[keys[i]] = res }
+	default:
+		e.error(v.ForEach, fmt.Sprintf("forEach must be a list or a map, not %v", typeString(value)))
+		return fail()
+	}
+
+	_, name := logicalName(node.Key, v)
+	for i := range loops {
+		instance := loopResourceName(name, loops[i].suffix)
+		if other, ok := e.resourceNamed(instance); ok {
+			e.error(v.ForEach, fmt.Sprintf("the name %q of an instance of resource %q is already the name of resource %q", instance, k, other))
+			return fail()
+		}
+	}
+	for i := range loops {
+		e.loopInstances[loopResourceName(name, loops[i].suffix)] = k
+		loops[i].dependsOn = dependsOn
+		e.loop = &loops[i]
+		res, ok := e.registerResource(node)
+		if !ok {
+			return fail()
+		}
+		setResult(i, res)
+	}
+	e.setVariableValue(k, results)
+	return true
+}
+
+// resourceNamed returns the key of the resource whose logical name, or the name of one of whose
+// instances registered so far, is name.
+func (e *programEvaluator) resourceNamed(name string) (string, bool) {
+	if k, ok := e.loopInstances[name]; ok {
+		return k, true
+	}
+	for _, kvp := range e.t.Resources.Entries {
+		if kvp.Value.ForEach != nil {
+			continue
+		}
+		if _, other := logicalName(kvp.Key, kvp.Value); other == name {
+			return kvp.Key.Value, true
+		}
+	}
+	return "", false
+}
+
+// checkLoopNames ensures that resources declared with forEach have different names, since their
+// instances are named by suffixing the resource's name.
+func checkLoopNames(r *Runner) syntax.Diagnostics {
+	var diags syntax.Diagnostics
+	looped := map[string]*ast.StringExpr{}
+	for _, kvp := range r.t.Resources.Entries {
+		if kvp.Value.ForEach == nil {
+			continue
+		}
+		expr, name := logicalName(kvp.Key, kvp.Value)
+		if prev, ok := looped[name]; ok {
+			diags.Extend(ast.ExprError(expr,
+				fmt.Sprintf("resources %q and %q declared with forEach are both named %q", prev.Value, kvp.Key.Value, name),
+				"The name of each instance is suffixed with its index or key, so the names of their instances can be the same"))
+			continue
+		}
+		looped[name] = kvp.Key
+	}
+	return diags
+}
+
+// checkLoopInstanceNames reports resources whose names are the same as the name of an instance
+// of the resource node, which is declared with a forEach of type forEach. When the keys of a map
+// aren't known until the program runs, the names are checked then.
+func checkLoopInstanceNames(ctx *evalContext, node resourceNode, forEach schema.Type) {
+	var isInstance func(suffix string) (string, bool)
+	switch codegen.UnwrapType(forEach).(type) {
+	case *schema.ArrayType:
+		isInstance = func(suffix string) (string, bool) {
+			index, err := strconv.Atoi(suffix)
+			if err != nil || index < 0 || strconv.Itoa(index) != suffix {
+				return "", false
+			}
+			return suffix, true
+		}
+	case *schema.MapType, *schema.ObjectType:
+		obj, ok := node.Value.ForEach.(*ast.ObjectExpr)
+		if !ok {
+			return
+		}
+		keys := map[string]bool{}
+		for _, entry := range obj.Entries {
+			if key, ok := entry.Key.(*ast.StringExpr); ok {
+				keys[key.Value] = true
+			}
+		}
+		isInstance = func(suffix string) (string, bool) {
+			return strconv.Quote(suffix), keys[suffix]
+		}
+	default:
+		return
+	}
+
+	_, name := logicalName(node.Key, node.Value)
+	for _, kvp := range ctx.t.Resources.Entries {
+		if kvp.Value.ForEach != nil {
+			continue
+		}
+		expr, other := logicalName(kvp.Key, kvp.Value)
+		suffix, ok := strings.CutPrefix(other, name+"-")
+		if !ok {
+			continue
+		}
+		if instance, ok := isInstance(suffix); ok {
+			ctx.addErrDiag(expr.Syntax().Syntax().Range(),
+				fmt.Sprintf("resource name %q conflicts with the name of instance %s of resource %q", other, instance, node.Key.Value),
+				"Resources declared with forEach name each instance by suffixing the resource's name with its index or key")
+		}
+	}
+}
+
+// typeLoop returns the type of a resource declared with forEach, given the type of its forEach
+// and the type of each instance.
+func typeLoop(ctx *evalContext, forEach ast.Expr, forEachType, instance schema.Type) schema.Type {
+	switch codegen.UnwrapType(forEachType).(type) {
+	case *schema.ArrayType:
+		return &schema.ArrayType{ElementType: instance}
+	case *schema.MapType, *schema.ObjectType:
+		return &schema.MapType{ElementType: instance}
+	case *schema.UnionType, *schema.InvalidType:
+		return schema.AnyType
+	}
+	if forEachType != schema.AnyType {
+		ctx.error(forEach, fmt.Sprintf("forEach must be a list or a map, not %s", displayType(forEachType)))
+	}
+	return schema.AnyType
+}
+
+// typeLoopBinding returns the type of the symbol t if it refers to a loop variable of the resource
+// being typed.
+func (tc *typeCache) typeLoopBinding(ctx *evalContext, t *ast.SymbolExpr) (schema.Type, bool) {
+	name := t.Property.RootName()
+	node, ok := ctx.root.(resourceNode)
+	if !ok || node.Value.ForEach == nil || !isLoopVariable(name) {
+		return nil, false
+	}
+	unbound := func(kind, names string) (schema.Type, bool) {
+		summary := fmt.Sprintf("%q isn't bound when forEach is a %s", name, kind)
+		ctx.addErrDiag(t.Syntax().Syntax().Range(), summary, fmt.Sprintf("The %s is bound to %s", kind, names))
+		return &schema.InvalidType{}, true
+	}
+	forEach := tc.exprs[node.Value.ForEach]
+	switch t := codegen.UnwrapType(forEach).(type) {
+	case *schema.ArrayType:
+		switch name {
+		case loopIndexName:
+			return schema.IntType, true
+		case loopItemName:
+			return t.ElementType, true
+		}
+		return unbound("list", "${index} and ${item}")
+	case *schema.MapType, *schema.ObjectType:
+		switch name {
+		case loopKeyName:
+			return schema.StringType, true
+		case loopValueName:
+			return mapValueType(forEach), true
+		}
+		return unbound("map", "${key} and ${value}")
+	default:
+		// forEach isn't known to be a list or a map, so any of the loop variables may be bound.
+		switch name {
+		case loopIndexName:
+			return schema.IntType, true
+		case loopKeyName:
+			return schema.StringType, true
+		}
+		return schema.AnyType, true
+	}
+}
+
+// loopDependencies removes the loop variables from the dependencies of a resource declared with
+// forEach, and adds the dependencies of its forEach.
+func loopDependencies(r *ast.ResourceDecl, deps []*ast.StringExpr) []*ast.StringExpr {
+	filtered := deps[:0]
+	for _, dep := range deps {
+		if !isLoopVariable(dep.Value) {
+			filtered = append(filtered, dep)
+		}
+	}
+	getExpressionDependencies(&filtered, r.ForEach)
+	return filtered
+}
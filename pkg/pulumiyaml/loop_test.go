@@ -0,0 +1,363 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-range
+runtime: yaml
+variables:
+  up:
+    fn::range: [0, 3]
+  down:
+    fn::range: [5, 0, -2]
+  empty:
+    fn::range: [3, 3]
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, []interface{}{0.0, 1.0, 2.0}, e.variables["up"])
+		assert.Equal(t, []interface{}{5.0, 3.0, 1.0}, e.variables["down"])
+		assert.Equal(t, []interface{}{}, e.variables["empty"])
+	})
+}
+
+func TestRangeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr     *ast.RangeExpr
+		expected string
+	}{
+		{
+			expr:     ast.Range(ast.Number(0), ast.Number(3), ast.Number(0)),
+			expected: "step must not be 0",
+		},
+		{
+			expr:     ast.Range(ast.Number(0), ast.Number(1.5), nil),
+			expected: "end must be an integer, not 1.5",
+		},
+		{
+			expr:     ast.Range(ast.String("a"), ast.Number(3), nil),
+			expected: "start must be a number, not a string",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				_, ok := e.evaluateBuiltinRange(tt.expr)
+				assert.False(t, ok)
+				require.Len(t, e.sdiags.diags, 1)
+				assert.Equal(t, tt.expected, e.sdiags.diags[0].Summary)
+			})
+		})
+	}
+}
+
+func TestResourceForEachList(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+variables:
+  zones: [a, b, c]
+resources:
+  subnet:
+    type: test:resource:with-tags
+    forEach: ${zones}
+    properties:
+      foo: zone-${item}
+      tags:
+        position: ${index}
+  named:
+    type: test:resource:with-tags
+    name: counted
+    forEach:
+      fn::range: [0, 2]
+    properties:
+      foo: ${subnet[1].foo}
+    options:
+      dependsOn: ${subnet}
+`)
+	var lock sync.Mutex
+	inputs := map[string]resource.PropertyMap{}
+	dependencies := map[string]int{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			inputs[args.Name] = args.Inputs
+			dependencies[args.Name] = len(args.RegisterRPC.Dependencies)
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	require.Len(t, inputs, 5)
+	for i, zone := range []string{"a", "b", "c"} {
+		name := loopResourceName("subnet", strconv.Itoa(i))
+		require.Contains(t, inputs, name)
+		assert.Equal(t, "zone-"+zone, inputs[name]["foo"].StringValue())
+		assert.Equal(t, float64(i), inputs[name]["tags"].ObjectValue()["position"].NumberValue())
+	}
+	for _, name := range []string{"counted-0", "counted-1"} {
+		require.Contains(t, inputs, name)
+		assert.Equal(t, "zone-b", inputs[name]["foo"].StringValue())
+		assert.Equal(t, 3, dependencies[name])
+	}
+}
+
+func TestResourceForEachTypes(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+resources:
+  subnet:
+    type: test:resource:with-tags
+    forEach:
+      fn::range: [0, 2]
+    properties:
+      foo: ${item}
+variables:
+  all: ${subnet}
+  first: ${subnet[0]}
+`)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	all, ok := typing.TypeVariable("all").(*schema.ArrayType)
+	require.True(t, ok, "expected a list, got %v", typing.TypeVariable("all"))
+	assert.IsType(t, &schema.ResourceType{}, all.ElementType)
+	assert.Equal(t, all.ElementType, typing.TypeVariable("first"))
+
+	tmpl = yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+resources:
+  subnet:
+    type: test:resource:with-tags
+    forEach: not-a-list
+`)
+	_, diags = TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diagString(diags[0]), "7:14: ")
+}
+
+func TestResourceForEachNameConflicts(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+resources:
+  subnet:
+    type: test:resource:with-tags
+    forEach: [a, b]
+  other:
+    type: test:resource:with-tags
+    name: subnet
+    forEach: [c]
+  single:
+    type: test:resource:with-tags
+    name: subnet-1
+  unrelated:
+    type: test:resource:with-tags
+    name: subnet-01
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.Len(t, diags, 3)
+	assert.Equal(t, `resources "subnet" and "other" declared with forEach are both named "subnet"`, diags[0].Summary)
+	assert.Equal(t, 10, diags[0].Subject.Start.Line)
+	assert.Equal(t, `resource name "subnet-1" conflicts with the name of instance 1 of resource "subnet"`, diags[1].Summary)
+	assert.Equal(t, 14, diags[1].Subject.Start.Line)
+	// The instances of the second resource have the same names, so its conflicts are reported too.
+	assert.Equal(t, `resource name "subnet-1" conflicts with the name of instance 1 of resource "other"`, diags[2].Summary)
+}
+
+func TestResourceForEachMap(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+configuration:
+  buckets:
+    type: Object
+    default:
+      logs: log-data
+      assets: static-assets
+resources:
+  bucket:
+    type: test:resource:with-tags
+    forEach: ${buckets}
+    properties:
+      foo: ${value}
+      tags:
+        purpose: ${key}
+  policy:
+    type: test:resource:with-tags
+    properties:
+      foo: ${bucket.logs.foo}
+    options:
+      dependsOn: ${bucket}
+`)
+	var lock sync.Mutex
+	inputs := map[string]resource.PropertyMap{}
+	dependencies := map[string]int{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			inputs[args.Name] = args.Inputs
+			dependencies[args.Name] = len(args.RegisterRPC.Dependencies)
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	require.Len(t, inputs, 3)
+	require.Contains(t, inputs, "bucket-logs")
+	assert.Equal(t, "log-data", inputs["bucket-logs"]["foo"].StringValue())
+	assert.Equal(t, "logs", inputs["bucket-logs"]["tags"].ObjectValue()["purpose"].StringValue())
+	require.Contains(t, inputs, "bucket-assets")
+	assert.Equal(t, "static-assets", inputs["bucket-assets"]["foo"].StringValue())
+	require.Contains(t, inputs, "policy")
+	assert.Equal(t, "log-data", inputs["policy"]["foo"].StringValue())
+	assert.Equal(t, 2, dependencies["policy"])
+}
+
+func TestResourceForEachUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+resources:
+  source:
+    type: test:resource:with-tags
+  bucket:
+    type: test:resource:with-tags
+    forEach: ${source.tags}
+    properties:
+      foo: ${value}
+`)
+	run := func(preview bool) ([]string, syntax.Diagnostics) {
+		var lock sync.Mutex
+		var names []string
+		var diags syntax.Diagnostics
+		mocks := &testMonitor{
+			NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				names = append(names, args.Name)
+				outputs := args.Inputs.Copy()
+				if args.Name == "source" && !preview {
+					outputs["tags"] = resource.NewObjectProperty(resource.PropertyMap{
+						"a": resource.NewStringProperty("first"),
+						"b": resource.NewStringProperty("second"),
+					})
+				}
+				return args.Name, outputs, nil
+			},
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks), func(ri *pulumi.RunInfo) {
+			ri.DryRun = preview
+		})
+		require.NoError(t, err)
+		return names, diags
+	}
+
+	names, diags := run(true)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"source"}, names)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `forEach of resource "bucket" is unknown, so its instances are not shown in the preview`, diags[0].Summary)
+
+	names, diags = run(false)
+	requireNoErrors(t, tmpl, diags)
+	assert.ElementsMatch(t, []string{"source", "bucket-a", "bucket-b"}, names)
+}
+
+func TestResourceForEachRuntimeNameConflicts(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+variables:
+  zones:
+    prod: a
+resources:
+  bucket:
+    type: test:resource:with-tags
+    forEach: ${zones}
+  other:
+    type: test:resource:with-tags
+    name: bucket-prod
+`)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diagString(diags[0]),
+		`the name "bucket-prod" of an instance of resource "bucket" is already the name of resource "other"`)
+}
+
+func TestResourceForEachBindings(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-foreach
+runtime: yaml
+resources:
+  bucket:
+    type: test:resource:with-tags
+    forEach: [a, b]
+    properties:
+      foo: ${key}
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `"key" isn't bound when forEach is a list`, diags[0].Summary)
+}
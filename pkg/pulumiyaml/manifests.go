@@ -0,0 +1,413 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	yamldiags "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/diags"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+const (
+	// configFileType is the Kubernetes component that applies the manifests in a file.
+	configFileType = "kubernetes:yaml:ConfigFile"
+	// kustomizeDirectoryType is the Kubernetes component that applies the manifests built from a
+	// kustomization directory.
+	kustomizeDirectoryType = "kubernetes:kustomize:Directory"
+)
+
+// When set, kubernetes:kustomize:Directory resources are expanded by running kustomize build.
+var enableKustomize = cmdutil.IsTruthy(os.Getenv("PULUMI_YAML_ENABLE_KUSTOMIZE"))
+
+// A manifestExpansion replaces a Kubernetes component, which YAML can't construct, by the
+// resources that its manifests describe.
+type manifestExpansion struct {
+	// source is the property that names the file or directory that the manifests are read from.
+	source string
+	// read reads the manifests from the path named by source, which must be inside the project
+	// directory root.
+	read func(root, path string) ([]kubernetesManifest, error)
+}
+
+// manifestExpansionOf returns the expansion of resources of type typ, if they are expanded.
+func manifestExpansionOf(typ string) (manifestExpansion, bool) {
+	switch typ {
+	case configFileType:
+		return manifestExpansion{source: "file", read: readManifests}, true
+	case kustomizeDirectoryType:
+		if enableKustomize {
+			return manifestExpansion{source: "directory", read: buildKustomization}, true
+		}
+	}
+	return manifestExpansion{}, false
+}
+
+// properties are the properties of the expanded component that YAML supports.
+func (x manifestExpansion) properties() []*schema.Property {
+	return []*schema.Property{
+		{Name: x.source, Type: schema.StringType},
+		{Name: "resourcePrefix", Type: &schema.OptionalType{ElementType: schema.StringType}},
+	}
+}
+
+// expandsManifests reports whether the resource v is registered by expanding the manifests it
+// refers to. That is the case for the components with a manifestExpansion when the kubernetes
+// package is available; otherwise they are resolved like any other resource, which reports that
+// they aren't supported.
+func expandsManifests(ctx *evalContext, v *ast.ResourceDecl) bool {
+	if _, ok := manifestExpansionOf(v.Type.GetValue()); !ok {
+		return false
+	}
+	version, err := ParseVersion(v.Options.Version)
+	if err != nil {
+		return false
+	}
+	_, err = loadPackage(context.TODO(), ctx.pkgLoader, ctx.packageDescriptors, v.Type.Value, version)
+	return err == nil
+}
+
+// typeManifestResource checks a component whose manifests are expanded. Its value is a map from
+// the key of each manifest to the resource registered for it.
+func (tc *typeCache) typeManifestResource(ctx *evalContext, node resourceNode) {
+	k, v := node.Key.Value, node.Value
+	x, _ := manifestExpansionOf(v.Type.Value)
+	if v.ForEach != nil {
+		ctx.error(v.ForEach, fmt.Sprintf("forEach isn't supported for %v resources", v.Type.Value))
+	}
+	if v.Get.Id != nil || len(v.Get.State.Entries) > 0 {
+		ctx.addErrDiag(node.Key.Syntax().Syntax().Range(),
+			fmt.Sprintf("%v resources can't be read with get", v.Type.Value), "")
+	}
+	fmtr := yamldiags.NonExistentFieldFormatter{
+		ParentLabel:         fmt.Sprintf("Resource %s", v.Type.Value),
+		Fields:              []string{x.source, "resourcePrefix"},
+		MaxElements:         5,
+		FieldsAreProperties: true,
+	}
+	tc.typePropertyEntries(ctx, k, v.Type.Value, fmtr, v.Properties.Entries, x.properties())
+	tc.registerResource(k, v, &schema.MapType{ElementType: schema.AnyType})
+}
+
+// evalManifestResource registers a resource for each of the manifests of a component whose
+// manifests are expanded, as the component does in other languages. Each resource is named by
+// the manifest's namespace and name, prefixed by resourcePrefix, which defaults to the name of the
+// component. The component's options apply to each of the resources.
+func (e programEvaluator) evalManifestResource(r *Runner, node resourceNode) bool {
+	ctx := r.newContext(node)
+	k, v := node.Key.Value, node.Value
+	x, _ := manifestExpansionOf(v.Type.Value)
+	fail := func() bool {
+		e.setVariableValue(k, poisonMarker{})
+		msg := fmt.Sprintf("Error registering resource [%v]: %v", k, ctx.sdiags.Error())
+		return e.pulumiCtx.Log.Error(msg, &pulumi.LogArgs{}) == nil
+	}
+
+	var source *ast.StringExpr
+	var path string
+	_, prefix := logicalName(node.Key, v)
+	for _, entry := range v.Properties.Entries {
+		value, ok := e.evaluateExpr(entry.Value)
+		if !ok {
+			return fail()
+		}
+		if p, ok := value.(poisonMarker); ok {
+			e.setVariableValue(k, p)
+			return true
+		}
+		s, ok := value.(string)
+		if !ok {
+			e.error(entry.Value, fmt.Sprintf("%v must be a string, not %v", entry.Key.Value, typeString(value)))
+			return fail()
+		}
+		switch entry.Key.Value {
+		case x.source:
+			source, path = entry.Key, s
+		case "resourcePrefix":
+			prefix = s
+		}
+	}
+	if source == nil {
+		e.error(v.Type, fmt.Sprintf("%v resources must have a %v", v.Type.Value, x.source))
+		return fail()
+	}
+
+	manifests, err := x.read(e.cwd, path)
+	if err != nil {
+		e.addErrDiag(source.Syntax().Syntax().Range(),
+			fmt.Sprintf("unable to expand the manifests of resource %v: %v", k, err),
+			unsupportedKubernetesResource(v.Type.Value).Error())
+		return fail()
+	}
+
+	results := make(map[string]interface{}, len(manifests))
+	for _, m := range manifests {
+		key := m.key()
+		if _, ok := results[key]; ok {
+			e.error(source, fmt.Sprintf("the manifest %v appears more than once in %q", key, path))
+			return fail()
+		}
+		decl := *v
+		decl.Type = withValue(v.Type, m.typeToken())
+		decl.Name = withValue(v.Type, m.resourceName(prefix))
+		decl.Properties = ast.PropertyMapDecl{Entries: m.properties}
+		res, ok := e.registerResource(resourceNode{Key: node.Key, Value: &decl})
+		if !ok {
+			return fail()
+		}
+		results[key] = res
+	}
+	e.setVariableValue(k, results)
+	return true
+}
+
+// withValue returns a string expression with the syntax of s and the given value, so that
+// diagnostics about the value point at s.
+func withValue(s *ast.StringExpr, value string) *ast.StringExpr {
+	if node, ok := s.Syntax().(*syntax.StringNode); ok && node != nil {
+		return ast.StringSyntaxValue(node, value)
+	}
+	return ast.String(value)
+}
+
+// A kubernetesManifest is a Kubernetes object read from a manifest.
+type kubernetesManifest struct {
+	apiVersion string
+	kind       string
+	namespace  string
+	name       string
+	// properties are the fields of the object, as literal expressions.
+	properties []ast.PropertyMapEntry
+}
+
+// typeToken returns the token of the resource type of the object.
+func (m kubernetesManifest) typeToken() string {
+	group, version, ok := strings.Cut(m.apiVersion, "/")
+	if !ok {
+		group, version = "core", m.apiVersion
+	}
+	return fmt.Sprintf("kubernetes:%s/%s:%s", group, version, m.kind)
+}
+
+// key identifies the object among the manifests it was read with.
+func (m kubernetesManifest) key() string {
+	if m.namespace == "" {
+		return m.kind + "/" + m.name
+	}
+	return m.kind + "/" + m.namespace + "/" + m.name
+}
+
+// resourceName returns the logical name of the resource registered for the object.
+func (m kubernetesManifest) resourceName(prefix string) string {
+	name := m.name
+	if m.namespace != "" {
+		name = m.namespace + "/" + name
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "-" + name
+}
+
+// readManifests reads the objects in the manifest file at path, which must be inside the project
+// directory root.
+func readManifests(root, path string) ([]kubernetesManifest, error) {
+	resolved, err := resolveProjectPath(root, "manifest", path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("manifest %q does not exist", path)
+		}
+		return nil, fmt.Errorf("unable to read manifest %q: %w", path, err)
+	}
+	return parseManifests(data)
+}
+
+// kustomizeBuild runs kustomize build on the kustomization directory dir, or kubectl kustomize
+// if kustomize isn't installed, and returns the manifests that it prints.
+var kustomizeBuild = func(dir string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("kustomize"); err == nil {
+		cmd = exec.Command(path, "build", dir)
+	} else if path, err := exec.LookPath("kubectl"); err == nil {
+		cmd = exec.Command(path, "kustomize", dir)
+	} else {
+		return nil, errors.New("neither kustomize nor kubectl is installed")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// buildKustomization reads the objects in the manifests built from the kustomization directory at
+// path, which must be inside the project directory root.
+func buildKustomization(root, path string) ([]kubernetesManifest, error) {
+	resolved, err := resolveProjectPath(root, "kustomization directory", path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("kustomization directory %q does not exist", path)
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("kustomization directory %q is not a directory", path)
+	}
+	data, err := kustomizeBuild(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build of %q failed: %w", path, err)
+	}
+	return parseManifests(data)
+}
+
+// parseManifests parses the objects in a stream of YAML documents. Objects whose kind ends in
+// List are replaced by their items.
+func parseManifests(data []byte) ([]kubernetesManifest, error) {
+	var manifests []kubernetesManifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 1; ; i++ {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return manifests, nil
+			}
+			return nil, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].ShortTag() == "!!null" {
+			continue
+		}
+		objects, err := parseManifest(doc.Content[0])
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		manifests = append(manifests, objects...)
+	}
+}
+
+func parseManifest(node *yaml.Node) ([]kubernetesManifest, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errors.New("a manifest must be an object")
+	}
+	var header struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Items      []yaml.Node
+		Metadata   struct {
+			Name      string
+			Namespace string
+		}
+	}
+	if err := node.Decode(&header); err != nil {
+		return nil, err
+	}
+	if header.APIVersion == "" || header.Kind == "" {
+		return nil, errors.New("a manifest must have an apiVersion and a kind")
+	}
+
+	if strings.HasSuffix(header.Kind, "List") {
+		var manifests []kubernetesManifest
+		for i := range header.Items {
+			items, err := parseManifest(&header.Items[i])
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			manifests = append(manifests, items...)
+		}
+		return manifests, nil
+	}
+
+	if header.Metadata.Name == "" {
+		return nil, fmt.Errorf("the %v manifest must have a metadata.name", header.Kind)
+	}
+	m := kubernetesManifest{
+		apiVersion: header.APIVersion,
+		kind:       header.Kind,
+		namespace:  header.Metadata.Namespace,
+		name:       header.Metadata.Name,
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value, err := manifestExpr(node.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+		m.properties = append(m.properties, ast.PropertyMapEntry{
+			Key:   ast.String(node.Content[i].Value),
+			Value: value,
+		})
+	}
+	return []kubernetesManifest{m}, nil
+}
+
+// manifestExpr converts a node of a manifest to a literal expression. Strings are never
+// interpolated, since manifests often contain shell scripts and templates.
+func manifestExpr(node *yaml.Node) (ast.Expr, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return manifestExpr(node.Alias)
+	case yaml.SequenceNode:
+		elements := make([]ast.Expr, len(node.Content))
+		for i, n := range node.Content {
+			e, err := manifestExpr(n)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = e
+		}
+		return ast.List(elements...), nil
+	case yaml.MappingNode:
+		entries := make([]ast.ObjectProperty, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := manifestExpr(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, ast.ObjectProperty{Key: ast.String(node.Content[i].Value), Value: value})
+		}
+		return ast.Object(entries...), nil
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return ast.Null(), nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, err
+		}
+		return ast.Boolean(b), nil
+	case "!!int", "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, err
+		}
+		return ast.Number(f), nil
+	default:
+		return ast.String(node.Value), nil
+	}
+}
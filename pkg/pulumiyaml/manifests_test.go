@@ -0,0 +1,221 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func newKubernetesPackageMap() PackageLoader {
+	return MockPackageLoader{packages: map[string]Package{
+		"kubernetes": MockPackage{
+			isComponent: func(string) (bool, error) { return false, nil },
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+		},
+		"test": newMockPackageMap().(MockPackageLoader).packages["test"],
+	}}
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-config-file
+runtime: yaml
+resources:
+  app:
+    type: kubernetes:yaml:ConfigFile
+    properties:
+      file: testdata/manifests.yaml
+  prefixed:
+    type: kubernetes:yaml:ConfigFile
+    properties:
+      file: testdata/manifests.yaml
+      resourcePrefix: other
+  after:
+    type: test:resource:with-tags
+    properties:
+      foo: ${app["Deployment/web/nginx"].metadata.name}
+    options:
+      dependsOn: ${app}
+`)
+	var lock sync.Mutex
+	types := map[string]string{}
+	inputs := map[string]resource.PropertyMap{}
+	dependencies := map[string]int{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			types[args.Name] = args.TypeToken
+			inputs[args.Name] = args.Inputs
+			dependencies[args.Name] = len(args.RegisterRPC.Dependencies)
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newKubernetesPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"app-web":         "kubernetes:core/v1:Namespace",
+		"app-web/nginx":   "kubernetes:apps/v1:Deployment",
+		"app-scripts":     "kubernetes:core/v1:ConfigMap",
+		"other-web":       "kubernetes:core/v1:Namespace",
+		"other-web/nginx": "kubernetes:apps/v1:Deployment",
+		"other-scripts":   "kubernetes:core/v1:ConfigMap",
+		"after":           "test:resource:with-tags",
+	}, types)
+	assert.Equal(t, 2.0, inputs["app-web/nginx"]["spec"].ObjectValue()["replicas"].NumberValue())
+	assert.Equal(t, "echo ${HOME}", inputs["app-scripts"]["data"].ObjectValue()["start.sh"].StringValue())
+	assert.Equal(t, "nginx", inputs["after"]["foo"].StringValue())
+	assert.Equal(t, 3, dependencies["after"])
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-config-file
+runtime: yaml
+resources:
+  app:
+    type: kubernetes:yaml:ConfigFile
+    properties:
+      file: ../manifests.yaml
+`)
+	var diags syntax.Diagnostics
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newKubernetesPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diagString(diags[0]),
+		`unable to expand the manifests of resource app: manifest "../manifests.yaml" must be inside the project directory`)
+	assert.Contains(t, diags[0].Detail, "The resource type [kubernetes:yaml:ConfigFile] is not supported in YAML at this time")
+
+	tmpl = yamlTemplate(t, `
+name: test-config-file
+runtime: yaml
+resources:
+  app:
+    type: kubernetes:yaml:ConfigFile
+    properties:
+      file: testdata/manifests.yaml
+      transformations: []
+`)
+	_, diags = TypeCheck(newRunner(tmpl, newKubernetesPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags[0].Summary, "transformations")
+}
+
+func TestParseManifests(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseManifests([]byte("apiVersion: v1\nkind: Service\n"))
+	assert.EqualError(t, err, "document 1: the Service manifest must have a metadata.name")
+
+	_, err = parseManifests([]byte("---\n- a\n"))
+	assert.EqualError(t, err, "document 1: a manifest must be an object")
+
+	manifests, err := parseManifests([]byte(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: prod
+`))
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "kubernetes:networking.k8s.io/v1:Ingress", manifests[0].typeToken())
+	assert.Equal(t, "Ingress/prod/web", manifests[0].key())
+	assert.Equal(t, "site-prod/web", manifests[0].resourceName("site"))
+}
+
+// Not parallel, since it sets enableKustomize and kustomizeBuild.
+func TestKustomizeDirectory(t *testing.T) {
+	tmpl := yamlTemplate(t, `
+name: test-kustomize
+runtime: yaml
+resources:
+  app:
+    type: kubernetes:kustomize:Directory
+    properties:
+      directory: testdata
+`)
+	evaluate := func() ([]string, syntax.Diagnostics) {
+		var lock sync.Mutex
+		var names []string
+		mocks := &testMonitor{
+			NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				names = append(names, args.Name)
+				return args.Name, args.Inputs, nil
+			},
+		}
+		var diags syntax.Diagnostics
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			runner := newRunner(tmpl, newKubernetesPackageMap())
+			if _, diags = TypeCheck(runner); diags.HasErrors() {
+				return nil
+			}
+			diags = runner.Evaluate(ctx)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		require.NoError(t, err)
+		return names, diags
+	}
+
+	// Without the feature flag, the resource is still unsupported.
+	_, diags := evaluate()
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags[0].Summary,
+		"The resource type [kubernetes:kustomize:Directory] is not supported in YAML at this time")
+
+	enableKustomize = true
+	t.Cleanup(func() { enableKustomize = false })
+	build := kustomizeBuild
+	t.Cleanup(func() { kustomizeBuild = build })
+
+	var built string
+	kustomizeBuild = func(dir string) ([]byte, error) {
+		built = dir
+		return []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"), nil
+	}
+	names, diags := evaluate()
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"app-web"}, names)
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cwd, "testdata"), built)
+
+	kustomizeBuild = func(dir string) ([]byte, error) {
+		return nil, errors.New("exit status 1: Error: no kustomization file found")
+	}
+	_, diags = evaluate()
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `unable to expand the manifests of resource app: kustomize build of "testdata" failed: `+
+		`exit status 1: Error: no kustomization file found`, diags[0].Summary)
+}
@@ -4,13 +4,22 @@ package pulumiyaml
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/blang/semver"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/iancoleman/strcase"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/packages"
@@ -22,6 +31,8 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type ResourceTypeToken string
@@ -92,12 +103,119 @@ func (l packageLoader) Close() {
 	}
 }
 
+// NewPackageLoader creates a PackageLoader that loads schemas from plugins. Loads that fail with a
+// transient error are retried according to DefaultRetryPolicy.
 func NewPackageLoader(plugins *workspace.Plugins) (PackageLoader, error) {
 	host, err := newResourcePackageHost(plugins)
 	if err != nil {
 		return nil, err
 	}
-	return packageLoader{schema.NewPluginLoader(host), host}, nil
+	return NewRetryingPackageLoader(packageLoader{schema.NewPluginLoader(host), host}, DefaultRetryPolicy), nil
+}
+
+// A RetryPolicy controls how loading a package is retried after a transient failure.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed load is retried.
+	MaxRetries int
+	// InitialDelay is the delay before the first retry. The delay doubles on each following
+	// retry, up to MaxDelay, and a random jitter of up to half the delay is added.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// IsTransient reports whether a load error is worth retrying. If nil, only network and
+	// connection errors are retried.
+	IsTransient func(error) bool
+	// Sleep waits for the given duration, returning early if ctx is done. If nil, a timer is used.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// DefaultRetryPolicy is kept small so that loading a package that doesn't exist fails quickly.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:   2,
+	InitialDelay: 250 * time.Millisecond,
+	MaxDelay:     2 * time.Second,
+}
+
+type retryingPackageLoader struct {
+	PackageLoader
+
+	policy RetryPolicy
+}
+
+// NewRetryingPackageLoader wraps loader so that loads failing with a transient error are retried
+// according to policy. Errors from schemas that can't be retrieved and from missing plugins are
+// never retried.
+func NewRetryingPackageLoader(loader PackageLoader, policy RetryPolicy) PackageLoader {
+	return retryingPackageLoader{loader, policy}
+}
+
+func (l retryingPackageLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	isTransient := l.policy.IsTransient
+	if isTransient == nil {
+		isTransient = isTransientLoadError
+	}
+	sleep := l.policy.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	delay := l.policy.InitialDelay
+	for attempt := 0; ; attempt++ {
+		pkg, err := l.PackageLoader.LoadPackage(ctx, descriptor)
+		if err == nil || attempt >= l.policy.MaxRetries ||
+			errors.Is(err, schema.ErrGetSchemaNotImplemented) || isNotFoundLoadError(err) ||
+			!isTransient(err) {
+			return pkg, err
+		}
+
+		wait := delay
+		if delay > 0 {
+			wait += time.Duration(rand.Int63n(int64(delay)/2 + 1)) //nolint:gosec
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		delay *= 2
+		if l.policy.MaxDelay > 0 && delay > l.policy.MaxDelay {
+			delay = l.policy.MaxDelay
+		}
+	}
+}
+
+func isNotFoundLoadError(err error) bool {
+	var missing *workspace.MissingError
+	return errors.As(err, &missing)
+}
+
+// isTransientLoadError reports whether err looks like a network or connection failure.
+func isTransientLoadError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // Unsafely create a PackageLoader from a schema.Loader, forfeiting the ability to close the host
@@ -106,10 +224,101 @@ func NewPackageLoaderFromSchemaLoader(loader schema.ReferenceLoader) PackageLoad
 	return packageLoader{loader, nil}
 }
 
+type directoryPackageLoader struct {
+	dir string
+}
+
+// NewDirectoryPackageLoader creates a PackageLoader that reads package schemas from JSON files in
+// dir, without loading any plugins. Schemas are read from files named "$name-$version.json"; a
+// file named "$name.json" is used when no versioned file matches.
+//
+// When a version is requested, the highest available version with the same major version that is
+// at least the requested version is loaded. Otherwise the highest available version is loaded.
+// Schemas must not reference types from other packages.
+func NewDirectoryPackageLoader(dir string) PackageLoader {
+	return directoryPackageLoader{dir: dir}
+}
+
+func (l directoryPackageLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	path, err := l.schemaPath(descriptor.Name, descriptor.Version)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec schema.PackageSpec
+	if err := json.Unmarshal(bytes, &spec); err != nil {
+		return nil, fmt.Errorf("reading schema %q: %w", path, err)
+	}
+	pkg, err := schema.ImportSpec(spec, nil)
+	if err != nil {
+		return nil, fmt.Errorf("binding schema %q: %w", path, err)
+	}
+	return resourcePackage{pkg.Reference()}, nil
+}
+
+// schemaPath finds the schema file to load for the given package.
+func (l directoryPackageLoader) schemaPath(name string, version *semver.Version) (string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return "", err
+	}
+	var best *semver.Version
+	var bestPath string
+	unversioned := ""
+	prefix := name + "-"
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".json") {
+			continue
+		}
+		base := strings.TrimSuffix(fileName, ".json")
+		if base == name {
+			unversioned = filepath.Join(l.dir, fileName)
+			continue
+		}
+		if !strings.HasPrefix(base, prefix) {
+			continue
+		}
+		v, err := semver.ParseTolerant(strings.TrimPrefix(base, prefix))
+		if err != nil {
+			continue
+		}
+		if version != nil && (v.Major != version.Major || v.LT(*version)) {
+			continue
+		}
+		if best == nil || v.GT(*best) {
+			v := v
+			best, bestPath = &v, filepath.Join(l.dir, fileName)
+		}
+	}
+	if best != nil {
+		return bestPath, nil
+	}
+	if unversioned != "" {
+		return unversioned, nil
+	}
+	if version != nil {
+		return "", fmt.Errorf("no schema for package %s satisfying version %v found in %s", name, version, l.dir)
+	}
+	return "", fmt.Errorf("no schema for package %s found in %s", name, l.dir)
+}
+
+func (l directoryPackageLoader) Close() {}
+
 // GetReferencedPackages returns the packages and (if provided) versions for each referenced package
 // used in the program.
 func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, syntax.Diagnostics) {
-	packageMap := map[string]*packages.PackageDecl{}
+	// packageEntry tracks where a package's version and download URL were first set, so
+	// conflicts can point back at the original declaration.
+	type packageEntry struct {
+		*packages.PackageDecl
+
+		versionExpr, downloadURLExpr *ast.StringExpr
+	}
+	packageMap := map[string]*packageEntry{}
 
 	// Iterate over the package declarations
 	for _, pkg := range tmpl.Packages {
@@ -128,9 +337,20 @@ func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, synt
 			if entry.DownloadURL == "" {
 				entry.DownloadURL = pkg.DownloadURL
 			}
+			if len(entry.Checksums) == 0 {
+				entry.Checksums = pkg.Checksums
+			}
 		} else {
-			packageMap[name] = &pkg
+			packageMap[name] = &packageEntry{PackageDecl: &pkg}
+		}
+	}
+
+	// firstDeclared returns where a conflicting value was first declared, if known.
+	firstDeclared := func(expr *ast.StringExpr) *hcl.Range {
+		if expr == nil {
+			return nil
 		}
+		return exprRange(expr)
 	}
 
 	acceptType := func(r *Runner, typeName string, version, pluginDownloadURL *ast.StringExpr) {
@@ -139,27 +359,36 @@ func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, synt
 			if v := version.GetValue(); v != "" && entry.Version != v {
 				if entry.Version == "" {
 					entry.Version = v
+					entry.versionExpr = version
 				} else {
-					r.sdiags.Extend(ast.ExprError(version, fmt.Sprintf("Package %v already declared with a conflicting version: %v", pkg, entry.Version), ""))
+					r.sdiags.Extend(ast.ExprError(version, fmt.Sprintf("Package %v already declared with a conflicting version: %v", pkg, entry.Version), "").
+						WithRelated(firstDeclared(entry.versionExpr), "First declared here"))
 				}
 			}
 			if url := pluginDownloadURL.GetValue(); url != "" && entry.DownloadURL != url {
 				if entry.DownloadURL == "" {
 					entry.DownloadURL = url
+					entry.downloadURLExpr = pluginDownloadURL
 				} else {
-					r.sdiags.Extend(ast.ExprError(pluginDownloadURL, fmt.Sprintf("Package %v already declared with a conflicting plugin download URL: %v", pkg, entry.DownloadURL), ""))
+					r.sdiags.Extend(ast.ExprError(pluginDownloadURL, fmt.Sprintf("Package %v already declared with a conflicting plugin download URL: %v", pkg, entry.DownloadURL), "").
+						WithRelated(firstDeclared(entry.downloadURLExpr), "First declared here"))
 				}
 			}
 		} else {
-			packageMap[pkg] = &packages.PackageDecl{
-				Name:        pkg,
-				Version:     version.GetValue(),
-				DownloadURL: pluginDownloadURL.GetValue(),
+			packageMap[pkg] = &packageEntry{
+				PackageDecl: &packages.PackageDecl{
+					Name:        pkg,
+					Version:     version.GetValue(),
+					DownloadURL: pluginDownloadURL.GetValue(),
+				},
+				versionExpr:     version,
+				downloadURLExpr: pluginDownloadURL,
 			}
 		}
 	}
 
-	diags := newRunner(tmpl, nil).Run(walker{
+	var dockerImages []*ast.ResourceDecl
+	visitor := walker{
 		VisitResource: func(r *Runner, node resourceNode) bool {
 			res := node.Value
 
@@ -168,11 +397,19 @@ func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, synt
 				return true
 			}
 			acceptType(r, res.Type.Value, res.Options.Version, res.Options.PluginDownloadURL)
+			if _, found := docker3ResourceNames[res.Type.Value]; found {
+				dockerImages = append(dockerImages, res)
+			}
 
 			return true
 		},
 		VisitExpr: func(ctx *evalContext, expr ast.Expr) bool {
 			if expr, ok := expr.(*ast.InvokeExpr); ok {
+				if expr.Token == nil && expr.TokenRef != nil {
+					// The function token is only known at runtime, so its package can't be
+					// determined ahead of time.
+					return true
+				}
 				if expr.Token == nil {
 					ctx.Runner.sdiags.Extend(syntax.NodeError(expr.Syntax(), "Invoke declared without a 'function' type", ""))
 					return true
@@ -181,23 +418,43 @@ func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, synt
 			}
 			return true
 		},
-	})
+	}
+	diags := newRunner(tmpl, nil).Run(visitor)
+	// The packages used by components are referenced by the template too.
+	for _, entry := range tmpl.Components.Entries {
+		if entry.Value != nil {
+			diags.Extend(newRunner(tmpl.ComponentTemplate(entry.Value), nil).Run(visitor)...)
+		}
+	}
 
 	if diags.HasErrors() {
 		return nil, diags
 	}
+	if docker, ok := packageMap["docker"]; ok {
+		diags.Extend(checkDockerImageVersions(dockerImages, docker.Version)...)
+	}
 
-	var packages []packages.PackageDecl
+	var referenced []packages.PackageDecl
 	for _, pkg := range packageMap {
-		// Skip the built-in pulumi package
-		if pkg.Name == "pulumi" {
+		// Skip the built-in pulumi package, and the components declared by the template
+		if pkg.Name == "pulumi" || pkg.Name == ast.ComponentPackageName {
 			continue
 		}
-		packages = append(packages, *pkg)
+		url, err := packages.ExpandDownloadURL(pkg.DownloadURL, pkg.Version)
+		if err != nil {
+			diags.Extend(ast.ExprError(pkg.downloadURLExpr, err.Error(), ""))
+			continue
+		}
+		decl := *pkg.PackageDecl
+		decl.DownloadURL = url
+		referenced = append(referenced, decl)
+	}
+	if diags.HasErrors() {
+		return nil, diags
 	}
 
-	sort.Slice(packages, func(i, j int) bool {
-		pI, pJ := packages[i], packages[j]
+	sort.Slice(referenced, func(i, j int) bool {
+		pI, pJ := referenced[i], referenced[j]
 		if pI.Name != pJ.Name {
 			return pI.Name < pJ.Name
 		}
@@ -219,7 +476,319 @@ func GetReferencedPackages(tmpl *ast.TemplateDecl) ([]packages.PackageDecl, synt
 		return pI.Parameterization.Version < pJ.Parameterization.Version
 	})
 
-	return packages, nil
+	return referenced, diags
+}
+
+// checkDockerImageVersions warns about Docker Image resources that don't request version 4 or
+// later of the docker package, either with the version resource option or through the version of
+// the package, which is packageVersion. Resolving their types fails unless the version of the
+// package that happens to be installed is recent enough, and this explains how to avoid that
+// before any packages are loaded.
+func checkDockerImageVersions(images []*ast.ResourceDecl, packageVersion string) syntax.Diagnostics {
+	const detail = "Docker Image resources require version 4 or later of the docker package. " +
+		"Request it by adding 'version: 4.0.0' to the resource's options, " +
+		"see https://github.com/pulumi/pulumi-yaml/issues/421"
+
+	var diags syntax.Diagnostics
+	for _, res := range images {
+		version := packageVersion
+		if v := res.Options.Version.GetValue(); v != "" {
+			version = v
+		}
+		var summary string
+		if version == "" {
+			summary = fmt.Sprintf("%s resources should request version 4 or later of the docker package", res.Type.Value)
+		} else if v, err := semver.ParseTolerant(version); err == nil && v.Major <= 3 {
+			summary = fmt.Sprintf("%s resources are not supported by version %s of the docker package", res.Type.Value, version)
+		} else {
+			// Either the version is recent enough, or it is invalid, which is reported elsewhere.
+			continue
+		}
+		d := ast.ExprError(res.Type, summary, detail)
+		d.Severity = hcl.DiagWarning
+		if res.Options.Version != nil {
+			// There's no safe place to add a version that isn't requested, but one can be replaced.
+			suggestReplacement(d, res.Options.Version, "4.0.0")
+		}
+		diags.Extend(d)
+	}
+	return diags
+}
+
+// A PlannedResource is a resource registration that a template would perform.
+type PlannedResource struct {
+	// Name is the logical name of the resource.
+	Name string
+	// Type is the resource type token as written in the template.
+	Type string
+	// Provider is the name of the explicit provider resource, if any. An empty provider means
+	// the default provider for the package is used.
+	Provider string
+	// Package is the package that provides the resource, with its resolved version and
+	// download URL.
+	Package packages.PackageDecl
+}
+
+// A PlannedInvoke is a function invocation that a template would perform.
+type PlannedInvoke struct {
+	// Function is the function token as written in the template.
+	Function string
+	// FunctionRef is the reference to the config value that holds the function token, such as
+	// ${fnToken}, if the function is only known at runtime. Function and Package are empty then.
+	FunctionRef string
+	// Provider is the name of the explicit provider resource, if any.
+	Provider string
+	// Package is the package that provides the function, with its resolved version and
+	// download URL.
+	Package packages.PackageDecl
+}
+
+// A Manifest lists the provider calls that a template would make when run.
+type Manifest struct {
+	Resources []PlannedResource
+	Invokes   []PlannedInvoke
+}
+
+// GetManifest returns the resource registrations and function invocations that the template
+// would perform, without evaluating it. Invokes whose function is only known at runtime are listed
+// with the reference to their function.
+func GetManifest(tmpl *ast.TemplateDecl) (*Manifest, syntax.Diagnostics) {
+	pkgs, diags := GetReferencedPackages(tmpl)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	resolvePackage := func(typeName string) packages.PackageDecl {
+		name := ResolvePkgName(typeName)
+		for _, pkg := range pkgs {
+			if pkg.Name == name || (pkg.Parameterization != nil && pkg.Parameterization.Name == name) {
+				return pkg
+			}
+		}
+		return packages.PackageDecl{Name: name}
+	}
+	providerName := func(expr ast.Expr) string {
+		if symbol, ok := expr.(*ast.SymbolExpr); ok {
+			return symbol.Property.RootName()
+		}
+		return ""
+	}
+
+	manifest := &Manifest{}
+	diags = newRunner(tmpl, nil).Run(walker{
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			res := node.Value
+			manifest.Resources = append(manifest.Resources, PlannedResource{
+				Name:     node.Key.Value,
+				Type:     res.Type.Value,
+				Provider: providerName(res.Options.Provider),
+				Package:  resolvePackage(res.Type.Value),
+			})
+			return true
+		},
+		VisitExpr: func(ctx *evalContext, expr ast.Expr) bool {
+			invoke, ok := expr.(*ast.InvokeExpr)
+			switch {
+			case !ok:
+			case invoke.Token != nil:
+				manifest.Invokes = append(manifest.Invokes, PlannedInvoke{
+					Function: invoke.Token.Value,
+					Provider: providerName(invoke.CallOpts.Provider),
+					Package:  resolvePackage(invoke.Token.Value),
+				})
+			case invoke.TokenRef != nil:
+				manifest.Invokes = append(manifest.Invokes, PlannedInvoke{
+					FunctionRef: "${" + invoke.TokenRef.Property.String() + "}",
+					Provider:    providerName(invoke.CallOpts.Provider),
+				})
+			}
+			return true
+		},
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return manifest, diags
+}
+
+// ResolvedTokens holds the canonical type tokens of the resources and invokes of a template, after
+// aliases are followed and the ':index:' module is expanded the way they are when the template
+// runs.
+type ResolvedTokens struct {
+	// Resources maps the logical name of each resource to the canonical token of its type.
+	Resources map[string]ResourceTypeToken
+	// Functions maps the location of the function token of each invoke, written as
+	// "file:line:column", to the canonical token of its function. Invokes whose function is only
+	// known at runtime are omitted.
+	Functions map[string]FunctionTypeToken
+}
+
+// ResolveTypeTokens resolves the type of each resource and the function of each invoke of the
+// template with the packages that loader loads, including the parameterized packages declared by
+// the template. Tokens that can't be resolved are reported, and are omitted from the result.
+func ResolveTypeTokens(tmpl *ast.TemplateDecl, loader PackageLoader) (*ResolvedTokens, syntax.Diagnostics) {
+	r := newRunner(tmpl, loader)
+	if err := r.setPackageDesciptors(); err != nil {
+		return nil, syntax.Diagnostics{syntax.Error(nil, err.Error(), "")}
+	}
+
+	resolved := &ResolvedTokens{
+		Resources: map[string]ResourceTypeToken{},
+		Functions: map[string]FunctionTypeToken{},
+	}
+	diags := r.Run(walker{
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			res := node.Value
+			if res.Type == nil {
+				return true
+			}
+			version, err := ParseVersion(res.Options.Version)
+			if err != nil {
+				r.sdiags.Extend(ast.ExprError(res.Options.Version,
+					fmt.Sprintf("error parsing version of resource %v: %v", node.Key.Value, err), ""))
+				return true
+			}
+			_, typ, err := ResolveResource(context.TODO(), r.pkgLoader, r.packageDescriptors, res.Type.Value, version)
+			if err != nil {
+				r.sdiags.Extend(ast.ExprError(res.Type, err.Error(), ""))
+				return true
+			}
+			resolved.Resources[node.Key.Value] = typ
+			return true
+		},
+		VisitExpr: func(ctx *evalContext, expr ast.Expr) bool {
+			invoke, ok := expr.(*ast.InvokeExpr)
+			if !ok || invoke.Token == nil || invoke.Token.Syntax() == nil {
+				return true
+			}
+			version, err := ParseVersion(invoke.CallOpts.Version)
+			if err != nil {
+				ctx.Runner.sdiags.Extend(ast.ExprError(invoke.CallOpts.Version,
+					fmt.Sprintf("error parsing version of invoke %v: %v", invoke.Token.Value, err), ""))
+				return true
+			}
+			_, fn, err := ResolveFunction(context.TODO(), ctx.Runner.pkgLoader, ctx.Runner.packageDescriptors,
+				invoke.Token.Value, version)
+			if err != nil {
+				ctx.Runner.sdiags.Extend(ast.ExprError(invoke.Token, err.Error(), ""))
+				return true
+			}
+			rng := invoke.Token.Syntax().Syntax().Range()
+			if rng == nil {
+				return true
+			}
+			resolved.Functions[fmt.Sprintf("%s:%d:%d", rng.Filename, rng.Start.Line, rng.Start.Column)] = fn
+			return true
+		},
+	})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return resolved, diags
+}
+
+// maxConcurrentPackageLoads bounds how many package schemas are loaded at once by preloadPackages.
+const maxConcurrentPackageLoads = 8
+
+// preloadPackages concurrently loads the schemas of all packages referenced by the template, so that
+// the package loader's cache is warm before the template is analysed. Errors are reported once per
+// package, at the first reference to it.
+func (r *Runner) preloadPackages(ctx context.Context) syntax.Diagnostics {
+	pkgs, diags := GetReferencedPackages(r.t)
+	if diags.HasErrors() {
+		// The template is invalid. Type checking reports the problems in context.
+		return nil
+	}
+
+	type result struct {
+		name  string
+		label string
+		err   error
+	}
+	results := make([]result, len(pkgs))
+	sem := make(chan struct{}, maxConcurrentPackageLoads)
+	var wg sync.WaitGroup
+	for i, pkg := range pkgs {
+		name := pkg.Name
+		if pkg.Parameterization != nil {
+			name = pkg.Parameterization.Name
+		}
+		// Build the same descriptor that loadPackage would, without mutating the runner's.
+		descriptor := &schema.PackageDescriptor{Name: name}
+		if d, ok := r.packageDescriptors[tokens.Package(name)]; ok {
+			copy := *d
+			descriptor = &copy
+		}
+		label := name
+		if pkg.Version != "" {
+			version, err := semver.ParseTolerant(pkg.Version)
+			if err != nil {
+				// Invalid versions are reported when the template is analysed.
+				continue
+			}
+			if pkg.Parameterization == nil {
+				descriptor.Version = &version
+			}
+			label = fmt.Sprintf("%s@%s", name, pkg.Version)
+		}
+
+		wg.Add(1)
+		go func(i int, name, label string, descriptor *schema.PackageDescriptor) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, err := r.pkgLoader.LoadPackage(ctx, descriptor)
+			results[i] = result{name, label, err}
+		}(i, name, label, descriptor)
+	}
+	wg.Wait()
+
+	var references map[string]*ast.StringExpr
+	for _, result := range results {
+		if result.err == nil {
+			continue
+		}
+		if references == nil {
+			references = firstPackageReferences(r.t)
+		}
+		var rng *hcl.Range
+		if token, ok := references[result.name]; ok && token.Syntax() != nil {
+			rng = token.Syntax().Syntax().Range()
+		}
+		diags.Extend(syntax.Error(rng, fmt.Sprintf("error loading package %s", result.label), result.err.Error()))
+	}
+	return diags
+}
+
+// firstPackageReferences returns the type token of the resource or invoke that refers to each
+// package first in the template, by package name.
+func firstPackageReferences(t *ast.TemplateDecl) map[string]*ast.StringExpr {
+	first := map[string]*ast.StringExpr{}
+	accept := func(token *ast.StringExpr) {
+		if token == nil || token.Syntax() == nil || token.Syntax().Syntax().Range() == nil {
+			return
+		}
+		pkg := ResolvePkgName(token.Value)
+		if prev, ok := first[pkg]; ok {
+			prevStart, start := prev.Syntax().Syntax().Range().Start, token.Syntax().Syntax().Range().Start
+			if prevStart.Line < start.Line || prevStart.Line == start.Line && prevStart.Column <= start.Column {
+				return
+			}
+		}
+		first[pkg] = token
+	}
+	newRunner(t, nil).Run(walker{
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			accept(node.Value.Type)
+			return true
+		},
+		VisitExpr: func(ctx *evalContext, expr ast.Expr) bool {
+			if invoke, ok := expr.(*ast.InvokeExpr); ok {
+				accept(invoke.Token)
+			}
+			return true
+		},
+	})
+	return first
 }
 
 func ResolvePkgName(typeString string) string {
@@ -233,13 +802,23 @@ func ResolvePkgName(typeString string) string {
 	return typeParts[0]
 }
 
+// splitTypeToken splits a type token into its segments. Type tokens are usually of the form
+// `$pkg:$module:$member`, or `$pkg:$member` as shorthand for `$pkg:index:$member`. Some schemas
+// use a fourth segment, which is accepted as long as the token matches the schema exactly.
+func splitTypeToken(typeName string) ([]string, error) {
+	typeParts := strings.Split(typeName, ":")
+	if len(typeParts) < 2 || len(typeParts) > 4 {
+		return nil, fmt.Errorf("invalid type token %q", typeName)
+	}
+	return typeParts, nil
+}
+
 func loadPackage(
 	ctx context.Context, loader PackageLoader,
 	descriptors map[tokens.Package]*schema.PackageDescriptor, typeString string, version *semver.Version,
 ) (Package, error) {
-	typeParts := strings.Split(typeString, ":")
-	if len(typeParts) < 2 || len(typeParts) > 3 {
-		return nil, fmt.Errorf("invalid type token %q", typeString)
+	if _, err := splitTypeToken(typeString); err != nil {
+		return nil, err
 	}
 
 	packageName := ResolvePkgName(typeString)
@@ -279,6 +858,11 @@ var kubernetesResourceNames = map[string]string{
 	"kubernetes:yaml:ConfigGroup":                    "https://github.com/pulumi/pulumi-kubernetes/issues/1971",
 }
 
+func unsupportedKubernetesResource(typeString string) error {
+	return fmt.Errorf("The resource type [%v] is not supported in YAML at this time, see: %v",
+		typeString, kubernetesResourceNames[typeString])
+}
+
 var helmResourceNames = map[string]struct{}{
 	"kubernetes:helm.sh/v2:Chart": {},
 	"kubernetes:helm.sh/v3:Chart": {},
@@ -290,8 +874,8 @@ var helmResourceNames = map[string]struct{}{
 func ResolveResource(ctx context.Context, loader PackageLoader,
 	descriptors map[tokens.Package]*schema.PackageDescriptor,
 	typeString string, version *semver.Version) (Package, ResourceTypeToken, error) {
-	if issue, found := kubernetesResourceNames[typeString]; found {
-		return nil, "", fmt.Errorf("The resource type [%v] is not supported in YAML at this time, see: %v", typeString, issue)
+	if _, found := kubernetesResourceNames[typeString]; found {
+		return nil, "", unsupportedKubernetesResource(typeString)
 	}
 
 	if _, found := helmResourceNames[typeString]; found {
@@ -359,9 +943,9 @@ func (p resourcePackage) resolveProvider(typeName string) (ResourceTypeToken, bo
 }
 
 func resolveToken(typeName string, resolve func(string) (string, bool, error)) (string, bool, error) {
-	typeParts := strings.Split(typeName, ":")
-	if len(typeParts) < 2 || len(typeParts) > 3 {
-		return "", false, fmt.Errorf("invalid type token %q", typeName)
+	typeParts, err := splitTypeToken(typeName)
+	if err != nil {
+		return "", false, err
 	}
 
 	if token, found, err := resolve(typeName); found {
@@ -398,6 +982,98 @@ func resolveToken(typeName string, resolve func(string) (string, bool, error)) (
 	return "", false, nil
 }
 
+// IndexExpansion controls whether type tokens of the form `pkg:Type`, which are expanded to
+// `pkg:index:Type`, are reported. See WithIndexExpansion.
+type IndexExpansion int
+
+const (
+	// AllowIndexExpansion accepts `pkg:Type` as shorthand for the canonical token.
+	AllowIndexExpansion IndexExpansion = iota
+	// WarnIndexExpansion warns about `pkg:Type`.
+	WarnIndexExpansion
+	// RejectIndexExpansion reports `pkg:Type` as an error.
+	RejectIndexExpansion
+)
+
+// PULUMI_YAML_STRICT_TOKENS is "warn" to warn about type tokens that leave out the index module,
+// or true to reject them. It is the default for runners that aren't given WithIndexExpansion.
+var defaultIndexExpansion = parseIndexExpansion(os.Getenv("PULUMI_YAML_STRICT_TOKENS"))
+
+func parseIndexExpansion(s string) IndexExpansion {
+	switch {
+	case strings.EqualFold(s, "warn"):
+		return WarnIndexExpansion
+	case strings.EqualFold(s, "error") || cmdutil.IsTruthy(s):
+		return RejectIndexExpansion
+	}
+	return AllowIndexExpansion
+}
+
+// checkIndexExpansion reports the type token token, which resolved to canonical, if it relied on
+// the implicit expansion of `pkg:Type` to `pkg:index:Type` and the runner doesn't allow that.
+func (ctx *evalContext) checkIndexExpansion(token *ast.StringExpr, canonical string) {
+	if ctx.indexExpansion == AllowIndexExpansion || token.Value == canonical {
+		return
+	}
+	if parts, err := splitTypeToken(token.Value); err != nil || len(parts) != 2 {
+		return
+	}
+	var rng *hcl.Range
+	if token.Syntax() != nil {
+		rng = token.Syntax().Syntax().Range()
+	}
+	summary := fmt.Sprintf("type token %q leaves out the module; use the canonical token %q", token.Value, canonical)
+	detail := "Tokens of the form pkg:Type are only expanded to pkg:index:Type when strict tokens, " +
+		"such as PULUMI_YAML_STRICT_TOKENS, aren't enabled"
+	diag := syntax.Warning(rng, summary, detail)
+	if ctx.indexExpansion == RejectIndexExpansion {
+		diag = syntax.Error(rng, summary, detail)
+	}
+	suggestReplacement(diag, token, canonical)
+	ctx.addDiag(diag)
+}
+
+// resolveAliasType normalizes the type token of an alias of a resource of type typ. The old type
+// usually no longer exists in the package, so it is matched against the aliases declared by the
+// resource's schema, and otherwise expanded to the same token style as typ: `pkg:Type` becomes
+// `pkg:index:Type`, and `pkg:module:Type` becomes `pkg:module/type:Type` for classic providers.
+func resolveAliasType(pkg Package, typ ResourceTypeToken, typeName string) (string, error) {
+	if _, err := splitTypeToken(typeName); err != nil {
+		return "", err
+	}
+	if ResolvePkgName(typeName) != ResolvePkgName(typ.String()) {
+		return typeName, nil
+	}
+
+	var aliases []*schema.Alias
+	if pkg != nil {
+		if hint := pkg.ResourceTypeHint(typ); hint != nil && hint.Resource != nil {
+			aliases = hint.Resource.Aliases
+		}
+	}
+	isClassic := func(tk string) bool {
+		parts := strings.Split(tk, ":")
+		return len(parts) == 3 && strings.Contains(parts[1], "/")
+	}
+	tk, ok, err := resolveToken(typeName, func(tk string) (string, bool, error) {
+		for _, alias := range aliases {
+			if alias.Type != nil && *alias.Type == tk {
+				return tk, true, nil
+			}
+		}
+		if len(strings.Split(tk, ":")) > 2 && isClassic(tk) == isClassic(typ.String()) {
+			return tk, true, nil
+		}
+		return "", false, nil
+	})
+	if err != nil {
+		return "", err
+	} else if !ok {
+		return typeName, nil
+	}
+	return tk, nil
+}
+
 func (p resourcePackage) ResolveResource(typeName string) (ResourceTypeToken, error) {
 	if tk, ok := p.resolveProvider(typeName); ok {
 		return tk, nil
@@ -422,11 +1098,6 @@ func (p resourcePackage) ResolveResource(typeName string) (ResourceTypeToken, er
 }
 
 func (p resourcePackage) ResolveFunction(typeName string) (FunctionTypeToken, error) {
-	typeParts := strings.Split(typeName, ":")
-	if len(typeParts) < 2 || len(typeParts) > 3 {
-		return "", fmt.Errorf("invalid type token %q", typeName)
-	}
-
 	tk, ok, err := resolveToken(typeName, func(tk string) (string, bool, error) {
 		if fn, found, err := p.Functions().Get(tk); found {
 			return fn.Token, true, nil
@@ -545,3 +1216,11 @@ func newResourcePackageHost(plugins *workspace.Plugins) (plugin.Host, error) {
 
 	return pluginCtx.Host, nil
 }
+
+// exprRange returns the source range of x, if it has one.
+func exprRange(x ast.Expr) *hcl.Range {
+	if x == nil || x.Syntax() == nil || x.Syntax().Syntax() == nil {
+		return nil
+	}
+	return x.Syntax().Syntax().Range()
+}
@@ -0,0 +1,41 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package packages
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+)
+
+var downloadURLPlaceholder = regexp.MustCompile(`\$\{[^}]*\}`)
+
+// ExpandDownloadURL substitutes the placeholders in a plugin download URL: ${version} with the
+// version of the package, and ${os} and ${arch} with the platform of the host. URLs without
+// placeholders are returned as-is. It is an error for the URL to contain ${version} when version
+// is empty, or to contain any other placeholder.
+func ExpandDownloadURL(url, version string) (string, error) {
+	var err error
+	expanded := downloadURLPlaceholder.ReplaceAllStringFunc(url, func(placeholder string) string {
+		switch placeholder {
+		case "${version}":
+			if version == "" && err == nil {
+				err = fmt.Errorf("plugin download URL %q uses ${version}, but the package's version isn't known", url)
+			}
+			return version
+		case "${os}":
+			return runtime.GOOS
+		case "${arch}":
+			return runtime.GOARCH
+		}
+		if err == nil {
+			err = fmt.Errorf("plugin download URL %q has an unknown placeholder %s; expected ${version}, ${os} or ${arch}",
+				url, placeholder)
+		}
+		return placeholder
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package packages
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandDownloadURL(t *testing.T) {
+	t.Parallel()
+
+	url, err := ExpandDownloadURL("https://mirror.example.com/releases", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com/releases", url)
+
+	url, err = ExpandDownloadURL("https://mirror.example.com/v${version}/${os}-${arch}", "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com/v1.2.3/"+runtime.GOOS+"-"+runtime.GOARCH, url)
+
+	_, err = ExpandDownloadURL("https://mirror.example.com/${version}", "")
+	assert.EqualError(t, err,
+		`plugin download URL "https://mirror.example.com/${version}" uses ${version}, but the package's version isn't known`)
+
+	_, err = ExpandDownloadURL("https://mirror.example.com/${platform}", "1.2.3")
+	assert.EqualError(t, err, `plugin download URL "https://mirror.example.com/${platform}" has an unknown placeholder `+
+		"${platform}; expected ${version}, ${os} or ${arch}")
+}
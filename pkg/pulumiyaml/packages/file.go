@@ -4,11 +4,14 @@
 package packages
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/blang/semver"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
@@ -44,10 +47,30 @@ type PackageDecl struct {
 	Name string `yaml:"name"`
 	// Version is the version of the plugin.
 	Version string `yaml:"version,omitempty"`
-	// PluginDownloadURL is the URL to download the plugin from.
+	// PluginDownloadURL is the URL to download the plugin from. It may contain ${version}, ${os} and ${arch},
+	// see ExpandDownloadURL.
 	DownloadURL string `yaml:"downloadUrl,omitempty"`
 	// Parameterization is the parameterization of the package.
 	Parameterization *ParameterizationDecl `yaml:"parameterization,omitempty"`
+	// Checksums are the hex encoded SHA-256 checksums of the plugin's archive for each platform, keyed by
+	// "os-arch" (e.g. "linux-amd64"). When set, the plugin fails to install if its download doesn't match.
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+}
+
+// GetChecksums returns the checksums of the plugin's archive as byte arrays, or nil if there are none.
+func (p *PackageDecl) GetChecksums() (map[string][]byte, error) {
+	if len(p.Checksums) == 0 {
+		return nil, nil
+	}
+	checksums := make(map[string][]byte, len(p.Checksums))
+	for platform, checksum := range p.Checksums {
+		value, err := hex.DecodeString(checksum)
+		if err != nil {
+			return nil, fmt.Errorf("decoding checksum for %s: %w", platform, err)
+		}
+		checksums[platform] = value
+	}
+	return checksums, nil
 }
 
 // Validate checks if a package declaration is valid. The first return value is a boolean indicating if the package declaration is even a
@@ -73,6 +96,16 @@ func (p *PackageDecl) Validate() (bool, error) {
 		}
 	}
 
+	// Checksums must be SHA-256 hashes of the archive for a platform.
+	for platform, checksum := range p.Checksums {
+		if goos, goarch, ok := strings.Cut(platform, "-"); !ok || goos == "" || goarch == "" {
+			return true, fmt.Errorf("checksum platform %q must be of the form os-arch", platform)
+		}
+		if value, err := hex.DecodeString(checksum); err != nil || len(value) != sha256.Size {
+			return true, fmt.Errorf("checksum for %s must be a hex encoded SHA-256 hash, got %q", platform, checksum)
+		}
+	}
+
 	return true, nil
 }
 
@@ -159,10 +192,15 @@ func ToPackageDescriptors(packages []PackageDecl) (map[tokens.Package]*schema.Pa
 			version = &v
 		}
 
+		downloadURL, err := ExpandDownloadURL(pkg.DownloadURL, pkg.Version)
+		if err != nil {
+			return nil, fmt.Errorf("expanding download URL for package %s: %w", name, err)
+		}
+
 		packageDescriptors[tokens.Package(name)] = &schema.PackageDescriptor{
 			Name:             pkg.Name,
 			Version:          version,
-			DownloadURL:      pkg.DownloadURL,
+			DownloadURL:      downloadURL,
 			Parameterization: parameterization,
 		}
 	}
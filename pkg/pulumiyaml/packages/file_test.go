@@ -5,6 +5,7 @@ package packages
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -51,3 +52,43 @@ func TestSearchPackageLocks_BadParam(t *testing.T) {
 	_, err := SearchPackageDecls("testdata/bad_param")
 	require.ErrorContains(t, err, "validating testdata/bad_param/bad_param.yaml: parameterization version is required")
 }
+
+func TestSearchPackageLocks_Checksums(t *testing.T) {
+	t.Parallel()
+
+	decls, err := SearchPackageDecls("testdata/checksums")
+	require.NoError(t, err)
+	require.Len(t, decls, 1)
+	assert.Equal(t, map[string]string{
+		"linux-amd64":  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"darwin-arm64": "5feceb66ffc86f38d952786c6d696c79c2dbc239dd4e91b46729d73a27fb57e9",
+	}, decls[0].Checksums)
+}
+
+func TestSearchPackageLocks_BadChecksum(t *testing.T) {
+	t.Parallel()
+
+	_, err := SearchPackageDecls("testdata/bad_checksum")
+	require.ErrorContains(t, err, "validating testdata/bad_checksum/bad_checksum.yaml: "+
+		`checksum for linux-amd64 must be a hex encoded SHA-256 hash, got "da39a3ee5e6b4b0d3255bfef95601890afd80709"`)
+}
+
+func TestPackageDeclChecksums(t *testing.T) {
+	t.Parallel()
+
+	decl := PackageDecl{PackageDeclarationVersion: 1, Name: "pkg"}
+	checksums, err := decl.GetChecksums()
+	require.NoError(t, err)
+	require.Nil(t, checksums)
+
+	decl.Checksums = map[string]string{"linux": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}
+	_, err = decl.Validate()
+	require.EqualError(t, err, `checksum platform "linux" must be of the form os-arch`)
+
+	decl.Checksums = map[string]string{"darwin-arm64": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}
+	_, err = decl.Validate()
+	require.NoError(t, err)
+	checksums, err = decl.GetChecksums()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xe3, 0xb0, 0xc4, 0x42}, checksums["darwin-arm64"][:4])
+}
@@ -0,0 +1,542 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/packages"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryPackageLoader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.2.0", "2.0.0"} {
+		spec := fmt.Sprintf(`{
+  "name": "test",
+  "version": %q,
+  "resources": {
+    "test:index:Resource": {
+      "inputProperties": {"foo": {"type": "string"}}
+    }
+  }
+}`, version)
+		err := os.WriteFile(filepath.Join(dir, "test-"+version+".json"), []byte(spec), 0o600)
+		require.NoError(t, err)
+	}
+
+	loader := NewDirectoryPackageLoader(dir)
+	defer loader.Close()
+
+	cases := []struct {
+		version  string
+		expected string
+		err      string
+	}{
+		{expected: "2.0.0"},
+		{version: "1.0.0", expected: "1.2.0"},
+		{version: "1.2.0", expected: "1.2.0"},
+		{version: "2.0.0", expected: "2.0.0"},
+		{version: "1.3.0", err: "no schema for package test satisfying version 1.3.0 found in " + dir},
+		{version: "3.0.0", err: "no schema for package test satisfying version 3.0.0 found in " + dir},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.version, func(t *testing.T) {
+			t.Parallel()
+
+			descriptor := &schema.PackageDescriptor{Name: "test"}
+			if c.version != "" {
+				v := semver.MustParse(c.version)
+				descriptor.Version = &v
+			}
+			pkg, err := loader.LoadPackage(context.Background(), descriptor)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, pkg.Version().String())
+
+			token, err := pkg.ResolveResource("test:Resource")
+			require.NoError(t, err)
+			assert.Equal(t, ResourceTypeToken("test:index:Resource"), token)
+		})
+	}
+
+	_, err := loader.LoadPackage(context.Background(), &schema.PackageDescriptor{Name: "other"})
+	assert.EqualError(t, err, "no schema for package other found in "+dir)
+}
+
+func TestResolveLongTypeTokens(t *testing.T) {
+	t.Parallel()
+
+	// Schemas bound by pulumi reject tokens with more than three segments, but other Package
+	// implementations may use them.
+	known := map[string]bool{
+		"test:index:Resource":         true,
+		"test:nested:module:Resource": true,
+		"test:nested:module:getThing": true,
+	}
+	resolve := func(tk string) (string, bool, error) {
+		return tk, known[tk], nil
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"test": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				tk, ok, err := resolveToken(typeName, resolve)
+				if err != nil || !ok {
+					return "", fmt.Errorf("unable to resolve %q: %v", typeName, err)
+				}
+				return ResourceTypeToken(tk), nil
+			},
+		},
+	}}
+	ctx := context.Background()
+	descriptors := map[tokens.Package]*schema.PackageDescriptor{}
+
+	_, res, err := ResolveResource(ctx, loader, descriptors, "test:nested:module:Resource", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ResourceTypeToken("test:nested:module:Resource"), res)
+
+	_, res, err = ResolveResource(ctx, loader, descriptors, "test:Resource", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ResourceTypeToken("test:index:Resource"), res)
+
+	tk, ok, err := resolveToken("test:nested:module:getThing", resolve)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "test:nested:module:getThing", tk)
+
+	_, ok, err = resolveToken("test:nested:module:Missing", resolve)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, token := range []string{"test", "test:a:b:c:Resource"} {
+		_, _, err = ResolveResource(ctx, loader, descriptors, token, nil)
+		assert.EqualError(t, err, fmt.Sprintf("invalid type token %q", token))
+		_, _, err = resolveToken(token, resolve)
+		assert.EqualError(t, err, fmt.Sprintf("invalid type token %q", token))
+	}
+}
+
+type failingPackageLoader struct {
+	errs     []error
+	attempts int
+}
+
+func (l *failingPackageLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	l.attempts++
+	if l.attempts <= len(l.errs) {
+		return nil, l.errs[l.attempts-1]
+	}
+	return MockPackage{}, nil
+}
+
+func (l *failingPackageLoader) Close() {}
+
+func TestRetryingPackageLoader(t *testing.T) {
+	t.Parallel()
+
+	transient := &net.OpError{Op: "dial", Err: syscall.ECONNRESET}
+	cases := []struct {
+		name     string
+		errs     []error
+		attempts int
+		delays   []time.Duration
+		err      string
+	}{
+		{
+			name:     "succeeds after retries",
+			errs:     []error{transient, transient},
+			attempts: 3,
+			delays:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:     "gives up after max retries",
+			errs:     []error{transient, transient, transient, transient},
+			attempts: 4,
+			delays:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+			err:      "dial: connection reset by peer",
+		},
+		{
+			name:     "schema not implemented",
+			errs:     []error{fmt.Errorf("loading: %w", schema.ErrGetSchemaNotImplemented)},
+			attempts: 1,
+			err:      "loading: it looks like GetSchema is not implemented",
+		},
+		{
+			name:     "plugin not found",
+			errs:     []error{workspace.NewMissingError(apitype.ResourcePlugin, "test", nil, false)},
+			attempts: 1,
+			err:      "no resource plugin 'pulumi-resource-test' found in the workspace",
+		},
+		{
+			name:     "not transient",
+			errs:     []error{errors.New("invalid schema")},
+			attempts: 1,
+			err:      "invalid schema",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var delays []time.Duration
+			failing := &failingPackageLoader{errs: c.errs}
+			loader := NewRetryingPackageLoader(failing, RetryPolicy{
+				MaxRetries:   3,
+				InitialDelay: 100 * time.Millisecond,
+				MaxDelay:     300 * time.Millisecond,
+				Sleep: func(ctx context.Context, d time.Duration) error {
+					delays = append(delays, d)
+					return nil
+				},
+			})
+
+			pkg, err := loader.LoadPackage(context.Background(), &schema.PackageDescriptor{Name: "test"})
+			assert.Equal(t, c.attempts, failing.attempts)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, pkg)
+			}
+			require.Len(t, delays, len(c.delays))
+			for i, d := range delays {
+				// Each delay has up to half of the base delay added as jitter.
+				assert.GreaterOrEqual(t, d, c.delays[i])
+				assert.LessOrEqual(t, d, c.delays[i]+c.delays[i]/2)
+			}
+		})
+	}
+}
+
+// slowPackageLoader simulates loading schemas from plugins: each load takes a while, and loaded
+// packages are cached.
+type slowPackageLoader struct {
+	delay time.Duration
+
+	m     sync.Mutex
+	cache map[string]Package
+	loads int
+}
+
+func (l *slowPackageLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	l.m.Lock()
+	if pkg, ok := l.cache[descriptor.Name]; ok {
+		l.m.Unlock()
+		return pkg, nil
+	}
+	l.m.Unlock()
+
+	time.Sleep(l.delay)
+	if strings.HasPrefix(descriptor.Name, "missing") {
+		return nil, fmt.Errorf("plugin %s not found", descriptor.Name)
+	}
+	pkg := MockPackage{
+		resourceTypeHint: func(typeName string) *schema.ResourceType {
+			return inputProperties(typeName)
+		},
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.cache == nil {
+		l.cache = map[string]Package{}
+	}
+	l.cache[descriptor.Name] = pkg
+	l.loads++
+	return pkg, nil
+}
+
+func (l *slowPackageLoader) Close() {}
+
+func fiveProviderTemplate(t testing.TB, names ...string) *ast.TemplateDecl {
+	text := "name: test-yaml\nruntime: yaml\nresources:\n"
+	for i, name := range names {
+		text += fmt.Sprintf("  res%d:\n    type: %s:index:Resource\n", i, name)
+	}
+	tmpl, diags, err := LoadYAMLBytes("<stdin>", []byte(text))
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return tmpl
+}
+
+func TestPreloadPackages(t *testing.T) {
+	t.Parallel()
+
+	tmpl := fiveProviderTemplate(t, "p1", "p2", "p3", "p4", "p5")
+	loader := &slowPackageLoader{}
+	_, diags, err := PrepareTemplate(tmpl, nil, loader)
+	require.NoError(t, err)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, 5, loader.loads)
+}
+
+func TestPreloadPackagesErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := fiveProviderTemplate(t, "p1", "missing1", "p3", "missing2")
+	_, diags, err := PrepareTemplate(tmpl, nil, &slowPackageLoader{})
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "<stdin>:7:11: error loading package missing1; plugin missing1 not found", diagString(diags[0]))
+	assert.Equal(t, "<stdin>:11:11: error loading package missing2; plugin missing2 not found", diagString(diags[1]))
+}
+
+func TestPreloadPackagesErrorRange(t *testing.T) {
+	t.Parallel()
+
+	// The error is reported at the first reference to the package, whether a resource or an invoke.
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: missing:index:Resource
+    properties:
+      foo: ${lookup}
+variables:
+  lookup:
+    fn::invoke:
+      function: missing:index:getThing
+`)
+	_, diags, err := PrepareTemplate(tmpl, nil, &slowPackageLoader{})
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "<stdin>:6:11: error loading package missing; plugin missing not found", diagString(diags[0]))
+}
+
+func BenchmarkPackageLoading(b *testing.B) {
+	tmpl := fiveProviderTemplate(b, "p1", "p2", "p3", "p4", "p5")
+	const delay = 10 * time.Millisecond
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := newRunner(tmpl, &slowPackageLoader{delay: delay})
+			_, diags := TypeCheck(r)
+			require.False(b, diags.HasErrors(), diags.Error())
+		}
+	})
+	b.Run("preloaded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := newRunner(tmpl, &slowPackageLoader{delay: delay})
+			diags := r.preloadPackages(context.Background())
+			require.False(b, diags.HasErrors(), diags.Error())
+			_, diags = TypeCheck(r)
+			require.False(b, diags.HasErrors(), diags.Error())
+		}
+	})
+}
+
+func TestResolveAliasType(t *testing.T) {
+	t.Parallel()
+
+	pkg := MockPackage{
+		resourceTypeHint: func(typeName string) *schema.ResourceType {
+			hint := inputProperties(typeName)
+			hint.Resource.Aliases = []*schema.Alias{{Type: ptr("aws:legacy:Bucket")}}
+			return hint
+		},
+	}
+	cases := []struct {
+		typ, alias, expected string
+	}{
+		{"aws:storage/bucket:Bucket", "aws:s3:Bucket", "aws:s3/bucket:Bucket"},
+		{"aws:storage/bucket:Bucket", "aws:s3/bucket:Bucket", "aws:s3/bucket:Bucket"},
+		{"aws:storage/bucket:Bucket", "aws:legacy:Bucket", "aws:legacy:Bucket"},
+		{"test:index:Resource", "test:OldResource", "test:index:OldResource"},
+		{"test:index:Resource", "test:old:Resource", "test:old:Resource"},
+		{"test:index:Resource", "other:index:Resource", "other:index:Resource"},
+	}
+	for _, c := range cases {
+		actual, err := resolveAliasType(pkg, ResourceTypeToken(c.typ), c.alias)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, actual, "alias %q of %q", c.alias, c.typ)
+	}
+
+	_, err := resolveAliasType(pkg, "test:index:Resource", "Resource")
+	assert.EqualError(t, err, `invalid type token "Resource"`)
+}
+
+func TestIndexExpansion(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-tokens
+runtime: yaml
+resources:
+  short:
+    type: strict:Thing
+  canonical:
+    type: strict:index:Thing
+variables:
+  thing:
+    fn::invoke:
+      function: strict:getThing
+      return: name
+`)
+	expand := func(typeName string) string {
+		if parts := strings.Split(typeName, ":"); len(parts) == 2 {
+			return parts[0] + ":index:" + parts[1]
+		}
+		return typeName
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"strict": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				return ResourceTypeToken(expand(typeName)), nil
+			},
+			resolveFunction: func(typeName string) (FunctionTypeToken, error) {
+				return FunctionTypeToken(expand(typeName)), nil
+			},
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+			functionTypeHint: func(typeName string) *schema.Function {
+				return function(typeName, nil, []schema.Property{{Name: "name", Type: schema.StringType}})
+			},
+		},
+	}}
+
+	// By default, the expansion is allowed.
+	_, diags := TypeCheck(newRunner(tmpl, loader))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+
+	for _, mode := range []IndexExpansion{WarnIndexExpansion, RejectIndexExpansion} {
+		_, diags := TypeCheck(NewRunner(tmpl, loader, WithIndexExpansion(mode)))
+		assert.Equal(t, mode == RejectIndexExpansion, diags.HasErrors())
+		var summaries []string
+		for _, d := range diags {
+			summaries = append(summaries, fmt.Sprintf("%v:%v: %s", d.Subject.Start.Line, d.Subject.Start.Column, d.Summary))
+		}
+		assert.ElementsMatch(t, []string{
+			`6:11: type token "strict:Thing" leaves out the module; use the canonical token "strict:index:Thing"`,
+			`12:17: type token "strict:getThing" leaves out the module; use the canonical token "strict:index:getThing"`,
+		}, summaries)
+	}
+}
+
+func TestParseIndexExpansion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, AllowIndexExpansion, parseIndexExpansion(""))
+	assert.Equal(t, AllowIndexExpansion, parseIndexExpansion("false"))
+	assert.Equal(t, WarnIndexExpansion, parseIndexExpansion("warn"))
+	assert.Equal(t, RejectIndexExpansion, parseIndexExpansion("true"))
+	assert.Equal(t, RejectIndexExpansion, parseIndexExpansion("error"))
+}
+
+// parameterizedPackageLoader loads the package "param", which is the package "base" with the
+// parameter "value".
+type parameterizedPackageLoader struct {
+	MockPackageLoader
+}
+
+func (l parameterizedPackageLoader) LoadPackage(ctx context.Context, descriptor *schema.PackageDescriptor) (Package, error) {
+	if descriptor.Name == "param" {
+		return nil, errors.New("package param is only available with its parameterization")
+	}
+	p := descriptor.Parameterization
+	if descriptor.Name != "base" || p == nil || p.Name != "param" || string(p.Value) != "value" {
+		return l.MockPackageLoader.LoadPackage(ctx, descriptor)
+	}
+	known := map[string]bool{
+		"param:index:Thing":    true,
+		"param:index:getThing": true,
+	}
+	resolve := func(tk string) (string, bool, error) {
+		return tk, known[tk], nil
+	}
+	return MockPackage{
+		resolveResource: func(typeName string) (ResourceTypeToken, error) {
+			tk, ok, err := resolveToken(typeName, resolve)
+			if err != nil || !ok {
+				return "", fmt.Errorf("unable to find resource type %q", typeName)
+			}
+			return ResourceTypeToken(tk), nil
+		},
+		resolveFunction: func(typeName string) (FunctionTypeToken, error) {
+			tk, ok, err := resolveToken(typeName, resolve)
+			if err != nil || !ok {
+				return "", fmt.Errorf("unable to find function %q", typeName)
+			}
+			return FunctionTypeToken(tk), nil
+		},
+	}, nil
+}
+
+func TestResolveTypeTokens(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-resolve
+runtime: yaml
+variables:
+  thing:
+    fn::invoke:
+      function: param:getThing
+resources:
+  param:
+    type: param:Thing
+  plain:
+    type: test:resource:type
+  parameterized:
+    type: param:index:Thing
+`
+	param := &packages.ParameterizationDecl{Name: "param", Version: "2.0.0"}
+	param.SetValue([]byte("value"))
+	tmpl := yamlTemplate(t, text)
+	tmpl.Packages = []packages.PackageDecl{{Name: "base", Version: "1.0.0", Parameterization: param}}
+
+	loader := parameterizedPackageLoader{newMockPackageMap().(MockPackageLoader)}
+	resolved, diags := ResolveTypeTokens(tmpl, loader)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, map[string]ResourceTypeToken{
+		"param":         "param:index:Thing",
+		"plain":         testResourceToken,
+		"parameterized": "param:index:Thing",
+	}, resolved.Resources)
+	assert.Equal(t, map[string]FunctionTypeToken{
+		"<stdin>:7:17": "param:index:getThing",
+	}, resolved.Functions)
+
+	// Without the parameterization, the package can't be loaded.
+	tmpl = yamlTemplate(t, text)
+	_, diags = ResolveTypeTokens(tmpl, loader)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diagStrings(diags)[0], "package param is only available with its parameterization")
+
+	tmpl = yamlTemplate(t, `
+name: test-resolve
+runtime: yaml
+resources:
+  missing:
+    type: param:Missing
+`)
+	tmpl.Packages = []packages.PackageDecl{{Name: "base", Version: "1.0.0", Parameterization: param}}
+	_, diags = ResolveTypeTokens(tmpl, loader)
+	assert.Equal(t, []string{`<stdin>:6:11: unable to find resource type "param:Missing"`}, diagStrings(diags))
+}
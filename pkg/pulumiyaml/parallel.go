@@ -0,0 +1,172 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// PULUMI_YAML_PARALLELISM bounds the number of independent variables that are evaluated at once.
+// Variables that call invokes wait on providers, so they are worth overlapping. It defaults to
+// maxParallelism, and 1 evaluates variables one at a time.
+var defaultParallelism = parseParallelism(os.Getenv("PULUMI_YAML_PARALLELISM"))
+
+// maxParallelism is the number of variables that are evaluated at once by default. Most of the
+// time is spent waiting on providers rather than on the CPU, so it doesn't depend on the number of
+// CPUs.
+const maxParallelism = 8
+
+func parseParallelism(s string) int {
+	if s == "" {
+		return maxParallelism
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// A variableBatchEvaluator evaluates a batch of variables that are consecutive in the sorted
+// template, rather than one at a time.
+type variableBatchEvaluator interface {
+	EvalVariables(r *Runner, nodes []variableNode) bool
+}
+
+// variableBatch returns the variables at the start of nodes, up to the first node that isn't a
+// variable.
+func variableBatch(nodes []graphNode) []variableNode {
+	var batch []variableNode
+	for _, n := range nodes {
+		v, ok := n.(variableNode)
+		if !ok {
+			break
+		}
+		batch = append(batch, v)
+	}
+	return batch
+}
+
+// variableLevels groups a batch of sorted variables into levels, such that each variable only
+// depends on variables in earlier levels or outside the batch. The variables of a level keep their
+// order in the batch.
+func variableLevels(batch []variableNode) [][]variableNode {
+	level := make(map[string]int, len(batch))
+	var levels [][]variableNode
+	for _, node := range batch {
+		l := 0
+		for _, dep := range GetVariableDependencies(ast.VariablesMapEntry(node)) {
+			if d, ok := level[dep.Value]; ok && d+1 > l {
+				l = d + 1
+			}
+		}
+		level[node.Key.Value] = l
+		if l == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[l] = append(levels[l], node)
+	}
+	return levels
+}
+
+// EvalVariables evaluates the independent variables of each level of the batch concurrently. Their
+// values and diagnostics are recorded in the order of the batch once the level is done, so that
+// the result is the same as evaluating them one at a time.
+func (e programEvaluator) EvalVariables(r *Runner, nodes []variableNode) bool {
+	if r.parallelism <= 1 || len(nodes) == 1 {
+		for _, node := range nodes {
+			if !e.EvalVariable(r, node) {
+				return false
+			}
+		}
+		return true
+	}
+
+	held := make(map[string]*pendingDiags, len(nodes))
+	for _, level := range variableLevels(nodes) {
+		values := make([]interface{}, len(level))
+		oks := make([]bool, len(level))
+		contexts := make([]*evalContext, len(level))
+
+		sem := make(chan struct{}, r.parallelism)
+		var wg sync.WaitGroup
+		for i, node := range level {
+			ne := e
+			ne.evalContext = &evalContext{Runner: r, root: node, pending: &pendingDiags{}}
+			held[node.Key.Value] = ne.pending
+			contexts[i] = ne.evalContext
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, node variableNode) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				values[i], oks[i] = ne.evaluateVariable(node)
+			}(i, node)
+		}
+		wg.Wait()
+
+		for i, node := range level {
+			e.sdiags.Extend(contexts[i].pending.flush(r)...)
+			if !e.setVariable(r, node, values[i], oks[i]) {
+				return false
+			}
+		}
+	}
+
+	// Levels reorder the batch, so trace steps are only added once all of it is done.
+	if r.trace != nil {
+		for _, node := range nodes {
+			r.trace.add(held[node.Key.Value].steps...)
+		}
+	}
+	return true
+}
+
+// pendingDiags holds the diagnostics and trace steps of a node that is evaluated concurrently with
+// others, so that they can be added to the runner in a deterministic order. Those that are
+// reported after the node is flushed, such as from applies, are added to the runner directly.
+type pendingDiags struct {
+	mutex   sync.Mutex
+	diags   syntax.Diagnostics
+	steps   []TraceStep
+	flushed bool
+}
+
+// hold holds diag, and returns false if the diagnostics were already flushed.
+func (p *pendingDiags) hold(diag *syntax.Diagnostic) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.flushed {
+		return false
+	}
+	p.diags.Extend(diag)
+	return true
+}
+
+// holdStep holds step, and returns false if the node was already flushed. Held steps are added
+// to the trace by EvalVariables.
+func (p *pendingDiags) holdStep(step TraceStep) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.flushed {
+		return false
+	}
+	p.steps = append(p.steps, step)
+	return true
+}
+
+// flush adds the held diagnostics to the runner, and returns them.
+func (p *pendingDiags) flush(r *Runner) syntax.Diagnostics {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.flushed = true
+	r.sdiags.Extend(p.diags...)
+	return p.diags
+}
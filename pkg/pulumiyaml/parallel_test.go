@@ -0,0 +1,160 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// invokeTemplate declares n independent variables that each call an invoke, and a variable that
+// depends on all of them.
+func invokeTemplate(t testing.TB, n int) *ast.TemplateDecl {
+	var b strings.Builder
+	b.WriteString("name: test-parallel\nruntime: yaml\nvariables:\n")
+	var refs []string
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "  v%d:\n    fn::invoke:\n      function: test:invoke:slow\n      arguments:\n        yesArg: \"%d\"\n      return: outString\n", i, i)
+		refs = append(refs, fmt.Sprintf("${v%d}", i))
+	}
+	fmt.Fprintf(&b, "  all: %s\n", strings.Join(refs, ","))
+	tmpl, diags, err := LoadYAMLBytes("<stdin>", []byte(b.String()))
+	require.NoError(t, err)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return tmpl
+}
+
+// slowInvokes is a monitor whose invokes take delay, and which records how many ran at once.
+func slowInvokes(delay time.Duration, maxConcurrent *int) *testMonitor {
+	var lock sync.Mutex
+	running := 0
+	return &testMonitor{
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			lock.Lock()
+			running++
+			if running > *maxConcurrent {
+				*maxConcurrent = running
+			}
+			lock.Unlock()
+			time.Sleep(delay)
+			lock.Lock()
+			running--
+			lock.Unlock()
+			return resource.PropertyMap{"outString": args.Args["yesArg"]}, nil
+		},
+	}
+}
+
+// runParallelism evaluates tmpl, and returns the value of its variable "all", if it has one.
+func runParallelism(t testing.TB, tmpl *ast.TemplateDecl, parallelism int, mocks *testMonitor) (interface{}, syntax.Diagnostics) {
+	runner := newRunner(tmpl, newMockPackageMap())
+	runner.parallelism = parallelism
+	var diags syntax.Diagnostics
+	var all interface{}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = runner.Evaluate(ctx)
+		all = runner.variables["all"]
+		if o, ok := all.(pulumi.Output); ok {
+			result, err := internals.UnsafeAwaitOutput(ctx.Context(), o)
+			if err != nil {
+				return err
+			}
+			all = result.Value
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+	return all, diags
+}
+
+func TestParallelVariables(t *testing.T) {
+	t.Parallel()
+
+	tmpl := invokeTemplate(t, 6)
+
+	maxConcurrent := 0
+	all, diags := runParallelism(t, tmpl, 4, slowInvokes(20*time.Millisecond, &maxConcurrent))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, 4, maxConcurrent)
+	assert.Equal(t, "0,1,2,3,4,5", all)
+
+	maxConcurrent = 0
+	all, diags = runParallelism(t, tmpl, 1, slowInvokes(0, &maxConcurrent))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, 1, maxConcurrent)
+	assert.Equal(t, "0,1,2,3,4,5", all)
+}
+
+func TestParallelVariablesDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-parallel
+runtime: yaml
+variables:
+  a:
+    fn::fromBase64: "!a"
+  b:
+    fn::fromBase64: "!b"
+  c:
+    fn::select: [3, [1]]
+  d:
+    fn::fromBase64: ${a}
+  e:
+    fn::fromBase64: "!e"
+`)
+	summaries := func(diags syntax.Diagnostics) []string {
+		var s []string
+		for _, d := range diags {
+			s = append(s, diagString(d))
+		}
+		return s
+	}
+	_, diags := runParallelism(t, tmpl, 1, &testMonitor{})
+	require.True(t, diags.HasErrors())
+	serial := summaries(diags)
+	require.Len(t, serial, 4)
+
+	// However the variables are scheduled, the diagnostics are those of evaluating them in order.
+	for i := 0; i < 10; i++ {
+		_, diags := runParallelism(t, tmpl, 8, &testMonitor{})
+		assert.Equal(t, serial, summaries(diags))
+	}
+}
+
+func BenchmarkParallelVariables(b *testing.B) {
+	tmpl := invokeTemplate(b, 8)
+	for _, parallelism := range []int{1, 8} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				maxConcurrent := 0
+				_, diags := runParallelism(b, tmpl, parallelism, slowInvokes(5*time.Millisecond, &maxConcurrent))
+				if diags.HasErrors() {
+					b.Fatal(diags.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParseParallelism(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, maxParallelism, parseParallelism(""))
+	assert.Equal(t, 1, parseParallelism("1"))
+	assert.Equal(t, 1, parseParallelism("0"))
+	assert.Equal(t, 1, parseParallelism("many"))
+	assert.Equal(t, 4, parseParallelism("4"))
+}
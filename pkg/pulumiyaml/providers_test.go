@@ -0,0 +1,184 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+func TestProvidersSection(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-providers
+runtime: yaml
+configuration:
+  region:
+    type: String
+    default: us-west-2
+providers:
+  test:
+    region: ${region}
+resources:
+  implicit:
+    type: test:resource:type
+    properties:
+      foo: bar
+  other:
+    type: pulumi:providers:test
+  explicit:
+    type: test:resource:type
+    properties:
+      foo: bar
+    options:
+      provider: ${other}
+variables:
+  result:
+    fn::invoke:
+      function: test:invoke:type
+`)
+	var lock sync.Mutex
+	providers := map[string]string{}
+	var providerInputs resource.PropertyMap
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			if args.TypeToken == "pulumi:providers:test" {
+				if args.Name == ast.DefaultProviderName("test") {
+					providerInputs = args.Inputs
+				}
+				return args.Name + "-id", args.Inputs, nil
+			}
+			providers[args.Name] = args.RegisterRPC.Provider
+			return args.Name, args.Inputs, nil
+		},
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			providers["invoke"] = args.Provider
+			return resource.PropertyMap{"retval": resource.NewStringProperty("ok")}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		return RunTemplate(ctx, tmpl, nil, nil, newMockPackageMap())
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	urn := func(name string) string {
+		return fmt.Sprintf("urn:pulumi:stack::project::pulumi:providers:test::%s::%s-id", name, name)
+	}
+	assert.Equal(t, "us-west-2", providerInputs["region"].StringValue())
+	assert.Equal(t, urn("test-default-provider"), providers["implicit"])
+	assert.Equal(t, urn("test-default-provider"), providers["invoke"])
+	// A provider set on the resource wins over the default.
+	assert.Equal(t, urn("other"), providers["explicit"])
+}
+
+func TestProvidersSectionErrors(t *testing.T) {
+	t.Parallel()
+
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-providers
+runtime: yaml
+providers:
+  test:
+    region: us-west-2
+  other: us-east-1
+resources:
+  mine:
+    type: pulumi:providers:test
+    defaultProvider: true
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`<stdin>:11:22: the default provider of "test" is already declared in providers; ` +
+			`Remove defaultProvider from this resource, or remove the package from providers`,
+		`<stdin>:7:10: the configuration of the default provider of "other" must be an object`,
+	}, diagStrings(diags))
+
+	// Properties are checked against the schema of the provider.
+	tmpl := yamlTemplate(t, `
+name: test-providers
+runtime: yaml
+providers:
+  test:
+    regoin: us-west-2
+`)
+	_, diags = TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "regoin does not exist")
+}
+
+func TestProviderConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	spec := `{
+  "name": "test",
+  "version": "1.0.0",
+  "provider": {
+    "inputProperties": {"region": {"type": "string"}}
+  }
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test-1.0.0.json"), []byte(spec), 0o600))
+	loader := NewDirectoryPackageLoader(dir)
+	defer loader.Close()
+
+	tmpl := yamlTemplate(t, `
+name: test-providers
+runtime: yaml
+providers:
+  test:
+    regoin: us-west-2
+resources:
+  explicit:
+    type: pulumi:providers:test
+    properties:
+      region: [1]
+`)
+	_, diags, err := PrepareTemplate(tmpl, nil, loader)
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	require.Len(t, diags, 2)
+	assert.Equal(t, "pulumi:providers:test is not assignable from {region: List<number>}", diags[0].Summary)
+	assert.Equal(t, "Property regoin does not exist on 'pulumi:providers:test'", diags[1].Summary)
+	assert.Equal(t, 6, diags[1].Subject.Start.Line)
+}
+
+func TestResourceWithoutSchema(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-providers
+runtime: yaml
+resources:
+  prov:
+    type: pulumi:providers:test
+    properties:
+      region: us-west-2
+`)
+	loader := MockPackageLoader{packages: map[string]Package{
+		"test": MockPackage{
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return nil
+			},
+		},
+	}}
+	_, diags := TypeCheck(newRunner(tmpl, loader))
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "unable to load the schema of resource prov of type pulumi:providers:test", diags[0].Summary)
+}
@@ -0,0 +1,231 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// When set, fn::uuid and fn::randomId derive their values from it instead of generating them,
+// which makes their values the same for every run and every stack, such as in tests.
+var defaultRandomSeed = os.Getenv("PULUMI_YAML_RANDOM_SEED")
+
+// maxRandomIDLength bounds the byte length of fn::randomId.
+const maxRandomIDLength = 1024
+
+// randomType is the type of the component resources that store the values of fn::uuid and
+// fn::randomId in resources, one for each use, so that later deployments of the stack reuse them.
+const randomType = "pulumi-yaml:index:Random"
+
+// randomResource is a component resource of type randomType. Its "value" output is the value
+// that it stores.
+type randomResource struct {
+	pulumi.ResourceState
+}
+
+// stableBytes returns n bytes that look random, but are a function of seed and name.
+func stableBytes(seed, name string, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	for i := uint32(0); len(out) < n; i++ {
+		h := sha256.New()
+		h.Write([]byte(seed))
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+		_ = binary.Write(h, binary.BigEndian, i)
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+// formatUUID formats 16 bytes as a random UUID, which is version 4.
+func formatUUID(b []byte) string {
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// randomValue returns the value of fn::uuid or fn::randomId x, which format makes from n random
+// bytes.
+//
+// In a resource, the value behaves like one of Pulumi's random provider: it is generated once by
+// an update and stored in the stack's state, and later runs reuse it. A preview that has no value to
+// reuse sees an unknown value, so it agrees with the update that follows it. Elsewhere, nothing is
+// stored and each run generates a new value. With a seed, the value is derived from the seed and
+// from where x is used instead, and nothing is stored.
+func (e *programEvaluator) randomValue(x ast.Expr, n int, format func([]byte) string) (interface{}, bool) {
+	name := e.randomName(x)
+	if e.randomSeed != "" {
+		return format(stableBytes(e.randomSeed, name, n)), true
+	}
+	if _, ok := e.scope.(resourceNode); !ok || e.pulumiCtx == nil {
+		b := make([]byte, n)
+		if _, err := rand.Read(b); err != nil {
+			return e.error(x, fmt.Sprintf("unable to generate a random value: %v", err))
+		}
+		return format(b), true
+	}
+
+	var opts []pulumi.ResourceOption
+	parentType := tokens.Type("")
+	if e.component != nil {
+		parent := e.component.resource.pulumiResource()
+		urn, err := internals.UnsafeAwaitOutput(e.pulumiCtx.Context(), parent.URN())
+		if err != nil {
+			return e.error(x, fmt.Sprintf("unable to store the value of %s: %v", randomBuiltinName(x), err))
+		}
+		if parentURN, ok := urn.Value.(pulumi.URN); ok {
+			parentType = resource.URN(parentURN).QualifiedType()
+		}
+		opts = append(opts, pulumi.Parent(parent))
+	}
+	urn := resource.NewURN(tokens.QName(e.pulumiCtx.Stack()), tokens.PackageName(e.pulumiCtx.Project()),
+		parentType, randomType, name)
+	value, err := e.storedRandomValue(urn)
+	if err != nil {
+		return e.error(x, fmt.Sprintf("unable to read the stored value of %s: %v", randomBuiltinName(x), err))
+	}
+	// A value of a different length was stored for a different byte length, so it is replaced.
+	if len(value) != len(format(make([]byte, n))) {
+		value = ""
+		if !e.pulumiCtx.DryRun() {
+			b := make([]byte, n)
+			if _, err := rand.Read(b); err != nil {
+				return e.error(x, fmt.Sprintf("unable to generate a random value: %v", err))
+			}
+			value = format(b)
+		}
+	}
+
+	store := &randomResource{}
+	err = e.pulumiCtx.RegisterComponentResource(randomType, name, store, opts...)
+	if err == nil && value != "" {
+		err = e.pulumiCtx.RegisterResourceOutputs(store, pulumi.Map{"value": pulumi.String(value)})
+	}
+	if err != nil {
+		return e.error(x, fmt.Sprintf("unable to store the value of %s: %v", randomBuiltinName(x), err))
+	}
+	if value == "" {
+		return pulumi.UnsafeUnknownOutput(nil), true
+	}
+	return value, true
+}
+
+// storedRandomValue reads the value stored by the randomType resource urn from the stack's state.
+// It returns "" if there is no such resource.
+func (e *programEvaluator) storedRandomValue(urn resource.URN) (string, error) {
+	var result struct {
+		State map[string]interface{} `pulumi:"state"`
+	}
+	err := e.pulumiCtx.Invoke("pulumi:pulumi:getResource", map[string]interface{}{"urn": string(urn)}, &result)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown resource") {
+			return "", nil
+		}
+		return "", err
+	}
+	value, _ := result.State["value"].(string)
+	return value, nil
+}
+
+// randomBuiltinName returns the name of the builtin x, which is fn::uuid or fn::randomId.
+func randomBuiltinName(x ast.Expr) string {
+	if x, ok := x.(ast.BuiltinExpr); ok {
+		return x.Name().Value
+	}
+	return "the builtin"
+}
+
+// randomName returns the name of where fn::uuid or fn::randomId x is used: the logical name of the
+// resource, variable or output, and the path to x within it. It names the value that is stored for
+// x, and is what the value is derived from when there is a seed.
+func (e *programEvaluator) randomName(x ast.Expr) string {
+	var name string
+	switch root := e.scope.(type) {
+	case resourceNode:
+		_, name = logicalName(root.Key, root.Value)
+		if e.loop != nil {
+			name = loopResourceName(name, e.loop.suffix)
+		}
+		if e.component != nil {
+			name = e.component.name + "-" + name
+		}
+		name = "resources." + name
+		for _, entry := range root.Value.Properties.Entries {
+			if path, ok := exprPath(entry.Value, x, entry.Key.Value); ok {
+				name += ".properties." + path
+				break
+			}
+		}
+	case variableNode:
+		name = "variables." + root.Key.Value
+		if path, ok := exprPath(root.Value, x, ""); ok && path != "" {
+			name += "." + path
+		}
+	case ast.PropertyMapEntry:
+		name = "outputs." + root.Key.Value
+		if path, ok := exprPath(root.Value, x, ""); ok && path != "" {
+			name += "." + path
+		}
+	}
+	return name
+}
+
+// exprPath returns the path to target within x, whose own path is path.
+func exprPath(x, target ast.Expr, path string) (string, bool) {
+	if x == target {
+		return path, true
+	}
+	join := func(elem string) string {
+		if path == "" {
+			return elem
+		}
+		return path + "." + elem
+	}
+	switch x := x.(type) {
+	case *ast.ObjectExpr:
+		for _, entry := range x.Entries {
+			key, ok := entry.Key.(*ast.StringExpr)
+			if !ok {
+				continue
+			}
+			if p, ok := exprPath(entry.Value, target, join(key.Value)); ok {
+				return p, true
+			}
+		}
+	case *ast.ListExpr:
+		for i, elem := range x.Elements {
+			if p, ok := exprPath(elem, target, path+"["+strconv.Itoa(i)+"]"); ok {
+				return p, true
+			}
+		}
+	case ast.BuiltinExpr:
+		return exprPath(x.Args(), target, join(x.Name().Value))
+	}
+	return "", false
+}
+
+// checkRandomScope points out fn::uuid and fn::randomId outside of resources. Their values are
+// only stored when they are used by a resource, so elsewhere they change every time the template
+// runs.
+func checkRandomScope(ctx *evalContext, x ast.BuiltinExpr) {
+	if _, ok := ctx.root.(resourceNode); ok {
+		return
+	}
+	ctx.addHintDiag(x.Syntax().Syntax().Range(),
+		fmt.Sprintf("the value of %s is only stable when it is used by a resource", x.Name().Value),
+		"Outside of a resource, the value is not stored, so a new value is generated every time the "+
+			"template runs. Use it in the properties of a resource to generate it once and keep it")
+}
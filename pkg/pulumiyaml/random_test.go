@@ -0,0 +1,181 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+func TestStableRandom(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-random
+runtime: yaml
+resources:
+  first:
+    type: test:resource:with-tags
+    properties:
+      foo:
+        fn::uuid: {}
+      tags:
+        suffix:
+          fn::randomId: 4
+        other:
+          fn::randomId: 4
+  second:
+    type: test:resource:with-tags
+    properties:
+      foo:
+        fn::uuid: {}
+`)
+	const storedUUID = "0b5e7a3c-7f21-4c4e-9d3a-5a1f2b3c4d5e"
+	// run runs the template, after storing the given values as an earlier deployment would have.
+	run := func(preview bool, seed string, stored map[string]string) (map[string]resource.PropertyMap, []string) {
+		var lock sync.Mutex
+		inputs := map[string]resource.PropertyMap{}
+		var stores []string
+		mocks := &testMonitor{
+			NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				if args.TypeToken == randomType {
+					stores = append(stores, args.Name)
+				} else {
+					inputs[args.Name] = args.Inputs
+				}
+				return args.Name, args.Inputs, nil
+			},
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			for name, value := range stored {
+				var res randomResource
+				err := ctx.RegisterResource(randomType, name, pulumi.Map{"value": pulumi.String(value)}, &res)
+				require.NoError(t, err)
+				_, err = internals.UnsafeAwaitOutput(ctx.Context(), res.URN())
+				require.NoError(t, err)
+			}
+			lock.Lock()
+			stores = nil
+			lock.Unlock()
+
+			runner := newRunner(tmpl, newMockPackageMap())
+			runner.randomSeed = seed
+			_, diags := TypeCheck(runner)
+			requireNoErrors(t, tmpl, diags)
+			assert.Empty(t, diags)
+			diags = runner.Evaluate(ctx)
+			requireNoErrors(t, tmpl, diags)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks), func(ri *pulumi.RunInfo) {
+			ri.DryRun = preview
+		})
+		require.NoError(t, err)
+		sort.Strings(stores)
+		return inputs, stores
+	}
+
+	// The first update generates the values, and stores each of them.
+	update, stores := run(false, "", nil)
+	first := update["first"]
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, first["foo"].StringValue())
+	assert.NotEqual(t, first["foo"], update["second"]["foo"])
+	tags := first["tags"].ObjectValue()
+	assert.Regexp(t, `^[0-9a-f]{8}$`, tags["suffix"].StringValue())
+	assert.NotEqual(t, tags["suffix"], tags["other"])
+	assert.Equal(t, []string{
+		"resources.first.properties.foo",
+		"resources.first.properties.tags.other",
+		"resources.first.properties.tags.suffix",
+		"resources.second.properties.foo",
+	}, stores)
+
+	// Before then, a preview doesn't know the values.
+	preview, _ := run(true, "", nil)
+	// The mocks leave unknown inputs out.
+	assert.NotContains(t, preview["first"], resource.PropertyKey("foo"))
+
+	// Later runs reuse the stored values. A value that was stored for another byte length is
+	// replaced.
+	stored := map[string]string{
+		"resources.first.properties.foo":         storedUUID,
+		"resources.first.properties.tags.suffix": "0a1b2c3d",
+		"resources.first.properties.tags.other":  "0a1b2c",
+	}
+	for _, preview := range []bool{true, false} {
+		inputs, _ := run(preview, "", stored)
+		assert.Equal(t, storedUUID, inputs["first"]["foo"].StringValue())
+		tags := inputs["first"]["tags"].ObjectValue()
+		assert.Equal(t, "0a1b2c3d", tags["suffix"].StringValue())
+		if preview {
+			assert.NotContains(t, tags, resource.PropertyKey("other"))
+		} else {
+			assert.Regexp(t, `^[0-9a-f]{8}$`, tags["other"].StringValue())
+		}
+	}
+
+	// A seed makes the values the same for every run, and nothing is stored.
+	seeded, stores := run(false, "seed", nil)
+	assert.Empty(t, stores)
+	seededPreview, _ := run(true, "seed", stored)
+	assert.Equal(t, seeded, seededPreview)
+	assert.Equal(t, formatUUID(stableBytes("seed", "resources.first.properties.foo", 16)),
+		seeded["first"]["foo"].StringValue())
+}
+
+func TestStableRandomOutsideResources(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-random
+runtime: yaml
+variables:
+  id:
+    fn::uuid:
+  bad:
+    fn::randomId: 0
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "the value of fn::uuid is only stable when it is used by a resource", diags[0].Summary)
+	assert.Equal(t, "the value of fn::randomId is only stable when it is used by a resource", diags[1].Summary)
+	for _, d := range diags {
+		assert.True(t, syntax.IsHint(d.HCL()))
+	}
+
+	run := func(seed string) (interface{}, syntax.Diagnostics) {
+		var id interface{}
+		var evalDiags syntax.Diagnostics
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			runner := newRunner(tmpl, newMockPackageMap())
+			runner.randomSeed = seed
+			evalDiags = runner.Evaluate(ctx)
+			id = runner.variables["id"]
+			return nil
+		}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+		require.NoError(t, err)
+		return id, evalDiags
+	}
+
+	id, evalDiags := run("seed")
+	assert.Equal(t, formatUUID(stableBytes("seed", "variables.id", 16)), id)
+	require.True(t, evalDiags.HasErrors())
+	assert.Equal(t, "the byte length of fn::randomId must be between 1 and 1024, not 0", evalDiags[0].Summary)
+
+	// Without a seed, nothing is stored, so every run has a new value.
+	first, _ := run("")
+	second, _ := run("")
+	assert.Regexp(t, `^[0-9a-f]{8}-`, first)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,81 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultReadFileTimeout bounds how long fn::readFile waits for a URL when no timeout is given.
+const defaultReadFileTimeout = 30 * time.Second
+
+// isRemotePath reports whether fn::readFile should fetch path rather than read it from disk.
+func isRemotePath(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func isSha256Hex(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// parseReadFileTimeout parses the timeout of fn::readFile, a duration such as "10s" or "1m30s".
+func parseReadFileTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("timeout must be a positive duration such as \"10s\" or \"1m\", got %q", s)
+	}
+	return d, nil
+}
+
+// fetchURL returns the body of a GET request to url. Only a complete 2xx response is returned;
+// anything else is an error describing what went wrong.
+func fetchURL(url string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %v", timeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("server responded with %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %v while reading the response", timeout)
+		}
+		return nil, fmt.Errorf("reading the response: %w", err)
+	}
+	return body, nil
+}
+
+// checkSha256Sum returns an error if the SHA-256 checksum sum isn't expected.
+func checkSha256Sum(sum [sha256.Size]byte, expected string) error {
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("expected sha256 %s, got %s", strings.ToLower(expected), actual)
+	}
+	return nil
+}
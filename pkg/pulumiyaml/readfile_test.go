@@ -0,0 +1,235 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFileServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hello":
+			fmt.Fprint(w, "hello, world")
+		case "/slow":
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadFileURL(t *testing.T) {
+	t.Parallel()
+
+	server := readFileServer(t)
+	sum := sha256.Sum256([]byte("hello, world"))
+	tmpl := yamlTemplate(t, fmt.Sprintf(`
+name: test-readfile
+runtime: yaml
+variables:
+  plain:
+    fn::readFile: %[1]s/hello
+  checked:
+    fn::readFile:
+      path: %[1]s/hello
+      sha256: %[2]s
+      timeout: 5s
+  encoded:
+    fn::toBase64:
+      fn::readFile: %[1]s/hello
+`, server.URL, hex.EncodeToString(sum[:])))
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, "hello, world", e.variables["plain"])
+		assert.Equal(t, "hello, world", e.variables["checked"])
+		assert.Equal(t, "aGVsbG8sIHdvcmxk", e.variables["encoded"])
+	})
+}
+
+func TestReadFileURLErrors(t *testing.T) {
+	t.Parallel()
+
+	server := readFileServer(t)
+	tests := []struct {
+		name     string
+		readFile string
+		expected string
+	}{
+		{
+			name:     "not found",
+			readFile: "fn::readFile: " + server.URL + "/missing",
+			expected: "Error fetching " + server.URL + "/missing: server responded with 404 Not Found",
+		},
+		{
+			name: "timeout",
+			readFile: `fn::readFile:
+      path: ` + server.URL + `/slow
+      timeout: 100ms`,
+			expected: "Error fetching " + server.URL + "/slow: timed out after 100ms",
+		},
+		{
+			name: "checksum mismatch",
+			readFile: `fn::readFile:
+      path: ` + server.URL + `/hello
+      sha256: abababababababababababababababababababababababababababababababab`,
+			expected: "checksum mismatch for " + server.URL + "/hello: expected sha256 " +
+				"abababababababababababababababababababababababababababababababab, " +
+				"got 09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b",
+		},
+		{
+			name: "encoded timeout",
+			readFile: `fn::toBase64:
+      fn::readFile:
+        path: ` + server.URL + `/slow
+        timeout: 100ms`,
+			expected: "Error fetching " + server.URL + "/slow: timed out after 100ms",
+		},
+		{
+			name: "encoded checksum mismatch",
+			readFile: `fn::toBase64:
+      fn::readFile:
+        path: ` + server.URL + `/hello
+        sha256: abababababababababababababababababababababababababababababababab`,
+			expected: "checksum mismatch for " + server.URL + "/hello: expected sha256 " +
+				"abababababababababababababababababababababababababababababababab, " +
+				"got 09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := yamlTemplate(t, `
+name: test-readfile
+runtime: yaml
+variables:
+  content:
+    `+tt.readFile+`
+`)
+			diags := testTemplateDiags(t, tmpl, nil)
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diagString(diags[0]), tt.expected)
+		})
+	}
+}
+
+func TestReadFileChecksumLocal(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello, world"), 0o600))
+	tmpl := yamlTemplate(t, `
+name: test-readfile
+runtime: yaml
+variables:
+  content:
+    fn::readFile:
+      path: `+path+`
+      sha256: 09CA7E4EAA6E8AE9C7D261167129184883644D07DFBA7CBFBC4C8A2E08360D5B
+`)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, "hello, world", e.variables["content"])
+	})
+}
+
+func TestToBase64ReadFileChecksum(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello, world"), 0o600))
+	tmpl := yamlTemplate(t, `
+name: test-readfile
+runtime: yaml
+variables:
+  matching:
+    fn::toBase64:
+      fn::readFile:
+        path: `+path+`
+        sha256: 09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b
+  tampered:
+    fn::toBase64:
+      fn::readFile:
+        path: `+path+`
+        sha256: abababababababababababababababababababababababababababababababab
+`)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "checksum mismatch for "+path+": expected sha256 "+
+		"abababababababababababababababababababababababababababababababab, "+
+		"got 09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b", diags[0].Summary)
+}
+
+func TestReadFileArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		readFile string
+		expected string
+	}{
+		{
+			name: "unknown property",
+			readFile: `fn::readFile:
+      path: ./file
+      checksum: abc`,
+			expected: `unknown property "checksum"; fn::readFile accepts 'path', 'sha256' and 'timeout'`,
+		},
+		{
+			name: "missing path",
+			readFile: `fn::readFile:
+      timeout: 1s`,
+			expected: "missing path or URL to read ('path')",
+		},
+		{
+			name: "bad checksum",
+			readFile: `fn::readFile:
+      path: ./file
+      sha256: abc`,
+			expected: `sha256 must be 64 hexadecimal characters, got "abc"`,
+		},
+		{
+			name: "bad timeout",
+			readFile: `fn::readFile:
+      path: https://example.com
+      timeout: soon`,
+			expected: `timeout must be a positive duration such as "10s" or "1m", got "soon"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-readfile
+runtime: yaml
+variables:
+  content:
+    `+tt.readFile+`
+`))
+			require.NoError(t, err)
+			if !diags.HasErrors() {
+				diags = testTemplateDiags(t, tmpl, nil)
+			}
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diagString(diags[0]), tt.expected)
+		})
+	}
+}
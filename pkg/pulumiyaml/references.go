@@ -0,0 +1,77 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	yamldiags "github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/diags"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// checkReferences returns an error for each reference, in an interpolation or a symbol, to a
+// name that the template does not declare as a resource, variable or config value. It only needs
+// the sorted intermediates, so it runs before packages are loaded, which lets a typo be reported
+// without waiting on plugins.
+func checkReferences(r *Runner) syntax.Diagnostics {
+	t := r.t
+	declared := map[string]bool{PulumiVarName: true}
+	for _, n := range r.intermediates {
+		if _, ok := n.(missingNode); ok {
+			continue
+		}
+		declared[n.key().Value] = true
+	}
+	// Config without a value has no node, but is still declared.
+	for _, entries := range [][]ast.ConfigMapEntry{t.Configuration.Entries, t.Config.Entries} {
+		for _, kvp := range entries {
+			declared[kvp.Key.Value] = true
+		}
+	}
+
+	var deps []*ast.StringExpr
+	for _, kvp := range t.Configuration.Entries {
+		if kvp.Value != nil {
+			getExpressionDependencies(&deps, kvp.Value.Default)
+		}
+	}
+	for _, kvp := range t.Variables.Entries {
+		deps = append(deps, GetVariableDependencies(kvp)...)
+	}
+	for _, kvp := range t.Resources.Entries {
+		if kvp.Value != nil {
+			deps = append(deps, GetResourceDependencies(kvp.Value)...)
+		}
+	}
+	for _, kvp := range r.outputs() {
+		getExpressionDependencies(&deps, kvp.Value)
+	}
+	for _, kvp := range t.DefaultTags.Entries {
+		getExpressionDependencies(&deps, kvp.Value)
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmtr := yamldiags.NonExistentFieldFormatter{Fields: names}
+
+	var diags syntax.Diagnostics
+	for _, dep := range deps {
+		if declared[dep.Value] {
+			continue
+		}
+		if t.Name != nil && declared[stripConfigNamespace(t.Name.Value, dep.Value)] {
+			continue
+		}
+		detail := "Declare it as a resource, a variable or config"
+		if closest, ok := fmtr.Closest(dep.Value, 2); ok {
+			detail = fmt.Sprintf("Did you mean '%s'?", closest)
+		}
+		diags.Extend(ast.ExprError(dep, fmt.Sprintf("unknown reference %q", dep.Value), detail))
+	}
+	return diags
+}
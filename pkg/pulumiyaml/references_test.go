@@ -0,0 +1,42 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownReferenceInOutput(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-references
+runtime: yaml
+config:
+  prefix:
+    type: string
+variables:
+  name: ${prefix}-${pulumi.stack}
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${name}
+outputs:
+  bucketName: ${buckt.bar}
+  other: ${nothingLikeIt}
+`)
+	loader := &failingPackageLoader{}
+	_, diags, err := PrepareTemplate(tmpl, nil, loader)
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, `<stdin>:15:15: unknown reference "buckt"; Did you mean 'bucket'?`, diagString(diags[0]))
+	assert.Equal(t, `unknown reference "nothingLikeIt"`, diags[1].Summary)
+	assert.Equal(t, "Declare it as a resource, a variable or config", diags[1].Detail)
+
+	// The references are reported without loading any packages.
+	assert.Zero(t, loader.attempts)
+}
@@ -0,0 +1,175 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// typeSyntax returns the type of a resource as written in the template. Types aren't interpolated
+// when the template is parsed, and once they are resolved, the syntax still has the type as written.
+func typeSyntax(typ *ast.StringExpr) *syntax.StringNode {
+	if node, ok := typ.Syntax().(*syntax.StringNode); ok && node != nil {
+		return node
+	}
+	return syntax.String(typ.Value)
+}
+
+// isInterpolatedType reports whether the type of a resource is interpolated, such as
+// ${cloud}:compute:Instance.
+func isInterpolatedType(typ *ast.StringExpr) bool {
+	return typ != nil && strings.Contains(strings.ReplaceAll(typeSyntax(typ).Value(), "$$", ""), "${")
+}
+
+// parseInterpolatedType parses the type of a resource as an interpolated string. It returns nil
+// if the type isn't interpolated.
+func parseInterpolatedType(typ *ast.StringExpr) ([]ast.Interpolation, syntax.Diagnostics) {
+	if !isInterpolatedType(typ) {
+		return nil, nil
+	}
+	x, diags := ast.ParseExpr(typeSyntax(typ))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	switch x := x.(type) {
+	case *ast.InterpolateExpr:
+		return x.Parts, nil
+	case *ast.SymbolExpr:
+		return []ast.Interpolation{{Value: x.Property}}, nil
+	}
+	return nil, nil
+}
+
+// typeDependencies returns the config that the type of a resource refers to.
+func typeDependencies(r *ast.ResourceDecl) []*ast.StringExpr {
+	parts, _ := parseInterpolatedType(r.Type)
+	var deps []*ast.StringExpr
+	for _, part := range parts {
+		if part.Value != nil {
+			deps = append(deps, ast.String(part.Value.RootName()))
+		}
+	}
+	return deps
+}
+
+// resolveResourceTypes returns a copy of t whose interpolated resource types, such as
+// ${cloud}:compute:Instance, are replaced by the tokens they resolve to. Types may only refer to
+// config, and are resolved from its value in externalConfig or else its default in the template,
+// before the template is analysed. If no type is interpolated, t is returned as-is.
+func resolveResourceTypes(t *ast.TemplateDecl, externalConfig []configNode) (*ast.TemplateDecl, syntax.Diagnostics) {
+	interpolated := false
+	for _, kvp := range t.Resources.Entries {
+		if kvp.Value != nil && isInterpolatedType(kvp.Value.Type) {
+			interpolated = true
+			break
+		}
+	}
+	if !interpolated {
+		return t, nil
+	}
+
+	external := map[string]resource.PropertyValue{}
+	for _, node := range externalConfig {
+		if prop, ok := node.(configNodeProp); ok {
+			external[prop.k] = prop.v
+		}
+	}
+	declared := map[string]*ast.ConfigParamDecl{}
+	for _, kvp := range t.Configuration.Entries {
+		declared[kvp.Key.Value] = kvp.Value
+	}
+	isRuntimeValue := func(name string) bool {
+		for _, kvp := range t.Variables.Entries {
+			if kvp.Key.Value == name {
+				return true
+			}
+		}
+		for _, kvp := range t.Resources.Entries {
+			if kvp.Key.Value == name {
+				return true
+			}
+		}
+		return name == PulumiVarName
+	}
+
+	var diags syntax.Diagnostics
+	configValue := func(key string, typ *ast.StringExpr, access *ast.PropertyAccess) (string, bool) {
+		name := access.RootName()
+		if len(access.Accessors) > 1 {
+			diags.Extend(ast.ExprError(typ, fmt.Sprintf("the type of resource %q can only refer to config by name, not %q",
+				key, access.String()), ""))
+			return "", false
+		}
+		if v, ok := external[name]; ok {
+			if v.IsSecret() || !v.IsString() {
+				diags.Extend(ast.ExprError(typ, fmt.Sprintf("the type of resource %q refers to config %q, which must be a string",
+					key, name), ""))
+				return "", false
+			}
+			return v.StringValue(), true
+		}
+		if c, ok := declared[name]; ok {
+			if c != nil {
+				if def, ok := c.Default.(*ast.StringExpr); ok {
+					return def.Value, true
+				}
+			}
+			diags.Extend(ast.ExprError(typ, fmt.Sprintf("the type of resource %q refers to config %q, which has no value",
+				key, name), "The type of a resource must be known before the template runs, so the config must "+
+				"be set, or have a default that is a string literal"))
+			return "", false
+		}
+		if isRuntimeValue(name) {
+			diags.Extend(ast.ExprError(typ, fmt.Sprintf("the type of resource %q can only refer to config, not %q",
+				key, name), "The type of a resource must be known before the template runs, so it can't depend on "+
+				"variables or resources"))
+			return "", false
+		}
+		diags.Extend(ast.ExprError(typ, fmt.Sprintf("the type of resource %q refers to %q, which is not config",
+			key, name), ""))
+		return "", false
+	}
+
+	resolved := *t
+	resolved.Resources.Entries = make([]ast.ResourcesMapEntry, len(t.Resources.Entries))
+	for i, kvp := range t.Resources.Entries {
+		resolved.Resources.Entries[i] = kvp
+		if kvp.Value == nil {
+			continue
+		}
+		parts, pdiags := parseInterpolatedType(kvp.Value.Type)
+		diags.Extend(pdiags...)
+		if parts == nil {
+			continue
+		}
+		var token strings.Builder
+		ok := true
+		for _, part := range parts {
+			token.WriteString(part.Text)
+			if part.Default != nil {
+				diags.Extend(ast.ExprError(kvp.Value.Type, fmt.Sprintf("the type of resource %q can't have a default",
+					kvp.Key.Value), "Give the config it refers to a default instead"))
+				ok = false
+				continue
+			}
+			if part.Value != nil {
+				v, vok := configValue(kvp.Key.Value, kvp.Value.Type, part.Value)
+				ok = ok && vok
+				token.WriteString(v)
+			}
+		}
+		if !ok {
+			continue
+		}
+		decl := *kvp.Value
+		decl.Type = ast.StringSyntaxValue(typeSyntax(kvp.Value.Type), token.String())
+		resolved.Resources.Entries[i].Value = &decl
+	}
+	return &resolved, diags
+}
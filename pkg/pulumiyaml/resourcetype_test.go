@@ -0,0 +1,135 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolatedResourceType(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-type
+runtime: yaml
+configuration:
+  cloud:
+    type: String
+    default: aws
+  kind:
+    type: String
+    default: with-tags
+resources:
+  res:
+    type: ${cloud}:resource:${kind}
+  plain:
+    type: test:resource:with-tags
+`
+	loader := MockPackageLoader{packages: map[string]Package{
+		"aws": MockPackage{
+			isComponent: func(string) (bool, error) { return false, nil },
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+		},
+		"test": newMockPackageMap().(MockPackageLoader).packages["test"],
+	}}
+	run := func(config resource.PropertyMap) map[string]string {
+		tmpl := yamlTemplate(t, text)
+		var lock sync.Mutex
+		types := map[string]string{}
+		mocks := &testMonitor{
+			NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				types[args.Name] = args.TypeToken
+				return args.Name, args.Inputs, nil
+			},
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			runner := newRunner(tmpl, loader)
+			runner.setIntermediates("project", nil, config, false)
+			_, diags := TypeCheck(runner)
+			requireNoErrors(t, tmpl, diags)
+			diags = runner.Evaluate(ctx)
+			requireNoErrors(t, tmpl, diags)
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		require.NoError(t, err)
+
+		// The template itself is left as written.
+		assert.Equal(t, "${cloud}:resource:${kind}", tmpl.Resources.Entries[0].Value.Type.Value)
+		return types
+	}
+
+	assert.Equal(t, map[string]string{
+		"res":   "aws:resource:with-tags",
+		"plain": "test:resource:with-tags",
+	}, run(nil))
+	assert.Equal(t, map[string]string{
+		"res":   "test:resource:with-tags",
+		"plain": "test:resource:with-tags",
+	}, run(resource.PropertyMap{"project:cloud": resource.NewStringProperty("test")}))
+}
+
+func TestInterpolatedResourceTypeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		typ      string
+		expected string
+	}{
+		{
+			typ:      "${bucket.id}:resource:type",
+			expected: `the type of resource "res" can only refer to config by name, not "bucket.id"`,
+		},
+		{
+			typ:      "${bucket}:resource:type",
+			expected: `the type of resource "res" can only refer to config, not "bucket"`,
+		},
+		{
+			typ:      "${prefix}:resource:type",
+			expected: `the type of resource "res" can only refer to config, not "prefix"`,
+		},
+		{
+			typ:      "${region}:resource:type",
+			expected: `the type of resource "res" refers to config "region", which has no value`,
+		},
+		{
+			typ:      "${unknown}:resource:type",
+			expected: `the type of resource "res" refers to "unknown", which is not config`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.typ, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := yamlTemplate(t, `
+name: test-type
+runtime: yaml
+configuration:
+  region:
+    type: String
+variables:
+  prefix: test
+resources:
+  bucket:
+    type: test:resource:type
+  res:
+    type: `+tt.typ+`
+`)
+			_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+			require.True(t, diags.HasErrors())
+			assert.Equal(t, tt.expected, diags[0].Summary)
+			assert.Equal(t, 13, diags[0].Subject.Start.Line)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"github.com/blang/semver"
+)
+
+func (r *Runner) recordResourceVersion(name string, version *semver.Version) {
+	r.resourceVersionsLock.Lock()
+	defer r.resourceVersionsLock.Unlock()
+	if r.resourceVersions == nil {
+		r.resourceVersions = map[string]*semver.Version{}
+	}
+	r.resourceVersions[name] = version
+}
+
+// ResourceVersions returns the version of the package that each resource registered by Evaluate
+// resolved to, by the resource's logical name. The versions are those of the packages that were
+// loaded, after the version resource option, default providers and package declarations are taken
+// into account. A resource whose package has no version maps to nil.
+func (r *Runner) ResourceVersions() map[string]*semver.Version {
+	r.resourceVersionsLock.Lock()
+	defer r.resourceVersionsLock.Unlock()
+	versions := make(map[string]*semver.Version, len(r.resourceVersions))
+	for name, version := range r.resourceVersions {
+		if version != nil {
+			v := *version
+			version = &v
+		}
+		versions[name] = version
+	}
+	return versions
+}
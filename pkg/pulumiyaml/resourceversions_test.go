@@ -0,0 +1,77 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceVersions(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-versions
+runtime: yaml
+resources:
+  latest:
+    type: versioned:index:Resource
+    name: my-resource
+  pinned:
+    type: versioned:index:Resource
+    options:
+      version: 1.5.0
+  unversioned:
+    type: unversioned:index:Resource
+  looped:
+    type: versioned:index:Resource
+    forEach: [a, b]
+`)
+	mockPackage := func(version string) MockPackage {
+		pkg := MockPackage{
+			isComponent: func(string) (bool, error) { return false, nil },
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+		}
+		if version != "" {
+			v := semver.MustParse(version)
+			pkg.version = &v
+		}
+		return pkg
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"versioned":       mockPackage("2.1.0"),
+		"versioned@1.5.0": mockPackage("1.5.0"),
+		"unversioned":     mockPackage(""),
+	}}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			return args.Name, args.Inputs, nil
+		},
+	}
+	var versions map[string]*semver.Version
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, loader)
+		diags := runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		versions = runner.ResourceVersions()
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	latest, pinned := semver.MustParse("2.1.0"), semver.MustParse("1.5.0")
+	assert.Equal(t, map[string]*semver.Version{
+		"my-resource": &latest,
+		"pinned":      &pinned,
+		"unversioned": nil,
+		"looped-0":    &latest,
+		"looped-1":    &latest,
+	}, versions)
+}
@@ -4,12 +4,17 @@ package pulumiyaml
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	b64 "encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
+	"net/netip"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -18,8 +23,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 	"unicode/utf8"
 
+	"github.com/blang/semver"
 	"github.com/google/shlex"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl/v2"
@@ -39,10 +47,8 @@ import (
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax/encoding"
 )
 
-// MainTemplate is the assumed name of the JSON template file.
-// TODO: would be nice to permit multiple files, but we'd need to know which is "main", and there's
-// no notion of "import" so we'd need to be a bit more clever. Might be nice to mimic e.g. Kustomize.
-// One idea is to hijack Pulumi.yaml's "main" directive and then just globally toposort the rest.
+// MainTemplate is the assumed name of the JSON template file. Other files are only read if the
+// template lists them under 'includes'.
 const MainTemplate = "Main"
 
 func LoadFromCompiler(compiler string, workingDirectory string, env []string) (*ast.TemplateDecl, syntax.Diagnostics, error) {
@@ -132,6 +138,10 @@ func LoadDir(directory string) (*ast.TemplateDecl, syntax.Diagnostics, error) {
 	if err != nil {
 		return nil, diags, err
 	}
+	if template != nil {
+		diags.Extend(loadIncludes(template, directory, filename)...)
+		diags.Extend(loadComponentFiles(template, directory, filename)...)
+	}
 
 	if diags.HasErrors() {
 		return nil, diags, diags
@@ -155,9 +165,11 @@ func LoadFile(path string) (*ast.TemplateDecl, syntax.Diagnostics, error) {
 	defer f.Close()
 
 	template, diags, err := LoadYAML(filepath.Base(path), f)
-	if err != nil {
+	if err != nil || template == nil {
 		return nil, diags, err
 	}
+	diags.Extend(loadIncludes(template, filepath.Dir(path), filepath.Base(path))...)
+	diags.Extend(loadComponentFiles(template, filepath.Dir(path), filepath.Base(path))...)
 
 	packages, err := packages.SearchPackageDecls(filepath.Dir(path))
 	if err != nil {
@@ -209,12 +221,49 @@ func LoadTemplate(t *Template) (*ast.TemplateDecl, syntax.Diagnostics) {
 		return nil, diags
 	}
 
-	td, tdiags := ast.ParseTemplate(nil, syn)
+	td, tdiags := ast.ParseTemplate(nil, omitEmptyConditions(syn))
 	diags.Extend(tdiags...)
 
 	return td, diags
 }
 
+// omitEmptyConditions removes the Condition of each resource decoded from a Template that leaves
+// it unset. The zero value of Resource.Condition means that the resource is always created, but it
+// decodes as an empty string, which isn't a boolean.
+func omitEmptyConditions(syn syntax.Node) syntax.Node {
+	obj, ok := syn.(*syntax.ObjectNode)
+	if !ok {
+		return syn
+	}
+	entries := make([]syntax.ObjectPropertyDef, obj.Len())
+	for i := range entries {
+		entries[i] = obj.Index(i)
+		resources, ok := entries[i].Value.(*syntax.ObjectNode)
+		if entries[i].Key.Value() != "Resources" || !ok {
+			continue
+		}
+		resourceEntries := make([]syntax.ObjectPropertyDef, resources.Len())
+		for j := range resourceEntries {
+			resourceEntries[j] = resources.Index(j)
+			resource, ok := resourceEntries[j].Value.(*syntax.ObjectNode)
+			if !ok {
+				continue
+			}
+			var fields []syntax.ObjectPropertyDef
+			for k := 0; k < resource.Len(); k++ {
+				field := resource.Index(k)
+				if str, ok := field.Value.(*syntax.StringNode); ok && field.Key.Value() == "Condition" && str.Value() == "" {
+					continue
+				}
+				fields = append(fields, field)
+			}
+			resourceEntries[j].Value = syntax.Object(fields...)
+		}
+		entries[i].Value = syntax.Object(resourceEntries...)
+	}
+	return syntax.Object(entries...)
+}
+
 func HasDiagnostics(err error) (syntax.Diagnostics, bool) {
 	if err == nil {
 		return nil, false
@@ -303,12 +352,12 @@ func (r *Runner) setDefaultProviders() {
 			}
 
 			if v.Options.Provider == nil {
-				if v.Options.Version != nil && v.Options.Version.Value != defaultProviderInfo.version.Value {
+				if v.Options.Version != nil && v.Options.Version.Value != defaultProviderInfo.version.GetValue() {
 					ctx.addErrDiag(node.Key.Syntax().Syntax().Range(),
 						"Version conflicts with the default provider version",
 						fmt.Sprintf("Try removing this option on resource \"%s\"", k))
 				}
-				if v.Options.PluginDownloadURL != nil && v.Options.PluginDownloadURL.Value != defaultProviderInfo.pluginDownloadURL.Value {
+				if v.Options.PluginDownloadURL != nil && v.Options.PluginDownloadURL.Value != defaultProviderInfo.pluginDownloadURL.GetValue() {
 					ctx.addErrDiag(node.Key.Syntax().Syntax().Range(),
 						"PluginDownloadURL conflicts with the default provider URL",
 						fmt.Sprintf("Try removing this option on resource \"%s\"", k))
@@ -334,6 +383,10 @@ func (r *Runner) setDefaultProviders() {
 
 			switch t := v.(type) {
 			case *ast.InvokeExpr:
+				if t.Token == nil {
+					// The function token is only known at runtime.
+					return true
+				}
 				pkgName := strings.Split(t.Token.Value, ":")[0]
 				if _, ok := defaultProviderInfoMap[pkgName]; !ok {
 					return true
@@ -366,9 +419,6 @@ func (r *Runner) setDefaultProviders() {
 		VisitConfig: func(r *Runner, node configNode) bool {
 			return true
 		},
-		VisitOutput: func(r *Runner, node ast.PropertyMapEntry) bool {
-			return true
-		},
 	})
 
 	// This function communicates errors by appending to the internal diags field of `r`.
@@ -407,6 +457,11 @@ func PrepareTemplate(t *ast.TemplateDecl, r *Runner, loader PackageLoader) (*Run
 	// do some basic validation of each resource
 	r.validateResources()
 
+	// report references to undeclared names before loading any packages
+	if refDiags := checkReferences(r); refDiags.HasErrors() {
+		return r, refDiags, nil
+	}
+
 	// runner hooks up default providers
 	r.setDefaultProviders()
 
@@ -416,14 +471,57 @@ func PrepareTemplate(t *ast.TemplateDecl, r *Runner, loader PackageLoader) (*Run
 		return nil, nil, err
 	}
 
+	// load the referenced packages up front, rather than one at a time during type checking
+	preloadDiags := r.preloadPackages(context.TODO())
+	if preloadDiags.HasErrors() {
+		return r, preloadDiags, nil
+	}
+
 	// runner type checks nodes
 	_, diags := TypeCheck(r)
-	return r, diags, nil
+	diags.Extend(r.checkComponents()...)
+	return r, append(preloadDiags, diags...), nil
+}
+
+// Analyze performs the same package resolution, validation and type checking that precede
+// running a template, and returns all of the resulting diagnostics. It never registers
+// resources or calls invokes, so it is suitable for editors. It is safe to call concurrently for
+// different templates, provided that loader is safe for concurrent use.
+func Analyze(t *ast.TemplateDecl, loader PackageLoader, opts ...RunnerOption) syntax.Diagnostics {
+	r := NewRunner(t, loader, opts...)
+	_, diags, err := PrepareTemplate(t, r, loader)
+	if err != nil {
+		diags.Extend(syntax.Error(nil, err.Error(), ""))
+	}
+
+	// Type checking returns the diagnostics recorded on the runner, but if it didn't run then
+	// those from sorting and validating the template must be added.
+	seen := make(map[*syntax.Diagnostic]bool, len(diags))
+	for _, d := range diags {
+		seen[d] = true
+	}
+	r.sdiags.mutex.Lock()
+	defer r.sdiags.mutex.Unlock()
+	for _, d := range r.sdiags.diags {
+		if !seen[d] {
+			diags.Extend(d)
+		}
+	}
+	return diags
 }
 
 // RunTemplate runs the programEvaluator against a template using the given request/settings.
-func RunTemplate(ctx *pulumi.Context, t *ast.TemplateDecl, config map[string]string, configPropertyMap resource.PropertyMap, loader PackageLoader) error {
-	r := newRunner(t, loader)
+func RunTemplate(ctx *pulumi.Context, t *ast.TemplateDecl, config map[string]string, configPropertyMap resource.PropertyMap,
+	loader PackageLoader, opts ...RunnerOption,
+) error {
+	return runTemplate(ctx, t, config, configPropertyMap, loader, nil, opts)
+}
+
+func runTemplate(ctx *pulumi.Context, t *ast.TemplateDecl, config map[string]string, configPropertyMap resource.PropertyMap,
+	loader PackageLoader, trace *Trace, opts []RunnerOption,
+) error {
+	r := NewRunner(t, loader, opts...)
+	r.trace = trace
 	r.setIntermediates(ctx.Project(), config, configPropertyMap, false)
 	if r.sdiags.HasErrors() {
 		return &r.sdiags
@@ -484,13 +582,64 @@ type Runner struct {
 	pkgLoader PackageLoader
 	config    map[string]interface{}
 	variables map[string]interface{}
-	resources map[string]lateboundResource
-	stackRefs map[string]*pulumi.StackReference
+	// Guards variables, which apply callbacks read while later variables are evaluated.
+	variablesLock sync.RWMutex
+	resources     map[string]lateboundResource
+	// Guards resources and absentResources, which apply callbacks read while later resources are
+	// registered.
+	resourcesLock sync.RWMutex
+	stackRefs     map[string]*pulumi.StackReference
+	// Guards stackRefs, since variables may be evaluated concurrently.
+	stackRefsLock sync.Mutex
+	// The keys of the resources declared with forEach, by the names of their instances.
+	loopInstances map[string]string
+	// The resources that weren't registered because their condition was false, and whether
+	// referring to them is an error.
+	absentResources      map[string]bool
+	absentResourceErrors bool
 
 	cwd string
 
+	// Whether type tokens may leave out the index module.
+	indexExpansion IndexExpansion
+	// The kinds of deprecations in provider schemas that are reported.
+	deprecationWarnings deprecationWarnings
+
+	// Results of invokes, shared between identical invokes within a run.
+	invokeCache     map[invokeCacheKey]*invokeCacheEntry
+	invokeCacheLock sync.Mutex
+
+	// The versions of the packages that registered resources resolved to, by logical name.
+	resourceVersions     map[string]*semver.Version
+	resourceVersionsLock sync.Mutex
+
 	sdiags syncDiags
 
+	// The plaintext of secrets, which is masked in diagnostics. It is shared with the bodies of
+	// components.
+	secrets *secretStrings
+
+	// The clock that fn::now reads, and the time that it read, which is shared by the whole run.
+	clock func() time.Time
+	now   *runTime
+
+	// The seed of fn::uuid and fn::randomId. The stack is used if it is empty.
+	randomSeed string
+
+	// The number of independent variables that may be evaluated at once.
+	parallelism int
+
+	// If set, records how each value is computed. See ExplainTemplate.
+	trace *Trace
+
+	// If set, the runner evaluates the body of this instance of a component.
+	component *componentInstance
+
+	// The evaluated defaultTags of the template.
+	defaultTags     map[string]interface{}
+	defaultTagsOk   bool
+	defaultTagsOnce sync.Once
+
 	// Used to store sorted nodes. A non `nil` value indicates that the runner
 	// is already setup for running.
 	intermediates []graphNode
@@ -501,25 +650,49 @@ type evalContext struct {
 
 	root   interface{}
 	sdiags syncDiags
+	// pending, if set, holds the diagnostics of a node that is evaluated concurrently with others.
+	pending *pendingDiags
+	// The nesting of the expression being traced.
+	traceDepth int
+	// elements are the fn::map, fn::filter and fn::reduce expressions whose bodies enclose the
+	// expression being walked, innermost last.
+	elements []ast.ElementExpr
 }
 
 func (ctx *evalContext) addWarnDiag(rng *hcl.Range, summary string, detail string) {
-	ctx.sdiags.diags.Extend(syntax.Warning(rng, summary, detail))
-	ctx.Runner.sdiags.diags.Extend(syntax.Warning(rng, summary, detail))
+	ctx.addDiag(syntax.Warning(rng, summary, detail))
+}
+
+func (ctx *evalContext) addHintDiag(rng *hcl.Range, summary string, detail string) {
+	ctx.addDiag(syntax.Hint(rng, summary, detail))
 }
 
 func (ctx *evalContext) addErrDiag(rng *hcl.Range, summary string, detail string) {
-	ctx.sdiags.diags.Extend(syntax.Error(rng, summary, detail))
-	ctx.Runner.sdiags.diags.Extend(syntax.Error(rng, summary, detail))
+	ctx.addDiag(syntax.Error(rng, summary, detail))
+}
+
+func (ctx *evalContext) addDiag(diag *syntax.Diagnostic) {
+	ctx.secrets.mask(diag)
+	ctx.sdiags.Extend(diag)
+	ctx.report(diag)
 }
 
 func (ctx *evalContext) error(expr ast.Expr, summary string) (interface{}, bool) {
 	diag := ast.ExprError(expr, summary, "")
+	ctx.secrets.mask(diag)
 	ctx.sdiags.Extend(diag)
-	ctx.Runner.sdiags.Extend(diag)
+	ctx.report(diag)
 	return nil, false
 }
 
+// report adds diag to the runner's diagnostics, unless the node is evaluated concurrently with
+// others, in which case it is held until the node is flushed.
+func (ctx *evalContext) report(diag *syntax.Diagnostic) {
+	if ctx.pending == nil || !ctx.pending.hold(diag) {
+		ctx.Runner.sdiags.Extend(diag)
+	}
+}
+
 func (ctx *evalContext) errorf(expr ast.Expr, format string, a ...interface{}) (interface{}, bool) {
 	return ctx.error(expr, fmt.Sprintf(format, a...))
 }
@@ -542,8 +715,13 @@ type lateboundResource interface {
 	GetOutputs() pulumi.Output
 	CustomResource() *pulumi.CustomResourceState
 	ProviderResource() *pulumi.ProviderResourceState
+	// pulumiResource returns the resource, for the options that take any kind of resource.
+	pulumiResource() pulumi.Resource
 	GetRawOutputs() pulumi.Output
 	GetResourceSchema() *schema.Resource
+	// isProtected returns whether the resource is protected, either by its own options or by
+	// those of its parent.
+	isProtected() bool
 }
 
 // lateboundCustomResourceState is a resource state that stores all computed outputs into a single
@@ -554,6 +732,7 @@ type lateboundCustomResourceState struct {
 	name           string
 	Outputs        pulumi.MapOutput `pulumi:""`
 	resourceSchema *schema.Resource
+	protected      bool
 }
 
 // GetOutputs returns the resource's outputs.
@@ -580,6 +759,10 @@ func (st *lateboundCustomResourceState) ProviderResource() *pulumi.ProviderResou
 	return nil
 }
 
+func (st *lateboundCustomResourceState) pulumiResource() pulumi.Resource {
+	return &st.CustomResourceState
+}
+
 func (*lateboundCustomResourceState) ElementType() reflect.Type {
 	return reflect.TypeOf((**lateboundCustomResourceState)(nil)).Elem()
 }
@@ -592,11 +775,16 @@ func (st *lateboundCustomResourceState) GetResourceSchema() *schema.Resource {
 	return st.resourceSchema
 }
 
+func (st *lateboundCustomResourceState) isProtected() bool {
+	return st.protected
+}
+
 type lateboundProviderResourceState struct {
 	pulumi.ProviderResourceState
 	name           string
 	Outputs        pulumi.MapOutput `pulumi:""`
 	resourceSchema *schema.Resource
+	protected      bool
 }
 
 // GetOutputs returns the resource's outputs.
@@ -623,6 +811,10 @@ func (st *lateboundProviderResourceState) ProviderResource() *pulumi.ProviderRes
 	return &st.ProviderResourceState
 }
 
+func (st *lateboundProviderResourceState) pulumiResource() pulumi.Resource {
+	return &st.CustomResourceState
+}
+
 func (*lateboundProviderResourceState) ElementType() reflect.Type {
 	return reflect.TypeOf((**lateboundProviderResourceState)(nil)).Elem()
 }
@@ -635,6 +827,10 @@ func (st *lateboundProviderResourceState) GetResourceSchema() *schema.Resource {
 	return st.resourceSchema
 }
 
+func (st *lateboundProviderResourceState) isProtected() bool {
+	return st.protected
+}
+
 type poisonMarker struct{}
 
 // GetOutputs returns the resource's outputs.
@@ -655,6 +851,10 @@ func (st poisonMarker) ProviderResource() *pulumi.ProviderResourceState {
 	return nil
 }
 
+func (st poisonMarker) pulumiResource() pulumi.Resource {
+	return nil
+}
+
 func (poisonMarker) ElementType() reflect.Type {
 	return reflect.TypeOf((*lateboundResource)(nil)).Elem()
 }
@@ -667,6 +867,10 @@ func (st poisonMarker) GetResourceSchema() *schema.Resource {
 	return nil
 }
 
+func (st poisonMarker) isProtected() bool {
+	return false
+}
+
 // Check if a value is either a poisonMarker or is a collection that contains a
 // poisonMarker.
 func isPoisoned(v interface{}) (poisonMarker, bool) {
@@ -689,14 +893,47 @@ func isPoisoned(v interface{}) (poisonMarker, bool) {
 	return poisonMarker{}, false
 }
 
+// A RunnerOption configures a Runner. Options that aren't given default to the environment.
+type RunnerOption func(r *Runner)
+
+// WithIndexExpansion sets whether the runner reports type tokens of the form `pkg:Type` that leave
+// out the index module. It defaults to the value of PULUMI_YAML_STRICT_TOKENS.
+func WithIndexExpansion(mode IndexExpansion) RunnerOption {
+	return func(r *Runner) {
+		r.indexExpansion = mode
+	}
+}
+
+// NewRunner creates a runner for the template t, which loads packages with loader. It can be
+// passed to PrepareTemplate.
+func NewRunner(t *ast.TemplateDecl, loader PackageLoader, opts ...RunnerOption) *Runner {
+	r := newRunner(t, loader)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
 func newRunner(t *ast.TemplateDecl, p PackageLoader) *Runner {
 	return &Runner{
 		t:         t,
-		pkgLoader: p,
+		pkgLoader: withComponents(t, p),
 		config:    make(map[string]interface{}),
 		variables: make(map[string]interface{}),
 		resources: make(map[string]lateboundResource),
 		stackRefs: make(map[string]*pulumi.StackReference),
+
+		loopInstances:        make(map[string]string),
+		absentResources:      make(map[string]bool),
+		absentResourceErrors: defaultAbsentResourceErrors,
+		invokeCache:          make(map[invokeCacheKey]*invokeCacheEntry),
+		indexExpansion:       defaultIndexExpansion,
+		deprecationWarnings:  defaultDeprecationWarnings,
+		secrets:              &secretStrings{},
+		clock:                time.Now,
+		now:                  &runTime{},
+		randomSeed:           defaultRandomSeed,
+		parallelism:          defaultParallelism,
 	}
 }
 
@@ -714,6 +951,13 @@ type programEvaluator struct {
 	*evalContext
 	pulumiCtx   *pulumi.Context
 	packageRefs map[tokens.Package]string
+	// loop is the iteration of the resource being registered, if it has a forEach.
+	loop *resourceLoop
+	// element binds the element variables while the body of a fn::map, fn::filter or fn::reduce
+	// is evaluated, if one is.
+	element *elementScope
+	// scope is the resource, variable or output being evaluated.
+	scope interface{}
 }
 
 func (e *programEvaluator) error(expr ast.Expr, summary string) (interface{}, bool) {
@@ -723,9 +967,10 @@ func (e *programEvaluator) error(expr ast.Expr, summary string) (interface{}, bo
 }
 
 func (e *programEvaluator) addDiag(diag *syntax.Diagnostic) {
+	e.secrets.mask(diag)
 	defer func() {
 		e.sdiags.Extend(diag)
-		e.evalContext.Runner.sdiags.Extend(diag)
+		e.report(diag)
 	}()
 
 	var buf bytes.Buffer
@@ -737,8 +982,11 @@ func (e *programEvaluator) addDiag(diag *syntax.Diagnostic) {
 		s := buf.String()
 		// We strip off the appropriate HCL error message, since it will be
 		// added back on via the pulumi.Log framework.
-		switch diag.Severity {
-		case hcl.DiagWarning:
+		switch {
+		case syntax.IsHint(diag.HCL()):
+			s = strings.TrimPrefix(s, "Warning: ")
+			err = e.pulumiCtx.Log.Info(s, &pulumi.LogArgs{})
+		case diag.Severity == hcl.DiagWarning:
 			s = strings.TrimPrefix(s, "Warning: ")
 			err = e.pulumiCtx.Log.Warn(s, &pulumi.LogArgs{})
 		default:
@@ -759,6 +1007,7 @@ func (e *programEvaluator) errorf(expr ast.Expr, format string, a ...interface{}
 
 func (e programEvaluator) EvalConfig(r *Runner, node configNode) bool {
 	ctx := r.newContext(node)
+	e.scope = node
 	c, ok := e.registerConfig(node)
 	if !ok {
 		e.config[node.key().Value] = poisonMarker{}
@@ -774,37 +1023,111 @@ func (e programEvaluator) EvalConfig(r *Runner, node configNode) bool {
 }
 
 func (e programEvaluator) EvalVariable(r *Runner, node variableNode) bool {
+	value, ok := e.evaluateVariable(node)
+	return e.setVariable(r, node, value, ok)
+}
+
+func (e programEvaluator) evaluateVariable(node variableNode) (interface{}, bool) {
+	e.scope = node
+	return e.evaluateExpr(node.Value)
+}
+
+// setVariable records the value of a variable, or logs that it failed to evaluate.
+func (e programEvaluator) setVariable(r *Runner, node variableNode, value interface{}, ok bool) bool {
 	ctx := r.newContext(node)
-	value, ok := e.evaluateExpr(node.Value)
 	if !ok {
-		e.variables[node.Key.Value] = poisonMarker{}
+		e.setVariableValue(node.Key.Value, poisonMarker{})
 		msg := fmt.Sprintf("Error registering variable [%v]: %v", node.Key.Value, ctx.sdiags.Error())
 		err := e.pulumiCtx.Log.Error(msg, &pulumi.LogArgs{})
 		if err != nil {
 			return false
 		}
 	} else {
-		e.variables[node.Key.Value] = value
+		e.setVariableValue(node.Key.Value, value)
 	}
 	return true
 }
 
+// variable returns the value of the variable name, if it has been evaluated.
+func (r *Runner) variable(name string) (interface{}, bool) {
+	r.variablesLock.RLock()
+	defer r.variablesLock.RUnlock()
+	v, ok := r.variables[name]
+	return v, ok
+}
+
+// setVariableValue records the value of the variable name.
+func (r *Runner) setVariableValue(name string, value interface{}) {
+	r.variablesLock.Lock()
+	defer r.variablesLock.Unlock()
+	r.variables[name] = value
+}
+
 func (e programEvaluator) EvalResource(r *Runner, node resourceNode) bool {
+	e.scope = node
+	if node.Value.Condition != nil {
+		create, ok := e.evaluateCondition(node)
+		if !ok {
+			e.setResource(node.Key.Value, poisonMarker{})
+			msg := fmt.Sprintf("Error registering resource [%v]: %v", node.Key.Value, r.newContext(node).sdiags.Error())
+			return e.pulumiCtx.Log.Error(msg, &pulumi.LogArgs{}) == nil
+		}
+		if !create {
+			e.setResourceAbsent(node.Key.Value)
+			return true
+		}
+	}
+	if node.Value.ForEach != nil {
+		return e.evalResourceLoop(r, node)
+	}
 	ctx := r.newContext(node)
+	if expandsManifests(ctx, node.Value) {
+		return e.evalManifestResource(r, node)
+	}
 	res, ok := e.registerResource(node)
 	if !ok {
-		e.resources[node.Key.Value] = poisonMarker{}
+		e.setResource(node.Key.Value, poisonMarker{})
 		msg := fmt.Sprintf("Error registering resource [%v]: %v", node.Key.Value, ctx.sdiags.Error())
 		err := e.pulumiCtx.Log.Error(msg, &pulumi.LogArgs{})
 		if err != nil {
 			return false
 		}
 	} else {
-		e.resources[node.Key.Value] = res
+		e.setResource(node.Key.Value, res)
 	}
 	return true
 }
 
+// resource returns the resource name, if it has been registered.
+func (r *Runner) resource(name string) (lateboundResource, bool) {
+	r.resourcesLock.RLock()
+	defer r.resourcesLock.RUnlock()
+	res, ok := r.resources[name]
+	return res, ok
+}
+
+// setResource records the resource name.
+func (r *Runner) setResource(name string, res lateboundResource) {
+	r.resourcesLock.Lock()
+	defer r.resourcesLock.Unlock()
+	r.resources[name] = res
+}
+
+// resourceAbsent reports whether the resource name wasn't registered because its condition was
+// false.
+func (r *Runner) resourceAbsent(name string) bool {
+	r.resourcesLock.RLock()
+	defer r.resourcesLock.RUnlock()
+	return r.absentResources[name]
+}
+
+// setResourceAbsent records that the resource name isn't registered because its condition is false.
+func (r *Runner) setResourceAbsent(name string) {
+	r.resourcesLock.Lock()
+	defer r.resourcesLock.Unlock()
+	r.absentResources[name] = true
+}
+
 func (e programEvaluator) EvalMissing(r *Runner, node missingNode) bool {
 	e.error(node.key(), fmt.Sprintf("resource, variable, or config value %q not found", node.key().Value))
 	return false
@@ -812,6 +1135,7 @@ func (e programEvaluator) EvalMissing(r *Runner, node missingNode) bool {
 
 func (e programEvaluator) EvalOutput(r *Runner, node ast.PropertyMapEntry) bool {
 	ctx := r.newContext(node)
+	e.scope = node
 	out, ok := e.registerOutput(node)
 	if !ok {
 		msg := fmt.Sprintf("Error registering output [%v]: %v", node.Key.Value, ctx.sdiags.Error())
@@ -820,7 +1144,14 @@ func (e programEvaluator) EvalOutput(r *Runner, node ast.PropertyMapEntry) bool
 			return false
 		}
 	} else if _, poisoned := out.(poisonMarker); !poisoned {
-		e.pulumiCtx.Export(node.Key.Value, out)
+		if out, ok = e.applyWhenUnknown(node.Value, out); !ok {
+			return true
+		}
+		if e.component != nil {
+			e.component.outputs[node.Key.Value] = out
+		} else {
+			e.pulumiCtx.Export(node.Key.Value, out)
+		}
 	}
 	return true
 }
@@ -896,6 +1227,15 @@ func (r *Runner) setIntermediates(project string, config map[string]string, conf
 	r.intermediates = []graphNode{}
 	confNodes := getConfNodesFromMap(project, configPropertyMap)
 
+	// Resolve interpolated resource types from config, so that the rest of the template only
+	// sees type tokens.
+	t, tdiags := resolveResourceTypes(r.t, confNodes)
+	r.sdiags.Extend(tdiags...)
+	if tdiags.HasErrors() && !force {
+		return
+	}
+	r.t = t
+
 	// Topologically sort the intermediates based on implicit and explicit dependencies
 	intermediates, rdiags := topologicallySortedResources(r.t, confNodes)
 	r.sdiags.Extend(rdiags...)
@@ -951,8 +1291,8 @@ func (r *Runner) Run(e Evaluator) syntax.Diagnostics {
 		return returnDiags()
 	}
 
-	for _, kvp := range r.intermediates {
-		switch kvp := kvp.(type) {
+	for i := 0; i < len(r.intermediates); i++ {
+		switch kvp := r.intermediates[i].(type) {
 		case configNode:
 			if ctx != nil {
 				err := ctx.Log.Debug(fmt.Sprintf("Registering config [%v]", kvp.key().Value), &pulumi.LogArgs{})
@@ -971,6 +1311,22 @@ func (r *Runner) Run(e Evaluator) syntax.Diagnostics {
 					return returnDiags()
 				}
 			}
+			if be, ok := e.(variableBatchEvaluator); ok {
+				batch := variableBatch(r.intermediates[i:])
+				for _, node := range batch[1:] {
+					if ctx != nil {
+						err := ctx.Log.Debug(fmt.Sprintf("Registering variable [%v]", node.Key.Value), &pulumi.LogArgs{})
+						if err != nil {
+							return returnDiags()
+						}
+					}
+				}
+				i += len(batch) - 1
+				if !be.EvalVariables(r, batch) {
+					return returnDiags()
+				}
+				continue
+			}
 			if !e.EvalVariable(r, kvp) {
 				return returnDiags()
 			}
@@ -991,7 +1347,7 @@ func (r *Runner) Run(e Evaluator) syntax.Diagnostics {
 		}
 	}
 
-	for _, kvp := range r.t.Outputs.Entries {
+	for _, kvp := range r.outputs() {
 		if !e.EvalOutput(r, kvp) {
 			return returnDiags()
 		}
@@ -1000,17 +1356,27 @@ func (r *Runner) Run(e Evaluator) syntax.Diagnostics {
 	return returnDiags()
 }
 
+// outputs returns the stack outputs of the template, including the outputs declared in output
+// groups under their dotted names.
+func (r *Runner) outputs() []ast.PropertyMapEntry {
+	return append(append([]ast.PropertyMapEntry{}, r.t.Outputs.Entries...), r.t.OutputGroups.Flatten()...)
+}
+
 func (e *programEvaluator) registerConfig(intm configNode) (interface{}, bool) {
 	var expectedType ctypes.Type
 	var isSecretInConfig, markSecret bool
 	var defaultValue interface{}
 	var k string
 	var intmKey ast.Expr
+	var c *ast.ConfigParamDecl
 
 	switch intm := intm.(type) {
 	case configNodeYaml:
+		if e.component != nil {
+			return e.componentInput(intm)
+		}
 		k, intmKey = intm.Key.Value, intm.Key
-		c := intm.Value
+		c = intm.Value
 		if c.Name != nil && c.Name.Value != "" {
 			k = c.Name.Value
 		}
@@ -1024,24 +1390,41 @@ func (e *programEvaluator) registerConfig(intm configNode) (interface{}, bool) {
 			if !ok {
 				return nil, false
 			}
-			var err error
-			expectedType, err = ctypes.TypeValue(d)
-			if err != nil {
-				return e.error(c.Default, err.Error())
+			if _, isOutput := d.(pulumi.Output); isOutput {
+				// The default depends on a secret or unknown value, so its type can't be inferred.
+				if c.Type == nil {
+					return e.errorf(intm.Key,
+						"unable to infer type: 'type' is required when the default is not known until the program runs")
+				}
+			} else {
+				var err error
+				expectedType, err = ctypes.TypeValue(d)
+				if err != nil {
+					return e.error(c.Default, err.Error())
+				}
 			}
 			defaultValue = d
 		}
 		if c.Type != nil {
-			t, ok := ctypes.Parse(c.Type.Value)
-			if !ok {
-				return e.errorf(c.Type,
-					"unexpected configuration type '%s': valid types are %s",
-					c.Type.Value, ctypes.ConfigTypes)
+			t, diags := configDeclType(c)
+			if diags.HasErrors() {
+				for _, diag := range diags {
+					e.addDiag(diag)
+				}
+				return nil, false
 			}
 
 			// We have both a default value and a explicit type. Make sure they
 			// agree.
-			if ctypes.IsValidType(expectedType) && t != expectedType {
+			if ctypes.IsStructured(t) {
+				if _, isOutput := defaultValue.(pulumi.Output); defaultValue != nil && !isOutput {
+					d, err := ctypes.Conform(t, defaultValue)
+					if err != nil {
+						return e.error(c.Default, configShapeError(k, err, c.Secret != nil && c.Secret.Value))
+					}
+					defaultValue = applyConfigDecl(c, d)
+				}
+			} else if ctypes.IsValidType(expectedType) && t != expectedType {
 				return e.errorf(intm.Key,
 					"type mismatch: default value of type %s but type %s was specified",
 					expectedType, t)
@@ -1152,6 +1535,21 @@ func (e *programEvaluator) registerConfig(intm configNode) (interface{}, bool) {
 				v = arr
 			}
 		}
+	default:
+		if ctypes.IsStructured(expectedType) {
+			var raw interface{}
+			err = config.TryObject(e.pulumiCtx, k, &raw)
+			if err == nil {
+				v, err = ctypes.Conform(expectedType, raw)
+				if err != nil {
+					return e.errorf(intmKey, "%s", configShapeError(k, err, isSecretInConfig || markSecret))
+				}
+				v = applyConfigDecl(c, v)
+				if isSecretInConfig {
+					v = pulumi.ToSecret(v)
+				}
+			}
+		}
 	}
 
 	if errors.Is(err, config.ErrMissingVar) && defaultValue != nil {
@@ -1162,6 +1560,23 @@ func (e *programEvaluator) registerConfig(intm configNode) (interface{}, bool) {
 
 	contract.Assertf(v != nil, "let an uninitialized var slip through")
 
+	if c.HasConstraints() {
+		if out, ok := v.(pulumi.Output); ok {
+			// Secret values are checked once they are known.
+			v = out.ApplyT(func(x interface{}) (interface{}, error) {
+				if diags := checkConfigConstraints(k, c, x, true); diags.HasErrors() {
+					return nil, diags
+				}
+				return x, nil
+			})
+		} else if diags := checkConfigConstraints(k, c, v, markSecret); diags.HasErrors() {
+			for _, diag := range diags {
+				e.addDiag(diag)
+			}
+			return nil, false
+		}
+	}
+
 	// The value was marked secret in the configuration section, but in the
 	// config section. We need to wrap it in `pulumi.ToSecret`.
 	if markSecret {
@@ -1225,9 +1640,28 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 
 	if v.Options.Aliases != nil {
 		var aliases []pulumi.Alias
-		for _, s := range v.Options.Aliases.Elements {
-			alias := pulumi.Alias{
-				URN: pulumi.URN(s.Value),
+		for _, a := range v.Options.Aliases.Elements {
+			var alias pulumi.Alias
+			if a.Urn != nil {
+				alias.URN = pulumi.URN(a.Urn.Value)
+			}
+			if a.Name != nil {
+				alias.Name = pulumi.String(a.Name.Value)
+			}
+			if a.Type != nil {
+				aliasType, err := resolveAliasType(pkg, typ, a.Type.Value)
+				if err != nil {
+					e.error(a.Type, fmt.Sprintf("invalid alias type: %v", err))
+					overallOk = false
+					continue
+				}
+				alias.Type = pulumi.String(aliasType)
+			}
+			if a.Project != nil {
+				alias.Project = pulumi.String(a.Project.Value)
+			}
+			if a.Stack != nil {
+				alias.Stack = pulumi.String(a.Stack.Value)
 			}
 			aliases = append(aliases, alias)
 		}
@@ -1258,7 +1692,7 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 				if p, ok := r.(poisonMarker); ok {
 					return p, true
 				}
-				dependsOn = append(dependsOn, r.CustomResource())
+				dependsOn = append(dependsOn, r.pulumiResource())
 			}
 			opts = append(opts, pulumi.DependsOn(dependsOn))
 		} else {
@@ -1266,29 +1700,64 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 		}
 	}
 	if v.Options.Import != nil {
-		opts = append(opts, pulumi.Import(pulumi.ID(v.Options.Import.Value)))
+		importValue, ok := e.evaluateExpr(v.Options.Import)
+		if ok {
+			if p, ok := importValue.(poisonMarker); ok {
+				return p, true
+			}
+			if !hasOutputs(importValue) {
+				importID, ok := importValue.(string)
+				if ok {
+					opts = append(opts, pulumi.Import(pulumi.ID(importID)))
+				} else {
+					e.error(v.Options.Import, "import must be a string value")
+					overallOk = false
+				}
+			} else {
+				e.error(v.Options.Import, "import must not be an output")
+				overallOk = false
+			}
+		} else {
+			overallOk = false
+		}
 	}
 	if v.Options.IgnoreChanges != nil {
 		opts = append(opts, pulumi.IgnoreChanges(listStrings(v.Options.IgnoreChanges)))
 	}
+	var parent lateboundResource
 	if v.Options.Parent != nil {
 		parentOpt, ok := e.evaluateResourceValuedOption(v.Options.Parent, "parent")
 		if ok {
 			if p, ok := parentOpt.(poisonMarker); ok {
 				return p, true
 			}
-			opts = append(opts, pulumi.Parent(parentOpt.CustomResource()))
+			parent = parentOpt
+			opts = append(opts, pulumi.Parent(parentOpt.pulumiResource()))
 		} else {
 			overallOk = false
 		}
 	}
+	if parent == nil && e.component != nil {
+		// The resources of a component are its children, unless they set a parent of their own.
+		parent = e.component.resource
+		opts = append(opts, pulumi.Parent(parent.pulumiResource()))
+	}
+	// A resource inherits protect from its parent unless it sets it itself. The provider is
+	// inherited by the SDK in the same way, from the parent's provider or providers for the
+	// resource's package.
+	protected := parent != nil && parent.isProtected()
 	if v.Options.Protect != nil {
 		protectValue, ok := e.evaluateExpr(v.Options.Protect)
 		if ok {
 			if !hasOutputs(protectValue) {
 				protect, ok := protectValue.(bool)
 				if ok {
-					opts = append(opts, pulumi.Protect(protect))
+					if !protect && protected {
+						// The SDK protects the children of a protected resource regardless.
+						e.addWarnDiag(v.Options.Protect.Syntax().Syntax().Range(),
+							"'protect: false' has no effect, because the parent of this resource is protected", "")
+					}
+					protected = protect || protected
 				} else {
 					e.error(v.Options.Protect, "protect must be a boolean value")
 					overallOk = false
@@ -1302,6 +1771,9 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 			overallOk = false
 		}
 	}
+	if protected {
+		opts = append(opts, pulumi.Protect(true))
+	}
 
 	if v.Options.Provider != nil {
 		providerOpt, ok := e.evaluateResourceValuedOption(v.Options.Provider, "provider")
@@ -1342,7 +1814,11 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 	}
 
 	if v.Options.PluginDownloadURL != nil {
-		opts = append(opts, pulumi.PluginDownloadURL(v.Options.PluginDownloadURL.Value))
+		if url, ok := e.evaluatePluginDownloadURL(v.Options.PluginDownloadURL, version, pkg); ok {
+			opts = append(opts, pulumi.PluginDownloadURL(url))
+		} else {
+			overallOk = false
+		}
 	}
 	if v.Options.ReplaceOnChanges != nil {
 		opts = append(opts, pulumi.ReplaceOnChanges(listStrings(v.Options.ReplaceOnChanges)))
@@ -1356,7 +1832,15 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 			if p, ok := deletedWithOpt.(poisonMarker); ok {
 				return p, true
 			}
-			opts = append(opts, pulumi.DeletedWith(deletedWithOpt.CustomResource()))
+			opts = append(opts, pulumi.DeletedWith(deletedWithOpt.pulumiResource()))
+		} else {
+			overallOk = false
+		}
+	}
+	if v.Options.Transforms != nil {
+		transforms, ok := e.resourceTransforms(v.Options.Transforms)
+		if ok {
+			opts = append(opts, pulumi.Transforms(transforms))
 		} else {
 			overallOk = false
 		}
@@ -1368,6 +1852,17 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 	if v.Name != nil && v.Name.Value != "" {
 		resourceName = v.Name.Value
 	}
+	if e.loop != nil {
+		resourceName = loopResourceName(resourceName, e.loop.suffix)
+		if len(e.loop.dependsOn) > 0 {
+			opts = append(opts, pulumi.DependsOn(e.loop.dependsOn))
+		}
+	}
+	if e.component != nil {
+		// The resources of a component are named after its instance, so that the resources of
+		// different instances don't collide.
+		resourceName = e.component.name + "-" + resourceName
+	}
 
 	var state lateboundResource
 	var res pulumi.Resource
@@ -1376,13 +1871,18 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 		resourceSchema = resType.Resource
 	}
 	isProvider := false
+	component, isLocalComponent := pkg.(componentPackage)
 	if strings.HasPrefix(v.Type.Value, "pulumi:providers:") {
-		r := lateboundProviderResourceState{name: resourceName, resourceSchema: resourceSchema}
+		r := lateboundProviderResourceState{name: resourceName, resourceSchema: resourceSchema, protected: protected}
 		state = &r
 		res = &r
 		isProvider = true
+	} else if isLocalComponent {
+		r := lateboundComponentResourceState{name: resourceName, resourceSchema: resourceSchema, protected: protected}
+		state = &r
+		res = &r
 	} else {
-		r := lateboundCustomResourceState{name: resourceName, resourceSchema: resourceSchema}
+		r := lateboundCustomResourceState{name: resourceName, resourceSchema: resourceSchema, protected: protected}
 		state = &r
 		res = &r
 	}
@@ -1405,6 +1905,7 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 	if !overallOk || e.sdiags.HasErrors() {
 		return nil, false
 	}
+	e.recordResourceVersion(resourceName, pkg.Version())
 
 	isComponent := false
 	if !isProvider {
@@ -1447,8 +1948,16 @@ func (e *programEvaluator) registerResource(kvp resourceNode) (lateboundResource
 		}
 	}
 
+	if !isRead && !isProvider && !e.applyDefaultTags(resourceSchema, props) {
+		return nil, false
+	}
+
 	// Now register the resulting resource with the engine.
-	if isComponent {
+	if isLocalComponent {
+		if !e.registerComponent(component, kvp, typ, state.(*lateboundComponentResourceState), props, opts) {
+			return nil, false
+		}
+	} else if isComponent {
 		typ := tokens.Type(typ)
 		packageRef := e.packageRefs[typ.Package()]
 		err = e.pulumiCtx.RegisterPackageRemoteComponentResource(string(typ), resourceName, untypedArgs(props), res, packageRef, opts...)
@@ -1521,13 +2030,34 @@ func (e *programEvaluator) evaluateResourceListValuedOption(optionExpr ast.Expr,
 		e.error(optionExpr, fmt.Sprintf("resource option %v value must be a list of resource, not an output", key))
 		return nil, false
 	}
+	// Resources declared with forEach are lists or maps of resources, which are flattened.
+	if m, ok := value.(map[string]interface{}); ok {
+		value = []interface{}{m}
+	}
 	dependencies, ok := value.([]interface{})
 	if !ok {
 		e.error(optionExpr, fmt.Sprintf("resource option %v value must be a list of resources", key))
 		return nil, false
 	}
-	var resources []lateboundResource
+	var flattened []interface{}
 	for _, dep := range dependencies {
+		switch dep := dep.(type) {
+		case []interface{}:
+			flattened = append(flattened, dep...)
+		case map[string]interface{}:
+			for _, k := range sortedKeys(dep) {
+				flattened = append(flattened, dep[k])
+			}
+		default:
+			flattened = append(flattened, dep)
+		}
+	}
+	var resources []lateboundResource
+	for _, dep := range flattened {
+		// Resources that aren't created because of their condition are null, and are left out.
+		if dep == nil {
+			continue
+		}
 		res, err := asResource(dep)
 		if err != nil {
 			e.error(optionExpr, err.Error())
@@ -1538,6 +2068,26 @@ func (e *programEvaluator) evaluateResourceListValuedOption(optionExpr ast.Expr,
 	return resources, true
 }
 
+// evaluatePluginDownloadURL expands the placeholders of a pluginDownloadURL option, using the
+// version requested by the version option or else the version of the package pkg, which may be nil.
+func (e *programEvaluator) evaluatePluginDownloadURL(
+	url *ast.StringExpr, version *semver.Version, pkg Package,
+) (string, bool) {
+	if version == nil && pkg != nil {
+		version = pkg.Version()
+	}
+	var v string
+	if version != nil {
+		v = version.String()
+	}
+	expanded, err := packages.ExpandDownloadURL(url.Value, v)
+	if err != nil {
+		e.error(url, err.Error())
+		return "", false
+	}
+	return expanded, true
+}
+
 func (e *programEvaluator) evaluateResourceValuedOption(optionExpr ast.Expr, key string) (lateboundResource, bool) {
 	value, ok := e.evaluateExpr(optionExpr)
 	if !ok {
@@ -1592,6 +2142,13 @@ func (e *programEvaluator) registerOutput(kvp ast.PropertyMapEntry) (pulumi.Inpu
 // - map[string]interface{}
 // - pulumi.Output, where the element type is one of the above
 func (e *programEvaluator) evaluateExpr(x ast.Expr) (interface{}, bool) {
+	if e.trace != nil {
+		return e.traceExpr(x)
+	}
+	return e.evaluateExprValue(x)
+}
+
+func (e *programEvaluator) evaluateExprValue(x ast.Expr) (interface{}, bool) {
 	switch x := x.(type) {
 	case *ast.NullExpr:
 		return nil, true
@@ -1619,14 +2176,78 @@ func (e *programEvaluator) evaluateExpr(x ast.Expr) (interface{}, bool) {
 		return e.evaluateBuiltinJoin(x)
 	case *ast.SplitExpr:
 		return e.evaluateBuiltinSplit(x)
+	case *ast.FormatExpr:
+		return e.evaluateBuiltinFormat(x)
+	case *ast.TrimExpr:
+		return e.evaluateBuiltinTrim(x)
+	case *ast.UpperExpr:
+		return e.evaluateStringFunction(x, x.Value, strings.ToUpper)
+	case *ast.LowerExpr:
+		return e.evaluateStringFunction(x, x.Value, strings.ToLower)
+	case *ast.TitleExpr:
+		return e.evaluateStringFunction(x, x.Value, titleCase)
+	case *ast.ToStringExpr:
+		return e.evaluateConversion(x.Value, convertToString)
+	case *ast.ToNumberExpr:
+		return e.evaluateConversion(x.Value, convertToNumber)
+	case *ast.ToBoolExpr:
+		return e.evaluateConversion(x.Value, convertToBool)
+	case *ast.JSONPathExpr:
+		return e.evaluateBuiltinJSONPath(x)
+	case *ast.LookupExpr:
+		return e.evaluateBuiltinLookup(x)
+	case *ast.SemverSatisfiesExpr:
+		return e.evaluateBuiltinSemverSatisfies(x)
+	case *ast.SemverCompareExpr:
+		return e.evaluateBuiltinSemverCompare(x)
 	case *ast.ToJSONExpr:
 		return e.evaluateBuiltinToJSON(x)
 	case *ast.SelectExpr:
 		return e.evaluateBuiltinSelect(x)
+	case *ast.GetAttExpr:
+		return e.evaluateBuiltinGetAtt(x)
+	case *ast.ResourceByURNExpr:
+		return e.evaluateBuiltinGetResource(x)
+	case *ast.MapExpr:
+		return e.evaluateBuiltinMap(x)
+	case *ast.FilterExpr:
+		return e.evaluateBuiltinFilter(x)
+	case *ast.ReduceExpr:
+		return e.evaluateBuiltinReduce(x)
 	case *ast.ToBase64Expr:
 		return e.evaluateBuiltinToBase64(x)
 	case *ast.FromBase64Expr:
 		return e.evaluateBuiltinFromBase64(x)
+	case *ast.Base64GzipExpr:
+		return e.evaluateBuiltinBase64Gzip(x)
+	case *ast.SortExpr:
+		return e.evaluateBuiltinSort(x)
+	case *ast.UniqueExpr:
+		return e.evaluateBuiltinUnique(x)
+	case *ast.KeysExpr:
+		return e.evaluateBuiltinKeys(x)
+	case *ast.ValuesExpr:
+		return e.evaluateBuiltinValues(x)
+	case *ast.FlattenExpr:
+		return e.evaluateBuiltinFlatten(x)
+	case *ast.RangeExpr:
+		return e.evaluateBuiltinRange(x)
+	case *ast.NowExpr:
+		return e.evaluateBuiltinNow(x)
+	case *ast.TimeAddExpr:
+		return e.evaluateBuiltinTimeAdd(x)
+	case *ast.EnvExpr:
+		return e.evaluateBuiltinEnv(x)
+	case *ast.StackConfigExpr:
+		return e.evaluateBuiltinStackConfig(x)
+	case *ast.UUIDExpr:
+		return e.evaluateBuiltinUUID(x)
+	case *ast.RandomIDExpr:
+		return e.evaluateBuiltinRandomID(x)
+	case *ast.CidrSubnetExpr:
+		return e.evaluateBuiltinCidrSubnet(x)
+	case *ast.CidrHostExpr:
+		return e.evaluateBuiltinCidrHost(x)
 	case *ast.FileAssetExpr:
 		return e.evaluateInterpolatedBuiltinAssetArchive(x, x.Source)
 	case *ast.StringAssetExpr:
@@ -1639,6 +2260,8 @@ func (e *programEvaluator) evaluateExpr(x ast.Expr) (interface{}, bool) {
 		return e.evaluateInterpolatedBuiltinAssetArchive(x, x.Source)
 	case *ast.AssetArchiveExpr:
 		return e.evaluateBuiltinAssetArchive(x)
+	case *ast.GlobAssetsExpr:
+		return e.evaluateBuiltinGlobAssets(x)
 	case *ast.StackReferenceExpr:
 		e.addWarnDiag(x.Syntax().Syntax().Range(),
 			"'fn::stackReference' is deprecated",
@@ -1649,6 +2272,12 @@ func (e *programEvaluator) evaluateExpr(x ast.Expr) (interface{}, bool) {
 		return e.evaluateBuiltinSecret(x)
 	case *ast.ReadFileExpr:
 		return e.evaluateBuiltinReadFile(x)
+	case *ast.EnvFileExpr:
+		return e.evaluateBuiltinEnvFile(x)
+	case *ast.FileHashExpr:
+		return e.evaluateBuiltinFileHash(x)
+	case *ast.WhenUnknownExpr:
+		return e.evaluateExpr(x.Value)
 	default:
 		panic(fmt.Sprintf("fatal: invalid expr type %v", reflect.TypeOf(x)))
 	}
@@ -1724,8 +2353,9 @@ func (e *programEvaluator) evaluateInterpolate(x *ast.InterpolateExpr) (interfac
 }
 
 func (e *programEvaluator) evaluateInterpolations(x *ast.InterpolateExpr, b *strings.Builder, parts []ast.Interpolation) (interface{}, bool) {
-	for ; len(parts) > 0; parts = parts[1:] {
+	for len(parts) > 0 {
 		i := parts[0]
+		parts = parts[1:]
 		b.WriteString(i.Text)
 
 		if i.Value != nil {
@@ -1739,8 +2369,7 @@ func (e *programEvaluator) evaluateInterpolations(x *ast.InterpolateExpr, b *str
 
 			if o, ok := p.(pulumi.Output); ok {
 				return o.ApplyT(func(v interface{}) (interface{}, error) {
-					fmt.Fprintf(b, "%v", v)
-					v, ok := e.evaluateInterpolations(x, b, parts[1:])
+					v, ok := e.evaluateInterpolations(x, b, writeInterpolation(b, i, v, parts))
 					if !ok {
 						return nil, fmt.Errorf("runtime error")
 					}
@@ -1748,12 +2377,23 @@ func (e *programEvaluator) evaluateInterpolations(x *ast.InterpolateExpr, b *str
 				}), true
 			}
 
-			fmt.Fprintf(b, "%v", p)
+			parts = writeInterpolation(b, i, p, parts)
 		}
 	}
 	return b.String(), true
 }
 
+// writeInterpolation writes the value v of the interpolation i to b, and returns the parts that
+// are left to evaluate. If v is null or empty and i has a default, nothing is written, and the
+// default is evaluated before the rest.
+func writeInterpolation(b *strings.Builder, i ast.Interpolation, v interface{}, rest []ast.Interpolation) []ast.Interpolation {
+	if i.Default != nil && (v == nil || v == "") {
+		return append(append([]ast.Interpolation{}, i.Default.Parts...), rest...)
+	}
+	fmt.Fprintf(b, "%v", v)
+	return rest
+}
+
 func unknownOutput() pulumi.Output {
 	return pulumi.UnsafeUnknownOutput(nil)
 }
@@ -1765,11 +2405,17 @@ func unknownOutput() pulumi.Output {
 func (e *programEvaluator) evaluatePropertyAccess(expr ast.Expr, access *ast.PropertyAccess) (interface{}, bool) {
 	resourceName := access.RootName()
 	var receiver interface{}
-	if res, ok := e.resources[resourceName]; ok {
+	if v, ok := e.element.binding(resourceName); ok {
+		receiver = v
+	} else if v, ok := e.loop.binding(resourceName); ok {
+		receiver = v
+	} else if res, ok := e.resource(resourceName); ok {
 		receiver = res
+	} else if e.resourceAbsent(resourceName) {
+		return e.evaluateAbsentResource(expr, resourceName)
 	} else if p, ok := e.config[resourceName]; ok {
 		receiver = p
-	} else if v, ok := e.variables[resourceName]; ok {
+	} else if v, ok := e.variable(resourceName); ok {
 		receiver = v
 	} else if p, ok := e.config[stripConfigNamespace(e.pulumiCtx.Project(), resourceName)]; ok {
 		receiver = p
@@ -1801,10 +2447,10 @@ func (e *programEvaluator) evaluatePropertyAccessTail(expr ast.Expr, receiver in
 				// Peak ahead at the next accessor to implement .urn and .id:
 				if len(accessors) >= 1 {
 					sub, ok := accessors[0].(*ast.PropertyName)
-					if ok && sub.Name == "id" {
+					if ok && sub.Name == "id" && x.CustomResource() != nil {
 						return x.CustomResource().ID().ToStringOutput(), true
 					} else if ok && sub.Name == "urn" {
-						return x.CustomResource().URN().ToStringOutput(), true
+						return x.pulumiResource().URN().ToStringOutput(), true
 					}
 
 					outputs := x.GetRawOutputs()
@@ -1885,19 +2531,7 @@ func (e *programEvaluator) evaluatePropertyAccessTail(expr ast.Expr, receiver in
 					receiver = x.ArchiveValue()
 				case x.IsResourceReference():
 					ref := x.ResourceReferenceValue()
-					var state lateboundResource
-					var res pulumi.Resource
-					if strings.HasPrefix(string(ref.URN.Type()), "pulumi:providers:") {
-						r := lateboundProviderResourceState{name: ""}
-						state = &r
-						res = &r
-					} else {
-						r := lateboundCustomResourceState{name: ""}
-						state = &r
-						res = &r
-					}
-					// Use the `getResource` invoke to get and deserialize the resource from state:
-					err := e.pulumiCtx.RegisterResource("_", "_", nil, res, pulumi.URN_(string(ref.URN)))
+					state, err := e.getResourceByURN(ref.URN)
 					if err != nil {
 						e.error(expr, fmt.Sprintf("Failed to get resource %q: %v", ref.URN, err))
 						return nil, false
@@ -1984,32 +2618,44 @@ func (e *programEvaluator) evaluateBuiltinInvoke(t *ast.InvokeExpr) (interface{}
 		return nil, false
 	}
 
+	// The function token is either a literal or a reference to a config value that is only known
+	// at runtime.
+	var token interface{}
+	var tokenExpr ast.Expr = t.Token
+	if t.TokenRef != nil {
+		tokenExpr = t.TokenRef
+		token, ok = e.evaluateExpr(t.TokenRef)
+		if !ok {
+			return nil, false
+		}
+	} else {
+		token = t.Token.Value
+	}
+
 	var opts []pulumi.InvokeOption
 
 	if t.CallOpts.Version != nil {
 		opts = append(opts, pulumi.Version(t.CallOpts.Version.Value))
 	}
-	if t.CallOpts.PluginDownloadURL != nil {
-		opts = append(opts, pulumi.PluginDownloadURL(t.CallOpts.PluginDownloadURL.Value))
-	}
 	if t.CallOpts.Parent != nil {
 		parentOpt, ok := e.evaluateResourceValuedOption(t.CallOpts.Parent, "parent")
 		if ok {
 			if p, ok := parentOpt.(poisonMarker); ok {
 				return p, true
 			}
-			opts = append(opts, pulumi.Parent(parentOpt.CustomResource()))
+			opts = append(opts, pulumi.Parent(parentOpt.pulumiResource()))
 		} else {
 			e.error(t.Return, fmt.Sprintf("Unable to evaluate options Parent field: %+v", t.CallOpts.Parent))
 		}
 	}
+	var provider *pulumi.ProviderResourceState
 	if t.CallOpts.Provider != nil {
 		providerOpt, ok := e.evaluateResourceValuedOption(t.CallOpts.Provider, "provider")
 		if ok {
 			if p, ok := providerOpt.(poisonMarker); ok {
 				return p, true
 			}
-			provider := providerOpt.ProviderResource()
+			provider = providerOpt.ProviderResource()
 			if provider == nil {
 				e.error(t.CallOpts.Provider, fmt.Sprintf("resource passed as Provider was not a provider resource '%s'", providerOpt))
 			} else {
@@ -2028,13 +2674,36 @@ func (e *programEvaluator) evaluateBuiltinInvoke(t *ast.InvokeExpr) (interface{}
 				if p, ok := r.(poisonMarker); ok {
 					return p, true
 				}
-				dependsOn = append(dependsOn, r.CustomResource())
+				dependsOn = append(dependsOn, r.pulumiResource())
 			}
 			opts = append(opts, pulumi.DependsOn(dependsOn))
 		} else {
 			e.error(t.Return, fmt.Sprintf("Unable to evaluate options DependsOn field: %+v", t.CallOpts.DependsOn))
 		}
 	}
+	// Variables listed in 'after' only affect the order of evaluation, but the invoke must also
+	// wait for any resources listed there to be created.
+	if after, ok := t.CallOpts.After.(*ast.ListExpr); ok {
+		var afterResources []pulumi.Resource
+		for _, elem := range after.Elements {
+			sym, ok := elem.(*ast.SymbolExpr)
+			if !ok {
+				continue
+			}
+			res, ok := e.resource(sym.Property.RootName())
+			if !ok {
+				continue
+			}
+			if p, ok := res.(poisonMarker); ok {
+				return p, true
+			}
+			afterResources = append(afterResources, res.pulumiResource())
+		}
+		if len(afterResources) > 0 {
+			dependsOn = append(dependsOn, afterResources...)
+			opts = append(opts, pulumi.DependsOn(afterResources))
+		}
+	}
 	performInvoke := e.lift(func(args ...interface{}) (interface{}, bool) {
 		// At this point, we've got a function to invoke and some parameters! Invoke away.
 		result := map[string]interface{}{}
@@ -2043,43 +2712,187 @@ func (e *programEvaluator) evaluateBuiltinInvoke(t *ast.InvokeExpr) (interface{}
 			e.error(t.CallOpts.Version, fmt.Sprintf("unable to parse function provider version: %v", err))
 			return nil, true
 		}
-		_, functionName, err := ResolveFunction(e.pulumiCtx.Context(), e.pkgLoader, e.packageDescriptors, t.Token.Value, version)
+		tokenStr, ok := args[1].(string)
+		if !ok {
+			return e.error(tokenExpr, fmt.Sprintf("expected function token to be a string, got %v", typeString(args[1])))
+		}
+		pkg, functionName, err := ResolveFunction(e.pulumiCtx.Context(), e.pkgLoader, e.packageDescriptors, tokenStr, version)
 		if err != nil {
+			if t.TokenRef != nil {
+				return e.error(tokenExpr, fmt.Sprintf("unable to resolve function token %q: %v", tokenStr, err))
+			}
 			return e.error(t, err.Error())
 		}
+		if t.CallOpts.PluginDownloadURL != nil {
+			url, ok := e.evaluatePluginDownloadURL(t.CallOpts.PluginDownloadURL, version, pkg)
+			if !ok {
+				return nil, false
+			}
+			opts = append(opts, pulumi.PluginDownloadURL(url))
+		}
 
+		// Identical invokes share a single call, unless they must wait for other resources or
+		// the template opts out.
+		cacheKey, cacheable := invokeCacheKey{}, false
+		if len(dependsOn) == 0 && (t.CallOpts.Cache == nil || t.CallOpts.Cache.Value) {
+			cacheKey, cacheable = newInvokeCacheKey(string(functionName), args[0], t.CallOpts, provider)
+		}
+		var owned *invokeCacheEntry
+		if cacheable {
+			entry, first := e.invokeCacheEntry(cacheKey)
+			if first {
+				owned = entry
+			} else {
+				// An identical invoke is already running, so wait for its result.
+				<-entry.done
+				if entry.shared {
+					// Each invoke gets its own copy, so that none can change what the others see.
+					result := copyValue(entry.result).(map[string]interface{})
+					return e.invokeResult(t, tokenStr, result, dependsOn, false)
+				}
+			}
+		}
 		typ := tokens.Type(functionName)
 		packageRef := e.packageRefs[typ.Package()]
 		secret, err := e.pulumiCtx.InvokePackageRaw(string(functionName), args[0], &result, packageRef, opts...)
+		if owned != nil {
+			// Secret results aren't shared, so that an identical invoke can't observe them
+			// without being marked secret itself. Failed invokes are retried by each caller.
+			owned.result, owned.shared = result, err == nil && !secret
+			close(owned.done)
+		}
 		if err != nil {
 			return e.error(t, err.Error())
 		}
+		return e.invokeResult(t, tokenStr, result, dependsOn, secret)
+	})
+	return performInvoke(args, token)
+}
 
-		if t.Return.GetValue() == "" {
-			output := pulumi.OutputWithDependencies(e.pulumiCtx.Context(), pulumi.Any(result), dependsOn...)
-			if secret {
-				return pulumi.ToSecret(output), true
-			}
-			return output, true
-		}
-
-		retv, ok := result[t.Return.Value]
-		if !ok {
-			e.error(t.Return, fmt.Sprintf("Unable to evaluate result[%v], result is: %+v", t.Return.Value, t.Return))
-			return e.error(t.Return, fmt.Sprintf("fn::invoke of %s did not contain a property '%s' in the returned value", t.Token.Value, t.Return.Value))
-		}
-
-		output := pulumi.OutputWithDependencies(e.pulumiCtx.Context(), pulumi.Any(retv), dependsOn...)
+// invokeResult builds the value of an invoke expression from the function's result.
+func (e *programEvaluator) invokeResult(
+	t *ast.InvokeExpr, tokenStr string, result map[string]interface{}, dependsOn []pulumi.Resource, secret bool,
+) (interface{}, bool) {
+	if t.Return.GetValue() == "" {
+		output := pulumi.OutputWithDependencies(e.pulumiCtx.Context(), pulumi.Any(result), dependsOn...)
 		if secret {
 			return pulumi.ToSecret(output), true
 		}
 		return output, true
-	})
-	return performInvoke(args)
+	}
+
+	retv, ok := result[t.Return.Value]
+	if !ok {
+		e.error(t.Return, fmt.Sprintf("Unable to evaluate result[%v], result is: %+v", t.Return.Value, t.Return))
+		return e.error(t.Return, fmt.Sprintf("fn::invoke of %s did not contain a property '%s' in the returned value", tokenStr, t.Return.Value))
+	}
+
+	output := pulumi.OutputWithDependencies(e.pulumiCtx.Context(), pulumi.Any(retv), dependsOn...)
+	if secret {
+		return pulumi.ToSecret(output), true
+	}
+	return output, true
 }
 
-func (e *programEvaluator) evaluateBuiltinJoin(v *ast.JoinExpr) (interface{}, bool) {
-	overallOk := true
+// invokeCacheKey identifies invokes that are expected to return the same result.
+type invokeCacheKey struct {
+	function          string
+	args              string
+	version           string
+	pluginDownloadURL string
+	provider          *pulumi.ProviderResourceState
+}
+
+// newInvokeCacheKey returns the cache key for an invoke. Invokes are only cached if their arguments
+// are plain values that can be canonicalized.
+func newInvokeCacheKey(
+	function string, args interface{}, opts ast.InvokeOptionsDecl, provider *pulumi.ProviderResourceState,
+) (invokeCacheKey, bool) {
+	if !isPlainValue(args) {
+		return invokeCacheKey{}, false
+	}
+	// encoding/json sorts map keys, so equal arguments always encode the same way.
+	canonicalArgs, err := json.Marshal(args)
+	if err != nil {
+		return invokeCacheKey{}, false
+	}
+	return invokeCacheKey{
+		function:          function,
+		args:              string(canonicalArgs),
+		version:           opts.Version.GetValue(),
+		pluginDownloadURL: opts.PluginDownloadURL.GetValue(),
+		provider:          provider,
+	}, true
+}
+
+// isPlainValue reports whether v is made up only of JSON compatible values.
+func isPlainValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, bool, int, float64, string:
+		return true
+	case []interface{}:
+		for _, e := range v {
+			if !isPlainValue(e) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		for _, e := range v {
+			if !isPlainValue(e) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// invokeCacheEntry is the result of an invoke that is shared with identical invokes. done is closed
+// once the invoke has returned.
+type invokeCacheEntry struct {
+	done   chan struct{}
+	result map[string]interface{}
+	// Whether result may be used by identical invokes.
+	shared bool
+}
+
+// copyValue returns a deep copy of the maps and lists of v.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = copyValue(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = copyValue(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// invokeCacheEntry returns the entry for key, and whether it was just created. The caller that
+// creates an entry performs the invoke, and the others wait for it.
+func (e *programEvaluator) invokeCacheEntry(key invokeCacheKey) (*invokeCacheEntry, bool) {
+	e.invokeCacheLock.Lock()
+	defer e.invokeCacheLock.Unlock()
+	if entry, ok := e.invokeCache[key]; ok {
+		return entry, false
+	}
+	entry := &invokeCacheEntry{done: make(chan struct{})}
+	e.invokeCache[key] = entry
+	return entry, true
+}
+
+func (e *programEvaluator) evaluateBuiltinJoin(v *ast.JoinExpr) (interface{}, bool) {
+	overallOk := true
 
 	delim, ok := e.evaluateExpr(v.Delimiter)
 	overallOk = overallOk && ok
@@ -2125,122 +2938,906 @@ func (e *programEvaluator) evaluateBuiltinJoin(v *ast.JoinExpr) (interface{}, bo
 			}
 		}
 
-		if !overallOk {
-			return "", false
+		if !overallOk {
+			return "", false
+		}
+
+		return strings.Join(strs, delimStr), true
+	})
+	return join(delim, items)
+}
+
+func (e *programEvaluator) evaluateBuiltinSplit(v *ast.SplitExpr) (interface{}, bool) {
+	delimiter, delimOk := e.evaluateExpr(v.Delimiter)
+	source, sourceOk := e.evaluateExpr(v.Source)
+	if !delimOk || !sourceOk {
+		return nil, false
+	}
+
+	split := e.lift(func(args ...interface{}) (interface{}, bool) {
+		d, delimOk := args[0].(string)
+		if !delimOk {
+			e.error(v.Delimiter, fmt.Sprintf("Must be a string, not %v", typeString(d)))
+		}
+		s, sourceOk := args[1].(string)
+		if !sourceOk {
+			e.error(v.Source, fmt.Sprintf("Must be a string, not %v", typeString(s)))
+		}
+		if !delimOk || !sourceOk {
+			return nil, false
+		}
+		return strings.Split(s, d), true
+	})
+	return split(delimiter, source)
+}
+
+func (e *programEvaluator) evaluateBuiltinTrim(v *ast.TrimExpr) (interface{}, bool) {
+	if v.Cutset == nil {
+		return e.evaluateStringFunction(v, v.Value, strings.TrimSpace)
+	}
+	value, valueOk := e.evaluateExpr(v.Value)
+	cutset, cutsetOk := e.evaluateExpr(v.Cutset)
+	if !valueOk || !cutsetOk {
+		return nil, false
+	}
+	trim := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("expected argument to fn::trim to be a string, got %v", typeString(args[0])))
+		}
+		c, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Cutset, fmt.Sprintf("expected the cutset of fn::trim to be a string, got %v", typeString(args[1])))
+		}
+		return strings.Trim(s, c), true
+	})
+	return trim(value, cutset)
+}
+
+func (e *programEvaluator) evaluateBuiltinFormat(v *ast.FormatExpr) (interface{}, bool) {
+	args := make([]interface{}, 1+len(v.Values))
+	allOk := true
+	for i, x := range append([]ast.Expr{v.Format}, v.Values...) {
+		arg, ok := e.evaluateExpr(x)
+		args[i], allOk = arg, allOk && ok
+	}
+	if !allOk {
+		return nil, false
+	}
+	format := e.lift(func(args ...interface{}) (interface{}, bool) {
+		f, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Format, fmt.Sprintf("the format of fn::format must be a string, not %v", typeString(args[0])))
+		}
+		text, verbs, err := parseFormat(f)
+		if err != nil {
+			return e.error(v.Format, err.Error())
+		}
+		values := args[1:]
+		if len(verbs) != len(values) {
+			return e.error(v, formatArgsError(len(verbs), len(values)).Error())
+		}
+		var b strings.Builder
+		for i, verb := range verbs {
+			b.WriteString(text[i])
+			s, err := formatValue(verb, values[i])
+			if err != nil {
+				return e.error(v.Values[i], err.Error())
+			}
+			b.WriteString(s)
+		}
+		b.WriteString(text[len(verbs)])
+		return b.String(), true
+	})
+	return format(args...)
+}
+
+// evaluateStringFunction evaluates a builtin, such as fn::upper, that applies f to a single string.
+func (e *programEvaluator) evaluateStringFunction(v ast.BuiltinExpr, value ast.Expr, f func(string) string) (interface{}, bool) {
+	str, ok := e.evaluateExpr(value)
+	if !ok {
+		return nil, false
+	}
+	apply := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(value, fmt.Sprintf("expected argument to %s to be a string, got %v", v.Name().Value, typeString(args[0])))
+		}
+		return f(s), true
+	})
+	return apply(str)
+}
+
+// evaluateConversion evaluates fn::toString, fn::toNumber or fn::toBool, whose conversion is
+// convert. Unknown values stay unknown.
+func (e *programEvaluator) evaluateConversion(value ast.Expr, convert func(interface{}) (interface{}, error)) (interface{}, bool) {
+	v, ok := e.evaluateExpr(value)
+	if !ok {
+		return nil, false
+	}
+	apply := e.lift(func(args ...interface{}) (interface{}, bool) {
+		result, err := convert(args[0])
+		if err != nil {
+			return e.error(value, err.Error())
+		}
+		return result, true
+	})
+	return apply(v)
+}
+
+// titleCase maps the first letter of each word in s to title case. Words are separated by
+// anything but letters, digits, underscores and apostrophes.
+func titleCase(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		start := !(unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '_' || prev == '\'')
+		prev = r
+		if start {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+func (e *programEvaluator) evaluateBuiltinLookup(v *ast.LookupExpr) (interface{}, bool) {
+	m, mapOk := e.evaluateExpr(v.Map)
+	key, keyOk := e.evaluateExpr(v.Key)
+	def, defOk := e.evaluateExpr(v.Default)
+	if !mapOk || !keyOk || !defOk {
+		return nil, false
+	}
+
+	lookup := e.lift(func(args ...interface{}) (interface{}, bool) {
+		switch m := args[0].(type) {
+		case map[string]interface{}:
+			key, ok := args[1].(string)
+			if !ok {
+				return e.error(v.Key, fmt.Sprintf("the key to look up in a map must be a string, not %v", typeString(args[1])))
+			}
+			if value, ok := m[key]; ok {
+				return value, true
+			}
+			return args[2], true
+		case []interface{}:
+			index, ok := e.integerArg(v.Key, args[1], "the key to look up in a list")
+			if !ok {
+				return nil, false
+			}
+			if index < 0 || index >= int64(len(m)) {
+				return e.error(v.Key, fmt.Sprintf("index %d is out of range for a list of %d elements", index, len(m)))
+			}
+			return m[index], true
+		default:
+			return e.error(v.Map, fmt.Sprintf("the first argument to fn::lookup must be a map or a list, not %v", typeString(args[0])))
+		}
+	})
+	return lookup(m, key, def)
+}
+
+func (e *programEvaluator) evaluateBuiltinRange(v *ast.RangeExpr) (interface{}, bool) {
+	start, startOk := e.evaluateExpr(v.Start)
+	end, endOk := e.evaluateExpr(v.End)
+	var step interface{} = float64(1)
+	stepOk := true
+	if v.Step != nil {
+		step, stepOk = e.evaluateExpr(v.Step)
+	}
+	if !startOk || !endOk || !stepOk {
+		return nil, false
+	}
+
+	rangeF := e.lift(func(args ...interface{}) (interface{}, bool) {
+		start, ok := e.integerArg(v.Start, args[0], "start")
+		if !ok {
+			return nil, false
+		}
+		end, ok := e.integerArg(v.End, args[1], "end")
+		if !ok {
+			return nil, false
+		}
+		stepExpr := v.Step
+		if stepExpr == nil {
+			stepExpr = v
+		}
+		step, ok := e.integerArg(stepExpr, args[2], "step")
+		if !ok {
+			return nil, false
+		}
+		if step == 0 {
+			return e.error(stepExpr, "step must not be 0")
+		}
+
+		result := []interface{}{}
+		for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+			result = append(result, float64(i))
+		}
+		return result, true
+	})
+	return rangeF(start, end, step)
+}
+
+func (e *programEvaluator) evaluateBuiltinNow(v *ast.NowExpr) (interface{}, bool) {
+	now, err := e.currentTime()
+	if err != nil {
+		return e.error(v, err.Error())
+	}
+	return now.Format(time.RFC3339), true
+}
+
+func (e *programEvaluator) evaluateBuiltinTimeAdd(v *ast.TimeAddExpr) (interface{}, bool) {
+	base, baseOk := e.evaluateExpr(v.Base)
+	duration, durationOk := e.evaluateExpr(v.Duration)
+	if !baseOk || !durationOk {
+		return nil, false
+	}
+
+	timeAdd := e.lift(func(args ...interface{}) (interface{}, bool) {
+		base, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Base, fmt.Sprintf("the time to fn::timeAdd must be a string, not %v", typeString(args[0])))
+		}
+		duration, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Duration, fmt.Sprintf("the duration to fn::timeAdd must be a string, not %v", typeString(args[1])))
+		}
+		t, err := parseTime(base)
+		if err != nil {
+			return e.error(v.Base, err.Error())
+		}
+		d, err := parseDuration(duration)
+		if err != nil {
+			return e.error(v.Duration, err.Error())
+		}
+		return t.Add(d).Format(time.RFC3339), true
+	})
+	return timeAdd(base, duration)
+}
+
+func (e *programEvaluator) evaluateBuiltinEnv(v *ast.EnvExpr) (interface{}, bool) {
+	name, ok := e.evaluateExpr(v.Variable)
+	if !ok {
+		return nil, false
+	}
+	var def interface{}
+	if v.Default != nil {
+		def, ok = e.evaluateExpr(v.Default)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	env := e.lift(func(args ...interface{}) (interface{}, bool) {
+		name, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Variable, fmt.Sprintf("the name of the environment variable must be a string, not %v", typeString(args[0])))
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		if v.Default == nil {
+			return e.error(v, fmt.Sprintf("environment variable %q is not set, and fn::env has no default", name))
+		}
+		def, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Default, fmt.Sprintf("the default of fn::env must be a string, not %v", typeString(args[1])))
+		}
+		return def, true
+	})
+	return env(name, def)
+}
+
+func (e *programEvaluator) evaluateBuiltinStackConfig(v *ast.StackConfigExpr) (interface{}, bool) {
+	key, ok := e.evaluateExpr(v.Key)
+	if !ok {
+		return nil, false
+	}
+	var def interface{}
+	if v.Default != nil {
+		def, ok = e.evaluateExpr(v.Default)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	stackConfig := e.lift(func(args ...interface{}) (interface{}, bool) {
+		key, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Key, fmt.Sprintf("the key of fn::stackConfig must be a string, not %v", typeString(args[0])))
+		}
+		if !strings.Contains(key, ":") {
+			key = e.pulumiCtx.Project() + ":" + key
+		}
+		if value, ok := e.pulumiCtx.GetConfig(key); ok {
+			if e.pulumiCtx.IsConfigSecret(key) {
+				return pulumi.ToSecret(value), true
+			}
+			return value, true
+		}
+		if v.Default == nil {
+			return e.error(v, fmt.Sprintf("stack config %q is not set, and fn::stackConfig has no default", key))
+		}
+		def, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Default, fmt.Sprintf("the default of fn::stackConfig must be a string, not %v", typeString(args[1])))
+		}
+		return def, true
+	})
+	return stackConfig(key, def)
+}
+
+func (e *programEvaluator) evaluateBuiltinUUID(v *ast.UUIDExpr) (interface{}, bool) {
+	return e.randomValue(v, 16, formatUUID)
+}
+
+func (e *programEvaluator) evaluateBuiltinRandomID(v *ast.RandomIDExpr) (interface{}, bool) {
+	byteLength, ok := e.evaluateExpr(v.ByteLength)
+	if !ok {
+		return nil, false
+	}
+
+	randomID := e.lift(func(args ...interface{}) (interface{}, bool) {
+		n, ok := e.integerArg(v.ByteLength, args[0], "the byte length of fn::randomId")
+		if !ok {
+			return nil, false
+		}
+		if n < 1 || n > maxRandomIDLength {
+			return e.error(v.ByteLength, fmt.Sprintf("the byte length of fn::randomId must be between 1 and %d, not %d",
+				maxRandomIDLength, n))
+		}
+		return e.randomValue(v, int(n), hex.EncodeToString)
+	})
+	return randomID(byteLength)
+}
+
+func (e *programEvaluator) evaluateBuiltinJSONPath(v *ast.JSONPathExpr) (interface{}, bool) {
+	doc, docOk := e.evaluateExpr(v.JSON)
+	path, pathOk := e.evaluateExpr(v.Path)
+	if !docOk || !pathOk {
+		return nil, false
+	}
+
+	jsonPath := e.lift(func(args ...interface{}) (interface{}, bool) {
+		doc, ok := args[0].(string)
+		if !ok {
+			return e.error(v.JSON, fmt.Sprintf("the document must be a JSON string, not %v", typeString(args[0])))
+		}
+		path, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Path, fmt.Sprintf("the path must be a string, not %v", typeString(args[1])))
+		}
+		p, err := compileJSONPath(path)
+		if err != nil {
+			return e.error(v.Path, err.Error())
+		}
+		var root interface{}
+		if err := json.Unmarshal([]byte(doc), &root); err != nil {
+			return e.error(v.JSON, fmt.Sprintf("invalid JSON: %v", err))
+		}
+		return p.selectFrom(root), true
+	})
+	return jsonPath(doc, path)
+}
+
+func (e *programEvaluator) evaluateBuiltinSemverSatisfies(v *ast.SemverSatisfiesExpr) (interface{}, bool) {
+	version, versionOk := e.evaluateExpr(v.Version)
+	constraint, constraintOk := e.evaluateExpr(v.Constraint)
+	if !versionOk || !constraintOk {
+		return nil, false
+	}
+
+	satisfies := e.lift(func(args ...interface{}) (interface{}, bool) {
+		version, ok := e.semverArg(v.Version, args[0])
+		if !ok {
+			return nil, false
+		}
+		c, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Constraint, fmt.Sprintf("the constraint must be a string, not %v", typeString(args[1])))
+		}
+		r, err := parseSemverRange(c)
+		if err != nil {
+			return e.error(v.Constraint, err.Error())
+		}
+		return r(version), true
+	})
+	return satisfies(version, constraint)
+}
+
+func (e *programEvaluator) evaluateBuiltinSemverCompare(v *ast.SemverCompareExpr) (interface{}, bool) {
+	left, leftOk := e.evaluateExpr(v.Left)
+	right, rightOk := e.evaluateExpr(v.Right)
+	if !leftOk || !rightOk {
+		return nil, false
+	}
+
+	compare := e.lift(func(args ...interface{}) (interface{}, bool) {
+		left, ok := e.semverArg(v.Left, args[0])
+		if !ok {
+			return nil, false
+		}
+		right, ok := e.semverArg(v.Right, args[1])
+		if !ok {
+			return nil, false
+		}
+		return float64(left.Compare(right)), true
+	})
+	return compare(left, right)
+}
+
+// semverArg parses the argument arg of fn::semverSatisfies or fn::semverCompare as a version.
+func (e *programEvaluator) semverArg(expr ast.Expr, arg interface{}) (semver.Version, bool) {
+	s, ok := arg.(string)
+	if !ok {
+		e.error(expr, fmt.Sprintf("the version must be a string, not %v", typeString(arg)))
+		return semver.Version{}, false
+	}
+	version, err := parseSemver(s)
+	if err != nil {
+		e.error(expr, err.Error())
+		return semver.Version{}, false
+	}
+	return version, true
+}
+
+func (e *programEvaluator) evaluateBuiltinToJSON(v *ast.ToJSONExpr) (interface{}, bool) {
+	value, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+
+	toJSON := e.lift(func(args ...interface{}) (interface{}, bool) {
+		b, err := json.Marshal(args[0])
+		if err != nil {
+			e.error(v, fmt.Sprintf("failed to encode JSON: %v", err))
+			return "", false
+		}
+		return string(b), true
+	})
+	return toJSON(value)
+}
+
+func (e *programEvaluator) evaluateBuiltinSelect(v *ast.SelectExpr) (interface{}, bool) {
+	index, ok := e.evaluateExpr(v.Index)
+	if !ok {
+		return nil, false
+	}
+	values, ok := e.evaluateExpr(v.Values)
+	if !ok {
+		return nil, false
+	}
+
+	selectFn := e.lift(func(args ...interface{}) (interface{}, bool) {
+		indexArg := args[0]
+		elemsArg := args[1]
+
+		index, ok := indexArg.(float64)
+		if !ok {
+			return e.error(v.Index, fmt.Sprintf("index must be a number, not %v", typeString(indexArg)))
+		}
+		if float64(int(index)) != index || int(index) < 0 {
+			// Cannot be a valid index, so we error
+			f := strconv.FormatFloat(index, 'f', -1, 64) // Manual formatting is so -3 does not get formatted as -3.0
+			return e.error(v.Index, fmt.Sprintf("index must be a positive integral, not %s", f))
+		}
+		intIndex := int(index)
+
+		return e.evaluatePropertyAccessTail(v.Values, elemsArg, []ast.PropertyAccessor{&ast.PropertySubscript{Index: intIndex}})
+	})
+	return selectFn(index, values)
+}
+
+func (e *programEvaluator) evaluateBuiltinGetAtt(v *ast.GetAttExpr) (interface{}, bool) {
+	res, ok := e.evaluateExpr(v.Resource)
+	if !ok {
+		return nil, false
+	}
+	attribute, ok := e.evaluateExpr(v.Attribute)
+	if !ok {
+		return nil, false
+	}
+
+	getAtt := e.lift(func(args ...interface{}) (interface{}, bool) {
+		if _, ok := args[0].(lateboundResource); !ok {
+			return e.error(v.Resource, fmt.Sprintf("the first argument to fn::getAtt must be a resource, not %v", typeString(args[0])))
+		}
+		name, ok := args[1].(string)
+		if !ok {
+			return e.error(v.Attribute, fmt.Sprintf("the name of the attribute must be a string, not %v", typeString(args[1])))
+		}
+		return e.evaluatePropertyAccessTail(v, args[0], []ast.PropertyAccessor{&ast.PropertyName{Name: name}})
+	})
+	return getAtt(res, attribute)
+}
+
+func (e *programEvaluator) evaluateBuiltinGetResource(v *ast.ResourceByURNExpr) (interface{}, bool) {
+	value, ok := e.evaluateExpr(v.URN)
+	if !ok {
+		return nil, false
+	}
+
+	getResource := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(v.URN, fmt.Sprintf("the URN of fn::getResource must be a string, not %v", typeString(args[0])))
+		}
+		urn, err := resource.ParseURN(s)
+		if err != nil {
+			return e.error(v.URN, err.Error())
+		}
+		state, err := e.getResourceByURN(urn)
+		if err != nil {
+			return e.error(v, fmt.Sprintf("Failed to get resource %q: %v", urn, err))
+		}
+		return state, true
+	})
+	return getResource(value)
+}
+
+// getResourceByURN gets the resource urn from the engine's state, whose outputs are resolved
+// once it has been read.
+func (e *programEvaluator) getResourceByURN(urn resource.URN) (lateboundResource, error) {
+	var state lateboundResource
+	var res pulumi.Resource
+	if strings.HasPrefix(string(urn.Type()), "pulumi:providers:") {
+		r := lateboundProviderResourceState{name: ""}
+		state = &r
+		res = &r
+	} else {
+		r := lateboundCustomResourceState{name: ""}
+		state = &r
+		res = &r
+	}
+	// Use the `getResource` invoke to get and deserialize the resource from state:
+	err := e.pulumiCtx.RegisterResource("_", "_", nil, res, pulumi.URN_(string(urn)))
+	return state, err
+}
+
+func (e *programEvaluator) evaluateBuiltinFromBase64(v *ast.FromBase64Expr) (interface{}, bool) {
+	str, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	fromBase64 := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("expected argument to fn::fromBase64 to be a string, got %v", typeString(args[0])))
+		}
+		b, err := b64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return e.error(v.Value, fmt.Sprintf("fn::fromBase64 unable to decode %v, error: %v", args[0], err))
+		}
+		decoded := string(b)
+		if !utf8.ValidString(decoded) {
+			return e.error(v.Value, "fn::fromBase64 output is not a valid UTF-8 string")
+		}
+		return decoded, true
+	})
+	return fromBase64(str)
+}
+
+func (e *programEvaluator) evaluateBuiltinToBase64(v *ast.ToBase64Expr) (interface{}, bool) {
+	// Encode files as they are read, rather than holding both the file and its encoding in memory.
+	if readFile, ok := v.Value.(*ast.ReadFileExpr); ok {
+		return e.evaluateReadFileToBase64(readFile)
+	}
+
+	str, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	toBase64 := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("expected argument to fn::toBase64 to be a string, got %v", typeString(args[0])))
+		}
+		return b64.StdEncoding.EncodeToString([]byte(s)), true
+	})
+	return toBase64(str)
+}
+
+// evaluateBuiltinBase64Gzip gzip-compresses the UTF-8 bytes of a string with the default
+// compression level and encodes the compressed stream as standard, padded base64.
+func (e *programEvaluator) evaluateBuiltinBase64Gzip(v *ast.Base64GzipExpr) (interface{}, bool) {
+	str, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	base64Gzip := e.lift(func(args ...interface{}) (interface{}, bool) {
+		s, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("expected argument to fn::base64gzip to be a string, got %v", typeString(args[0])))
+		}
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write([]byte(s)); err != nil {
+			return e.error(v.Value, fmt.Sprintf("fn::base64gzip unable to compress input: %v", err))
+		}
+		if err := w.Close(); err != nil {
+			return e.error(v.Value, fmt.Sprintf("fn::base64gzip unable to compress input: %v", err))
+		}
+		return b64.StdEncoding.EncodeToString(buf.Bytes()), true
+	})
+	return base64Gzip(str)
+}
+
+func (e *programEvaluator) evaluateBuiltinSort(v *ast.SortExpr) (interface{}, bool) {
+	list, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	sortFn := e.lift(func(args ...interface{}) (interface{}, bool) {
+		elems, ok := e.scalarListArg(v.Value, args[0], "fn::sort")
+		if !ok {
+			return nil, false
+		}
+		sorted := make([]interface{}, len(elems))
+		copy(sorted, elems)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			switch a := sorted[i].(type) {
+			case string:
+				return a < sorted[j].(string)
+			default:
+				return a.(float64) < sorted[j].(float64)
+			}
+		})
+		return sorted, true
+	})
+	return sortFn(list)
+}
+
+func (e *programEvaluator) evaluateBuiltinKeys(v *ast.KeysExpr) (interface{}, bool) {
+	m, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	keysFn := e.lift(func(args ...interface{}) (interface{}, bool) {
+		m, ok := args[0].(map[string]interface{})
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("the argument to fn::keys must be a map, not %v", typeString(args[0])))
+		}
+		keys := make([]interface{}, 0, len(m))
+		for _, k := range sortedKeys(m) {
+			keys = append(keys, k)
+		}
+		return keys, true
+	})
+	return keysFn(m)
+}
+
+func (e *programEvaluator) evaluateBuiltinValues(v *ast.ValuesExpr) (interface{}, bool) {
+	m, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	valuesFn := e.lift(func(args ...interface{}) (interface{}, bool) {
+		m, ok := args[0].(map[string]interface{})
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("the argument to fn::values must be a map, not %v", typeString(args[0])))
+		}
+		values := make([]interface{}, 0, len(m))
+		for _, k := range sortedKeys(m) {
+			values = append(values, m[k])
+		}
+		return values, true
+	})
+	return valuesFn(m)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e *programEvaluator) evaluateBuiltinUnique(v *ast.UniqueExpr) (interface{}, bool) {
+	list, ok := e.evaluateExpr(v.Value)
+	if !ok {
+		return nil, false
+	}
+	unique := e.lift(func(args ...interface{}) (interface{}, bool) {
+		elems, ok := e.scalarListArg(v.Value, args[0], "fn::unique")
+		if !ok {
+			return nil, false
 		}
-
-		return strings.Join(strs, delimStr), true
+		seen := map[interface{}]struct{}{}
+		result := make([]interface{}, 0, len(elems))
+		for _, elem := range elems {
+			if _, ok := seen[elem]; ok {
+				continue
+			}
+			seen[elem] = struct{}{}
+			result = append(result, elem)
+		}
+		return result, true
 	})
-	return join(delim, items)
+	return unique(list)
 }
 
-func (e *programEvaluator) evaluateBuiltinSplit(v *ast.SplitExpr) (interface{}, bool) {
-	delimiter, delimOk := e.evaluateExpr(v.Delimiter)
-	source, sourceOk := e.evaluateExpr(v.Source)
-	if !delimOk || !sourceOk {
+// scalarListArg checks that arg is a list whose elements are either all strings or all numbers.
+func (e *programEvaluator) scalarListArg(expr ast.Expr, arg interface{}, fn string) ([]interface{}, bool) {
+	elems, ok := arg.([]interface{})
+	if !ok {
+		e.error(expr, fmt.Sprintf("the argument to %s must be a list, not %v", fn, typeString(arg)))
 		return nil, false
 	}
-
-	split := e.lift(func(args ...interface{}) (interface{}, bool) {
-		d, delimOk := args[0].(string)
-		if !delimOk {
-			e.error(v.Delimiter, fmt.Sprintf("Must be a string, not %v", typeString(d)))
-		}
-		s, sourceOk := args[1].(string)
-		if !sourceOk {
-			e.error(v.Source, fmt.Sprintf("Must be a string, not %v", typeString(s)))
+	for i, elem := range elems {
+		switch elem.(type) {
+		case string, float64:
+		default:
+			e.error(expr, fmt.Sprintf("%s requires a list of strings or numbers, but element %d is %v", fn, i, typeString(elem)))
+			return nil, false
 		}
-		if !delimOk || !sourceOk {
+		if i > 0 && reflect.TypeOf(elem) != reflect.TypeOf(elems[0]) {
+			e.error(expr, fmt.Sprintf("%s requires all elements to have the same type, but element 0 is %v and element %d is %v",
+				fn, typeString(elems[0]), i, typeString(elem)))
 			return nil, false
 		}
-		return strings.Split(s, d), true
-	})
-	return split(delimiter, source)
+	}
+	return elems, true
 }
 
-func (e *programEvaluator) evaluateBuiltinToJSON(v *ast.ToJSONExpr) (interface{}, bool) {
-	value, ok := e.evaluateExpr(v.Value)
+func (e *programEvaluator) evaluateBuiltinFlatten(v *ast.FlattenExpr) (interface{}, bool) {
+	list, ok := e.evaluateExpr(v.Value)
 	if !ok {
 		return nil, false
 	}
+	var depth interface{} = 1.0
+	if v.Depth != nil {
+		depth, ok = e.evaluateExpr(v.Depth)
+		if !ok {
+			return nil, false
+		}
+	}
 
-	toJSON := e.lift(func(args ...interface{}) (interface{}, bool) {
-		b, err := json.Marshal(args[0])
-		if err != nil {
-			e.error(v, fmt.Sprintf("failed to encode JSON: %v", err))
-			return "", false
+	flatten := e.lift(func(args ...interface{}) (interface{}, bool) {
+		elems, ok := args[0].([]interface{})
+		if !ok {
+			return e.error(v.Value, fmt.Sprintf("the argument to fn::flatten must be a list, not %v", typeString(args[0])))
 		}
-		return string(b), true
+		depth, ok := e.integerArg(v.Depth, args[1], "depth")
+		if !ok {
+			return nil, false
+		}
+		if depth < 0 {
+			return e.error(v.Depth, fmt.Sprintf("depth must not be negative, got %d", depth))
+		}
+		return flattenList(elems, depth), true
 	})
-	return toJSON(value)
+	return flatten(list, depth)
 }
 
-func (e *programEvaluator) evaluateBuiltinSelect(v *ast.SelectExpr) (interface{}, bool) {
-	index, ok := e.evaluateExpr(v.Index)
+func flattenList(elems []interface{}, depth int64) []interface{} {
+	result := make([]interface{}, 0, len(elems))
+	for _, elem := range elems {
+		if inner, ok := elem.([]interface{}); ok && depth > 0 {
+			result = append(result, flattenList(inner, depth-1)...)
+		} else {
+			result = append(result, elem)
+		}
+	}
+	return result
+}
+
+func (e *programEvaluator) evaluateBuiltinCidrSubnet(v *ast.CidrSubnetExpr) (interface{}, bool) {
+	prefix, ok := e.evaluateExpr(v.Prefix)
 	if !ok {
 		return nil, false
 	}
-	values, ok := e.evaluateExpr(v.Values)
+	newbits, ok := e.evaluateExpr(v.Newbits)
+	if !ok {
+		return nil, false
+	}
+	netnum, ok := e.evaluateExpr(v.Netnum)
 	if !ok {
 		return nil, false
 	}
 
-	selectFn := e.lift(func(args ...interface{}) (interface{}, bool) {
-		indexArg := args[0]
-		elemsArg := args[1]
-
-		index, ok := indexArg.(float64)
+	cidrSubnet := e.lift(func(args ...interface{}) (interface{}, bool) {
+		prefix, ok := e.cidrPrefixArg(v.Prefix, args[0])
 		if !ok {
-			return e.error(v.Index, fmt.Sprintf("index must be a number, not %v", typeString(indexArg)))
+			return nil, false
 		}
-		if float64(int(index)) != index || int(index) < 0 {
-			// Cannot be a valid index, so we error
-			f := strconv.FormatFloat(index, 'f', -1, 64) // Manual formatting is so -3 does not get formatted as -3.0
-			return e.error(v.Index, fmt.Sprintf("index must be a positive integral, not %s", f))
+		newbits, ok := e.integerArg(v.Newbits, args[1], "newbits")
+		if !ok {
+			return nil, false
+		}
+		netnum, ok := e.integerArg(v.Netnum, args[2], "netnum")
+		if !ok {
+			return nil, false
 		}
-		intIndex := int(index)
 
-		return e.evaluatePropertyAccessTail(v.Values, elemsArg, []ast.PropertyAccessor{&ast.PropertySubscript{Index: intIndex}})
+		addrBits := prefix.Addr().BitLen()
+		if newbits < 0 || int64(prefix.Bits())+newbits > int64(addrBits) {
+			return e.error(v.Newbits, fmt.Sprintf("insufficient address space to extend prefix of %d by %d", prefix.Bits(), newbits))
+		}
+		maxNetnum := new(big.Int).Lsh(big.NewInt(1), uint(newbits))
+		if netnum < 0 || big.NewInt(netnum).Cmp(maxNetnum) >= 0 {
+			return e.error(v.Netnum, fmt.Sprintf("prefix extension of %d does not accommodate a subnet numbered %d", newbits, netnum))
+		}
+
+		bits := prefix.Bits() + int(newbits)
+		addr := new(big.Int).SetBytes(prefix.Addr().AsSlice())
+		addr.Or(addr, new(big.Int).Lsh(big.NewInt(netnum), uint(addrBits-bits)))
+		return netip.PrefixFrom(addrFromInt(addr, addrBits), bits).String(), true
 	})
-	return selectFn(index, values)
+	return cidrSubnet(prefix, newbits, netnum)
 }
 
-func (e *programEvaluator) evaluateBuiltinFromBase64(v *ast.FromBase64Expr) (interface{}, bool) {
-	str, ok := e.evaluateExpr(v.Value)
+func (e *programEvaluator) evaluateBuiltinCidrHost(v *ast.CidrHostExpr) (interface{}, bool) {
+	prefix, ok := e.evaluateExpr(v.Prefix)
 	if !ok {
 		return nil, false
 	}
-	fromBase64 := e.lift(func(args ...interface{}) (interface{}, bool) {
-		s, ok := args[0].(string)
+	hostnum, ok := e.evaluateExpr(v.Hostnum)
+	if !ok {
+		return nil, false
+	}
+
+	cidrHost := e.lift(func(args ...interface{}) (interface{}, bool) {
+		prefix, ok := e.cidrPrefixArg(v.Prefix, args[0])
 		if !ok {
-			return e.error(v.Value, fmt.Sprintf("expected argument to fn::fromBase64 to be a string, got %v", typeString(args[0])))
+			return nil, false
 		}
-		b, err := b64.StdEncoding.DecodeString(s)
-		if err != nil {
-			return e.error(v.Value, fmt.Sprintf("fn::fromBase64 unable to decode %v, error: %v", args[0], err))
+		hostnum, ok := e.integerArg(v.Hostnum, args[1], "hostnum")
+		if !ok {
+			return nil, false
 		}
-		decoded := string(b)
-		if !utf8.ValidString(decoded) {
-			return e.error(v.Value, "fn::fromBase64 output is not a valid UTF-8 string")
+
+		// Negative host numbers count back from the end of the range.
+		addrBits := prefix.Addr().BitLen()
+		size := new(big.Int).Lsh(big.NewInt(1), uint(addrBits-prefix.Bits()))
+		host := big.NewInt(hostnum)
+		if hostnum < 0 {
+			host.Add(host, size)
 		}
-		return decoded, true
+		if host.Sign() < 0 || host.Cmp(size) >= 0 {
+			return e.error(v.Hostnum, fmt.Sprintf("prefix of %d does not accommodate a host numbered %d", prefix.Bits(), hostnum))
+		}
+
+		addr := new(big.Int).SetBytes(prefix.Addr().AsSlice())
+		addr.Or(addr, host)
+		return addrFromInt(addr, addrBits).String(), true
 	})
-	return fromBase64(str)
+	return cidrHost(prefix, hostnum)
 }
 
-func (e *programEvaluator) evaluateBuiltinToBase64(v *ast.ToBase64Expr) (interface{}, bool) {
-	str, ok := e.evaluateExpr(v.Value)
+// cidrPrefixArg parses a network prefix in CIDR notation, discarding any host bits.
+func (e *programEvaluator) cidrPrefixArg(expr ast.Expr, arg interface{}) (netip.Prefix, bool) {
+	s, ok := arg.(string)
 	if !ok {
-		return nil, false
+		e.error(expr, fmt.Sprintf("prefix must be a string, not %v", typeString(arg)))
+		return netip.Prefix{}, false
 	}
-	toBase64 := e.lift(func(args ...interface{}) (interface{}, bool) {
-		s, ok := args[0].(string)
-		if !ok {
-			return e.error(v.Value, fmt.Sprintf("expected argument to fn::toBase64 to be a string, got %v", typeString(args[0])))
-		}
-		return b64.StdEncoding.EncodeToString([]byte(s)), true
-	})
-	return toBase64(str)
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		e.error(expr, fmt.Sprintf("invalid CIDR prefix %q: %v", s, err))
+		return netip.Prefix{}, false
+	}
+	return prefix.Masked(), true
+}
+
+func (e *programEvaluator) integerArg(expr ast.Expr, arg interface{}, name string) (int64, bool) {
+	f, ok := arg.(float64)
+	if !ok {
+		e.error(expr, fmt.Sprintf("%s must be a number, not %v", name, typeString(arg)))
+		return 0, false
+	}
+	if f != float64(int64(f)) {
+		e.error(expr, fmt.Sprintf("%s must be an integer, not %s", name, strconv.FormatFloat(f, 'f', -1, 64)))
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// addrFromInt converts an integer back into an IP address of the given bit length.
+func addrFromInt(i *big.Int, bits int) netip.Addr {
+	b := make([]byte, bits/8)
+	i.FillBytes(b)
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
 }
 
 func (e *programEvaluator) evaluateBuiltinAssetArchive(v *ast.AssetArchiveExpr) (interface{}, bool) {
@@ -2272,16 +3869,47 @@ func (e *programEvaluator) evaluateBuiltinAssetArchive(v *ast.AssetArchiveExpr)
 	return pulumi.NewAssetArchive(m), true
 }
 
+func (e *programEvaluator) evaluateBuiltinGlobAssets(v *ast.GlobAssetsExpr) (interface{}, bool) {
+	expr, ok := e.evaluateExpr(v.Pattern)
+	if !ok {
+		return nil, false
+	}
+
+	globAssetsF := e.lift(func(args ...interface{}) (interface{}, bool) {
+		pattern, ok := args[0].(string)
+		if !ok {
+			return e.error(v.Pattern, fmt.Sprintf("the argument to fn::globAssets must be a string, got %v", typeString(args[0])))
+		}
+		files, err := globProjectFiles(e.cwd, pattern)
+		if err != nil {
+			return e.error(v.Pattern, err.Error())
+		}
+		if len(files) == 0 {
+			return e.error(v.Pattern, fmt.Sprintf("the pattern %q does not match any files", pattern))
+		}
+		m := make(map[string]interface{}, len(files))
+		for key, file := range files {
+			m[key] = pulumi.NewFileAsset(file)
+		}
+		return pulumi.NewAssetArchive(m), true
+	})
+
+	return globAssetsF(expr)
+}
+
 func (e *programEvaluator) evaluateBuiltinStackReference(v *ast.StackReferenceExpr) (interface{}, bool) {
+	e.stackRefsLock.Lock()
 	stackRef, ok := e.stackRefs[v.StackName.Value]
 	if !ok {
 		var err error
 		stackRef, err = pulumi.NewStackReference(e.pulumiCtx, v.StackName.Value, &pulumi.StackReferenceArgs{})
 		if err != nil {
+			e.stackRefsLock.Unlock()
 			return e.error(v.StackName, err.Error())
 		}
 		e.stackRefs[v.StackName.Value] = stackRef
 	}
+	e.stackRefsLock.Unlock()
 
 	property, ok := e.evaluateExpr(v.PropertyName)
 	if !ok {
@@ -2341,24 +3969,252 @@ func (e *programEvaluator) evaluateInterpolatedBuiltinAssetArchive(x, s ast.Expr
 }
 
 func (e *programEvaluator) evaluateBuiltinReadFile(s *ast.ReadFileExpr) (interface{}, bool) {
-	expr, ok := e.evaluateExpr(s.Path)
+	path, sha, timeout, ok := e.evaluateReadFileArgs(s)
 	if !ok {
 		return nil, false
 	}
 
 	readFileF := e.lift(func(args ...interface{}) (interface{}, bool) {
+		path, sha, ok := e.readFileArgs(s, args[0], args[1])
+		if !ok {
+			return nil, false
+		}
+
+		var data []byte
+		if isRemotePath(path) {
+			data, ok = e.fetchReadFileURL(s, path, args[2])
+			if !ok {
+				return nil, false
+			}
+		} else {
+			var err error
+			data, err = os.ReadFile(path)
+			if err != nil {
+				e.error(s.Path, fmt.Sprintf("Error reading file at path %v: %v", path, err))
+			}
+		}
+		if !e.checkReadFileSha256(s, path, sha256.Sum256(data), sha) {
+			return nil, false
+		}
+		return string(data), true
+	})
+
+	return readFileF(path, sha, timeout)
+}
+
+// evaluateReadFileArgs evaluates the path, sha256 and timeout of fn::readFile s. The sha256 and
+// timeout are empty strings if they aren't given.
+func (e *programEvaluator) evaluateReadFileArgs(s *ast.ReadFileExpr) (path, sha, timeout interface{}, ok bool) {
+	if path, ok = e.evaluateExpr(s.Path); !ok {
+		return nil, nil, nil, false
+	}
+	sha, timeout = "", ""
+	if s.Sha256 != nil {
+		if sha, ok = e.evaluateExpr(s.Sha256); !ok {
+			return nil, nil, nil, false
+		}
+	}
+	if s.Timeout != nil {
+		if timeout, ok = e.evaluateExpr(s.Timeout); !ok {
+			return nil, nil, nil, false
+		}
+	}
+	return path, sha, timeout, true
+}
+
+// readFileArgs checks the known path and sha256 of fn::readFile s.
+func (e *programEvaluator) readFileArgs(s *ast.ReadFileExpr, path, sha interface{}) (string, string, bool) {
+	pathStr, ok := path.(string)
+	if !ok {
+		e.error(s.Path, fmt.Sprintf("Argument to fn::readFile must be a string, got %v", reflect.TypeOf(path)))
+		return "", "", false
+	}
+	shaStr, ok := sha.(string)
+	if !ok {
+		e.error(s.Sha256, fmt.Sprintf("sha256 must be a string, got %v", typeString(sha)))
+		return "", "", false
+	}
+	if shaStr != "" && !isSha256Hex(shaStr) {
+		e.error(s.Sha256, fmt.Sprintf("sha256 must be 64 hexadecimal characters, got %q", shaStr))
+		return "", "", false
+	}
+	return pathStr, shaStr, true
+}
+
+// checkReadFileSha256 checks the digest of the contents read by fn::readFile s against the expected
+// sha256, if one is given.
+func (e *programEvaluator) checkReadFileSha256(s *ast.ReadFileExpr, path string, sum [sha256.Size]byte, sha string) bool {
+	if sha == "" {
+		return true
+	}
+	if err := checkSha256Sum(sum, sha); err != nil {
+		e.error(s.Sha256, fmt.Sprintf("checksum mismatch for %v: %v", path, err))
+		return false
+	}
+	return true
+}
+
+// fetchReadFileURL fetches url for fn::readFile s, waiting at most the given timeout.
+func (e *programEvaluator) fetchReadFileURL(s *ast.ReadFileExpr, url string, timeout interface{}) ([]byte, bool) {
+	d := defaultReadFileTimeout
+	if str, ok := timeout.(string); !ok {
+		e.error(s.Timeout, fmt.Sprintf("timeout must be a string, got %v", typeString(timeout)))
+		return nil, false
+	} else if str != "" {
+		var err error
+		if d, err = parseReadFileTimeout(str); err != nil {
+			e.error(s.Timeout, err.Error())
+			return nil, false
+		}
+	}
+	data, err := fetchURL(url, d)
+	if err != nil {
+		e.error(s.Path, fmt.Sprintf("Error fetching %v: %v", url, err))
+		return nil, false
+	}
+	return data, true
+}
+
+func (e *programEvaluator) evaluateBuiltinEnvFile(s *ast.EnvFileExpr) (interface{}, bool) {
+	expr, ok := e.evaluateExpr(s.Path)
+	if !ok {
+		return nil, false
+	}
+
+	envFileF := e.lift(func(args ...interface{}) (interface{}, bool) {
 		path, ok := args[0].(string)
 		if !ok {
-			return e.error(s.Path, fmt.Sprintf("Argument to fn::readFile must be a string, got %v", reflect.TypeOf(args[0])))
+			return e.error(s.Path, fmt.Sprintf("the argument to fn::envFile must be a string, got %v", typeString(args[0])))
 		}
-		data, err := os.ReadFile(path)
+		resolved, err := resolveProjectPath(e.cwd, "env file", path)
 		if err != nil {
-			e.error(s.Path, fmt.Sprintf("Error reading file at path %v: %v", path, err))
+			return e.error(s.Path, err.Error())
 		}
-		return string(data), true
+		data, err := os.ReadFile(resolved)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return e.error(s.Path, fmt.Sprintf("env file %q does not exist", path))
+			}
+			return e.error(s.Path, fmt.Sprintf("unable to read env file %q: %v", path, err))
+		}
+		env, err := parseDotenv(data)
+		if err != nil {
+			return e.error(s.Path, fmt.Sprintf("invalid env file %q: %v", path, err))
+		}
+		result := make(map[string]interface{}, len(env))
+		for k, v := range env {
+			result[k] = v
+		}
+		return result, true
+	})
+
+	return envFileF(expr)
+}
+
+func (e *programEvaluator) evaluateBuiltinFileHash(s *ast.FileHashExpr) (interface{}, bool) {
+	path, ok := e.evaluateExpr(s.Path)
+	if !ok {
+		return nil, false
+	}
+	var algorithm interface{} = defaultFileHashAlgorithm
+	if s.Algorithm != nil {
+		algorithm, ok = e.evaluateExpr(s.Algorithm)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	fileHashF := e.lift(func(args ...interface{}) (interface{}, bool) {
+		path, ok := args[0].(string)
+		if !ok {
+			return e.error(s.Path, fmt.Sprintf("the path to fn::fileHash must be a string, got %v", typeString(args[0])))
+		}
+		algorithm, ok := args[1].(string)
+		if !ok {
+			return e.error(s.Algorithm, fmt.Sprintf("the algorithm of fn::fileHash must be a string, got %v", typeString(args[1])))
+		}
+		h, err := newFileHash(algorithm)
+		if err != nil {
+			return e.error(s.Algorithm, err.Error())
+		}
+		resolved, err := resolveProjectPath(e.cwd, "file", path)
+		if err != nil {
+			return e.error(s.Path, err.Error())
+		}
+		f, err := os.Open(resolved)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return e.error(s.Path, fmt.Sprintf("file %q does not exist", path))
+			}
+			return e.error(s.Path, fmt.Sprintf("unable to read file %q: %v", path, err))
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return e.error(s.Path, fmt.Sprintf("unable to read file %q: %v", path, err))
+		}
+		return hex.EncodeToString(h.Sum(nil)), true
+	})
+
+	return fileHashF(path, algorithm)
+}
+
+// evaluateReadFileToBase64 evaluates fn::toBase64 applied to fn::readFile. The result is the same
+// as encoding the file's contents read as a string, and the file is checked the same way.
+func (e *programEvaluator) evaluateReadFileToBase64(s *ast.ReadFileExpr) (interface{}, bool) {
+	path, sha, timeout, ok := e.evaluateReadFileArgs(s)
+	if !ok {
+		return nil, false
+	}
+
+	toBase64F := e.lift(func(args ...interface{}) (interface{}, bool) {
+		path, sha, ok := e.readFileArgs(s, args[0], args[1])
+		if !ok {
+			return nil, false
+		}
+		if isRemotePath(path) {
+			data, ok := e.fetchReadFileURL(s, path, args[2])
+			if !ok || !e.checkReadFileSha256(s, path, sha256.Sum256(data), sha) {
+				return nil, false
+			}
+			return b64.StdEncoding.EncodeToString(data), true
+		}
+		encoded, sum, err := encodeFileBase64(path)
+		if err != nil {
+			return e.error(s.Path, fmt.Sprintf("Error reading file at path %v: %v", path, err))
+		}
+		if !e.checkReadFileSha256(s, path, sum, sha) {
+			return nil, false
+		}
+		return encoded, true
 	})
 
-	return readFileF(expr)
+	return toBase64F(path, sha, timeout)
+}
+
+// encodeFileBase64 returns the standard base64 encoding of the file at path and the SHA-256
+// digest of its contents, streaming the file through the encoder.
+func encodeFileBase64(path string) (string, [sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return "", sum, err
+	}
+	defer f.Close()
+
+	var sb strings.Builder
+	if info, err := f.Stat(); err == nil {
+		sb.Grow(b64.StdEncoding.EncodedLen(int(info.Size())))
+	}
+	encoder := b64.NewEncoder(b64.StdEncoding, &sb)
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(encoder, h), f); err != nil {
+		return "", sum, err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sb.String(), sum, nil
 }
 
 func hasOutputs(v interface{}) bool {
@@ -2393,6 +4249,7 @@ func (ctx *evalContext) lift(fn func(args ...interface{}) (interface{}, bool)) f
 	return func(args ...interface{}) (interface{}, bool) {
 		if hasOutputs(args) {
 			return pulumi.All(args...).ApplyT(func(resolved []interface{}) (interface{}, error) {
+				ctx.addSecretArgs(args)
 				v, ok := fnOrPoison(resolved...)
 				if !ok {
 					// TODO: ensure that these appear in CLI
@@ -5,6 +5,7 @@ package pulumiyaml
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,6 +19,8 @@ const (
 )
 
 type interceptingLog struct {
+	// Guards the messages, which apply callbacks may log concurrently.
+	mutex         sync.Mutex
 	debugMessages []string
 	infoMessages  []string
 	warnMessages  []string
@@ -25,18 +28,26 @@ type interceptingLog struct {
 }
 
 func (l *interceptingLog) Debug(msg string, args *pulumi.LogArgs) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.debugMessages = append(l.debugMessages, msg)
 	return nil
 }
 func (l *interceptingLog) Info(msg string, args *pulumi.LogArgs) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.infoMessages = append(l.infoMessages, msg)
 	return nil
 }
 func (l *interceptingLog) Warn(msg string, args *pulumi.LogArgs) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.warnMessages = append(l.warnMessages, msg)
 	return nil
 }
 func (l *interceptingLog) Error(msg string, args *pulumi.LogArgs) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	l.errorMessages = append(l.errorMessages, msg)
 	return nil
 }
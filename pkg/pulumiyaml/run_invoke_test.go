@@ -5,14 +5,17 @@ package pulumiyaml
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -289,6 +292,78 @@ outputs:
 	}
 }
 
+func TestInvokeConfigFunctionToken(t *testing.T) { //nolint:paralleltest
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  fnToken:
+    type: String
+variables:
+  foo:
+    fn::invoke:
+      function: ${fnToken}
+      arguments:
+        quux: tuo
+      return: retval
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: ${foo}
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	setConfig(t,
+		resource.PropertyMap{
+			projectConfigKey("fnToken"): resource.NewStringProperty(testInvokeFnToken),
+		})
+	diags := testInvokeDiags(t, tmpl, func(r *Runner) {})
+	requireNoErrors(t, tmpl, diags)
+
+	// The analyser can't check the invoke, so it should say so.
+	_, diags = TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	var warnings []string
+	for _, d := range diags {
+		warnings = append(warnings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:9:17: the function to invoke is read from config value "fnToken" at runtime; Its arguments and return value are not type checked.`,
+	}, warnings)
+}
+
+func TestInvokeConfigFunctionTokenInvalid(t *testing.T) { //nolint:paralleltest
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  fnToken:
+    type: String
+variables:
+  foo:
+    fn::invoke:
+      function: ${fnToken}
+      return: retval
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	setConfig(t,
+		resource.PropertyMap{
+			projectConfigKey("fnToken"): resource.NewStringProperty("test-invoke-type"),
+		})
+	diags := testInvokeDiags(t, tmpl, func(r *Runner) {})
+	var diagStrings []string
+	for _, d := range diags {
+		if d.Severity == hcl.DiagError {
+			diagStrings = append(diagStrings, diagString(d))
+		}
+	}
+	assert.Equal(t, []string{
+		`<stdin>:9:17: unable to resolve function token "test-invoke-type": invalid type token "test-invoke-type"`,
+	}, diagStrings)
+}
+
 func testInvokeDiags(t *testing.T, template *ast.TemplateDecl, callback func(*Runner)) syntax.Diagnostics {
 	mocks := &testMonitor{
 		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
@@ -398,3 +473,570 @@ func testInvokeDiags(t *testing.T, template *ast.TemplateDecl, callback func(*Ru
 	assert.NoError(t, err)
 	return nil
 }
+
+func TestInvokeAfter(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        after:
+          - ${res-a}
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	diags := testInvokeDiags(t, tmpl, func(r *Runner) {
+		assert.Contains(t, r.variables, "lookup")
+	})
+	requireNoErrors(t, tmpl, diags)
+}
+
+func TestInvokeAfterInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  region:
+    type: String
+    default: us-west-2
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        after:
+          - ${region}
+          - ${res-a.foo}
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, d := range diags {
+		diagStrings = append(diagStrings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:13:13: "region" in the 'after' option is not a resource or variable`,
+		`<stdin>:14:13: the 'after' option must only contain references to resources or variables; Use a reference such as ${myResource}`,
+	}, diagStrings)
+}
+
+func TestInvokeProviderInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  valid:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        provider: ${testProvider}
+  notProvider:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        provider: ${res-a}
+  wrongPackage:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        provider: ${dockerProvider}
+resources:
+  testProvider:
+    type: pulumi:providers:test
+  dockerProvider:
+    type: pulumi:providers:docker
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, d := range diags {
+		diagStrings = append(diagStrings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:13:19: resource "res-a" is not a provider; The provider of an invoke must be a resource of type 'pulumi:providers:test'`,
+		`<stdin>:18:19: provider "dockerProvider" is for package "docker", but the function is in package "test"`,
+	}, diagStrings)
+}
+
+func TestInvokeParent(t *testing.T) {
+	t.Parallel()
+
+	// The invoke uses the provider that its parent was given for the function's package.
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  provider-a:
+    type: pulumi:providers:test
+  comp:
+    type: test:component:type
+    properties:
+      foo: oof
+    options:
+      providers:
+        - ${provider-a}
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:type2
+      arguments:
+        quux: tuo
+      options:
+        parent: ${comp}
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	runner := newRunner(tmpl, newMockPackageMap())
+	_, diags := TypeCheck(runner)
+	requireNoErrors(t, tmpl, diags)
+
+	var provider string
+	mocks := &testMonitor{
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			provider = args.Provider
+			return resource.PropertyMap{"retval": resource.NewStringProperty("value")}, nil
+		},
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			return args.Name + "-id", resource.PropertyMap{}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("foo", "dev", mocks))
+	require.NoError(t, err)
+	assert.Equal(t, "urn:pulumi:dev::foo::pulumi:providers:test::provider-a::provider-a-id", provider)
+}
+
+func TestInvokeParentForEach(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  comps:
+    type: test:component:type
+    forEach: [a, b]
+    properties:
+      foo: ${item}
+  byName:
+    type: test:component:type
+    forEach:
+      x: one
+    properties:
+      foo: ${value}
+variables:
+  fromList:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        parent: ${comps[1]}
+  fromMap:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        parent: ${byName["x"]}
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	runner := newRunner(tmpl, newMockPackageMap())
+	_, diags := TypeCheck(runner)
+	requireNoErrors(t, tmpl, diags)
+
+	mocks := &testMonitor{
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			return resource.PropertyMap{"retval": resource.NewStringProperty("value")}, nil
+		},
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			return args.Name + "-id", resource.PropertyMap{}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("foo", "dev", mocks))
+	require.NoError(t, err)
+
+	// The resources themselves are lists and maps of instances.
+	tmpl = yamlTemplate(t, strings.TrimSpace(strings.ReplaceAll(text, "${comps[1]}", "${comps}")))
+	_, diags = TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		`<stdin>:20:17: the 'parent' option of an invoke must be a reference to a resource; ` +
+			`comps is declared with forEach, so use ${comps[0]} or ${comps["key"]} to refer to one of its instances`,
+	}, diagStrings(diags))
+}
+
+func TestInvokeParentInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  region:
+    type: String
+    default: us-west-2
+variables:
+  fromConfig:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        parent: ${region}
+  fromProperty:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        parent: ${res-a.foo}
+  fromString:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        parent: res-a
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		`<stdin>:12:17: config "region" can't be the parent of an invoke; The parent of an invoke must be a resource`,
+		`<stdin>:17:17: the 'parent' option of an invoke must be a reference to a resource; ` +
+			`Use ${res-a} to refer to the resource itself, not one of its properties`,
+		`<stdin>:22:17: the 'parent' option of an invoke must be a reference to a resource; ` +
+			`Use a reference such as ${myResource}`,
+	}, diagStrings(diags))
+}
+
+func TestInvokeArgumentDiags(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+variables:
+  valid:
+    fn::invoke:
+      function: test:fn
+      arguments:
+        yesArg: ${res-a.bar}
+  missing:
+    fn::invoke:
+      function: test:fn
+      arguments:
+        someSuchArg: value
+  noArguments:
+    fn::invoke:
+      function: test:fn
+  misspelled:
+    fn::invoke:
+      function: test:fn
+      arguments:
+        yesArgg: value
+  mistyped:
+    fn::invoke:
+      function: test:fn
+      arguments:
+        yesArg: [1, 2]
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.ElementsMatch(t, []string{
+		`<stdin>:18:9: test:fn is not assignable from {someSuchArg: string}; Cannot assign '{someSuchArg: string}' to 'test:fn':
+  yesArg: Missing required property 'yesArg'`,
+		`<stdin>:21:17: test:fn is not assignable from {}; Cannot assign '{}' to 'test:fn':
+  yesArg: Missing required property 'yesArg'`,
+		`<stdin>:26:9: yesArgg does not exist on Invoke test:fn; Existing fields are: yesArg, someSuchArg`,
+		`<stdin>:26:9: test:fn is not assignable from {}; Cannot assign '{}' to 'test:fn':
+  yesArg: Missing required property 'yesArg'`,
+		`<stdin>:31:17: test:fn is not assignable from {yesArg: List<number>}; Cannot assign '{yesArg: List<number>}' to 'test:fn':
+  yesArg: Cannot assign 'List<number>' to 'string'`,
+	}, diagStrings(diags))
+
+	for _, d := range diags {
+		if d.Severity == hcl.DiagWarning {
+			require.NotNil(t, d.Fix)
+			assert.Equal(t, "yesArg", d.Fix.Replacement)
+		} else {
+			assert.Nil(t, d.Fix)
+		}
+	}
+}
+
+func TestInvokeCache(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  first:
+    fn::invoke:
+      function: test:invoke:count
+      arguments:
+        a: 1
+        b: two
+      return: count
+  second:
+    fn::invoke:
+      function: test:invoke:count
+      arguments:
+        b: two
+        a: 1
+      return: count
+  different:
+    fn::invoke:
+      function: test:invoke:count
+      arguments:
+        a: 2
+        b: two
+      return: count
+  uncached:
+    fn::invoke:
+      function: test:invoke:count
+      arguments:
+        a: 1
+        b: two
+      options:
+        cache: false
+      return: count
+  secret:
+    fn::invoke:
+      function: test:invoke:secret
+  secretAgain:
+    fn::invoke:
+      function: test:invoke:secret
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+
+	var m sync.Mutex
+	calls := map[string]int{}
+	mocks := &testMonitor{
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			calls[args.Token]++
+			switch args.Token {
+			case "test:invoke:count":
+				return resource.PropertyMap{
+					"count": resource.NewNumberProperty(float64(calls[args.Token])),
+				}, nil
+			case "test:invoke:secret":
+				return resource.PropertyMap{
+					"response": resource.MakeSecret(resource.NewStringProperty("super-secret")),
+				}, nil
+			}
+			return nil, fmt.Errorf("Unexpected invoke %s", args.Token)
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		// The counts depend on the order of the invokes.
+		runner.parallelism = 1
+		if diags := runner.Evaluate(ctx); diags.HasErrors() {
+			return diags
+		}
+		for name, expected := range map[string]float64{
+			"first":     1,
+			"second":    1,
+			"different": 2,
+			"uncached":  3,
+		} {
+			name, expected := name, expected
+			runner.variables[name].(pulumi.AnyOutput).ApplyT(func(v interface{}) interface{} {
+				assert.Equal(t, expected, v, name)
+				return nil
+			})
+		}
+		return nil
+	}, pulumi.WithMocks(testProject, "dev", mocks))
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		"test:invoke:count":  3,
+		"test:invoke:secret": 2,
+	}, calls)
+}
+
+func TestInvokeAssign(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+      assign:
+        region: settings.region
+        env: tags["env"]
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: ${region}-${env}
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+
+	var m sync.Mutex
+	calls := 0
+	mocks := &testMonitor{
+		CallF: func(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			calls++
+			return resource.NewPropertyMapFromMap(map[string]interface{}{
+				"settings": map[string]interface{}{"region": "us-west-2"},
+				"tags":     map[string]interface{}{"env": "prod"},
+			}), nil
+		},
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			assert.Equal(t, "us-west-2-prod", args.Inputs["foo"].StringValue())
+			return "id", args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		if diags.HasErrors() {
+			return diags
+		}
+		if diags := runner.Evaluate(ctx); diags.HasErrors() {
+			return diags
+		}
+		return nil
+	}, pulumi.WithMocks(testProject, "dev", mocks))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestInvokeAssignDiags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name: "unknown property",
+			text: `variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+      assign:
+        zone: settings.zone
+`,
+			expected: `<stdin>:8,15-28: zone does not exist on lookup.settings; Existing properties are: region`,
+		},
+		{
+			name: "with return",
+			text: `variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+      return: settings
+      assign:
+        region: settings.region
+`,
+			expected: `assign directive cannot be used with the return directive`,
+		},
+		{
+			name: "not a variable",
+			text: `resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo:
+        fn::invoke:
+          function: test:invoke:nested
+          assign:
+            region: settings.region
+`,
+			expected: `the assign directive can only be used when fn::invoke is the value of a variable`,
+		},
+		{
+			name: "duplicate",
+			text: `variables:
+  region: us-east-1
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+      assign:
+        region: settings.region
+`,
+			expected: `found duplicate variable region`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, diags, err := LoadYAMLBytes("<stdin>", []byte("name: test-yaml\nruntime: yaml\n"+tt.text))
+			require.NoError(t, err)
+			if !diags.HasErrors() {
+				_, diags = TypeCheck(newRunner(tmpl, newMockPackageMap()))
+			}
+			require.True(t, diags.HasErrors())
+			assert.Contains(t, diags.Error(), tt.expected)
+		})
+	}
+}
+
+func TestCopyValue(t *testing.T) {
+	t.Parallel()
+
+	original := map[string]interface{}{
+		"tags": map[string]interface{}{"env": "dev"},
+		"list": []interface{}{"a", map[string]interface{}{"b": 1.0}},
+		"name": "x",
+	}
+	c := copyValue(original).(map[string]interface{})
+	assert.Equal(t, original, c)
+
+	// Changing the copy doesn't change the original.
+	c["tags"].(map[string]interface{})["env"] = "prod"
+	c["list"].([]interface{})[1].(map[string]interface{})["b"] = 2.0
+	c["name"] = "y"
+	assert.Equal(t, map[string]interface{}{
+		"tags": map[string]interface{}{"env": "dev"},
+		"list": []interface{}{"a", map[string]interface{}{"b": 1.0}},
+		"name": "x",
+	}, original)
+}
@@ -5,12 +5,17 @@ package pulumiyaml
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
 )
 
 const fakeName = "foo"
@@ -183,3 +188,455 @@ variables:
 	}
 	assert.NoError(t, err)
 }
+
+func TestCustomTimeouts(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:trivial
+    options:
+      customTimeouts:
+        create: 30m
+        update: 1h30m
+        delete: 45s
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			timeouts := args.RegisterRPC.GetCustomTimeouts()
+			assert.Equal(t, "30m", timeouts.GetCreate())
+			assert.Equal(t, "1h30m", timeouts.GetUpdate())
+			assert.Equal(t, "45s", timeouts.GetDelete())
+			return "resourceId", resource.PropertyMap{}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(template, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, template, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, template, diags)
+		return nil
+	}, pulumi.WithMocks("projectFoo", "stackDev", mocks))
+	assert.NoError(t, err)
+}
+
+func TestCustomTimeoutsInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:trivial
+    options:
+      customTimeouts:
+        create: 30x
+        delete: 5m
+        update: soon
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.ElementsMatch(t, []string{
+		`<stdin>:8:17: invalid create timeout "30x"; Custom timeouts must be durations such as "30s", "5m" or "1h30m"`,
+		`<stdin>:10:17: invalid update timeout "soon"; Custom timeouts must be durations such as "30s", "5m" or "1h30m"`,
+	}, messages)
+}
+
+func TestRetainOnDeleteAndDeletedWith(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-b:
+    type: test:resource:trivial
+    options:
+      retainOnDelete: true
+      deletedWith: ${res-container}
+  res-a:
+    type: test:component:type
+    properties:
+      foo: bar
+    options:
+      retainOnDelete: true
+      deletedWith: ${res-container}
+  res-container:
+    type: test:resource:trivial
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+
+	const containerURN = "urn:pulumi:stackDev::projectFoo::test:resource:trivial::res-container"
+	var m sync.Mutex
+	var registered []string
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			m.Lock()
+			registered = append(registered, args.Name)
+			m.Unlock()
+			if args.Name == "res-container" {
+				assert.False(t, args.RegisterRPC.GetRetainOnDelete())
+				assert.Empty(t, args.RegisterRPC.GetDeletedWith())
+			} else {
+				assert.True(t, args.RegisterRPC.GetRetainOnDelete())
+				assert.Equal(t, containerURN, args.RegisterRPC.GetDeletedWith())
+			}
+			return "resourceId", resource.PropertyMap{}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(template, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, template, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, template, diags)
+		return nil
+	}, pulumi.WithMocks("projectFoo", "stackDev", mocks))
+	assert.NoError(t, err)
+	// The deletedWith target must be registered before the resources that reference it.
+	assert.ElementsMatch(t, []string{"res-container", "res-a", "res-b"}, registered)
+	assert.Equal(t, "res-container", registered[0])
+}
+
+func TestDeletedWithInvalid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  name:
+    type: string
+resources:
+  res-a:
+    type: test:resource:trivial
+    options:
+      deletedWith: res-b
+  res-b:
+    type: test:resource:trivial
+    options:
+      deletedWith: ${res-a.id}
+  res-c:
+    type: test:resource:trivial
+    options:
+      deletedWith: ${name}
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.ElementsMatch(t, []string{
+		"<stdin>:10:20: the 'deletedWith' option must be a reference to a resource; Use a reference such as ${myResource}",
+		"<stdin>:14:20: the 'deletedWith' option must be a reference to a resource; Use a reference such as ${myResource}",
+		`<stdin>:18:20: "name" in the 'deletedWith' option is not a resource`,
+	}, messages)
+}
+
+func TestDeletedWithMissing(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:trivial
+    options:
+      deletedWith: ${res-missing}
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	require.Len(t, diags, 1)
+	assert.Equal(t, `<stdin>:7:20: resource, variable, or config value "res-missing" not found`, diagString(diags[0]))
+}
+
+func TestIgnoreChangesValidation(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: hello
+    options:
+      ignoreChanges:
+        - foo
+        - bar.nested[0]
+        - '["foo"]'
+        - "*"
+        - fooo
+        - tags["env"]
+        - bar[
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	requireNoErrors(t, template, diags)
+	var messages []string
+	for _, d := range diags {
+		assert.Equal(t, hcl.DiagWarning, d.Severity)
+		messages = append(messages, diagString(d))
+	}
+	assert.Equal(t, []string{
+		"<stdin>:14:11: Property fooo does not exist on Resource test:resource:type; " +
+			"Did you mean 'foo'? Existing properties are: foo, bar",
+		"<stdin>:15:11: Property tags does not exist on Resource test:resource:type; " +
+			"Existing properties are: bar, foo",
+		`<stdin>:16:11: invalid ignoreChanges path "bar["; missing closing bracket in array index`,
+	}, messages)
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  bucketName:
+    type: string
+    default: my-bucket
+resources:
+  res-a:
+    type: test:resource:trivial
+    options:
+      import: ${bucketName}-id
+  res-b:
+    type: test:resource:trivial
+    options:
+      import: existing-id
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			switch args.Name {
+			case "res-a":
+				assert.Equal(t, "my-bucket-id", args.RegisterRPC.GetImportId())
+			case "res-b":
+				assert.Equal(t, "existing-id", args.RegisterRPC.GetImportId())
+			}
+			return args.RegisterRPC.GetImportId(), resource.PropertyMap{}, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(template, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, template, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, template, diags)
+		return nil
+	}, pulumi.WithMocks("projectFoo", "stackDev", mocks))
+	assert.NoError(t, err)
+}
+
+func TestImportDiags(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:with-read-only
+    properties:
+      arn: arn:bucket
+      size: 10
+    options:
+      import: bucket-id
+  res-b:
+    type: test:resource:trivial
+    options:
+      import: [not, a, string]
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:7:7: property "arn" of imported resource "res-a" is read-only; ` +
+			"It is an output of the resource that the provider sets, so the import will fail if it is set",
+		"<stdin>:14:15: string is not assignable from List<string>; Cannot assign 'List<string>' to 'string'",
+	}, messages)
+}
+
+func TestProtectInheritance(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  provider:
+    type: pulumi:providers:test
+  other:
+    type: pulumi:providers:test
+  component:
+    type: test:component:type
+    properties:
+      foo: bar
+    options:
+      protect: true
+      providers: ["${provider}"]
+  child:
+    type: test:resource:trivial
+    options:
+      parent: ${component}
+  grandchild:
+    type: test:resource:trivial
+    options:
+      parent: ${child}
+  overridden:
+    type: test:resource:trivial
+    options:
+      parent: ${component}
+      provider: ${other}
+  unprotected:
+    type: test:resource:trivial
+    options:
+      parent: ${component}
+      protect: false
+  standalone:
+    type: test:resource:trivial
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+
+	const providerURN = "urn:pulumi:stackDev::projectFoo::pulumi:providers:test::"
+	var m sync.Mutex
+	protect := map[string]bool{}
+	providers := map[string]string{}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			protect[args.Name] = args.RegisterRPC.GetProtect()
+			providers[args.Name] = strings.TrimPrefix(args.RegisterRPC.GetProvider(), providerURN)
+			return "resourceId", resource.PropertyMap{}, nil
+		},
+	}
+	var diags syntax.Diagnostics
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(template, newMockPackageMap())
+		_, tdiags := TypeCheck(runner)
+		requireNoErrors(t, template, tdiags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, template, diags)
+		return nil
+	}, pulumi.WithMocks("projectFoo", "stackDev", mocks))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]bool{
+		"provider":    false,
+		"other":       false,
+		"component":   true,
+		"child":       true,
+		"grandchild":  true,
+		"overridden":  true,
+		"unprotected": true,
+		"standalone":  false,
+	}, protect)
+	assert.Equal(t, "provider::resourceId", providers["child"])
+	assert.Equal(t, "provider::resourceId", providers["grandchild"])
+	assert.Equal(t, "other::resourceId", providers["overridden"])
+	assert.Equal(t, []string{
+		"<stdin>:32:16: 'protect: false' has no effect, because the parent of this resource is protected",
+	}, diagStrings(diags))
+}
+
+func TestAdditionalSecretOutputs(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: hello
+      bar: world
+    options:
+      additionalSecretOutputs: [bar]
+variables:
+  derived: ${res-a.bar}-suffix
+outputs:
+  bar: ${res-a.bar}
+  derived: ${derived}
+  foo: ${res-a.foo}
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+
+	var m sync.Mutex
+	var secretOutputs []string
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			m.Lock()
+			defer m.Unlock()
+			secretOutputs = args.RegisterRPC.GetAdditionalSecretOutputs()
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(template, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, template, diags)
+		requireNoErrors(t, template, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("projectFoo", "stackDev", mocks))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar"}, secretOutputs)
+
+	// Values computed from the output are secret too.
+	outputs, diags := GetSecretOutputs(template, newMockPackageMap())
+	requireNoErrors(t, template, diags)
+	assert.Equal(t, []string{"bar", "derived"}, outputs.Secret)
+}
+
+func TestAdditionalSecretOutputsValidation(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: hello
+    options:
+      additionalSecretOutputs:
+        - foo
+        - barr
+        - password
+`
+	template := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(template, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.Equal(t, []string{
+		"<stdin>:11:11: Output barr does not exist on Resource test:resource:type; " +
+			"Did you mean 'bar'? Existing properties are: bar, foo",
+		"<stdin>:12:11: Output password does not exist on Resource test:resource:type; " +
+			"Existing properties are: bar, foo",
+	}, messages)
+}
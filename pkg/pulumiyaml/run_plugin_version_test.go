@@ -3,12 +3,15 @@
 package pulumiyaml
 
 import (
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hexops/autogold"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/packages"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVersionValueComplex(t *testing.T) {
@@ -230,7 +233,190 @@ resources:
 
 	tmpl := yamlTemplate(t, strings.TrimSpace(text))
 	plugins, diags := GetReferencedPackages(tmpl)
-	assert.Contains(t, diagString(diags[0]), "<stdin>:13:16: Package test already declared with a conflicting version: 1.23.425-beta.6")
-	assert.Contains(t, diagString(diags[1]), "<stdin>:14:26: Package test already declared with a conflicting plugin download URL: https://example.com")
+	assert.Equal(t, "<stdin>:13:16: Package test already declared with a conflicting version: 1.23.425-beta.6 [<stdin>:7:16: First declared here]",
+		diagString(diags[0]))
+	assert.Equal(t, "<stdin>:14:26: Package test already declared with a conflicting plugin download URL: https://example.com [<stdin>:8:26: First declared here]",
+		diagString(diags[1]))
 	assert.Empty(t, plugins)
 }
+
+func TestGetManifest(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  prov:
+    type: pulumi:providers:test
+  res:
+    type: test:resource:type
+    options:
+      provider: ${prov}
+      version: 1.2.3
+    properties:
+      foo: ${fn.outString}
+variables:
+  fn:
+    fn::invoke:
+      function: test:fn
+      arguments:
+        yesArg: yes
+      options:
+        provider: ${prov}
+  configured:
+    fn::invoke:
+      function: ${fnToken}
+config:
+  fnToken:
+    type: string
+`
+
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	manifest, diags := GetManifest(tmpl)
+	requireNoErrors(t, tmpl, diags)
+
+	testPackage := packages.PackageDecl{Name: "test", Version: "1.2.3"}
+	assert.Equal(t, &Manifest{
+		Resources: []PlannedResource{
+			{Name: "prov", Type: "pulumi:providers:test", Package: testPackage},
+			{Name: "res", Type: "test:resource:type", Provider: "prov", Package: testPackage},
+		},
+		Invokes: []PlannedInvoke{
+			{Function: "test:fn", Provider: "prov", Package: testPackage},
+			{FunctionRef: "${fnToken}"},
+		},
+	}, manifest)
+}
+
+func TestDockerImageVersionWarnings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name: "unpinned",
+			text: `
+resources:
+  image:
+    type: docker:image:Image
+`,
+			expected: "<stdin>:5:11: docker:image:Image resources should request version 4 or later of the docker package",
+		},
+		{
+			name: "old version",
+			text: `
+resources:
+  image:
+    type: docker:Image
+    options:
+      version: 3.6.1
+`,
+			expected: "<stdin>:5:11: docker:Image resources are not supported by version 3.6.1 of the docker package",
+		},
+		{
+			name: "pinned",
+			text: `
+resources:
+  image:
+    type: docker:Image
+    options:
+      version: 4.5.0
+`,
+		},
+		{
+			name: "package version",
+			text: `
+resources:
+  provider:
+    type: pulumi:providers:docker
+    options:
+      version: 4.5.0
+  image:
+    type: docker:Image
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := yamlTemplate(t, "name: test-yaml\nruntime: yaml"+tt.text)
+			_, diags := GetReferencedPackages(tmpl)
+			assert.False(t, diags.HasErrors())
+			if tt.expected == "" {
+				assert.Empty(t, diags)
+				return
+			}
+			require.Len(t, diags, 1)
+			assert.Equal(t, hcl.DiagWarning, diags[0].Severity)
+			assert.Contains(t, diagString(diags[0]), tt.expected)
+			assert.Contains(t, diags[0].Detail, "'version: 4.0.0'")
+		})
+	}
+}
+
+func TestPackageChecksums(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+`)
+	checksums := map[string]string{
+		"linux-amd64": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	tmpl.Packages = []packages.PackageDecl{
+		{PackageDeclarationVersion: 1, Name: "test", Version: "1.0.0"},
+		{PackageDeclarationVersion: 1, Name: "test", Checksums: checksums},
+	}
+	plugins, diags := GetReferencedPackages(tmpl)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []packages.PackageDecl{{
+		PackageDeclarationVersion: 1,
+		Name:                      "test",
+		Version:                   "1.0.0",
+		Checksums:                 checksums,
+	}}, plugins)
+}
+
+func TestPluginDownloadURLPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    options:
+      version: 1.2.3
+      pluginDownloadURL: https://mirror.example.com/v${version}/${os}-${arch}
+`)
+	plugins, diags := GetReferencedPackages(tmpl)
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "https://mirror.example.com/v1.2.3/"+runtime.GOOS+"-"+runtime.GOARCH, plugins[0].DownloadURL)
+
+	tmpl = yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    options:
+      pluginDownloadURL: https://mirror.example.com/${version}/${platform}
+`)
+	_, diags = GetReferencedPackages(tmpl)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `plugin download URL "https://mirror.example.com/${version}/${platform}" uses ${version}, `+
+		"but the package's version isn't known", diags[0].Summary)
+	assert.Equal(t, 8, diags[0].Subject.Start.Line)
+}
@@ -3,14 +3,18 @@
 package pulumiyaml
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	b64 "encoding/base64"
@@ -216,6 +220,51 @@ func newMockPackageMap() PackageLoader {
 								ElementType: schema.StringType,
 							},
 						})
+					case "test:resource:with-create-only":
+						return inputProperties(typeName, schema.Property{
+							Name:                 "name",
+							Type:                 schema.StringType,
+							WillReplaceOnChanges: true,
+						}, schema.Property{
+							Name: "size",
+							Type: &schema.OptionalType{ElementType: schema.NumberType},
+						})
+					case "test:resource:with-tags":
+						return inputProperties(typeName, schema.Property{
+							Name: "foo",
+							Type: &schema.OptionalType{ElementType: schema.StringType},
+						}, schema.Property{
+							Name: "tags",
+							Type: &schema.OptionalType{ElementType: &schema.MapType{ElementType: schema.StringType}},
+						})
+					case "pulumi:providers:test":
+						return inputProperties(typeName, schema.Property{
+							Name: "region",
+							Type: &schema.OptionalType{ElementType: schema.StringType},
+						})
+					case "test:resource:with-read-only":
+						typ := inputProperties(typeName, schema.Property{
+							Name: "size",
+							Type: &schema.OptionalType{ElementType: schema.NumberType},
+						})
+						typ.Resource.Properties = append(typ.Resource.Properties, &schema.Property{
+							Name: "arn",
+							Type: schema.StringType,
+						})
+						return typ
+					case "test:resource:with-map-input":
+						return inputProperties(typeName, schema.Property{
+							Name: "mapInput",
+							Type: &schema.MapType{
+								ElementType: &schema.ObjectType{
+									Token: "test:index:MapValue",
+									Properties: []*schema.Property{
+										{Name: "name", Type: schema.StringType},
+										{Name: "size", Type: &schema.OptionalType{ElementType: schema.NumberType}},
+									},
+								},
+							},
+						})
 					default:
 						return inputProperties(typeName)
 					}
@@ -231,6 +280,17 @@ func newMockPackageMap() PackageLoader {
 							[]schema.Property{
 								{Name: "outString", Type: schema.StringType},
 							})
+					case "test:invoke:nested":
+						return function(typeName, nil,
+							[]schema.Property{
+								{Name: "settings", Type: &schema.ObjectType{
+									Token: "test:index:Settings",
+									Properties: []*schema.Property{
+										{Name: "region", Type: schema.StringType},
+									},
+								}},
+								{Name: "tags", Type: &schema.MapType{ElementType: schema.StringType}},
+							})
 					case "test:invoke:poison":
 						return function("test:invoke:poison",
 							[]schema.Property{{Name: "foo", Type: schema.StringType}},
@@ -502,6 +562,71 @@ outputs:
 	assert.Equal(t, `<stdin>:9:8: resource or variable named "res-b" could not be found`, diagString(diags[0]))
 }
 
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:type
+      arguments:
+        quux: 42
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: [1, 2]
+`)
+	diags := Analyze(tmpl, newMockPackageMap())
+	assert.Equal(t, []string{
+		"<stdin>:13:12: test:resource:type is not assignable from {foo: List<number>}; " +
+			"Cannot assign '{foo: List<number>}' to 'test:resource:type':\n  foo: Cannot assign 'List<number>' to 'string'",
+		"<stdin>:8:9: quux does not exist on Invoke test:invoke:type; Invoke test:invoke:type has no fields",
+	}, diagStrings(diags))
+}
+
+func TestAnalyzeSortAndPackageErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: missing:index:Resource
+    defaultProvider: true
+`)
+	diags := Analyze(tmpl, &slowPackageLoader{})
+	assert.ElementsMatch(t, []string{
+		`<stdin>:5:11: error loading package missing; plugin missing not found`,
+		`<stdin>:6:22: cannot set defaultProvider on non-provider resource`,
+	}, diagStrings(diags))
+}
+
+func TestAnalyzeConcurrently(t *testing.T) {
+	t.Parallel()
+
+	loader := newMockPackageMap()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		tmpl := yamlTemplate(t, fmt.Sprintf(`name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    properties:
+      foo: %d
+`, i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Len(t, Analyze(tmpl, loader), 0)
+		}()
+	}
+	wg.Wait()
+}
+
 func TestConfigTypes(t *testing.T) {
 	t.Parallel()
 
@@ -607,6 +732,42 @@ configuration:
 	assert.False(t, found, "We should not get any errors: '%s'", diags)
 }
 
+func TestConfigExpressionDefaults(t *testing.T) { //nolint:paralleltest
+	const text = `name: test-yaml
+runtime: yaml
+configuration:
+  bucketName:
+    default: ${projectName}-${suffix}
+  projectName:
+    type: String
+  region:
+    default: ${projectName}
+  replicas:
+    default: ${defaultReplicas}
+variables:
+  suffix: data
+  defaultReplicas: 3
+`
+
+	tmpl := yamlTemplate(t, text)
+	setConfig(t,
+		resource.PropertyMap{
+			projectConfigKey("projectName"): resource.NewStringProperty("acme"),
+			projectConfigKey("region"):      resource.NewStringProperty("us-west-2"),
+		})
+	testRan := false
+	err := testTemplateDiags(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, "acme-data", e.config["bucketName"])
+		assert.Equal(t, "us-west-2", e.config["region"])
+		assert.Equal(t, 3.0, e.config["replicas"])
+
+		testRan = true
+	})
+	assert.True(t, testRan, "Our tests didn't run")
+	diags, found := HasDiagnostics(err)
+	assert.False(t, found, "We should not get any errors: '%s'", diags)
+}
+
 func TestConflictingConfigSecrets(t *testing.T) { //nolint:paralleltest
 	const text = `name: test-yaml
 runtime: yaml
@@ -655,19 +816,20 @@ resources:
     type: test:resource:type
     properties:
       foo: oof
+outputs:
+  out: 1
+  out: 2
 `
 
-	tmpl := yamlTemplate(t, text)
-	diags := testTemplateDiags(t, tmpl, func(e *programEvaluator) {})
-	var diagStrings []string
-	for _, v := range diags {
-		diagStrings = append(diagStrings, diagString(v))
-	}
-	assert.Contains(t, diagStrings, "<stdin>:6:3: found duplicate config foo")
-	assert.Contains(t, diagStrings, "<stdin>:16:3: found duplicate resource res-a")
-	assert.Contains(t, diagStrings, "<stdin>:10:3: found duplicate variable bar")
-	assert.Len(t, diagStrings, 3)
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(text))
+	require.NoError(t, err)
 	require.True(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`<stdin>:6:3: found duplicate key "foo" in configuration [<stdin>:4:3: The other "foo" is declared here]`,
+		`<stdin>:10:3: found duplicate key "bar" in variables [<stdin>:9:3: The other "bar" is declared here]`,
+		`<stdin>:16:3: found duplicate key "res-a" in resources [<stdin>:12:3: The other "res-a" is declared here]`,
+		`<stdin>:22:3: found duplicate key "out" in outputs [<stdin>:21:3: The other "out" is declared here]`,
+	}, diagStrings(diags))
 }
 
 func TestConflictKeyDiags(t *testing.T) {
@@ -726,6 +888,123 @@ resources:
 	require.True(t, diags.HasErrors())
 }
 
+func TestResourceListForm(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+resources:
+  - name: res-a
+    type: test:resource:type
+    properties:
+      foo: oof
+  - name: comp-a
+    type: test:component:type
+    properties:
+      foo: ${res-a.bar}
+outputs:
+  foo: ${res-a.foo}
+`
+	tmpl := yamlTemplate(t, text)
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Contains(t, e.resources, "res-a")
+		assert.Contains(t, e.resources, "comp-a")
+	})
+}
+
+func TestOutputGroups(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+resources:
+  res-a:
+    type: test:resource:type
+    properties:
+      foo: oof
+outputs:
+  flat: ${res-a.foo}
+outputGroups:
+  network:
+    vpcId: ${res-a.id}
+    cidr: 10.0.0.0/16
+  compute.web:
+    instanceId: ${res-a.bar}
+`
+	tmpl := yamlTemplate(t, text)
+	testTemplate(t, tmpl, func(e *programEvaluator) {})
+
+	var names []string
+	diags := newRunner(tmpl, newMockPackageMap()).Run(walker{
+		VisitOutput: func(ctx *evalContext, name string, expr ast.Expr) bool {
+			names = append(names, name)
+			return true
+		},
+	})
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"flat", "network.vpcId", "network.cidr", "compute.web.instanceId"}, names)
+}
+
+func TestOutputGroupCollisions(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+outputs:
+  network.vpcId: vpc-1
+  compute: i-1
+outputGroups:
+  network:
+    vpcId: vpc-2
+  compute:
+    instanceId: i-2
+`
+	tmpl := yamlTemplate(t, text)
+	diags := testTemplateDiags(t, tmpl, nil)
+	var diagStrings []string
+	for _, v := range diags {
+		diagStrings = append(diagStrings, diagString(v))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:5:3: output "compute" conflicts with the output group "compute" [<stdin>:9:3: The output group is declared here]`,
+		`<stdin>:8:5: found duplicate output "network.vpcId" [<stdin>:4:3: The other output is declared here]`,
+	}, diagStrings)
+}
+
+func TestResourceListFormDuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  comp-a: 1
+resources:
+  - name: res-a
+    type: test:resource:type
+    properties:
+      foo: oof
+  - name: res-a
+    type: test:resource:type
+    properties:
+      foo: oof
+  - name: comp-a
+    type: test:component:type
+    properties:
+      foo: oof
+`
+
+	tmpl := yamlTemplate(t, text)
+	diags := testTemplateDiags(t, tmpl, func(e *programEvaluator) {})
+	var diagStrings []string
+	for _, v := range diags {
+		diagStrings = append(diagStrings, diagString(v))
+	}
+	assert.Contains(t, diagStrings, "<stdin>:10:11: found duplicate resource res-a")
+	assert.Contains(t, diagStrings, "<stdin>:4:3: variable comp-a cannot have the same name as resource comp-a")
+	assert.Len(t, diagStrings, 2)
+	require.True(t, diags.HasErrors())
+}
+
 func TestJSON(t *testing.T) {
 	t.Parallel()
 
@@ -838,6 +1117,110 @@ resources:
 		diagString(diags[0]))
 }
 
+func TestTypedMapPropertyDiags(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  r:
+    type: test:resource:with-map-input
+    properties:
+      mapInput:
+        first:
+          name: a
+          size: 1
+        second:
+          size: 2
+        third:
+          name: c
+`
+	tmpl := yamlTemplate(t, text)
+	diags := testTemplateDiags(t, tmpl, func(e *programEvaluator) {})
+	require.Truef(t, diags.HasErrors(), diags.Error())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "<stdin>:13:11: test:resource:with-map-input is not assignable from {mapInput: {first: {name: string, size: number}, second: {size: number}, third: {name: string}}}; "+
+		"Cannot assign '{mapInput: {first: {name: string, size: number}, second: {size: number}, third: {name: string}}}' to 'test:resource:with-map-input':\n"+
+		"  mapInput: Cannot assign '{first: {name: string, size: number}, second: {size: number}, third: {name: string}}' to 'Map<test:index:MapValue>':\n"+
+		"    second: Cannot assign '{size: number}' to 'test:index:MapValue':\n"+
+		"      name: Missing required property 'name'",
+		diagString(diags[0]))
+}
+
+func TestTypedMapPropertyValid(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+resources:
+  r:
+    type: test:resource:with-map-input
+    properties:
+      mapInput:
+        first:
+          name: a
+          size: 1
+        second:
+          name: b
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+}
+
+func TestInvokeResultPropertyAccess(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+resources:
+  r:
+    type: test:resource:type
+    properties:
+      foo: ${lookup.settings.region}
+      bar: ${lookup.tags.anyKey}
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+}
+
+func TestInvokeResultPropertyAccessTypo(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:nested
+resources:
+  r:
+    type: test:resource:type
+    properties:
+      foo: ${lookup.settings.regoin}
+      bar: ${lookup.tagz.anyKey}
+`
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, d := range diags {
+		diagStrings = append(diagStrings, diagString(d))
+	}
+	assert.Equal(t, []string{
+		"<stdin>:12:12: regoin does not exist on lookup.settings; Existing properties are: region",
+		"<stdin>:13:12: tagz does not exist on lookup; Existing properties are: tags, settings",
+	}, diagStrings)
+}
+
 func TestPropertyAccess(t *testing.T) {
 	t.Parallel()
 	tmpl := template(t, &Template{
@@ -1550,35 +1933,478 @@ func TestToBase64(t *testing.T) {
 	}
 }
 
-func TestSub(t *testing.T) {
+func TestBase64Gzip(t *testing.T) {
 	t.Parallel()
 
-	tmpl := template(t, &Template{
-		Variables: map[string]interface{}{
-			"foo": "oof",
+	decode := func(t *testing.T, s string) string {
+		b, err := b64.StdEncoding.DecodeString(s)
+		require.NoError(t, err)
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(decoded)
+	}
+
+	tests := []struct {
+		input    *ast.Base64GzipExpr
+		expected string
+		isOutput bool
+	}{
+		{
+			input:    ast.Base64Gzip(ast.String("#cloud-config\npackages:\n  - nginx\n")),
+			expected: "#cloud-config\npackages:\n  - nginx\n",
 		},
-		Resources: map[string]*Resource{
-			"resA": {
-				Type: testResourceToken,
-				Properties: map[string]interface{}{
-					"foo": "oof",
+		{
+			input:    ast.Base64Gzip(ast.String("")),
+			expected: "",
+		},
+		{
+			input: ast.Base64Gzip(&ast.SymbolExpr{
+				Property: &ast.PropertyAccess{
+					Accessors: []ast.PropertyAccessor{
+						&ast.PropertyName{Name: "resA"},
+						&ast.PropertyName{Name: "out"},
+					},
 				},
-			},
+			}),
+			expected: "tuo",
+			isOutput: true,
 		},
-	})
-	testTemplate(t, tmpl, func(e *programEvaluator) {
-		v, ok := e.evaluateInterpolate(ast.MustInterpolate("Hello ${foo}!"))
-		assert.True(t, ok)
-		assert.Equal(t, "Hello oof!", v)
+	}
 
-		v, ok = e.evaluateInterpolate(ast.MustInterpolate("Hello ${resA.out} - ${resA.id}!!"))
-		assert.True(t, ok)
-		out := v.(pulumi.AnyOutput).ApplyT(func(x interface{}) (interface{}, error) {
-			assert.Equal(t, "Hello tuo - someID!!", x)
-			return nil, nil
-		})
-		e.pulumiCtx.Export("out", out)
-	})
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{
+				Resources: map[string]*Resource{
+					"resA": {
+						Type: "test:resource:type",
+						Properties: map[string]interface{}{
+							"foo": "oof",
+						},
+					},
+				},
+			})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateBuiltinBase64Gzip(tt.input)
+				assert.True(t, ok)
+				if tt.isOutput {
+					out := v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+						assert.Equal(t, tt.expected, decode(t, x.(string)))
+						return nil, nil
+					})
+					e.pulumiCtx.Export("out", out)
+				} else {
+					assert.Equal(t, tt.expected, decode(t, v.(string)))
+				}
+			})
+		})
+	}
+}
+
+func TestBase64GzipTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  userData:
+    fn::base64gzip: "#!/bin/bash\necho hello\n"
+  notAString:
+    fn::base64gzip:
+      - 1
+`
+
+	tmpl := yamlTemplate(t, text)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.True(t, diags.HasErrors())
+	var diagStrings []string
+	for _, v := range diags {
+		diagStrings = append(diagStrings, diagString(v))
+	}
+	assert.Contains(t, diagStrings,
+		"<stdin>:8:7: string is not assignable from List<number>; Cannot assign 'List<number>' to 'string'")
+}
+
+func TestCidrSubnet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix   string
+		newbits  float64
+		netnum   float64
+		expected string
+		err      string
+	}{
+		{prefix: "10.0.0.0/16", newbits: 8, netnum: 2, expected: "10.0.2.0/24"},
+		{prefix: "172.16.0.0/12", newbits: 4, netnum: 15, expected: "172.31.0.0/16"},
+		{prefix: "10.1.2.3/16", newbits: 0, netnum: 0, expected: "10.1.0.0/16"},
+		{prefix: "fd00:fd12:3456:7890::/56", newbits: 16, netnum: 162, expected: "fd00:fd12:3456:7800:a200::/72"},
+		{prefix: "10.0.0.0/16", newbits: 8, netnum: 256, err: "prefix extension of 8 does not accommodate a subnet numbered 256"},
+		{prefix: "10.0.0.0/16", newbits: 8, netnum: -1, err: "prefix extension of 8 does not accommodate a subnet numbered -1"},
+		{prefix: "10.0.0.0/30", newbits: 3, netnum: 0, err: "insufficient address space to extend prefix of 30 by 3"},
+		{prefix: "10.0.0.0/16", newbits: 1.5, netnum: 0, err: "newbits must be an integer, not 1.5"},
+		{prefix: "10.0.0.0", newbits: 8, netnum: 0, err: `invalid CIDR prefix "10.0.0.0": netip.ParsePrefix("10.0.0.0"): no '/'`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%s/%v/%v", tt.prefix, tt.newbits, tt.netnum), func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateBuiltinCidrSubnet(ast.CidrSubnet(
+					ast.String(tt.prefix), ast.Number(tt.newbits), ast.Number(tt.netnum)))
+				if tt.err != "" {
+					assert.False(t, ok)
+					require.Len(t, e.sdiags.diags, 1)
+					assert.Equal(t, tt.err, e.sdiags.diags[0].Summary)
+					return
+				}
+				requireNoErrors(t, tmpl, e.sdiags.diags)
+				assert.Equal(t, tt.expected, v)
+			})
+		})
+	}
+}
+
+func TestCidrHost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix   string
+		hostnum  float64
+		expected string
+		err      string
+	}{
+		{prefix: "10.12.112.0/20", hostnum: 16, expected: "10.12.112.16"},
+		{prefix: "10.12.112.0/20", hostnum: 268, expected: "10.12.113.12"},
+		{prefix: "10.12.112.0/20", hostnum: -1, expected: "10.12.127.255"},
+		{prefix: "fd00:fd12:3456:7890:00a2::/72", hostnum: 34, expected: "fd00:fd12:3456:7890::22"},
+		{prefix: "10.0.0.0/24", hostnum: 256, err: "prefix of 24 does not accommodate a host numbered 256"},
+		{prefix: "10.0.0.0/24", hostnum: -257, err: "prefix of 24 does not accommodate a host numbered -257"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%s/%v", tt.prefix, tt.hostnum), func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateBuiltinCidrHost(ast.CidrHost(ast.String(tt.prefix), ast.Number(tt.hostnum)))
+				if tt.err != "" {
+					assert.False(t, ok)
+					require.Len(t, e.sdiags.diags, 1)
+					assert.Equal(t, tt.err, e.sdiags.diags[0].Summary)
+					return
+				}
+				requireNoErrors(t, tmpl, e.sdiags.diags)
+				assert.Equal(t, tt.expected, v)
+			})
+		})
+	}
+}
+
+func TestCidrSubnetTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  subnet:
+    fn::cidrsubnet: ["10.0.0.0/16", 8, 2]
+  host:
+    fn::cidrhost: ["10.0.0.0/24", 300]
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, tdiags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, tdiags)
+	assert.Equal(t, schema.StringType, typing.TypeVariable("subnet"))
+	assert.Equal(t, schema.StringType, typing.TypeVariable("host"))
+
+	diags := testTemplateDiags(t, tmpl, nil)
+	var diagStrings []string
+	for _, v := range diags {
+		diagStrings = append(diagStrings, diagString(v))
+	}
+	assert.Equal(t, []string{"<stdin>:7:35: prefix of 24 does not accommodate a host numbered 300"}, diagStrings)
+}
+
+func TestSortAndUnique(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    ast.Expr
+		expected interface{}
+		err      string
+	}{
+		{
+			name:     "sort strings",
+			input:    ast.Sort(ast.List(ast.String("us-west-2c"), ast.String("us-west-2a"), ast.String("us-west-2b"))),
+			expected: []interface{}{"us-west-2a", "us-west-2b", "us-west-2c"},
+		},
+		{
+			name:     "sort numbers",
+			input:    ast.Sort(ast.List(ast.Number(10), ast.Number(9), ast.Number(-1.5))),
+			expected: []interface{}{-1.5, 9.0, 10.0},
+		},
+		{
+			name:     "sort empty",
+			input:    ast.Sort(ast.List()),
+			expected: []interface{}{},
+		},
+		{
+			name:  "sort mixed",
+			input: ast.Sort(ast.List(ast.String("a"), ast.Number(1))),
+			err:   "fn::sort requires all elements to have the same type, but element 0 is a string and element 1 is a number",
+		},
+		{
+			name:  "sort objects",
+			input: ast.Sort(ast.List(ast.Object())),
+			err:   "fn::sort requires a list of strings or numbers, but element 0 is an object",
+		},
+		{
+			name:     "unique strings",
+			input:    ast.Unique(ast.List(ast.String("b"), ast.String("a"), ast.String("b"), ast.String("c"), ast.String("a"))),
+			expected: []interface{}{"b", "a", "c"},
+		},
+		{
+			name:     "unique numbers",
+			input:    ast.Unique(ast.List(ast.Number(3), ast.Number(3), ast.Number(1))),
+			expected: []interface{}{3.0, 1.0},
+		},
+		{
+			name:  "unique mixed",
+			input: ast.Unique(ast.List(ast.Number(1), ast.String("1"))),
+			err:   "fn::unique requires all elements to have the same type, but element 0 is a number and element 1 is a string",
+		},
+		{
+			name:  "unique not a list",
+			input: ast.Unique(ast.String("a")),
+			err:   "the argument to fn::unique must be a list, not a string",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateExpr(tt.input)
+				if tt.err != "" {
+					assert.False(t, ok)
+					require.Len(t, e.sdiags.diags, 1)
+					assert.Equal(t, tt.err, e.sdiags.diags[0].Summary)
+					return
+				}
+				requireNoErrors(t, tmpl, e.sdiags.diags)
+				assert.Equal(t, tt.expected, v)
+			})
+		})
+	}
+}
+
+func TestSortUnknownElements(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		e.variables["known"] = []interface{}{"b", pulumi.String("c").ToStringOutput(), "a"}
+		e.variables["unknown"] = []interface{}{"b", pulumi.UnsafeUnknownOutput(nil), "a"}
+
+		v, ok := e.evaluateExpr(ast.Sort(&ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "known"}}},
+		}))
+		require.True(t, ok)
+		e.pulumiCtx.Export("known", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Equal(t, []interface{}{"a", "b", "c"}, x)
+			return x, nil
+		}))
+
+		// The order of a list with unknown elements can't be determined, so the whole list is
+		// unknown.
+		v, ok = e.evaluateExpr(ast.Sort(&ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "unknown"}}},
+		}))
+		require.True(t, ok)
+		e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Failf(t, "unexpected apply", "sorted an unknown list: %v", x)
+			return x, nil
+		}))
+	})
+}
+
+func TestSortTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  azs:
+    fn::sort: [us-west-2b, us-west-2a]
+  ports:
+    fn::unique: [80, 443, 80]
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.StringType}, typing.TypeVariable("azs"))
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.NumberType}, typing.TypeVariable("ports"))
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	nested := func() ast.Expr {
+		return ast.List(
+			ast.List(ast.String("a"), ast.List(ast.String("b"))),
+			ast.String("c"),
+			ast.List(),
+		)
+	}
+
+	tests := []struct {
+		name     string
+		input    *ast.FlattenExpr
+		expected interface{}
+		err      string
+	}{
+		{
+			name:     "default depth",
+			input:    ast.Flatten(nested(), nil),
+			expected: []interface{}{"a", []interface{}{"b"}, "c"},
+		},
+		{
+			name:     "depth 2",
+			input:    ast.Flatten(nested(), ast.Number(2)),
+			expected: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:     "depth 0",
+			input:    ast.Flatten(nested(), ast.Number(0)),
+			expected: []interface{}{[]interface{}{"a", []interface{}{"b"}}, "c", []interface{}{}},
+		},
+		{
+			name:  "negative depth",
+			input: ast.Flatten(nested(), ast.Number(-1)),
+			err:   "depth must not be negative, got -1",
+		},
+		{
+			name:  "not a list",
+			input: ast.Flatten(ast.String("a"), nil),
+			err:   "the argument to fn::flatten must be a list, not a string",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateBuiltinFlatten(tt.input)
+				if tt.err != "" {
+					assert.False(t, ok)
+					require.Len(t, e.sdiags.diags, 1)
+					assert.Equal(t, tt.err, e.sdiags.diags[0].Summary)
+					return
+				}
+				requireNoErrors(t, tmpl, e.sdiags.diags)
+				assert.Equal(t, tt.expected, v)
+			})
+		})
+	}
+}
+
+func TestFlattenTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  subnets:
+    fn::flatten:
+      - [subnet-a, subnet-b]
+      - [subnet-c]
+  deep:
+    fn::flatten:
+      list: [[[1, 2]], [[3]]]
+      depth: 2
+  mixed:
+    fn::flatten: [[a], 1]
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.StringType}, typing.TypeVariable("subnets"))
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.NumberType}, typing.TypeVariable("deep"))
+	assert.Equal(t, &schema.ArrayType{
+		ElementType: &schema.UnionType{ElementTypes: []schema.Type{schema.StringType, schema.NumberType}},
+	}, typing.TypeVariable("mixed"))
+}
+
+func TestFlattenNegativeDepth(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  subnets:
+    fn::flatten:
+      list: [[subnet-a]]
+      depth: -1
+`
+
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var diagStrings []string
+	for _, v := range diags {
+		diagStrings = append(diagStrings, diagString(v))
+	}
+	assert.Equal(t, []string{"<stdin>:7:14: depth must not be negative, got -1"}, diagStrings)
+}
+
+func TestSub(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{
+		Variables: map[string]interface{}{
+			"foo": "oof",
+		},
+		Resources: map[string]*Resource{
+			"resA": {
+				Type: testResourceToken,
+				Properties: map[string]interface{}{
+					"foo": "oof",
+				},
+			},
+		},
+	})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		v, ok := e.evaluateInterpolate(ast.MustInterpolate("Hello ${foo}!"))
+		assert.True(t, ok)
+		assert.Equal(t, "Hello oof!", v)
+
+		v, ok = e.evaluateInterpolate(ast.MustInterpolate("Hello ${resA.out} - ${resA.id}!!"))
+		assert.True(t, ok)
+		out := v.(pulumi.AnyOutput).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Equal(t, "Hello tuo - someID!!", x)
+			return nil, nil
+		})
+		e.pulumiCtx.Export("out", out)
+	})
 }
 
 func TestSecret(t *testing.T) {
@@ -1636,13 +2462,85 @@ variables:
 		assert.True(t, ok)
 		assert.Equal(t, packageReadmeFile, result)
 
-		result, ok = e.variables["absInDirData"].(string)
-		assert.True(t, ok)
-		assert.Equal(t, packageReadmeFile, result)
+		result, ok = e.variables["absInDirData"].(string)
+		assert.True(t, ok)
+		assert.Equal(t, packageReadmeFile, result)
+
+		result, ok = e.variables["absOutOfDirData"].(string)
+		assert.True(t, ok)
+		assert.Equal(t, string(repoReadmeText), result)
+	})
+}
+
+func TestToBase64ReadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"empty":  {},
+		"small":  []byte("hello, world"),
+		"binary": {0xff, 0xfe, 0x00, 0x80, 0x7f},
+		"large":  bytes.Repeat([]byte("0123456789abcdef"), 64*1024+1),
+	}
+	var variables strings.Builder
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+		fmt.Fprintf(&variables, "  %s:\n    fn::toBase64:\n      fn::readFile: %s\n", name, path)
+	}
+
+	tmpl := yamlTemplate(t, "name: test-tobase64\nruntime: yaml\nvariables:\n"+variables.String())
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		for name, content := range files {
+			assert.Equal(t, b64.StdEncoding.EncodeToString([]byte(string(content))), e.variables[name], name)
+		}
+	})
+}
+
+func TestToBase64ReadFileMissing(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing")
+	tmpl := yamlTemplate(t, fmt.Sprintf(`
+name: test-tobase64
+runtime: yaml
+variables:
+  missing:
+    fn::toBase64:
+      fn::readFile: %s
+`, path))
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		e := &programEvaluator{evalContext: runner.newContext(nil), pulumiCtx: ctx}
+		_, ok := e.evaluateExpr(tmpl.Variables.Entries[0].Value)
+		assert.False(t, ok)
+		assert.Contains(t, e.sdiags.Error(), "Error reading file at path "+path)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+}
+
+func BenchmarkToBase64ReadFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "large")
+	require.NoError(b, os.WriteFile(path, bytes.Repeat([]byte("0123456789abcdef"), 512*1024), 0o600))
 
-		result, ok = e.variables["absOutOfDirData"].(string)
-		assert.True(t, ok)
-		assert.Equal(t, string(repoReadmeText), result)
+	// The approach of evaluating fn::readFile and then fn::toBase64 on the result.
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := os.ReadFile(path)
+			require.NoError(b, err)
+			_ = b64.StdEncoding.EncodeToString([]byte(string(data)))
+		}
+	})
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _, err := encodeFileBase64(path)
+			require.NoError(b, err)
+		}
 	})
 }
 
@@ -1835,6 +2733,62 @@ variables:
 		})
 }
 
+func TestInterpolationDefault(t *testing.T) {
+	t.Parallel()
+
+	text := `
+name: test-default
+runtime: yaml
+variables:
+  empty: ""
+  missing: null
+  region: us-west-2
+  fallback: ${empty:-us-east-1}
+  nested: ${missing:-${empty:-default-${region}}}
+  set: ${region:-us-east-1}
+  cleared: a${empty:-}b
+  literal: a:-b ${region}
+  escaped: $${region:-us-east-1} ${region}
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, "us-east-1", e.variables["fallback"])
+		assert.Equal(t, "default-us-west-2", e.variables["nested"])
+		assert.Equal(t, "us-west-2", e.variables["set"])
+		assert.Equal(t, "ab", e.variables["cleared"])
+		assert.Equal(t, "a:-b us-west-2", e.variables["literal"])
+		assert.Equal(t, "${region:-us-east-1} us-west-2", e.variables["escaped"])
+
+		// Unknown values stay unknown, rather than taking the default.
+		e.variables["unknown"] = pulumi.UnsafeUnknownOutput(nil)
+		v, ok := e.evaluateExpr(ast.MustInterpolate("${unknown:-default}"))
+		require.True(t, ok)
+		e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Failf(t, "unexpected apply", "interpolated an unknown value: %v", x)
+			return x, nil
+		}))
+
+		// Known outputs that are empty take the default.
+		e.variables["known"] = pulumi.String("").ToStringOutput()
+		v, ok = e.evaluateExpr(ast.MustInterpolate("${known:-default}!"))
+		require.True(t, ok)
+		e.pulumiCtx.Export("known", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Equal(t, "default!", x)
+			return x, nil
+		}))
+	})
+
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-default
+runtime: yaml
+variables:
+  unterminated: ${region:-${other}
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "unterminated interpolation", diags[0].Summary)
+}
+
 func TestReadResource(t *testing.T) {
 	t.Parallel()
 	text := `
@@ -2102,6 +3056,219 @@ resources:
 	assert.NoError(t, err)
 }
 
+func TestResourceAliasOldType(t *testing.T) {
+	t.Parallel()
+
+	text := `
+name: test-alias
+runtime: yaml
+resources:
+  bucket:
+    type: aws:storage:Bucket
+    options:
+      aliases:
+        - type: aws:s3:Bucket
+        - name: old-bucket
+          type: aws:s3/bucket:Bucket
+        - urn:pulumi:stack::project::aws:s3/bucket:Bucket::older-bucket
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	loader := MockPackageLoader{packages: map[string]Package{
+		"aws": MockPackage{
+			resolveResource: func(typeName string) (ResourceTypeToken, error) {
+				if typeName != "aws:storage:Bucket" {
+					return "", fmt.Errorf("unknown resource %q", typeName)
+				}
+				return "aws:storage/bucket:Bucket", nil
+			},
+			isComponent: func(typeName string) (bool, error) {
+				return false, nil
+			},
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				return inputProperties(typeName)
+			},
+		},
+	}}
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			assert.Equal(t, "aws:storage/bucket:Bucket", args.TypeToken)
+			aliases := args.RegisterRPC.GetAliases()
+			require.Len(t, aliases, 3)
+			assert.Equal(t, "aws:s3/bucket:Bucket", aliases[0].GetSpec().GetType())
+			assert.Equal(t, "", aliases[0].GetSpec().GetName())
+			assert.Equal(t, "aws:s3/bucket:Bucket", aliases[1].GetSpec().GetType())
+			assert.Equal(t, "old-bucket", aliases[1].GetSpec().GetName())
+			assert.Equal(t, "urn:pulumi:stack::project::aws:s3/bucket:Bucket::older-bucket", aliases[2].GetUrn())
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, loader)
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	assert.NoError(t, err)
+}
+
+// TestResourceAliasURN checks that a resource can be renamed by giving the URN it used to have,
+// including the URN of a child whose parent has also changed.
+func TestResourceAliasURN(t *testing.T) {
+	t.Parallel()
+
+	text := `
+name: test-alias
+runtime: yaml
+resources:
+  renamed:
+    type: test:resource:type
+    properties:
+      foo: bar
+    options:
+      aliases:
+        - urn: urn:pulumi:stack::project::test:resource:type::original
+        - urn:pulumi:stack::project::test:component:type$test:resource:type::child
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			aliases := args.RegisterRPC.GetAliases()
+			require.Len(t, aliases, 2)
+			assert.Equal(t, "urn:pulumi:stack::project::test:resource:type::original", aliases[0].GetUrn())
+			assert.Equal(t, "urn:pulumi:stack::project::test:component:type$test:resource:type::child", aliases[1].GetUrn())
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	assert.NoError(t, err)
+}
+
+func TestResourceAliasDiags(t *testing.T) {
+	t.Parallel()
+
+	text := `
+name: test-alias
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    properties:
+      foo: bar
+    options:
+      aliases:
+        - type: Resource
+        - type: test:a:b:c:Resource
+        - {}
+        - urn: urn:pulumi:stack::project::test:resource:type::old
+          name: old
+        - old-res
+        - urn: urn:pulumi:stack::project
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.Equal(t, []string{
+		`<stdin>:10:17: invalid alias type: invalid type token "Resource"`,
+		`<stdin>:11:17: invalid alias type: invalid type token "test:a:b:c:Resource"`,
+		"<stdin>:12:11: an alias must specify at least one of name, type, project or stack",
+		"<stdin>:13:11: an alias must either be a URN or list the name, type, project or stack the resource used to have",
+		`<stdin>:15:11: invalid alias URN "old-res": invalid URN "old-res"`,
+		`<stdin>:16:16: invalid alias URN "urn:pulumi:stack::project": invalid URN "urn:pulumi:stack::project"`,
+	}, messages)
+
+	text = `
+name: test-alias
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    options:
+      aliases:
+        - 42
+`
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(strings.TrimSpace(text)))
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "<stdin>:8:11: aliases[0] must be a URN or an object", diagString(diags[0]))
+}
+
+func TestResourceAttributeAccess(t *testing.T) {
+	t.Parallel()
+
+	text := `
+name: test-attributes
+runtime: yaml
+resources:
+  comp:
+    type: test:index:Component
+  res:
+    type: test:index:Resource
+outputs:
+  endpoint: ${comp.endpoint}
+  dynamic: ${comp.extra.any.path}
+  arn: ${res.arn}
+  badComponent: ${comp.endpiont}
+  badResource: ${res.anr}
+  getAttComponent:
+    fn::getAtt: ["${comp}", endpoint]
+  getAttResource:
+    fn::getAtt: ["${res}", arn]
+  getAttDynamic:
+    fn::getAtt: ["${comp}", "${res.arn}"]
+  getAttBadComponent:
+    fn::getAtt: ["${comp}", endpiont]
+  getAttBadResource:
+    fn::getAtt: ["${res}", anr]
+  getAttNotResource:
+    fn::getAtt: ["${res.arn}", length]
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	hint := func(typeName string, isComponent bool, outputs ...*schema.Property) *schema.ResourceType {
+		return &schema.ResourceType{
+			Token:    typeName,
+			Resource: &schema.Resource{Token: typeName, IsComponent: isComponent, Properties: outputs},
+		}
+	}
+	loader := MockPackageLoader{packages: map[string]Package{
+		"test": MockPackage{
+			isComponent: func(typeName string) (bool, error) {
+				return typeName == "test:index:Component", nil
+			},
+			resourceTypeHint: func(typeName string) *schema.ResourceType {
+				if typeName == "test:index:Component" {
+					return hint(typeName, true,
+						&schema.Property{Name: "endpoint", Type: schema.StringType},
+						&schema.Property{Name: "extra", Type: schema.AnyType})
+				}
+				return hint(typeName, false, &schema.Property{Name: "arn", Type: schema.StringType})
+			},
+		},
+	}}
+	_, diags := TypeCheck(newRunner(tmpl, loader))
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, diagString(d))
+	}
+	assert.Equal(t, []string{
+		"<stdin>:12:17: endpiont does not exist on comp; Existing properties are: endpoint, extra, urn",
+		"<stdin>:13:16: anr does not exist on res; Existing properties are: arn, id, urn",
+		"<stdin>:21:18: endpiont does not exist on comp; Existing properties are: endpoint, extra, urn",
+		"<stdin>:23:18: anr does not exist on res; Existing properties are: arn, id, urn",
+		"<stdin>:25:18: the first argument to fn::getAtt must be a resource, not string",
+	}, messages)
+}
+
 func TestResourceWithLogicalName(t *testing.T) {
 	t.Parallel()
 
@@ -2269,7 +3436,8 @@ func TestHandleUnknownPropertiesDuringPreview(t *testing.T) {
 			pulumiCtx: ctx,
 			evalContext: &evalContext{
 				Runner: &Runner{
-					t: &ast.TemplateDecl{},
+					t:       &ast.TemplateDecl{},
+					secrets: &secretStrings{},
 					resources: map[string]lateboundResource{
 						"image": &mockLateboundResource{
 							resourceSchema: &schema.Resource{
@@ -2391,6 +3559,10 @@ func (st *mockLateboundResource) ProviderResource() *pulumi.ProviderResourceStat
 	panic("not implemented")
 }
 
+func (st *mockLateboundResource) pulumiResource() pulumi.Resource {
+	panic("not implemented")
+}
+
 func (st *mockLateboundResource) ElementType() reflect.Type {
 	panic("not implemented")
 }
@@ -2403,6 +3575,10 @@ func (st *mockLateboundResource) GetResourceSchema() *schema.Resource {
 	return st.resourceSchema
 }
 
+func (st *mockLateboundResource) isProtected() bool {
+	return false
+}
+
 // TestResourceMissingType ensures that we fail with an error message when a resource is missing a type.
 func TestResourceMissingType(t *testing.T) {
 	t.Parallel()
@@ -2440,7 +3616,8 @@ func TestHandleUnknownNestedPropertiesDuringPreview(t *testing.T) {
 			pulumiCtx: ctx,
 			evalContext: &evalContext{
 				Runner: &Runner{
-					t: &ast.TemplateDecl{},
+					t:       &ast.TemplateDecl{},
+					secrets: &secretStrings{},
 					resources: map[string]lateboundResource{
 						"image": &mockLateboundResource{
 							resourceSchema: &schema.Resource{
@@ -2493,7 +3670,8 @@ func TestUnknownsDuringPreviewNotUpdate(t *testing.T) {
 				pulumiCtx: ctx,
 				evalContext: &evalContext{
 					Runner: &Runner{
-						t: &ast.TemplateDecl{},
+						t:       &ast.TemplateDecl{},
+						secrets: &secretStrings{},
 						resources: map[string]lateboundResource{
 							"image": &mockLateboundResource{
 								resourceSchema: &schema.Resource{
@@ -2583,3 +3761,288 @@ func TestConflictingEnvVarsMultipleDuplicates(t *testing.T) {
 	conflicts := conflictingEnvVars(env)
 	assert.ElementsMatch(t, []string{"FOO", "BAR"}, conflicts)
 }
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	m := func() ast.Expr {
+		return ast.Object(
+			ast.ObjectProperty{Key: ast.String("us-east-1"), Value: ast.String("ami-east")},
+			ast.ObjectProperty{Key: ast.String("us-west-2"), Value: ast.String("ami-west")},
+		)
+	}
+	list := func() ast.Expr {
+		return ast.List(ast.String("a"), ast.String("b"))
+	}
+
+	tests := []struct {
+		name     string
+		input    *ast.LookupExpr
+		expected interface{}
+		err      string
+	}{
+		{
+			name:     "present",
+			input:    ast.Lookup(m(), ast.String("us-west-2"), ast.String("ami-default")),
+			expected: "ami-west",
+		},
+		{
+			name:     "absent",
+			input:    ast.Lookup(m(), ast.String("eu-west-1"), ast.String("ami-default")),
+			expected: "ami-default",
+		},
+		{
+			name:     "null default",
+			input:    ast.Lookup(m(), ast.String("eu-west-1"), ast.Null()),
+			expected: nil,
+		},
+		{
+			name:     "list",
+			input:    ast.Lookup(list(), ast.Number(1), ast.String("z")),
+			expected: "b",
+		},
+		{
+			name:  "list out of range",
+			input: ast.Lookup(list(), ast.Number(2), ast.String("z")),
+			err:   "index 2 is out of range for a list of 2 elements",
+		},
+		{
+			name:  "map with a number key",
+			input: ast.Lookup(m(), ast.Number(1), ast.String("z")),
+			err:   "the key to look up in a map must be a string, not a number",
+		},
+		{
+			name:  "not a map",
+			input: ast.Lookup(ast.String("a"), ast.String("a"), ast.String("z")),
+			err:   "the first argument to fn::lookup must be a map or a list, not a string",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template(t, &Template{})
+			testTemplate(t, tmpl, func(e *programEvaluator) {
+				v, ok := e.evaluateBuiltinLookup(tt.input)
+				if tt.err != "" {
+					assert.False(t, ok)
+					require.Len(t, e.sdiags.diags, 1)
+					assert.Equal(t, tt.err, e.sdiags.diags[0].Summary)
+					return
+				}
+				requireNoErrors(t, tmpl, e.sdiags.diags)
+				assert.Equal(t, tt.expected, v)
+			})
+		})
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		e.variables["amis"] = pulumi.UnsafeUnknownOutput(nil)
+
+		v, ok := e.evaluateBuiltinLookup(ast.Lookup(&ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "amis"}}},
+		}, ast.String("us-west-2"), ast.String("ami-default")))
+		require.True(t, ok)
+		e.pulumiCtx.Export("ami", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Failf(t, "unexpected apply", "looked up a key in an unknown map: %v", x)
+			return x, nil
+		}))
+	})
+}
+
+func TestLookupTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+configuration:
+  counts:
+    type: List<Number>
+    default: [1, 2]
+variables:
+  ami:
+    fn::lookup:
+      - us-east-1: ami-east
+        us-west-2: ami-west
+      - us-west-2
+      - ami-default
+  count:
+    fn::lookup: ["${counts}", 0, none]
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, schema.StringType, typing.TypeVariable("ami"))
+	assert.Equal(t, &schema.UnionType{ElementTypes: []schema.Type{schema.NumberType, schema.StringType}},
+		typing.TypeVariable("count"))
+}
+
+func TestKeysAndValues(t *testing.T) {
+	t.Parallel()
+
+	m := func() ast.Expr {
+		return ast.Object(
+			ast.ObjectProperty{Key: ast.String("zone-b"), Value: ast.String("10.0.2.0/24")},
+			ast.ObjectProperty{Key: ast.String("zone-a"), Value: ast.String("10.0.1.0/24")},
+			ast.ObjectProperty{Key: ast.String("zone-c"), Value: ast.String("10.0.3.0/24")},
+		)
+	}
+
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		keys, ok := e.evaluateBuiltinKeys(ast.Keys(m()))
+		require.True(t, ok)
+		assert.Equal(t, []interface{}{"zone-a", "zone-b", "zone-c"}, keys)
+
+		values, ok := e.evaluateBuiltinValues(ast.Values(m()))
+		require.True(t, ok)
+		assert.Equal(t, []interface{}{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}, values)
+
+		keys, ok = e.evaluateBuiltinKeys(ast.Keys(ast.Object()))
+		require.True(t, ok)
+		assert.Equal(t, []interface{}{}, keys)
+	})
+}
+
+func TestKeysAndValuesErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		_, ok := e.evaluateBuiltinKeys(ast.Keys(ast.List(ast.String("a"))))
+		assert.False(t, ok)
+		_, ok = e.evaluateBuiltinValues(ast.Values(ast.String("a")))
+		assert.False(t, ok)
+
+		var summaries []string
+		for _, d := range e.sdiags.diags {
+			summaries = append(summaries, d.Summary)
+		}
+		assert.Equal(t, []string{
+			"the argument to fn::keys must be a map, not a list",
+			"the argument to fn::values must be a map, not a string",
+		}, summaries)
+	})
+}
+
+func TestKeysUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		e.variables["subnets"] = pulumi.UnsafeUnknownOutput(nil)
+		subnets := &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "subnets"}}},
+		}
+
+		v, ok := e.evaluateBuiltinKeys(ast.Keys(subnets))
+		require.True(t, ok)
+		e.pulumiCtx.Export("keys", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+			assert.Failf(t, "unexpected apply", "listed the keys of an unknown map: %v", x)
+			return x, nil
+		}))
+	})
+}
+
+func TestKeysAndValuesTemplate(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  subnets:
+    zone-a: 10.0.1.0/24
+    zone-b: 10.0.2.0/24
+  zones:
+    fn::keys: ${subnets}
+  cidrs:
+    fn::values: ${subnets}
+  mixed:
+    fn::values:
+      a: 1
+      b: two
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.StringType}, typing.TypeVariable("zones"))
+	assert.Equal(t, &schema.ArrayType{ElementType: schema.StringType}, typing.TypeVariable("cidrs"))
+	assert.Equal(t, &schema.ArrayType{
+		ElementType: &schema.UnionType{ElementTypes: []schema.Type{schema.NumberType, schema.StringType}},
+	}, typing.TypeVariable("mixed"))
+}
+
+func TestSemverSatisfiesAndCompare(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  provider: 4.2.1
+  modern:
+    fn::semverSatisfies: ["${provider}", ">=4.0.0"]
+  legacy:
+    fn::semverSatisfies: [v3.9, ">=4.0.0 || <3.0.0"]
+  older:
+    fn::semverCompare: [3.0.0, "${provider}"]
+  same:
+    fn::semverCompare: [v4.2.1, "${provider}"]
+  newer:
+    fn::semverCompare: [5.0.0-beta.1, "${provider}"]
+`
+
+	tmpl := yamlTemplate(t, text)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, schema.BoolType, typing.TypeVariable("modern"))
+	assert.Equal(t, schema.IntType, typing.TypeVariable("older"))
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		diags := e.evalContext.Evaluate(e.pulumiCtx)
+		requireNoErrors(t, tmpl, diags)
+		assert.Equal(t, true, e.variables["modern"])
+		assert.Equal(t, false, e.variables["legacy"])
+		assert.Equal(t, -1.0, e.variables["older"])
+		assert.Equal(t, 0.0, e.variables["same"])
+		assert.Equal(t, 1.0, e.variables["newer"])
+	})
+}
+
+func TestSemverErrors(t *testing.T) {
+	t.Parallel()
+
+	const text = `name: test-yaml
+runtime: yaml
+variables:
+  badVersion:
+    fn::semverSatisfies: [four, ">=4.0.0"]
+  badConstraint:
+    fn::semverSatisfies: [4.0.0, "=>4"]
+  badCompare:
+    fn::semverCompare: [4.0.0, 1.2.3.4]
+`
+
+	tmpl := yamlTemplate(t, text)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.Len(t, diags, 3)
+	assert.Contains(t, diagString(diags[0]), `5:27: invalid version "four"`)
+	assert.Contains(t, diagString(diags[1]), `7:34: invalid version constraint "=>4"`)
+	assert.Contains(t, diagString(diags[2]), `9:32: invalid version "1.2.3.4"`)
+
+	tmpl = template(t, &Template{})
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		_, ok := e.evaluateBuiltinSemverCompare(ast.SemverCompare(ast.Number(4), ast.String("4.0.0")))
+		assert.False(t, ok)
+		require.Len(t, e.sdiags.diags, 1)
+		assert.Equal(t, "the version must be a string, not a number", e.sdiags.diags[0].Summary)
+	})
+}
@@ -0,0 +1,315 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// SecretOutputs lists the stack outputs of a template that will be secret.
+type SecretOutputs struct {
+	// Secret are the outputs that are always secret.
+	Secret []string
+	// PossiblySecret are the outputs whose secrecy can't be known until the template runs, such as
+	// those that depend on stack config, which may be set as a secret, or on another stack.
+	PossiblySecret []string
+}
+
+// secrecy is how secret a value is known to be before the template runs. Combining values gives
+// the greatest secrecy of the parts.
+type secrecy int
+
+const (
+	notSecret secrecy = iota
+	possiblySecret
+	alwaysSecret
+)
+
+func (s secrecy) max(other secrecy) secrecy {
+	if other > s {
+		return other
+	}
+	return s
+}
+
+// GetSecretOutputs determines which stack outputs of a template are secret without running it.
+// A value is secret if it is computed from fn::secret, from config declared with 'secret: true',
+// or from a resource property that is secret in the schema of its package or is listed in
+// additionalSecretOutputs. Outputs are listed in sorted order.
+func GetSecretOutputs(tmpl *ast.TemplateDecl, loader PackageLoader) (*SecretOutputs, syntax.Diagnostics) {
+	r, diags, err := PrepareTemplate(tmpl, nil, loader)
+	if err != nil {
+		diags.Extend(syntax.Error(nil, err.Error(), ""))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	a := &secrecyAnalysis{
+		r:         r,
+		values:    map[string]secrecy{},
+		resources: map[string]*resourceSecrecy{},
+	}
+	outputs := &SecretOutputs{}
+	diags.Extend(r.Run(walker{
+		VisitConfig: func(r *Runner, node configNode) bool {
+			s := a.configSecrecy(node)
+			a.values[node.key().Value] = s
+			if tmpl.Name != nil {
+				a.values[stripConfigNamespace(tmpl.Name.Value, node.key().Value)] = s
+			}
+			return true
+		},
+		VisitVariable: func(r *Runner, node variableNode) bool {
+			a.values[node.Key.Value] = a.exprSecrecy(node.Value)
+			return true
+		},
+		VisitResource: func(r *Runner, node resourceNode) bool {
+			a.resources[node.Key.Value] = a.resourceSecrecy(node.Value)
+			return true
+		},
+		VisitOutput: func(ctx *evalContext, name string, expr ast.Expr) bool {
+			switch a.exprSecrecy(expr) {
+			case alwaysSecret:
+				outputs.Secret = append(outputs.Secret, name)
+			case possiblySecret:
+				outputs.PossiblySecret = append(outputs.PossiblySecret, name)
+			}
+			return true
+		},
+	})...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	sort.Strings(outputs.Secret)
+	sort.Strings(outputs.PossiblySecret)
+	return outputs, diags
+}
+
+type secrecyAnalysis struct {
+	r *Runner
+	// values holds the secrecy of each config entry and variable.
+	values    map[string]secrecy
+	resources map[string]*resourceSecrecy
+	// bindings holds the secrecy of the names bound by the forEach of the resource being analysed.
+	bindings map[string]secrecy
+}
+
+type resourceSecrecy struct {
+	// properties holds the secrecy of the properties that are set or are known from the schema.
+	properties map[string]secrecy
+	// unlisted is the secrecy of any other property.
+	unlisted secrecy
+}
+
+// property returns the secrecy of the property name, or of the resource as a whole if name is
+// empty.
+func (r *resourceSecrecy) property(name string) secrecy {
+	switch name {
+	case "":
+		s := r.unlisted
+		for _, p := range r.properties {
+			s = s.max(p)
+		}
+		return s
+	case "id", "urn":
+		return notSecret
+	}
+	if s, ok := r.properties[name]; ok {
+		return s
+	}
+	return r.unlisted
+}
+
+func (a *secrecyAnalysis) configSecrecy(node configNode) secrecy {
+	if node, ok := node.(configNodeYaml); ok && node.Value != nil && node.Value.Secret != nil {
+		if node.Value.Secret.Value {
+			return alwaysSecret
+		}
+		// The value can't be set as a secret in the stack config if it is declared not to be.
+		return notSecret
+	}
+	if node, ok := node.(configNodeProp); ok && !node.v.IsSecret() {
+		return notSecret
+	}
+	return possiblySecret
+}
+
+func (a *secrecyAnalysis) resourceSecrecy(res *ast.ResourceDecl) *resourceSecrecy {
+	result := &resourceSecrecy{properties: map[string]secrecy{}}
+	if res.Type == nil {
+		return result
+	}
+	if res.Type.Value == "pulumi:pulumi:StackReference" {
+		// The outputs of another stack may be secret.
+		result.unlisted = possiblySecret
+		return result
+	}
+
+	a.bindings = nil
+	if res.ForEach != nil {
+		s := a.exprSecrecy(res.ForEach)
+		a.bindings = map[string]secrecy{
+			loopIndexName: s,
+			loopItemName:  s,
+			loopKeyName:   s,
+			loopValueName: s,
+		}
+	}
+	defer func() { a.bindings = nil }()
+
+	var pkg Package
+	var typ ResourceTypeToken
+	if version, err := ParseVersion(res.Options.Version); err == nil {
+		pkg, typ, _ = ResolveResource(context.TODO(), a.r.pkgLoader, a.r.packageDescriptors,
+			res.Type.Value, version)
+	}
+	if pkg == nil {
+		// Without a schema, any property may be secret.
+		result.unlisted = possiblySecret
+	}
+
+	// The engine makes an output secret if the input of the same name is secret.
+	for _, prop := range res.Properties.Entries {
+		s := a.exprSecrecy(prop.Value)
+		if pkg != nil {
+			if secret, err := pkg.IsResourcePropertySecret(typ, prop.Key.Value); err == nil && secret {
+				s = alwaysSecret
+			}
+		}
+		result.properties[prop.Key.Value] = s
+	}
+	if pkg != nil {
+		if hint := pkg.ResourceTypeHint(typ); hint != nil && hint.Resource != nil {
+			for _, prop := range hint.Resource.Properties {
+				if prop.Secret {
+					result.properties[prop.Name] = alwaysSecret
+				}
+			}
+		}
+	}
+	if res.Options.AdditionalSecretOutputs != nil {
+		for _, name := range res.Options.AdditionalSecretOutputs.Elements {
+			result.properties[name.Value] = alwaysSecret
+		}
+	}
+	return result
+}
+
+func (a *secrecyAnalysis) exprSecrecy(x ast.Expr) secrecy {
+	switch x := x.(type) {
+	case nil, *ast.NullExpr, *ast.BooleanExpr, *ast.NumberExpr, *ast.StringExpr:
+		return notSecret
+	case *ast.ListExpr:
+		s := notSecret
+		for _, elem := range x.Elements {
+			s = s.max(a.exprSecrecy(elem))
+		}
+		return s
+	case *ast.ObjectExpr:
+		s := notSecret
+		for _, entry := range x.Entries {
+			s = s.max(a.exprSecrecy(entry.Key)).max(a.exprSecrecy(entry.Value))
+		}
+		return s
+	case *ast.SymbolExpr:
+		return a.accessSecrecy(x.Property)
+	case *ast.InterpolateExpr:
+		s := notSecret
+		for _, part := range x.Parts {
+			if part.Value != nil {
+				s = s.max(a.accessSecrecy(part.Value))
+			}
+			if part.Default != nil {
+				s = s.max(a.exprSecrecy(part.Default))
+			}
+		}
+		return s
+	case *ast.SecretExpr:
+		return alwaysSecret
+	case *ast.StackReferenceExpr:
+		return possiblySecret
+	case *ast.InvokeExpr:
+		return a.exprSecrecy(x.CallArgs).max(a.invokeSecrecy(x))
+	case ast.ElementExpr:
+		// Within the body, the element variables are as secret as the other arguments.
+		s := notSecret
+		for _, arg := range elementArgs(x) {
+			if arg != x.ElementBody() {
+				s = s.max(a.exprSecrecy(arg))
+			}
+		}
+		outer := a.bindings
+		a.bindings = map[string]secrecy{}
+		for k, v := range outer {
+			a.bindings[k] = v
+		}
+		for _, name := range elementVariables(x) {
+			a.bindings[name] = s
+		}
+		s = s.max(a.exprSecrecy(x.ElementBody()))
+		a.bindings = outer
+		return s
+	case *ast.GetAttExpr:
+		if access := x.Access(); access != nil {
+			return a.accessSecrecy(access.Property)
+		}
+		return a.exprSecrecy(x.Args())
+	case ast.BuiltinExpr:
+		return a.exprSecrecy(x.Args())
+	}
+	return possiblySecret
+}
+
+// invokeSecrecy is the secrecy of the result of an invoke that comes from the schema of its
+// function, regardless of its arguments.
+func (a *secrecyAnalysis) invokeSecrecy(x *ast.InvokeExpr) secrecy {
+	if x.Token == nil {
+		return possiblySecret
+	}
+	version, err := ParseVersion(x.CallOpts.Version)
+	if err != nil {
+		return possiblySecret
+	}
+	pkg, fn, err := ResolveFunction(context.TODO(), a.r.pkgLoader, a.r.packageDescriptors, x.Token.Value, version)
+	if err != nil {
+		return possiblySecret
+	}
+	hint := pkg.FunctionTypeHint(fn)
+	if hint == nil || hint.Outputs == nil {
+		return notSecret
+	}
+	for _, prop := range hint.Outputs.Properties {
+		if prop.Secret && (x.Return == nil || x.Return.Value == prop.Name) {
+			return alwaysSecret
+		}
+	}
+	return notSecret
+}
+
+func (a *secrecyAnalysis) accessSecrecy(access *ast.PropertyAccess) secrecy {
+	name := access.RootName()
+	if s, ok := a.bindings[name]; ok {
+		return s
+	}
+	if res, ok := a.resources[name]; ok {
+		// Resources declared with forEach are lists, so skip to the name of the property.
+		for _, accessor := range access.Accessors[1:] {
+			if prop, ok := accessor.(*ast.PropertyName); ok {
+				return res.property(prop.Name)
+			}
+		}
+		return res.property("")
+	}
+	if s, ok := a.values[name]; ok {
+		return s
+	}
+	if name == PulumiVarName {
+		return notSecret
+	}
+	return possiblySecret
+}
@@ -0,0 +1,107 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecretOutputs(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-secret-outputs
+runtime: yaml
+configuration:
+  password:
+    type: String
+    secret: true
+  region:
+    type: String
+    default: us-west-2
+  zone:
+    type: String
+    default: a
+    secret: false
+variables:
+  hidden:
+    fn::secret: shh
+  joined:
+    fn::join: ["-", ["${hidden}", x]]
+  endpoint: https://${region}.example.com
+resources:
+  sec:
+    type: test:resource:with-secret
+    properties:
+      foo: ${password}
+      bar: plain
+  plain:
+    type: test:resource:with-secret
+    properties:
+      foo: ${zone}
+      bar: plain
+    options:
+      additionalSecretOutputs: [foo]
+  other:
+    type: test:resource:with-secret
+    properties:
+      foo: ${zone}
+      bar: plain
+outputs:
+  password: ${password}
+  joined: ${joined}
+  fromSecretInput: ${sec.foo}
+  fromSchema: ${sec.bar}
+  fromOptions: ${plain.foo}
+  id: ${sec.id}
+  endpoint: ${endpoint}
+  zone: ${zone}
+  literal: hello
+  otherFoo: ${other.foo}
+  wholeResource: ${other}
+  group:
+    length:
+      fn::length: ${joined}
+`)
+	outputs, diags := GetSecretOutputs(tmpl, newMockPackageMap())
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{
+		"fromOptions", "fromSchema", "fromSecretInput", "group", "joined", "password",
+		"wholeResource",
+	}, outputs.Secret)
+	// The region may be set as a secret in the stack config.
+	assert.Equal(t, []string{"endpoint"}, outputs.PossiblySecret)
+}
+
+func TestGetSecretOutputsUnknownSchema(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-secret-outputs
+runtime: yaml
+resources:
+  ref:
+    type: pulumi:pulumi:StackReference
+    properties:
+      name: org/project/stack
+outputs:
+  fromStack: ${ref.outputs["password"]}
+  id: ${ref.id}
+`)
+	outputs, diags := GetSecretOutputs(tmpl, newMockPackageMap())
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, outputs.Secret)
+	assert.Equal(t, []string{"fromStack"}, outputs.PossiblySecret)
+
+	tmpl = yamlTemplate(t, `
+name: test-secret-outputs
+runtime: yaml
+outputs:
+  missing: ${nothing}
+`)
+	_, diags = GetSecretOutputs(tmpl, newMockPackageMap())
+	require.True(t, diags.HasErrors())
+}
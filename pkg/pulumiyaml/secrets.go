@@ -0,0 +1,86 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// secretMask replaces the plaintext of secrets in diagnostics.
+const secretMask = "[secret]"
+
+// secretStrings holds the plaintext of the secret strings that builtins have been applied to. A
+// diagnostic about a value may quote it, such as `"abc" is not a number`, so where a diagnostic
+// quotes the whole of a secret it is masked. Secrets are only matched when quoted, so that a short
+// secret such as "1" doesn't garble the rest of the diagnostic.
+type secretStrings struct {
+	mutex   sync.Mutex
+	strings []string
+}
+
+// add adds the strings within v.
+func (s *secretStrings) add(v interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			if v != "" {
+				s.strings = append(s.strings, v)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+}
+
+// mask masks the quoted secret strings in the summary and detail of diag.
+func (s *secretStrings) mask(diag *syntax.Diagnostic) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, secret := range s.strings {
+		quoted := strconv.Quote(secret)
+		diag.Summary = strings.ReplaceAll(diag.Summary, quoted, strconv.Quote(secretMask))
+		diag.Detail = strings.ReplaceAll(diag.Detail, quoted, strconv.Quote(secretMask))
+	}
+}
+
+// addSecretArgs adds the values of the secret outputs within args to the runner's secret strings.
+// It is called within an apply of args, when their outputs are already resolved.
+func (r *Runner) addSecretArgs(args []interface{}) {
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case pulumi.Output:
+			result, err := internals.UnsafeAwaitOutput(context.Background(), v)
+			if err == nil && result.Secret {
+				r.secrets.add(result.Value)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(args)
+}
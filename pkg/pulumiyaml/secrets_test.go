@@ -0,0 +1,231 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSecretPropagation checks that values derived from secret config are secret, however they are
+// combined with values that aren't.
+func TestSecretPropagation(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-secrets
+runtime: yaml
+configuration:
+  password:
+    type: String
+    default: hunter2
+    secret: true
+  port:
+    type: Number
+    default: 8080
+    secret: true
+  names:
+    type: List<String>
+    default: [a, b]
+    secret: true
+  network:
+    type: String
+    default: 10.0.0.0/16
+    secret: true
+  constraint:
+    type: String
+    default: ">=1.0.0"
+    secret: true
+variables:
+  plain: visible
+  interpolated: ${plain}:${password}
+  defaulted: ${password:-none}
+  joined:
+    fn::join: [",", [ "${plain}", "${password}" ]]
+  joinedList:
+    fn::join: [",", "${names}"]
+  delimiter:
+    fn::join: ["${password}", [a, b]]
+  split:
+    fn::split: [",", "${password}"]
+  formatted:
+    fn::format: ["%s:%d", "${plain}", "${port}"]
+  selected:
+    fn::select: [0, "${names}"]
+  json:
+    fn::toJSON:
+      plain: ${plain}
+      password: ${password}
+  base64:
+    fn::toBase64: ${password}
+  decoded:
+    fn::fromBase64:
+      fn::toBase64: ${password}
+  trimmed:
+    fn::trim: ${password}
+  upper:
+    fn::upper: ${password}
+  string:
+    fn::toString: ${port}
+  number:
+    fn::toNumber:
+      fn::toString: ${port}
+  sorted:
+    fn::sort: ${names}
+  unique:
+    fn::unique: ${names}
+  keys:
+    fn::keys:
+      secret: ${password}
+  values:
+    fn::values:
+      secret: ${password}
+  lookup:
+    fn::lookup: [{a: "${password}"}, a, none]
+  flattened:
+    fn::flatten: [["${plain}"], "${names}"]
+  host:
+    fn::cidrHost: ["${network}", 5]
+  satisfies:
+    fn::semverSatisfies: ["1.2.3", "${constraint}"]
+  path:
+    fn::jsonPath:
+      - fn::toJSON: {a: "${password}"}
+      - $.a
+outputs:
+  joined: ${joined}
+  password: ${password}
+  list:
+    - ${plain}
+    - ${password}
+  object:
+    plain: ${plain}
+    nested:
+      password: ${password}
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		for _, kvp := range tmpl.Variables.Entries {
+			name := kvp.Key.Value
+			v := e.variables[name]
+			if name == "plain" {
+				assert.Equal(t, "visible", v)
+				continue
+			}
+			out, ok := v.(pulumi.Output)
+			if !assert.True(t, ok, "%s is %T, not an output", name, v) {
+				continue
+			}
+			assert.True(t, pulumi.IsSecret(out), "%s is not secret", name)
+		}
+
+		// Outputs are secret as a whole, even when only part of them is.
+		for _, kvp := range tmpl.Outputs.Entries {
+			v, ok := e.registerOutput(kvp)
+			require.True(t, ok)
+			assert.True(t, pulumi.IsSecret(pulumi.ToOutput(v)), "output %s is not secret", kvp.Key.Value)
+		}
+	})
+}
+
+func TestSecretsAreMaskedInDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-secrets
+runtime: yaml
+configuration:
+  password:
+    type: String
+    default: hunter2
+    secret: true
+  plain:
+    type: String
+    default: visible
+variables:
+  number:
+    fn::toNumber: ${password}
+  constraint:
+    fn::semverSatisfies: ["1.2.3", "${password}"]
+  visible:
+    fn::toNumber: ${plain}
+outputs:
+  number: ${number}
+  constraint: ${constraint}
+`)
+	log := &interceptingLog{}
+	runner := newRunner(tmpl, newMockPackageMap())
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		ctx.Log = log
+		diags := runner.Evaluate(ctx)
+		assert.True(t, diags.HasErrors())
+		// The secret variables fail inside apply callbacks, so wait for them to be evaluated.
+		for _, name := range []string{"number", "constraint"} {
+			if out, ok := runner.variables[name].(pulumi.Output); ok {
+				_, _ = internals.UnsafeAwaitOutput(ctx.Context(), out)
+			}
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	assert.Error(t, err)
+
+	runner.sdiags.mutex.Lock()
+	defer runner.sdiags.mutex.Unlock()
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	var summaries []string
+	for _, d := range runner.sdiags.diags {
+		summaries = append(summaries, d.Summary)
+		assert.NotContains(t, d.Summary+d.Detail, "hunter2")
+	}
+	for _, msg := range log.errorMessages {
+		assert.NotContains(t, msg, "hunter2")
+	}
+	assert.Contains(t, summaries, `"[secret]" is not a number`)
+	// Values that aren't secret are quoted as they are.
+	assert.Contains(t, summaries, `"visible" is not a number`)
+}
+
+func TestShortSecretsAreMaskedWhole(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-secrets
+runtime: yaml
+configuration:
+  short:
+    type: String
+    default: a
+    secret: true
+variables:
+  number:
+    fn::toNumber: ${short}
+  visible:
+    fn::toNumber: abc
+outputs:
+  number: ${number}
+`)
+	runner := newRunner(tmpl, newMockPackageMap())
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags := runner.Evaluate(ctx)
+		assert.True(t, diags.HasErrors())
+		if out, ok := runner.variables["number"].(pulumi.Output); ok {
+			_, _ = internals.UnsafeAwaitOutput(ctx.Context(), out)
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	assert.Error(t, err)
+
+	runner.sdiags.mutex.Lock()
+	defer runner.sdiags.mutex.Unlock()
+	assert.ElementsMatch(t, []string{
+		`<stdin>:13:19: "abc" is not a number`,
+		`<stdin>:11:19: "[secret]" is not a number`,
+	}, diagStrings(runner.sdiags.diags))
+}
@@ -3,6 +3,8 @@
 package pulumiyaml
 
 import (
+	"fmt"
+
 	"github.com/blang/semver"
 	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
 )
@@ -19,3 +21,23 @@ func ParseVersion(v *ast.StringExpr) (*semver.Version, error) {
 
 	return &version, nil
 }
+
+// parseSemver parses a version given to fn::semverSatisfies or fn::semverCompare. Like the version
+// resource option, missing minor and patch numbers and a leading 'v' are allowed.
+func parseSemver(v string) (semver.Version, error) {
+	version, err := semver.ParseTolerant(v)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid version %q: %w", v, err)
+	}
+	return version, nil
+}
+
+// parseSemverRange parses a version constraint given to fn::semverSatisfies, such as
+// ">=4.0.0 <5.0.0 || >=6.0.0".
+func parseSemverRange(c string) (semver.Range, error) {
+	r, err := semver.ParseRange(c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", c, err)
+	}
+	return r, nil
+}
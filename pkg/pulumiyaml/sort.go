@@ -120,9 +120,21 @@ func topologicallySortedResources(t *ast.TemplateDecl, externalConfig []configNo
 
 	dependencies := map[string][]*ast.StringExpr{}
 
+	// Config entries whose default refers to other values, which are sorted along with the
+	// resources and variables.
+	dependentConfig := map[string]configNodeYaml{}
+
 	templateConfig := make([]configNode, len(t.Configuration.Entries))
 	for i, kvp := range t.Configuration.Entries {
 		templateConfig[i] = configNode(configNodeYaml(kvp))
+		if kvp.Value != nil {
+			var deps []*ast.StringExpr
+			getExpressionDependencies(&deps, kvp.Value.Default)
+			if len(deps) > 0 {
+				dependentConfig[kvp.Key.Value] = configNodeYaml(kvp)
+				dependencies[kvp.Key.Value] = deps
+			}
+		}
 	}
 	for _, node := range append(templateConfig, externalConfig...) {
 		cname := node.key().Value
@@ -131,6 +143,9 @@ func topologicallySortedResources(t *ast.TemplateDecl, externalConfig []configNo
 
 		if !cdiags.HasErrors() {
 			addIntermediate(cname, node)
+			if _, ok := dependentConfig[cname]; ok {
+				continue
+			}
 			dependencies[cname] = nil
 
 			// Special case: configuration goes first
@@ -139,6 +154,28 @@ func topologicallySortedResources(t *ast.TemplateDecl, externalConfig []configNo
 		}
 	}
 
+	// Every resource may be tagged with the template's defaultTags, which can only refer to
+	// config.
+	var defaultTagDeps []*ast.StringExpr
+	for _, kvp := range t.DefaultTags.Entries {
+		var deps []*ast.StringExpr
+		getExpressionDependencies(&deps, kvp.Value)
+		for _, dep := range deps {
+			if dep.Value == PulumiVarName {
+				continue
+			}
+			node, ok := intermediates[dep.Value]
+			if !ok && t.Name != nil {
+				node, ok = intermediates[stripConfigNamespace(t.Name.Value, dep.Value)]
+			}
+			if _, isConfig := node.(configNode); !ok || !isConfig {
+				diags.Extend(ast.ExprError(dep, fmt.Sprintf("defaultTags can only refer to config, not %q", dep.Value), ""))
+				continue
+			}
+			defaultTagDeps = append(defaultTagDeps, dep)
+		}
+	}
+
 	// Map of package name to default provider resource and it's key.
 	defaultProviders := map[string]*ast.StringExpr{}
 	for _, kvp := range t.Resources.Entries {
@@ -156,7 +193,7 @@ func topologicallySortedResources(t *ast.TemplateDecl, externalConfig []configNo
 
 		if !cdiags.HasErrors() {
 			addIntermediate(rname, node)
-			dependencies[rname] = GetResourceDependencies(r)
+			dependencies[rname] = append(GetResourceDependencies(r), defaultTagDeps...)
 		}
 	}
 	for _, kvp := range t.Variables.Entries {
@@ -241,6 +278,11 @@ func topologicallySortedResources(t *ast.TemplateDecl, externalConfig []configNo
 			visited[name.Value] = true
 			visiting[name.Value] = false
 
+			// The template's declaration of a config entry is registered before any value
+			// for it from the stack's config, as it is for config without dependencies.
+			if node, ok := dependentConfig[name.Value]; ok && isConfigNodeProp(e) {
+				sorted = append(sorted, node)
+			}
 			sorted = append(sorted, e)
 		}
 		return true
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -28,6 +29,9 @@ func diagString(d *syntax.Diagnostic) string {
 	if d.Detail != "" {
 		s += fmt.Sprintf("; %v", d.Detail)
 	}
+	if d.Related != nil {
+		s += fmt.Sprintf(" [%v: %v]", syntax.Position(&d.Related.Range), d.Related.Message)
+	}
 	return s
 }
 
@@ -135,6 +139,17 @@ func TestSortMultipleDefaultProviders(t *testing.T) {
 	assert.Equal(t, "echo", names[3])
 }
 
+func TestLoadDuplicateResource(t *testing.T) {
+	t.Parallel()
+
+	_, diags, err := LoadFile("../tests/testdata/duplicate-resource/Pulumi.yaml")
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`Pulumi.yaml:9:3: found duplicate key "bucket" in resources [Pulumi.yaml:5:3: The other "bucket" is declared here]`,
+	}, diagStrings(diags))
+}
+
 func TestSortErrorCycle(t *testing.T) {
 	t.Parallel()
 
@@ -168,3 +183,133 @@ func sortedNames(rs []graphNode) []string {
 	}
 	return names
 }
+
+func TestSortInvokeAfter(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        after:
+          - ${seed}
+          - ${bucket}
+  seed: 42
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: oof
+`)
+	confNodes := []configNode{}
+	resources, diags := topologicallySortedResources(tmpl, confNodes)
+	requireNoErrors(t, tmpl, diags)
+	names := sortedNames(resources)
+	assert.ElementsMatch(t, []string{"seed", "bucket", "lookup"}, names)
+	assert.Equal(t, "lookup", names[2])
+}
+
+func TestSortInvokeDependsOn(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        dependsOn:
+          - ${bucket}
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: oof
+`)
+	confNodes := []configNode{}
+	resources, diags := topologicallySortedResources(tmpl, confNodes)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"bucket", "lookup"}, sortedNames(resources))
+}
+
+func TestSortInvokeDependsOnCycle(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+variables:
+  lookup:
+    fn::invoke:
+      function: test:invoke:empty
+      options:
+        dependsOn:
+          - ${bucket}
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${lookup}
+`)
+	confNodes := []configNode{}
+	_, diags := topologicallySortedResources(tmpl, confNodes)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "circular dependency of")
+}
+
+func TestSortConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+configuration:
+  bucketName:
+    default: ${prefix}-data
+  prefix:
+    default: ${projectName}
+  projectName:
+    default: ${org}
+  region:
+    default: us-west-2
+variables:
+  org: ${pulumi.organization}
+`)
+	confNodes := []configNode{
+		configNodeProp{k: "prefix", v: resource.NewStringProperty("custom")},
+	}
+	resources, diags := topologicallySortedResources(tmpl, confNodes)
+	requireNoErrors(t, tmpl, diags)
+	assert.Equal(t, []string{"region", "org", "projectName", "prefix", "prefix", "bucketName"},
+		sortedNames(resources))
+	// The template's declaration comes before the value from the stack's config.
+	assert.IsType(t, configNodeYaml{}, resources[3])
+	assert.IsType(t, configNodeProp{}, resources[4])
+}
+
+func TestSortConfigDefaultsCycle(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+configuration:
+  first:
+    default: ${second}
+  second:
+    default: ${third}-suffix
+variables:
+  third: ${first}
+`)
+	_, diags := topologicallySortedResources(tmpl, nil)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		"<stdin>:10:10: circular dependency of config 'first' transitively on itself",
+	}, diagStrings(diags))
+}
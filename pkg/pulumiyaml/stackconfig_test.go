@@ -0,0 +1,103 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel, since it sets the config through environment variables.
+func TestStackConfig(t *testing.T) {
+	setConfig(t, resource.PropertyMap{
+		projectConfigKey("shared"): resource.NewStringProperty("from-stack"),
+		projectConfigKey("token"):  resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"aws:region":               resource.NewStringProperty("us-west-2"),
+	})
+
+	tmpl := yamlTemplate(t, `
+name: foo
+runtime: yaml
+variables:
+  shared:
+    fn::stackConfig: shared
+  namespaced:
+    fn::stackConfig: foo:shared
+  region:
+    fn::stackConfig: aws:region
+  token:
+    fn::stackConfig: token
+  overridden:
+    fn::stackConfig: [shared, default]
+  defaulted:
+    fn::stackConfig: [unset, default]
+`)
+	runner := newRunner(tmpl, newMockPackageMap())
+	typing, diags := TypeCheck(runner)
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+	assert.Equal(t, "string", displayType(typing.TypeVariable("shared")))
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags := runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks(testProject, "stack", &testMonitor{}))
+	require.NoError(t, err)
+	assert.Equal(t, "from-stack", runner.variables["shared"])
+	assert.Equal(t, "from-stack", runner.variables["namespaced"])
+	assert.Equal(t, "us-west-2", runner.variables["region"])
+	assert.Equal(t, "from-stack", runner.variables["overridden"])
+	assert.Equal(t, "default", runner.variables["defaulted"])
+	token, ok := runner.variables["token"].(pulumi.Output)
+	require.True(t, ok, "expected a secret output, got %v", runner.variables["token"])
+	assert.True(t, pulumi.IsSecret(token))
+}
+
+// Not parallel, since it sets the config through environment variables.
+func TestStackConfigDiags(t *testing.T) {
+	setConfig(t, resource.PropertyMap{
+		projectConfigKey("port"): resource.NewStringProperty("8080"),
+	})
+
+	tmpl := yamlTemplate(t, `
+name: foo
+runtime: yaml
+configuration:
+  port:
+    type: integer
+    default: 80
+variables:
+  rawPort:
+    fn::stackConfig: port
+  missing:
+    fn::stackConfig: unset
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	require.Len(t, diags, 1, diagStrings(diags))
+	assert.Equal(t, `config "port" is declared by the template`, diags[0].Summary)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks(testProject, "stack", &testMonitor{}))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, `stack config "foo:unset" is not set, and fn::stackConfig has no default`,
+		diags[len(diags)-1].Summary)
+
+	_, diags, err = LoadYAMLBytes("<stdin>", []byte(`
+name: foo
+runtime: yaml
+variables:
+  bad:
+    fn::stackConfig: [a, b, c]
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "the argument to fn::stackConfig must be a key, or a list of a key and a default", diags[0].Summary)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+func TestStringFunctions(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-strings
+runtime: yaml
+variables:
+  raw: "  My Bucket  "
+  trimmed:
+    fn::trim: ${raw}
+  listed:
+    fn::trim: ["\tx\n"]
+  cut:
+    fn::trim: ["--name--", "-"]
+  upper:
+    fn::upper: ${trimmed}
+  lower:
+    fn::lower: ${trimmed}
+  title:
+    fn::title: "hello wide-world, it's über_cool"
+`)
+	typing, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+	for _, name := range []string{"trimmed", "cut", "upper", "lower", "title"} {
+		assert.Equal(t, "string", displayType(typing.TypeVariable(name)), name)
+	}
+
+	testTemplate(t, tmpl, func(e *programEvaluator) {
+		assert.Equal(t, "My Bucket", e.variables["trimmed"])
+		assert.Equal(t, "x", e.variables["listed"])
+		assert.Equal(t, "name", e.variables["cut"])
+		assert.Equal(t, "MY BUCKET", e.variables["upper"])
+		assert.Equal(t, "my bucket", e.variables["lower"])
+		assert.Equal(t, "Hello Wide-World, It's Über_cool", e.variables["title"])
+
+		e.variables["unknown"] = pulumi.UnsafeUnknownOutput(nil)
+		unknown := &ast.SymbolExpr{
+			Property: &ast.PropertyAccess{Accessors: []ast.PropertyAccessor{&ast.PropertyName{Name: "unknown"}}},
+		}
+		for _, x := range []ast.Expr{ast.Trim(unknown, nil), ast.Trim(ast.String("x"), unknown), ast.Upper(unknown)} {
+			v, ok := e.evaluateExpr(x)
+			require.True(t, ok)
+			e.pulumiCtx.Export("unknown", v.(pulumi.Output).ApplyT(func(x interface{}) (interface{}, error) {
+				assert.Failf(t, "unexpected apply", "applied a string function to an unknown: %v", x)
+				return x, nil
+			}))
+		}
+	})
+}
+
+func TestStringFunctionErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-strings
+runtime: yaml
+variables:
+  upper:
+    fn::upper: 42
+  cutset:
+    fn::trim: [x, [y]]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.True(t, diags.HasErrors())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "string is not assignable from List<string>", diags[0].Summary)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		diags = newRunner(tmpl, newMockPackageMap()).Evaluate(ctx)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	assert.Equal(t, []string{
+		"expected argument to fn::upper to be a string, got a number",
+		"expected the cutset of fn::trim to be a string, got a list",
+	}, summaries)
+
+	_, diags, err = LoadYAMLBytes("<stdin>", []byte(`
+name: test-strings
+runtime: yaml
+variables:
+  bad:
+    fn::trim: [a, b, c]
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "the argument to fn::trim must be a string, or a list of a string and a cutset", diags[0].Summary)
+}
@@ -3,6 +3,7 @@
 package syntax
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -14,10 +15,44 @@ import (
 type Diagnostic struct {
 	hcl.Diagnostic
 
+	// Category optionally classifies the diagnostic for tools that consume diagnostics, e.g.
+	// "casing". Most diagnostics have no category.
+	Category string
+
+	// Fix optionally suggests an edit that resolves the diagnostic, such as an editor could offer
+	// as a quick fix. It is only set when the edit is safe to apply without review.
+	Fix *SuggestedEdit
+
+	// Related optionally points to another place in the source that the diagnostic refers to, such
+	// as where a duplicated key was first declared.
+	Related *RelatedRange
+
 	// Whether the diagnostic has been shown to the user
 	Shown bool
 }
 
+// A SuggestedEdit is an edit to a source file that replaces the text within Range with
+// Replacement.
+type SuggestedEdit struct {
+	Range       hcl.Range
+	Replacement string
+}
+
+// A RelatedRange is a place in the source that a diagnostic refers to, described by Message.
+type RelatedRange struct {
+	Range   hcl.Range
+	Message string
+}
+
+// WithRelated points the diagnostic to rng, described by message, without mutating the receiver.
+// If rng is nil, the diagnostic is returned unchanged.
+func (d Diagnostic) WithRelated(rng *hcl.Range, message string) *Diagnostic {
+	if rng != nil {
+		d.Related = &RelatedRange{Range: *rng, Message: message}
+	}
+	return &d
+}
+
 // WithContext adds context without mutating the receiver.
 func (d Diagnostic) WithContext(rng *hcl.Range) *Diagnostic {
 	d.Context = rng
@@ -25,9 +60,38 @@ func (d Diagnostic) WithContext(rng *hcl.Range) *Diagnostic {
 }
 
 func (d Diagnostic) HCL() *hcl.Diagnostic {
+	if d.Related != nil {
+		d.Extra = relatedExtra{related: *d.Related, wrapped: d.Extra}
+	}
 	return &d.Diagnostic
 }
 
+// relatedExtra carries the related range of a diagnostic in its HCL form, so that it can be
+// rendered. It wraps any other extra information, such as hintExtra.
+type relatedExtra struct {
+	related RelatedRange
+	wrapped interface{}
+}
+
+func (e relatedExtra) UnwrapDiagnosticExtra() interface{} {
+	return e.wrapped
+}
+
+// RelatedOf returns the related range of a diagnostic converted with HCL, if it has one.
+func RelatedOf(d *hcl.Diagnostic) *RelatedRange {
+	for extra := d.Extra; extra != nil; {
+		if e, ok := extra.(relatedExtra); ok {
+			return &e.related
+		}
+		unwrapper, ok := extra.(hcl.DiagnosticExtraUnwrapper)
+		if !ok {
+			break
+		}
+		extra = unwrapper.UnwrapDiagnosticExtra()
+	}
+	return nil
+}
+
 // Warning creates a new warning-level diagnostic from the given subject, summary, and detail.
 func Warning(rng *hcl.Range, summary, detail string) *Diagnostic {
 	return &Diagnostic{
@@ -40,6 +104,34 @@ func Warning(rng *hcl.Range, summary, detail string) *Diagnostic {
 	}
 }
 
+// Hint creates a new hint from the given subject, summary, and detail. A hint is a warning-level
+// diagnostic that points out something the user may not expect, but that is not a problem with the
+// template. It is shown at the info level.
+func Hint(rng *hcl.Range, summary, detail string) *Diagnostic {
+	d := Warning(rng, summary, detail)
+	d.Extra = hintExtra{}
+	return d
+}
+
+// hintExtra marks the HCL diagnostics of hints, so that they are still recognized after a
+// diagnostic is converted with HCL.
+type hintExtra struct{}
+
+// IsHint returns true if d is a hint. See Hint.
+func IsHint(d *hcl.Diagnostic) bool {
+	for extra := d.Extra; extra != nil; {
+		if _, ok := extra.(hintExtra); ok {
+			return true
+		}
+		unwrapper, ok := extra.(hcl.DiagnosticExtraUnwrapper)
+		if !ok {
+			break
+		}
+		extra = unwrapper.UnwrapDiagnosticExtra()
+	}
+	return false
+}
+
 // Error creates a new error-level diagnostic from the given subject, summary, and detail.
 func Error(rng *hcl.Range, summary, detail string) *Diagnostic {
 	return &Diagnostic{
@@ -57,6 +149,12 @@ func NodeError(node Node, summary, detail string) *Diagnostic {
 	return Error(rng, summary, detail)
 }
 
+// Position formats the start of rng as file:line:column, for rendering the related range of a
+// diagnostic.
+func Position(rng *hcl.Range) string {
+	return fmt.Sprintf("%s:%d:%d", rng.Filename, rng.Start.Line, rng.Start.Column)
+}
+
 // Diagnostics is a list of diagnostics.
 type Diagnostics []*Diagnostic
 
@@ -85,6 +183,8 @@ func (d Diagnostics) Error() string {
 		for _, diag := range d {
 			if diag.Severity == hcl.DiagError {
 				sb.WriteString("\n-error: ")
+			} else if IsHint(&diag.Diagnostic) {
+				sb.WriteString("\n-hint: ")
 			} else {
 				sb.WriteString("\n-warning: ")
 			}
@@ -126,6 +226,90 @@ func (d Diagnostics) Unshown() *Diagnostics {
 	return &diags
 }
 
+// A JSONPos is a position within a source file as reported in JSON diagnostics. Lines and columns
+// are 1-based.
+type JSONPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// A JSONEdit is the JSON representation of a SuggestedEdit. The edit is to the file of the
+// diagnostic that suggests it.
+type JSONEdit struct {
+	Start       JSONPos `json:"start"`
+	End         JSONPos `json:"end"`
+	Replacement string  `json:"replacement"`
+}
+
+// A JSONRelated is the JSON representation of a RelatedRange.
+type JSONRelated struct {
+	File    string  `json:"file"`
+	Start   JSONPos `json:"start"`
+	End     JSONPos `json:"end"`
+	Message string  `json:"message"`
+}
+
+// A JSONDiagnostic is the JSON representation of a Diagnostic. See MarshalDiagnostics.
+type JSONDiagnostic struct {
+	Severity string       `json:"severity"`
+	Message  string       `json:"message"`
+	Detail   string       `json:"detail,omitempty"`
+	Category string       `json:"category,omitempty"`
+	File     string       `json:"file,omitempty"`
+	Start    *JSONPos     `json:"start,omitempty"`
+	End      *JSONPos     `json:"end,omitempty"`
+	Fix      *JSONEdit    `json:"fix,omitempty"`
+	Related  *JSONRelated `json:"related,omitempty"`
+}
+
+// MarshalDiagnostics serializes diagnostics as a JSON array of JSONDiagnostic values, for tools
+// that can't parse rendered diagnostic text. Positions are taken from the diagnostic's subject,
+// or from its context if it has no subject. A suggested fix is only included if it edits the same
+// file. A related range is included with its own file.
+func MarshalDiagnostics(diags Diagnostics) ([]byte, error) {
+	result := make([]JSONDiagnostic, 0, len(diags))
+	for _, d := range diags {
+		jd := JSONDiagnostic{
+			Severity: "error",
+			Message:  d.Summary,
+			Detail:   d.Detail,
+			Category: d.Category,
+		}
+		if IsHint(&d.Diagnostic) {
+			jd.Severity = "hint"
+		} else if d.Severity == hcl.DiagWarning {
+			jd.Severity = "warning"
+		}
+		rng := d.Subject
+		if rng == nil {
+			rng = d.Context
+		}
+		if rng != nil {
+			jd.File = rng.Filename
+			jd.Start = &JSONPos{Line: rng.Start.Line, Column: rng.Start.Column}
+			jd.End = &JSONPos{Line: rng.End.Line, Column: rng.End.Column}
+		}
+		if fix := d.Fix; fix != nil && (rng == nil || fix.Range.Filename == rng.Filename) {
+			jd.File = fix.Range.Filename
+			jd.Fix = &JSONEdit{
+				Start:       JSONPos{Line: fix.Range.Start.Line, Column: fix.Range.Start.Column},
+				End:         JSONPos{Line: fix.Range.End.Line, Column: fix.Range.End.Column},
+				Replacement: fix.Replacement,
+			}
+		}
+		if related := d.Related; related != nil {
+			jd.Related = &JSONRelated{
+				File:    related.Range.Filename,
+				Start:   JSONPos{Line: related.Range.Start.Line, Column: related.Range.Start.Column},
+				End:     JSONPos{Line: related.Range.End.Line, Column: related.Range.End.Column},
+				Message: related.Message,
+			}
+		}
+		result = append(result, jd)
+	}
+	return json.Marshal(result)
+}
+
 // Inform the user that they did not conform with the expected capitalization style. If
 // `expected` matches `found`, then `nil` is returned. This allows
 // `Diagnostics.Extend(UnexpectedCasing(location, expected, found))` without checking if
@@ -136,5 +320,7 @@ func UnexpectedCasing(rng *hcl.Range, expected, found string) *Diagnostic {
 	}
 	summary := fmt.Sprintf("'%s' looks like a miscapitalization of '%s'", found, expected)
 	detail := "A future version of Pulumi YAML will enforce camelCase fields. See https://github.com/pulumi/pulumi-yaml/issues/355 for details."
-	return Warning(rng, summary, detail)
+	diag := Warning(rng, summary, detail)
+	diag.Category = "casing"
+	return diag
 }
@@ -31,6 +31,30 @@ func (s YAMLSyntax) Range() *hcl.Range {
 	return s.rng
 }
 
+// HeadComment returns the comment on the lines before the YAML node, if any.
+func (s YAMLSyntax) HeadComment() string {
+	if s.Node == nil {
+		return ""
+	}
+	return s.Node.HeadComment
+}
+
+// LineComment returns the comment on the line of the YAML node, if any.
+func (s YAMLSyntax) LineComment() string {
+	if s.Node == nil {
+		return ""
+	}
+	return s.Node.LineComment
+}
+
+// FootComment returns the comment on the lines after the YAML node, if any.
+func (s YAMLSyntax) FootComment() string {
+	if s.Node == nil {
+		return ""
+	}
+	return s.Node.FootComment
+}
+
 // yamlEndPos calculates the end position of a YAML node.
 //
 // For simple scalars, this is reasonably accurate: the end position is (start line + the number of lines, start
@@ -176,9 +200,9 @@ func MarshalYAML(n syntax.Node) (*yaml.Node, syntax.Diagnostics) {
 		yamlNode.Tag = s.Tag
 		yamlNode.Value = s.Value
 		yamlNode.Style = s.Style
-		yamlNode.HeadComment = s.HeadComment
-		yamlNode.LineComment = s.LineComment
-		yamlNode.FootComment = s.FootComment
+		yamlNode.HeadComment = s.Node.HeadComment
+		yamlNode.LineComment = s.Node.LineComment
+		yamlNode.FootComment = s.Node.FootComment
 
 		originalValue = s.value
 	case syntax.Trivia:
@@ -290,9 +314,23 @@ func (v *yamlValue) UnmarshalYAML(n *yaml.Node) error {
 
 // DecodeYAML decodes a YAML value from the given decoder into a syntax node. See UnmarshalYAML for mode details on the
 // decoding process.
+//
+// The comments at the start and end of the document, which are separated from its content by blank lines, are attached
+// to the top-level object as its head and foot comments.
 func DecodeYAML(filename string, d *yaml.Decoder, tags TagDecoder) (*syntax.ObjectNode, syntax.Diagnostics) {
+	var doc yaml.Node
+	if err := d.Decode(&doc); err != nil {
+		return nil, syntax.Diagnostics{syntax.Error(nil, err.Error(), "")}
+	}
+	n := &doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		n = doc.Content[0]
+		n.HeadComment = joinComments(doc.HeadComment, n.HeadComment)
+		n.FootComment = joinComments(n.FootComment, doc.FootComment)
+	}
+
 	v := yamlValue{filename: filename, tags: tags}
-	if err := d.Decode(&v); err != nil {
+	if err := v.UnmarshalYAML(n); err != nil {
 		return nil, syntax.Diagnostics{syntax.Error(nil, err.Error(), "")}
 	}
 	obj, ok := v.node.(*syntax.ObjectNode)
@@ -303,11 +341,28 @@ func DecodeYAML(filename string, d *yaml.Decoder, tags TagDecoder) (*syntax.Obje
 	return obj, v.diags
 }
 
+func joinComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n\n" + b
+}
+
 // EncodeYAML encodes a syntax node into YAML text using the given encoder. See MarshalYAML for mode details on the
 // encoding process.
+//
+// The head and foot comments of n are written at the start and end of the document, separated from its content by
+// blank lines.
 func EncodeYAML(e *yaml.Encoder, n syntax.Node) syntax.Diagnostics {
 	yamlNode, diags := MarshalYAML(n)
-	if err := e.Encode(yamlNode); err != nil {
+	doc := &yaml.Node{
+		Kind:        yaml.DocumentNode,
+		HeadComment: yamlNode.HeadComment,
+		FootComment: yamlNode.FootComment,
+		Content:     []*yaml.Node{yamlNode},
+	}
+	yamlNode.HeadComment, yamlNode.FootComment = "", ""
+	if err := e.Encode(doc); err != nil {
 		diags.Extend(syntax.Error(nil, err.Error(), ""))
 	}
 	return diags
@@ -0,0 +1,162 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+const commentedTemplate = `# A template with comments.
+
+# The project.
+name: comments
+runtime: yaml
+# The sections follow.
+resources:
+  # The bucket.
+  bucket:
+    type: aws:s3:Bucket # A classic token.
+    properties:
+      # Its name.
+      bucketName: logs # Not a secret.
+      tags:
+        - a # The first tag.
+        # The second tag.
+        - b
+    options:
+      version: 1.2.3 # Pinned.
+outputs:
+  # The name of the bucket.
+  name: ${bucket.bucketName}
+
+# The end.
+`
+
+func decodeString(t *testing.T, source string) *syntax.ObjectNode {
+	t.Helper()
+
+	n, diags := DecodeYAML("Pulumi.yaml", yaml.NewDecoder(bytes.NewReader([]byte(source))), nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return n
+}
+
+func encodeString(t *testing.T, n syntax.Node) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	diags := EncodeYAML(enc, n)
+	require.False(t, diags.HasErrors(), diags.Error())
+	require.NoError(t, enc.Close())
+	return b.String()
+}
+
+// property returns the entry of n at path.
+func property(t *testing.T, n syntax.Node, path ...string) syntax.ObjectPropertyDef {
+	t.Helper()
+
+	obj, ok := n.(*syntax.ObjectNode)
+	require.True(t, ok)
+	for i := 0; i < obj.Len(); i++ {
+		kvp := obj.Index(i)
+		if kvp.Key.Value() != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return kvp
+		}
+		return property(t, kvp.Value, path[1:]...)
+	}
+	require.Failf(t, "missing property", "%v", path)
+	return syntax.ObjectPropertyDef{}
+}
+
+// rewrite returns a copy of n with the value at path replaced by the result of calling f with it.
+func rewrite(n syntax.Node, path []string, f func(syntax.Node) syntax.Node) syntax.Node {
+	if len(path) == 0 {
+		return f(n)
+	}
+	obj := n.(*syntax.ObjectNode)
+	entries := make([]syntax.ObjectPropertyDef, obj.Len())
+	for i := range entries {
+		entries[i] = obj.Index(i)
+		if entries[i].Key.Value() == path[0] {
+			entries[i].Value = rewrite(entries[i].Value, path[1:], f)
+		}
+	}
+	return syntax.ObjectSyntax(obj.Syntax(), entries...)
+}
+
+func TestCommentsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	n := decodeString(t, commentedTemplate)
+	assert.Equal(t, commentedTemplate, encodeString(t, n))
+
+	// The comments are attached to the nodes that they describe.
+	assert.Equal(t, syntax.Comments{Head: "# A template with comments.", Foot: "# The end."}, syntax.CommentsOf(n))
+	name := property(t, n, "name")
+	assert.Equal(t, "# The project.", syntax.CommentsOf(name.Key).Head)
+	resources := property(t, n, "resources")
+	assert.Equal(t, "# The sections follow.", syntax.CommentsOf(resources.Key).Head)
+	bucket := property(t, n, "resources", "bucket")
+	assert.Equal(t, "# The bucket.", syntax.CommentsOf(bucket.Key).Head)
+	bucketName := property(t, n, "resources", "bucket", "properties", "bucketName")
+	assert.Equal(t, "# Its name.", syntax.CommentsOf(bucketName.Key).Head)
+	assert.Equal(t, "# Not a secret.", syntax.CommentsOf(bucketName.Value).Line)
+	assert.Equal(t, syntax.Comments{}, syntax.CommentsOf(syntax.String("new")))
+}
+
+func TestCommentsRewrite(t *testing.T) {
+	t.Parallel()
+
+	n := decodeString(t, commentedTemplate)
+	path := []string{"resources", "bucket", "options"}
+	rewritten := rewrite(n, path, func(options syntax.Node) syntax.Node {
+		version := property(t, options, "version")
+		return syntax.ObjectSyntax(options.Syntax(),
+			// Bump the version, keeping its comments.
+			syntax.ObjectProperty(version.Key, syntax.StringSyntax(syntax.CommentsOf(version.Value), "2.0.0")),
+			// Add an option with comments of its own.
+			syntax.ObjectProperty(
+				syntax.StringSyntax(syntax.Comments{Head: "# Keep the bucket."}, "protect"),
+				syntax.BooleanSyntax(syntax.Comments{Line: "# Added."}, true)))
+	})
+
+	expected := `# A template with comments.
+
+# The project.
+name: comments
+runtime: yaml
+# The sections follow.
+resources:
+  # The bucket.
+  bucket:
+    type: aws:s3:Bucket # A classic token.
+    properties:
+      # Its name.
+      bucketName: logs # Not a secret.
+      tags:
+        - a # The first tag.
+        # The second tag.
+        - b
+    options:
+      version: 2.0.0 # Pinned.
+      # Keep the bucket.
+      protect: true # Added.
+outputs:
+  # The name of the bucket.
+  name: ${bucket.bucketName}
+
+# The end.
+`
+	assert.Equal(t, expected, encodeString(t, rewritten))
+}
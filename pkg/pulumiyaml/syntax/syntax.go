@@ -16,6 +16,9 @@ func (noSyntax) Range() *hcl.Range {
 	return nil
 }
 
+// Trivia is implemented by syntax that carries the comments attached to a node. The head comment
+// is on the lines before the node, the line comment follows it on its line, and the foot comment is
+// on the lines after it.
 type Trivia interface {
 	Syntax
 
@@ -23,3 +26,42 @@ type Trivia interface {
 	LineComment() string
 	FootComment() string
 }
+
+// Comments is Trivia that only carries comments. It attaches comments to a node that doesn't come
+// from source text, such as a value written by a tool that rewrites a template:
+//
+//	syntax.StringSyntax(syntax.CommentsOf(old), "v2")
+type Comments struct {
+	Head string
+	Line string
+	Foot string
+}
+
+func (Comments) Range() *hcl.Range {
+	return nil
+}
+
+func (c Comments) HeadComment() string {
+	return c.Head
+}
+
+func (c Comments) LineComment() string {
+	return c.Line
+}
+
+func (c Comments) FootComment() string {
+	return c.Foot
+}
+
+// CommentsOf returns the comments attached to n. It returns no comments if n's syntax doesn't
+// carry any.
+func CommentsOf(n Node) Comments {
+	if n == nil {
+		return Comments{}
+	}
+	t, ok := n.Syntax().(Trivia)
+	if !ok {
+		return Comments{}
+	}
+	return Comments{Head: t.HeadComment(), Line: t.LineComment(), Foot: t.FootComment()}
+}
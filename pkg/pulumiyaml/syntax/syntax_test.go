@@ -5,6 +5,7 @@ package syntax
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,3 +34,83 @@ func TestStringer(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	rng := &hcl.Range{
+		Filename: "Pulumi.yaml",
+		Start:    hcl.Pos{Line: 3, Column: 5, Byte: 20},
+		End:      hcl.Pos{Line: 3, Column: 12, Byte: 27},
+	}
+	misspelled := Error(rng, "unknown property", "did you mean 'name'?")
+	misspelled.Fix = &SuggestedEdit{Range: *rng, Replacement: "name"}
+	diags := Diagnostics{
+		misspelled,
+		UnexpectedCasing(rng, "fooBar", "foo_bar"),
+		Warning(nil, "no location", ""),
+		Hint(nil, "just so you know", ""),
+		Error(rng, "found duplicate key", "").WithRelated(&hcl.Range{
+			Filename: "a.yaml",
+			Start:    hcl.Pos{Line: 1, Column: 1},
+			End:      hcl.Pos{Line: 1, Column: 4},
+		}, "The other key is declared here"),
+	}
+
+	bytes, err := MarshalDiagnostics(diags)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"severity": "error",
+			"message": "unknown property",
+			"detail": "did you mean 'name'?",
+			"file": "Pulumi.yaml",
+			"start": {"line": 3, "column": 5},
+			"end": {"line": 3, "column": 12},
+			"fix": {
+				"start": {"line": 3, "column": 5},
+				"end": {"line": 3, "column": 12},
+				"replacement": "name"
+			}
+		},
+		{
+			"severity": "warning",
+			"message": "'foo_bar' looks like a miscapitalization of 'fooBar'",
+			"detail": "A future version of Pulumi YAML will enforce camelCase fields. See https://github.com/pulumi/pulumi-yaml/issues/355 for details.",
+			"category": "casing",
+			"file": "Pulumi.yaml",
+			"start": {"line": 3, "column": 5},
+			"end": {"line": 3, "column": 12}
+		},
+		{
+			"severity": "warning",
+			"message": "no location"
+		},
+		{
+			"severity": "hint",
+			"message": "just so you know"
+		},
+		{
+			"severity": "error",
+			"message": "found duplicate key",
+			"file": "Pulumi.yaml",
+			"start": {"line": 3, "column": 5},
+			"end": {"line": 3, "column": 12},
+			"related": {
+				"file": "a.yaml",
+				"start": {"line": 1, "column": 1},
+				"end": {"line": 1, "column": 4},
+				"message": "The other key is declared here"
+			}
+		}
+	]`, string(bytes))
+
+	// The related range is kept by HCL, alongside the mark of a hint.
+	hint := Hint(rng, "just so you know", "").WithRelated(rng, "see here").HCL()
+	assert.True(t, IsHint(hint))
+	assert.Equal(t, &RelatedRange{Range: *rng, Message: "see here"}, RelatedOf(hint))
+
+	bytes, err = MarshalDiagnostics(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(bytes))
+}
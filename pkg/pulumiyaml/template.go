@@ -18,6 +18,8 @@ type Template struct {
 	Resources map[string]*Resource `json:",omitempty" yaml:",omitempty"`
 	// Outputs declares a set of output values that will be exported from the stack and usable from other stacks.
 	Outputs map[string]interface{} `json:",omitempty" yaml:",omitempty"`
+	// Providers configures the default provider of each package by name.
+	Providers map[string]interface{} `json:",omitempty" yaml:",omitempty"`
 
 	// TODO: Mappings and Conditions
 
@@ -98,10 +100,10 @@ type Resource struct {
 	// Options contains all Pulumi resource options used to register the resource.
 	ResourceOptions *ResourceOptions `json:",omitempty" yaml:",omitempty"`
 
-	// TODO: Condition, Metadata
+	// TODO: Metadata
 
-	// Condition makes this resource's creation conditional upon a predefined Condition attribute;
-	// see https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/conditions-section-structure.html.
+	// Condition makes this resource's creation conditional upon a boolean expression, such as
+	// "${isProduction}".
 	Condition string `json:",omitempty" yaml:",omitempty"`
 	// Metadata enables arbitrary metadata values to be associated with a resource.
 	Metadata map[string]interface{} `json:",omitempty" yaml:",omitempty"`
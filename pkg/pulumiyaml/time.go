@@ -0,0 +1,58 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// When set to an RFC3339 time, fn::now returns it instead of the current time, which makes builds
+// reproducible.
+var fixedNow = os.Getenv("PULUMI_YAML_NOW")
+
+// runTime is the time that fn::now read, which is shared by the whole run, including the bodies
+// of components.
+type runTime struct {
+	once sync.Once
+	now  time.Time
+	err  error
+}
+
+// currentTime is the time that fn::now returns. It is read from the runner's clock the first time
+// it is needed, and reused for the rest of the run.
+func (r *Runner) currentTime() (time.Time, error) {
+	r.now.once.Do(func() {
+		if fixedNow != "" {
+			now, err := time.Parse(time.RFC3339, fixedNow)
+			if err != nil {
+				r.now.err = fmt.Errorf("PULUMI_YAML_NOW must be an RFC3339 time: %w", err)
+				return
+			}
+			r.now.now = now.UTC()
+			return
+		}
+		r.now.now = r.clock().UTC()
+	})
+	return r.now.now, r.now.err
+}
+
+// parseTime parses the time argument of fn::timeAdd.
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC3339 time, such as 2006-01-02T15:04:05Z", s)
+	}
+	return t, nil
+}
+
+// parseDuration parses the duration argument of fn::timeAdd.
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration, such as 24h or -30m", s)
+	}
+	return d, nil
+}
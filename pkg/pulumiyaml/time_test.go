@@ -0,0 +1,80 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNow(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-now
+runtime: yaml
+variables:
+  deployed:
+    fn::now: {}
+  again:
+    fn::now:
+  expires:
+    fn::timeAdd:
+      - ${deployed}
+      - 720h
+  earlier:
+    fn::timeAdd: [2024-03-01T12:00:00+02:00, -90m]
+`)
+	reads := 0
+	runner := newRunner(tmpl, newMockPackageMap())
+	runner.clock = func() time.Time {
+		reads++
+		return time.Date(2024, 2, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, diags := TypeCheck(runner)
+		requireNoErrors(t, tmpl, diags)
+		diags = runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-02-01T08:30:00Z", runner.variables["deployed"])
+	assert.Equal(t, "2024-02-01T08:30:00Z", runner.variables["again"])
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, "2024-03-02T08:30:00Z", runner.variables["expires"])
+	assert.Equal(t, "2024-03-01T10:30:00+02:00", runner.variables["earlier"])
+}
+
+func TestTimeErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-now
+runtime: yaml
+variables:
+  bad:
+    fn::timeAdd: [yesterday, 1d]
+`)
+	diags := testTemplateDiags(t, tmpl, nil)
+	require.True(t, diags.HasErrors())
+	require.Len(t, diags, 2)
+	assert.Equal(t, `"yesterday" is not an RFC3339 time, such as 2006-01-02T15:04:05Z`, diags[0].Summary)
+	assert.Equal(t, `"1d" is not a duration, such as 24h or -30m`, diags[1].Summary)
+
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-now
+runtime: yaml
+variables:
+  bad:
+    fn::now: [1]
+`))
+	require.NoError(t, err)
+	require.True(t, diags.HasErrors())
+	assert.Equal(t, "fn::now takes no arguments", diags[0].Summary)
+}
@@ -0,0 +1,141 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// UnknownTraceValue is recorded in a trace in place of a value that isn't known until resources
+// are created. Every secret is such a value, so secrets are never recorded.
+const UnknownTraceValue = "[unknown]"
+
+// A Trace records how the values of a template were computed. See ExplainTemplate.
+type Trace struct {
+	mutex sync.Mutex
+	// Steps are in the order that they completed. An expression completes after its arguments, so
+	// the steps of each node end with the node's own expression.
+	Steps []TraceStep `json:"steps"`
+}
+
+// A TraceStep is the evaluation of a reference, an interpolation or a builtin function.
+type TraceStep struct {
+	// Node is the config, variable, resource or output that the expression belongs to, such as
+	// "variables.name" or "resources.bucket".
+	Node string `json:"node"`
+	// Expr is the expression as written: the name of a builtin, such as fn::join, or the text of a
+	// reference or interpolation.
+	Expr  string          `json:"expr"`
+	File  string          `json:"file,omitempty"`
+	Start *syntax.JSONPos `json:"start,omitempty"`
+	End   *syntax.JSONPos `json:"end,omitempty"`
+	// Depth is how deeply the expression is nested within the expression of the node.
+	Depth int `json:"depth"`
+	// Value is the value of the expression, with unknown values replaced by UnknownTraceValue.
+	Value interface{} `json:"value,omitempty"`
+	// Failed is set if the expression failed to evaluate, in which case it has no value.
+	Failed bool `json:"failed,omitempty"`
+}
+
+func (t *Trace) add(steps ...TraceStep) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.Steps = append(t.Steps, steps...)
+}
+
+// ExplainTemplate runs a template like RunTemplate, and also returns a trace of how its values
+// were computed. Tracing is only done here, so RunTemplate doesn't pay for it.
+func ExplainTemplate(ctx *pulumi.Context, t *ast.TemplateDecl, config map[string]string,
+	configPropertyMap resource.PropertyMap, loader PackageLoader, opts ...RunnerOption,
+) (*Trace, error) {
+	trace := &Trace{}
+	return trace, runTemplate(ctx, t, config, configPropertyMap, loader, trace, opts)
+}
+
+// traceExpr evaluates x, and records the step in the runner's trace.
+func (e *programEvaluator) traceExpr(x ast.Expr) (interface{}, bool) {
+	var text string
+	switch x := x.(type) {
+	case *ast.SymbolExpr:
+		text = x.String()
+	case *ast.InterpolateExpr:
+		text = x.String()
+	case ast.BuiltinExpr:
+		text = x.Name().Value
+	default:
+		// Literals, lists and objects are recorded through what they contain.
+		return e.evaluateExprValue(x)
+	}
+
+	e.traceDepth++
+	v, ok := e.evaluateExprValue(x)
+	e.traceDepth--
+
+	step := TraceStep{
+		Node:   e.scopeName(),
+		Expr:   text,
+		Depth:  e.traceDepth,
+		Failed: !ok,
+	}
+	if ok {
+		step.Value = traceValue(v)
+	}
+	if s := x.Syntax(); s != nil && s.Syntax() != nil {
+		rng := s.Syntax().Range()
+		step.File = rng.Filename
+		step.Start = &syntax.JSONPos{Line: rng.Start.Line, Column: rng.Start.Column}
+		step.End = &syntax.JSONPos{Line: rng.End.Line, Column: rng.End.Column}
+	}
+	if e.pending == nil || !e.pending.holdStep(step) {
+		e.trace.add(step)
+	}
+	return v, ok
+}
+
+// scopeName is the name of the node being evaluated, as it is recorded in a trace.
+func (e *programEvaluator) scopeName() string {
+	switch root := e.scope.(type) {
+	case configNode:
+		return "config." + root.key().Value
+	case resourceNode:
+		_, name := logicalName(root.Key, root.Value)
+		if e.loop != nil {
+			name = loopResourceName(name, e.loop.suffix)
+		}
+		return "resources." + name
+	case variableNode:
+		return "variables." + root.Key.Value
+	case ast.PropertyMapEntry:
+		return "outputs." + root.Key.Value
+	}
+	return ""
+}
+
+// traceValue returns v as it is recorded in a trace.
+func traceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case pulumi.Output:
+		return UnknownTraceValue
+	case poisonMarker, lateboundResource:
+		return nil
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, elem := range v {
+			values[i] = traceValue(elem)
+		}
+		return values
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			values[k] = traceValue(elem)
+		}
+		return values
+	}
+	return v
+}
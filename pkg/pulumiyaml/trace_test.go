@@ -0,0 +1,92 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const traceTemplate = `
+name: test-trace
+runtime: yaml
+variables:
+  name: world
+  greeting: hello ${name}
+  joined:
+    fn::join: ["-", ["${greeting}", x]]
+  hidden:
+    fn::secret: shh
+  upper:
+    fn::upper: ${name}
+outputs:
+  out: ${joined}
+`
+
+func TestExplainTemplate(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, traceTemplate)
+	var trace *Trace
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		var err error
+		trace, err = ExplainTemplate(ctx, tmpl, nil, nil, newMockPackageMap())
+		return err
+	}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+	require.NoError(t, err)
+
+	var joined []TraceStep
+	for _, step := range trace.Steps {
+		if step.Node == "variables.joined" {
+			joined = append(joined, step)
+		}
+	}
+	require.Len(t, joined, 2)
+	assert.Equal(t, "${greeting}", joined[0].Expr)
+	assert.Equal(t, 1, joined[0].Depth)
+	assert.Equal(t, "hello world", joined[0].Value)
+	assert.Equal(t, "fn::join", joined[1].Expr)
+	assert.Equal(t, 0, joined[1].Depth)
+	assert.Equal(t, "hello world-x", joined[1].Value)
+	require.NotNil(t, joined[1].Start)
+	assert.Equal(t, 8, joined[1].Start.Line)
+
+	for _, step := range trace.Steps {
+		switch step.Node {
+		case "variables.hidden":
+			assert.Equal(t, UnknownTraceValue, step.Value)
+		case "outputs.out":
+			assert.Equal(t, "${joined}", step.Expr)
+			assert.Equal(t, "hello world-x", step.Value)
+		}
+	}
+}
+
+func TestTraceIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, traceTemplate)
+	run := func(parallelism int) []TraceStep {
+		runner := newRunner(tmpl, newMockPackageMap())
+		runner.parallelism = parallelism
+		runner.trace = &Trace{}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			requireNoErrors(t, tmpl, runner.Evaluate(ctx))
+			return nil
+		}, pulumi.WithMocks("project", "stack", &testMonitor{}))
+		require.NoError(t, err)
+		return runner.trace.Steps
+	}
+	serial := run(1)
+	assert.NotEmpty(t, serial)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, serial, run(8))
+	}
+
+	// Tracing is off unless it is asked for.
+	runner := newRunner(tmpl, newMockPackageMap())
+	assert.Nil(t, runner.trace)
+}
@@ -0,0 +1,86 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+var transforms = struct {
+	sync.RWMutex
+	byName map[string]pulumi.ResourceTransform
+}{byName: map[string]pulumi.ResourceTransform{}}
+
+// RegisterTransform makes transform available to templates as name, for use in the transforms
+// resource option:
+//
+//	resources:
+//	  bucket:
+//	    type: aws:s3:Bucket
+//	    options:
+//	      transforms: [defaultTags]
+//
+// Programs that embed the YAML runtime register their transforms before running templates.
+// Registering a name again replaces its transform, and registering a nil transform removes it.
+func RegisterTransform(name string, transform pulumi.ResourceTransform) {
+	transforms.Lock()
+	defer transforms.Unlock()
+	if transform == nil {
+		delete(transforms.byName, name)
+		return
+	}
+	transforms.byName[name] = transform
+}
+
+func lookupTransform(name string) (pulumi.ResourceTransform, bool) {
+	transforms.RLock()
+	defer transforms.RUnlock()
+	t, ok := transforms.byName[name]
+	return t, ok
+}
+
+func registeredTransforms() []string {
+	transforms.RLock()
+	defer transforms.RUnlock()
+	names := make([]string, 0, len(transforms.byName))
+	for name := range transforms.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkTransforms reports the names in a transforms resource option that aren't registered.
+func checkTransforms(ctx *evalContext, names *ast.StringListDecl) {
+	for _, name := range names.GetElements() {
+		if _, ok := lookupTransform(name.Value); ok {
+			continue
+		}
+		detail := "No transforms are registered; they are registered with pulumiyaml.RegisterTransform by the program running the template"
+		if registered := registeredTransforms(); len(registered) > 0 {
+			detail = "Registered transforms are " + strings.Join(registered, ", ")
+		}
+		ctx.addErrDiag(name.Syntax().Syntax().Range(), fmt.Sprintf("unknown transform %q", name.Value), detail)
+	}
+}
+
+// resourceTransforms looks up the transforms named by a transforms resource option.
+func (e *programEvaluator) resourceTransforms(names *ast.StringListDecl) ([]pulumi.ResourceTransform, bool) {
+	result := make([]pulumi.ResourceTransform, 0, len(names.GetElements()))
+	for _, name := range names.GetElements() {
+		t, ok := lookupTransform(name.Value)
+		if !ok {
+			e.error(name, fmt.Sprintf("unknown transform %q", name.Value))
+			return nil, false
+		}
+		result = append(result, t)
+	}
+	return result, true
+}
@@ -0,0 +1,103 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultTags is a transform that adds an owner tag to resources that don't have one.
+func defaultTags(_ context.Context, args *pulumi.ResourceTransformArgs) *pulumi.ResourceTransformResult {
+	tags := pulumi.StringMap{"owner": pulumi.String("platform")}
+	if existing, ok := args.Props["tags"].(pulumi.StringMap); ok {
+		for k, v := range existing {
+			tags[k] = v
+		}
+	}
+	args.Props["tags"] = tags
+	return &pulumi.ResourceTransformResult{Props: args.Props, Opts: args.Opts}
+}
+
+func TestTransforms(t *testing.T) {
+	t.Parallel()
+
+	RegisterTransform("test-transforms-default-tags", defaultTags)
+	t.Cleanup(func() { RegisterTransform("test-transforms-default-tags", nil) })
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  tagged:
+    type: test:resource:type
+    properties:
+      foo: bar
+    options:
+      transforms: [test-transforms-default-tags]
+  untagged:
+    type: test:resource:type
+    properties:
+      foo: bar
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+
+	mocks := &testMonitor{
+		NewResourceF: func(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+			switch args.Name {
+			case "tagged":
+				assert.Len(t, args.RegisterRPC.Transforms, 1)
+			case "untagged":
+				assert.Empty(t, args.RegisterRPC.Transforms)
+			default:
+				return "", nil, fmt.Errorf("unexpected resource %q", args.Name)
+			}
+			return args.Name, args.Inputs, nil
+		},
+	}
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		runner := newRunner(tmpl, newMockPackageMap())
+		diags := runner.Evaluate(ctx)
+		requireNoErrors(t, tmpl, diags)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	require.NoError(t, err)
+
+	transform, ok := lookupTransform("test-transforms-default-tags")
+	require.True(t, ok)
+	result := transform(context.Background(), &pulumi.ResourceTransformArgs{
+		Props: pulumi.Map{"tags": pulumi.StringMap{"team": pulumi.String("yaml")}},
+	})
+	assert.Equal(t, pulumi.StringMap{
+		"owner": pulumi.String("platform"),
+		"team":  pulumi.String("yaml"),
+	}, result.Props["tags"])
+}
+
+func TestTransformsUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-yaml
+runtime: yaml
+resources:
+  res:
+    type: test:resource:type
+    properties:
+      foo: bar
+    options:
+      transforms: [test-transforms-missing]
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	require.Len(t, diags, 1)
+	// The detail lists the registered transforms, which depend on the tests running alongside.
+	assert.Equal(t, `unknown transform "test-transforms-missing"`, diags[0].Summary)
+	assert.Equal(t, 10, diags[0].Subject.Start.Line)
+}
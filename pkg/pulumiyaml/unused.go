@@ -0,0 +1,88 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/syntax"
+)
+
+// When set, type checking also warns about config, variables and resources that are never used.
+var warnUnused = cmdutil.IsTruthy(os.Getenv("PULUMI_YAML_WARN_UNUSED"))
+
+// checkUnused returns a warning for each config entry, variable and resource read with 'get'
+// that no expression, output or resource option refers to. Other resources are left alone, as
+// creating them is the point of declaring them, and so is namespaced config such as aws:region,
+// which is read by providers.
+func checkUnused(t *ast.TemplateDecl) syntax.Diagnostics {
+	configEntries := [][]ast.ConfigMapEntry{t.Configuration.Entries, t.Config.Entries}
+
+	var deps []*ast.StringExpr
+	for _, entries := range configEntries {
+		for _, kvp := range entries {
+			if kvp.Value != nil {
+				getExpressionDependencies(&deps, kvp.Value.Default)
+			}
+		}
+	}
+	for _, kvp := range t.Variables.Entries {
+		deps = append(deps, GetVariableDependencies(kvp)...)
+	}
+	for _, kvp := range t.Resources.Entries {
+		if kvp.Value != nil {
+			deps = append(deps, GetResourceDependencies(kvp.Value)...)
+			deps = append(deps, typeDependencies(kvp.Value)...)
+		}
+	}
+	for _, kvp := range (&Runner{t: t}).outputs() {
+		getExpressionDependencies(&deps, kvp.Value)
+	}
+	for _, kvp := range t.DefaultTags.Entries {
+		getExpressionDependencies(&deps, kvp.Value)
+	}
+
+	used := map[string]bool{}
+	for _, dep := range deps {
+		used[dep.Value] = true
+		if t.Name != nil {
+			used[stripConfigNamespace(t.Name.Value, dep.Value)] = true
+		}
+	}
+
+	var diags syntax.Diagnostics
+	unused := func(kind string, key *ast.StringExpr, detail string) {
+		if used[key.Value] {
+			return
+		}
+		var rng *hcl.Range
+		if s := key.Syntax(); s != nil {
+			rng = s.Syntax().Range()
+		}
+		diags.Extend(syntax.Warning(rng, fmt.Sprintf("%s %q is never used", kind, key.Value), detail))
+	}
+	for _, entries := range configEntries {
+		for _, kvp := range entries {
+			if strings.Contains(kvp.Key.Value, ":") {
+				continue
+			}
+			unused("config", kvp.Key, "Remove it, or refer to it with ${"+kvp.Key.Value+"}")
+		}
+	}
+	for _, kvp := range t.Variables.Entries {
+		unused("variable", kvp.Key, "Remove it, or refer to it with ${"+kvp.Key.Value+"}")
+	}
+	for _, kvp := range t.Resources.Entries {
+		if kvp.Value == nil || kvp.Value.Get.Id == nil {
+			continue
+		}
+		unused("resource", kvp.Key, "Resources read with 'get' have no effect unless they are used")
+	}
+	return diags
+}
@@ -0,0 +1,83 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUnused(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+configuration:
+  prefix:
+    type: String
+  unusedConfig:
+    type: String
+  suffix:
+    default: ${test-yaml:prefix}-data
+  aws:region:
+    type: String
+variables:
+  bucketName: ${prefix}-${suffix}
+  unusedVariable: 42
+  vpcId: ${existing.id}
+resources:
+  bucket:
+    type: test:resource:type
+    properties:
+      foo: ${bucketName}
+  unusedBucket:
+    type: test:resource:type
+  existing:
+    type: test:resource:type
+    get:
+      id: vpc-1234
+  unusedExisting:
+    type: test:resource:type
+    get:
+      id: vpc-5678
+outputs:
+  vpc: ${vpcId}
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	diags := checkUnused(tmpl)
+	assert.False(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`<stdin>:6:3: config "unusedConfig" is never used; Remove it, or refer to it with ${unusedConfig}`,
+		`<stdin>:14:3: variable "unusedVariable" is never used; Remove it, or refer to it with ${unusedVariable}`,
+		`<stdin>:27:3: resource "unusedExisting" is never used; Resources read with 'get' have no effect unless they are used`,
+	}, diagStrings(diags))
+}
+
+func TestCheckUnusedConfig(t *testing.T) {
+	t.Parallel()
+
+	const text = `
+name: test-yaml
+runtime: yaml
+config:
+  prefix:
+    type: string
+  unusedConfig:
+    type: string
+  aws:region:
+    type: string
+variables:
+  bucketName: ${prefix}-data
+outputs:
+  name: ${bucketName}
+`
+	tmpl := yamlTemplate(t, strings.TrimSpace(text))
+	diags := checkUnused(tmpl)
+	assert.False(t, diags.HasErrors())
+	assert.Equal(t, []string{
+		`<stdin>:6:3: config "unusedConfig" is never used; Remove it, or refer to it with ${unusedConfig}`,
+	}, diagStrings(diags))
+}
@@ -0,0 +1,112 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// A VersionLister lists the versions of the package name that are available to load.
+type VersionLister func(ctx context.Context, name string) ([]semver.Version, error)
+
+// InstalledPluginVersions lists the versions of the resource plugin name that are installed.
+func InstalledPluginVersions(ctx context.Context, name string) ([]semver.Version, error) {
+	plugins, err := workspace.GetPlugins()
+	if err != nil {
+		return nil, err
+	}
+	var versions []semver.Version
+	for _, p := range plugins {
+		if p.Kind == apitype.ResourcePlugin && p.Name == name && p.Version != nil {
+			versions = append(versions, *p.Version)
+		}
+	}
+	return versions, nil
+}
+
+// A VersionRangeResolver resolves semver ranges, such as ">=4.0.0 <5.0.0", to the highest
+// available version of a package that satisfies them, so that the package can be loaded at that
+// version. Resolutions are cached by package and range, so a range that is repeated throughout a
+// template only lists the versions of the package once. A resolver is meant to be used for a
+// single run: versions installed after a range is resolved aren't seen.
+//
+// A VersionRangeResolver is safe for concurrent use.
+type VersionRangeResolver struct {
+	list VersionLister
+
+	m        sync.Mutex
+	resolved map[versionRangeKey]*versionRangeEntry
+}
+
+type versionRangeKey struct {
+	name, versionRange string
+}
+
+// versionRangeEntry is the resolution of a range that is shared with identical resolutions. done
+// is closed once version and err are set.
+type versionRangeEntry struct {
+	done    chan struct{}
+	version semver.Version
+	err     error
+}
+
+// NewVersionRangeResolver creates a VersionRangeResolver that lists the versions of packages
+// with list.
+func NewVersionRangeResolver(list VersionLister) *VersionRangeResolver {
+	return &VersionRangeResolver{
+		list:     list,
+		resolved: map[versionRangeKey]*versionRangeEntry{},
+	}
+}
+
+// Resolve returns the highest version of the package name that satisfies versionRange.
+func (r *VersionRangeResolver) Resolve(ctx context.Context, name, versionRange string) (semver.Version, error) {
+	key := versionRangeKey{name, versionRange}
+	r.m.Lock()
+	entry, ok := r.resolved[key]
+	if !ok {
+		entry = &versionRangeEntry{done: make(chan struct{})}
+		r.resolved[key] = entry
+	}
+	r.m.Unlock()
+
+	if ok {
+		select {
+		case <-entry.done:
+			return entry.version, entry.err
+		case <-ctx.Done():
+			return semver.Version{}, ctx.Err()
+		}
+	}
+
+	entry.version, entry.err = r.resolve(ctx, name, versionRange)
+	close(entry.done)
+	return entry.version, entry.err
+}
+
+func (r *VersionRangeResolver) resolve(ctx context.Context, name, versionRange string) (semver.Version, error) {
+	satisfies, err := semver.ParseRange(versionRange)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid version range %q: %w", versionRange, err)
+	}
+	versions, err := r.list(ctx, name)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("listing the versions of package %s: %w", name, err)
+	}
+	var best *semver.Version
+	for i, v := range versions {
+		if satisfies(v) && (best == nil || v.GT(*best)) {
+			best = &versions[i]
+		}
+	}
+	if best == nil {
+		return semver.Version{}, fmt.Errorf("no version of package %s satisfies %q", name, versionRange)
+	}
+	return *best, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingVersionLister lists versions, and counts how many times each package is listed.
+type countingVersionLister struct {
+	versions map[string][]semver.Version
+	calls    sync.Map // map[string]*int32
+}
+
+func (l *countingVersionLister) list(ctx context.Context, name string) ([]semver.Version, error) {
+	calls, _ := l.calls.LoadOrStore(name, new(int32))
+	atomic.AddInt32(calls.(*int32), 1)
+	versions, ok := l.versions[name]
+	if !ok {
+		return nil, errors.New("package not found")
+	}
+	return versions, nil
+}
+
+func (l *countingVersionLister) count(name string) int32 {
+	calls, ok := l.calls.Load(name)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadInt32(calls.(*int32))
+}
+
+func TestVersionRangeResolver(t *testing.T) {
+	t.Parallel()
+
+	lister := &countingVersionLister{versions: map[string][]semver.Version{
+		"aws":    {semver.MustParse("4.1.0"), semver.MustParse("4.12.3"), semver.MustParse("5.0.0")},
+		"random": {semver.MustParse("4.2.0")},
+	}}
+	r := NewVersionRangeResolver(lister.list)
+	ctx := context.Background()
+
+	// Repeated identical ranges only list the versions of the package once.
+	for i := 0; i < 3; i++ {
+		v, err := r.Resolve(ctx, "aws", ">=4.0.0 <5.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "4.12.3", v.String())
+	}
+	assert.Equal(t, int32(1), lister.count("aws"))
+
+	// Other ranges are resolved separately.
+	v, err := r.Resolve(ctx, "aws", ">=4.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "5.0.0", v.String())
+	assert.Equal(t, int32(2), lister.count("aws"))
+
+	// So are other packages, even with the same range.
+	v, err = r.Resolve(ctx, "random", ">=4.0.0 <5.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "4.2.0", v.String())
+	assert.Equal(t, int32(1), lister.count("random"))
+
+	// Failures are cached as well.
+	for i := 0; i < 2; i++ {
+		_, err = r.Resolve(ctx, "aws", ">=6.0.0")
+		assert.EqualError(t, err, `no version of package aws satisfies ">=6.0.0"`)
+		_, err = r.Resolve(ctx, "missing", ">=1.0.0")
+		assert.EqualError(t, err, "listing the versions of package missing: package not found")
+	}
+	assert.Equal(t, int32(3), lister.count("aws"))
+	assert.Equal(t, int32(1), lister.count("missing"))
+
+	// Invalid ranges don't list any versions.
+	_, err = r.Resolve(ctx, "other", "not a range")
+	assert.ErrorContains(t, err, `invalid version range "not a range"`)
+	assert.Equal(t, int32(0), lister.count("other"))
+}
+
+func TestVersionRangeResolverConcurrent(t *testing.T) {
+	t.Parallel()
+
+	lister := &countingVersionLister{versions: map[string][]semver.Version{
+		"aws": {semver.MustParse("4.1.0"), semver.MustParse("4.12.3")},
+	}}
+	r := NewVersionRangeResolver(lister.list)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := r.Resolve(context.Background(), "aws", ">=4.0.0 <5.0.0")
+			assert.NoError(t, err)
+			assert.Equal(t, "4.12.3", v.String())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), lister.count("aws"))
+}
+
+func BenchmarkVersionRangeResolver(b *testing.B) {
+	var versions []semver.Version
+	for major := uint64(1); major <= 5; major++ {
+		for minor := uint64(0); minor < 40; minor++ {
+			versions = append(versions, semver.Version{Major: major, Minor: minor})
+		}
+	}
+	list := func(ctx context.Context, name string) ([]semver.Version, error) {
+		return versions, nil
+	}
+	ranges := make([]string, 10)
+	for i := range ranges {
+		ranges[i] = fmt.Sprintf(">=4.%d.0 <5.0.0", i)
+	}
+
+	// A template resolves each of its ranges many times, once for each resource that uses it.
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := NewVersionRangeResolver(list)
+			for j := 0; j < 100; j++ {
+				_, err := r.Resolve(context.Background(), "aws", ranges[j%len(ranges)])
+				require.NoError(b, err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < 100; j++ {
+				_, err := NewVersionRangeResolver(list).Resolve(context.Background(), "aws", ranges[j%len(ranges)])
+				require.NoError(b, err)
+			}
+		}
+	})
+}
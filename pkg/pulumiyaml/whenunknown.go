@@ -0,0 +1,47 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-yaml/pkg/pulumiyaml/ast"
+)
+
+// applyWhenUnknown applies the fn::whenUnknown policy of an output whose value is x, if it has
+// one, to the evaluated value out during a preview:
+//
+//	outputs:
+//	  url:
+//	    fn::whenUnknown:
+//	      value: ${site.url}
+//	      substitute: pending
+//	  address:
+//	    fn::whenUnknown:
+//	      value: ${site.address}
+//	      omit: true
+//
+// It returns the value to export, and false if the output isn't exported.
+func (e *programEvaluator) applyWhenUnknown(x ast.Expr, out pulumi.Input) (pulumi.Input, bool) {
+	policy, ok := x.(*ast.WhenUnknownExpr)
+	if !ok || !e.pulumiCtx.DryRun() {
+		return out, true
+	}
+	o, isOutput := out.(pulumi.Output)
+	if !isOutput {
+		return out, true
+	}
+	result, err := internals.UnsafeAwaitOutput(e.pulumiCtx.Context(), o)
+	if err != nil || result.Known {
+		return out, true
+	}
+	if policy.Omit {
+		return nil, false
+	}
+	value, ok := e.evaluateExpr(policy.Substitute)
+	if !ok {
+		return out, true
+	}
+	return pulumi.Any(value), true
+}
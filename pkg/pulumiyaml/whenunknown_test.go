@@ -0,0 +1,166 @@
+// Copyright 2026, Pulumi Corporation.  All rights reserved.
+
+package pulumiyaml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const whenUnknownTemplate = `
+name: test-when-unknown
+runtime: yaml
+resources:
+  site:
+    type: test:resource:type
+    properties:
+      foo: bar
+outputs:
+  url:
+    fn::whenUnknown:
+      value: ${site.bar}
+      substitute: pending
+  address:
+    fn::whenUnknown:
+      value: ${site.bar}
+      omit: true
+  other: ${site.bar}
+`
+
+func TestOutputsWhenUnknown(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, whenUnknownTemplate)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	requireNoErrors(t, tmpl, diags)
+	assert.Empty(t, diags)
+
+	type exported struct {
+		value    interface{}
+		known    bool
+		exported bool
+	}
+	run := func(preview bool, value pulumi.Input) map[string]exported {
+		results := map[string]exported{}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			runner := newRunner(tmpl, newMockPackageMap())
+			diags := runner.Evaluate(ctx)
+			requireNoErrors(t, tmpl, diags)
+
+			e := &programEvaluator{evalContext: runner.newContext(nil), pulumiCtx: ctx}
+			for _, kvp := range runner.outputs() {
+				name := kvp.Key.Value
+				out, ok := e.applyWhenUnknown(kvp.Value, value)
+				if !ok {
+					results[name] = exported{}
+					continue
+				}
+				result, err := internals.UnsafeAwaitOutput(context.Background(), out.(pulumi.Output))
+				require.NoError(t, err)
+				results[name] = exported{value: result.Value, known: result.Known, exported: true}
+			}
+			return nil
+		}, pulumi.WithMocks("project", "stack", &testMonitor{}), func(ri *pulumi.RunInfo) {
+			ri.DryRun = preview
+		})
+		require.NoError(t, err)
+		return results
+	}
+
+	// Unknown outputs are substituted or omitted during a preview.
+	assert.Equal(t, map[string]exported{
+		"url":     {value: "pending", known: true, exported: true},
+		"address": {},
+		"other":   {exported: true},
+	}, run(true, pulumi.UnsafeUnknownOutput(nil)))
+
+	// Known outputs are exported as they are.
+	assert.Equal(t, map[string]exported{
+		"url":     {value: "https://example.com", known: true, exported: true},
+		"address": {value: "https://example.com", known: true, exported: true},
+		"other":   {value: "https://example.com", known: true, exported: true},
+	}, run(true, pulumi.Any("https://example.com")))
+
+	// Updates always export the real value.
+	assert.Equal(t, map[string]exported{
+		"url":     {exported: true},
+		"address": {exported: true},
+		"other":   {exported: true},
+	}, run(false, pulumi.UnsafeUnknownOutput(nil)))
+}
+
+func TestOutputsWhenUnknownInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, diags, err := LoadYAMLBytes("<stdin>", []byte(`
+name: test-when-unknown
+runtime: yaml
+variables:
+  fallback: pending
+outputs:
+  a:
+    fn::whenUnknown: omit
+  b:
+    fn::whenUnknown:
+      value: 1
+      omit: false
+  c:
+    fn::whenUnknown:
+      value: 1
+      substitute: ${fallback}
+  d:
+    fn::whenUnknown:
+      value: 1
+      replace: x
+  e:
+    fn::whenUnknown:
+      substitute: [ok, {also: ok}]
+  f:
+    fn::whenUnknown:
+      value: 1
+      substitute: x
+      omit: true
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`<stdin>:8:22: the argument to fn::whenUnknown must be an object containing 'value' and either 'substitute' or 'omit'`,
+		`<stdin>:12:13: omit must be true; Use either 'substitute: <value>' or 'omit: true' alongside 'value'`,
+		`<stdin>:16:19: the substitute must be a literal value; ` +
+			`It may not refer to resources, variables or config, or call functions`,
+		`<stdin>:20:7: unknown property "replace"; fn::whenUnknown accepts 'value', 'substitute' and 'omit'`,
+		`<stdin>:19:7: missing what to export while the value is unknown; ` +
+			`Use either 'substitute: <value>' or 'omit: true' alongside 'value'`,
+		`<stdin>:23:7: missing the value of the output ('value')`,
+		`<stdin>:28:13: 'substitute' and 'omit' can't be used together; ` +
+			`Use either 'substitute: <value>' or 'omit: true' alongside 'value'`,
+	}, diagStrings(diags))
+}
+
+func TestWhenUnknownOutsideOutputs(t *testing.T) {
+	t.Parallel()
+
+	tmpl := yamlTemplate(t, `
+name: test-when-unknown
+runtime: yaml
+variables:
+  v:
+    fn::whenUnknown:
+      value: 1
+      omit: true
+outputs:
+  o:
+    - fn::whenUnknown:
+        value: 1
+        omit: true
+`)
+	_, diags := TypeCheck(newRunner(tmpl, newMockPackageMap()))
+	assert.Equal(t, []string{
+		`<stdin>:6:5: fn::whenUnknown can only be used as the value of an output`,
+		`<stdin>:11:7: fn::whenUnknown can only be used as the value of an output`,
+	}, diagStrings(diags))
+}
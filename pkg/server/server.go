@@ -27,10 +27,13 @@ import (
 	"strings"
 
 	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
@@ -143,11 +146,18 @@ func (host *yamlLanguageHost) GetRequiredPackages(ctx context.Context,
 			}
 		}
 
+		// The engine verifies the plugin's download against its checksums, if there are any.
+		checksums, err := pkg.GetChecksums()
+		if err != nil {
+			return nil, fmt.Errorf("decoding checksums for package %s: %w", pkg.Name, err)
+		}
+
 		packages = append(packages, &pulumirpc.PackageDependency{
 			Kind:             string(apitype.ResourcePlugin),
 			Name:             pkg.Name,
 			Version:          pkg.Version,
 			Server:           pkg.DownloadURL,
+			Checksums:        checksums,
 			Parameterization: parameterization,
 		})
 	}
@@ -156,6 +166,44 @@ func (host *yamlLanguageHost) GetRequiredPackages(ctx context.Context,
 	}, nil
 }
 
+// When set, diagnostics are written to stderr as a JSON array instead of rendered text, so that
+// tooling can consume them without scraping.
+var jsonDiagnostics = cmdutil.IsTruthy(os.Getenv("PULUMI_YAML_JSON_DIAGNOSTICS"))
+
+func writeDiagnostics(diagWriter hcl.DiagnosticWriter, diags syntax.Diagnostics) error {
+	if !jsonDiagnostics {
+		return diagWriter.WriteDiagnostics(diags.HCL())
+	}
+	bytes, err := syntax.MarshalDiagnostics(diags)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stderr, "%s\n", bytes)
+	return err
+}
+
+// When set, the program is run with ExplainTemplate, and the trace of how its values were computed
+// is written to this file as JSON.
+var explainFile = os.Getenv("PULUMI_YAML_EXPLAIN")
+
+// runTemplate runs the template, and writes its trace to explainFile if it is set.
+func runTemplate(ctx *pulumi.Context, template *ast.TemplateDecl, config map[string]string,
+	configPropertyMap resource.PropertyMap, loader pulumiyaml.PackageLoader,
+) error {
+	if explainFile == "" {
+		return pulumiyaml.RunTemplate(ctx, template, config, configPropertyMap, loader)
+	}
+	trace, err := pulumiyaml.ExplainTemplate(ctx, template, config, configPropertyMap, loader)
+	bytes, jsonErr := json.MarshalIndent(trace, "", "  ")
+	if jsonErr != nil {
+		return jsonErr
+	}
+	if writeErr := os.WriteFile(explainFile, bytes, 0o600); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
 // RPC endpoint for LanguageRuntimeServer::Run. This actually evaluates the JSON-based project.
 func (host *yamlLanguageHost) Run(ctx context.Context, req *pulumirpc.RunRequest) (*pulumirpc.RunResponse, error) {
 	configValue := req.GetConfig()
@@ -187,7 +235,7 @@ func (host *yamlLanguageHost) Run(ctx context.Context, req *pulumirpc.RunRequest
 
 	diagWriter := template.NewDiagnosticWriter(os.Stderr, 0, true)
 	if len(diags) != 0 {
-		err := diagWriter.WriteDiagnostics(diags.HCL())
+		err := writeDiagnostics(diagWriter, diags)
 		if err != nil {
 			return nil, err
 		}
@@ -251,10 +299,10 @@ func (host *yamlLanguageHost) Run(ctx context.Context, req *pulumirpc.RunRequest
 	// Now instruct the Pulumi Go SDK to run the pulumi YAML interpreter.
 	if err := pulumi.RunWithContext(pctx, func(ctx *pulumi.Context) error {
 		// Now "evaluate" the template.
-		return pulumiyaml.RunTemplate(pctx, template, req.GetConfig(), confPropMap, loader)
+		return runTemplate(pctx, template, req.GetConfig(), confPropMap, loader)
 	}); err != nil {
 		if diags, ok := pulumiyaml.HasDiagnostics(err); ok {
-			err := diagWriter.WriteDiagnostics(diags.Unshown().HCL())
+			err := writeDiagnostics(diagWriter, *diags.Unshown())
 			if err != nil {
 				return nil, err
 			}
@@ -56,6 +56,15 @@ func TestInvalidResourceObject(t *testing.T) {
 	})
 }
 
+//nolint:paralleltest // uses parallel programtest
+func TestDuplicateResource(t *testing.T) {
+	testWrapper(t, integrationDir("duplicate-resource"), ExpectFailure, StderrValidator{
+		f: func(t *testing.T, stderr string) {
+			assert.Contains(t, stderr, `found duplicate key "bucket" in resources`)
+		},
+	})
+}
+
 //nolint:paralleltest // uses parallel programtest
 func TestMismatchedConfigType(t *testing.T) {
 	testWrapper(t, integrationDir("mismatched-config-type"), ExpectFailure, StderrValidator{
@@ -70,9 +79,9 @@ func TestProjectConfigRef(t *testing.T) {
 	testWrapper(t, integrationDir("project-config-ref"), ExpectFailure, StderrValidator{
 		f: func(t *testing.T, stderr string) {
 			assert.Contains(t, stderr,
-				`resource, variable, or config value "wrong-namespace:foo" not found`)
+				`unknown reference "wrong-namespace:foo"`)
 			assert.False(t, strings.Contains(stderr,
-				`resource, variable, or config value "project-config-ref:foo" not found`))
+				`unknown reference "project-config-ref:foo"`))
 		},
 	})
 }